@@ -0,0 +1,145 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const CableKind = "Cable"
+
+// CableModel is the name of the Netbox model in webhook payloads
+const CableModel = "cable"
+
+// CableTermination is one end of a cable, either a port of a device or a circuit termination
+type CableTermination struct {
+	// Name of the Netbox Device of the interface, front port or rear port
+	// +kubebuilder:validation:MaxLength=64
+	// +kubebuilder:validation:Optional
+	Device string `json:"device,omitempty"`
+
+	// Name of an interface of the device
+	// +kubebuilder:validation:MaxLength=64
+	// +kubebuilder:validation:Optional
+	Interface string `json:"interface,omitempty"`
+
+	// Name of a front port of the device
+	// +kubebuilder:validation:MaxLength=64
+	// +kubebuilder:validation:Optional
+	FrontPort string `json:"front_port,omitempty"`
+
+	// Name of a rear port of the device
+	// +kubebuilder:validation:MaxLength=64
+	// +kubebuilder:validation:Optional
+	RearPort string `json:"rear_port,omitempty"`
+
+	// Circuit ID of a Netbox Circuit, terminated on the side set by term_side
+	// +kubebuilder:validation:MaxLength=100
+	// +kubebuilder:validation:Optional
+	Circuit string `json:"circuit,omitempty"`
+
+	// Side of the circuit termination, defaults to A
+	// +kubebuilder:validation:Enum=A;Z
+	// +kubebuilder:validation:Optional
+	TermSide string `json:"term_side,omitempty"`
+}
+
+// CableSpec defines the desired state of Netbox Cable
+type CableSpec struct {
+	// +required
+	TerminationA CableTermination `json:"termination_a"`
+
+	// +required
+	TerminationB CableTermination `json:"termination_b"`
+
+	// Netbox cable type, e.g. cat6 or smf
+	// +kubebuilder:validation:Enum=cat3;cat5;cat5e;cat6;cat6a;cat7;cat7a;cat8;dac-active;dac-passive;mrj21-trunk;coaxial;mmf;mmf-om1;mmf-om2;mmf-om3;mmf-om4;mmf-om5;smf;smf-os1;smf-os2;aoc;power
+	// +kubebuilder:validation:Optional
+	Type string `json:"type,omitempty"`
+
+	// Netbox status of the cable, Netbox defaults to connected
+	// +kubebuilder:validation:Enum=connected;planned;decommissioning
+	// +kubebuilder:validation:Optional
+	Status string `json:"status,omitempty"`
+
+	// Color as a hex RGB code, e.g. 2196f3
+	// +kubebuilder:validation:Pattern=`^[0-9a-f]{6}$`
+	// +kubebuilder:validation:Optional
+	Color string `json:"color,omitempty"`
+
+	// +kubebuilder:validation:MaxLength=100
+	// +kubebuilder:validation:Optional
+	Label string `json:"label,omitempty"`
+
+	// Length of the cable in length_unit
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Optional
+	Length int64 `json:"length,omitempty"`
+
+	// +kubebuilder:validation:Enum=km;m;cm;mi;ft;in
+	// +kubebuilder:validation:Optional
+	LengthUnit string `json:"length_unit,omitempty"`
+
+	// Slugs of existing Netbox Tags. If set, they replace the tags of the Netbox cable
+	// +kubebuilder:validation:Optional
+	Tags []string `json:"tags,omitempty"`
+
+	// What happens to the Netbox cable when this resource is deleted.
+	// Defaults to the controller's default deletion policy
+	// +kubebuilder:validation:Optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="ID",type=string,JSONPath=`.status.id`
+// +kubebuilder:printcolumn:name="A",type=string,JSONPath=`.spec.termination_a.device`
+// +kubebuilder:printcolumn:name="B",type=string,JSONPath=`.spec.termination_b.device`
+// +kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.spec.status`
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+
+// Cable is the Schema for the cables API
+type Cable struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CableSpec    `json:"spec,omitempty"`
+	Status ObjectStatus `json:"status,omitempty"`
+}
+
+// GetObjectStatus returns the status of the cable
+func (c *Cable) GetObjectStatus() *ObjectStatus {
+	return &c.Status
+}
+
+// GetDeletionPolicy returns the deletion policy of the cable
+func (c *Cable) GetDeletionPolicy() DeletionPolicy {
+	return c.Spec.DeletionPolicy
+}
+
+//+kubebuilder:object:root=true
+
+// CableList contains a list of Cable
+type CableList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Cable `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Cable{}, &CableList{})
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const ClusterKind = "Cluster"
+
+// ClusterModel is the name of the Netbox model in webhook payloads
+const ClusterModel = "cluster"
+
+// ClusterSpec defines the desired state of Netbox Cluster
+type ClusterSpec struct {
+	// Name of the cluster, defaults to the name of the resource
+	// +kubebuilder:validation:MaxLength=100
+	// +kubebuilder:validation:Optional
+	Name string `json:"name,omitempty"`
+
+	// Name of an existing Netbox Cluster Type
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=100
+	// +required
+	Type string `json:"type"`
+
+	// Name of an existing Netbox Site
+	// +kubebuilder:validation:MaxLength=100
+	// +kubebuilder:validation:Optional
+	Site string `json:"site,omitempty"`
+
+	// Name of an existing Netbox Tenant
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Optional
+	Tenant string `json:"tenant,omitempty"`
+
+	// Slugs of existing Netbox Tags. If set, they replace the tags of the Netbox cluster
+	// +kubebuilder:validation:Optional
+	Tags []string `json:"tags,omitempty"`
+
+	// What happens to the Netbox cluster when this resource is deleted.
+	// Defaults to the controller's default deletion policy
+	// +kubebuilder:validation:Optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="ID",type=string,JSONPath=`.status.id`
+// +kubebuilder:printcolumn:name="Name",type=string,JSONPath=`.spec.name`
+// +kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.type`
+// +kubebuilder:printcolumn:name="Site",type=string,JSONPath=`.spec.site`
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+
+// Cluster is the Schema for the clusters API
+type Cluster struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterSpec  `json:"spec,omitempty"`
+	Status ObjectStatus `json:"status,omitempty"`
+}
+
+// ClusterName returns the name of the cluster in Netbox
+func (c *Cluster) ClusterName() string {
+	if c.Spec.Name != "" {
+		return c.Spec.Name
+	}
+	return c.Name
+}
+
+// GetObjectStatus returns the status of the cluster
+func (c *Cluster) GetObjectStatus() *ObjectStatus {
+	return &c.Status
+}
+
+// GetDeletionPolicy returns the deletion policy of the cluster
+func (c *Cluster) GetDeletionPolicy() DeletionPolicy {
+	return c.Spec.DeletionPolicy
+}
+
+// GetTenant returns the tenant of the cluster
+func (c *Cluster) GetTenant() string {
+	return c.Spec.Tenant
+}
+
+// SetTenant sets the tenant of the cluster
+func (c *Cluster) SetTenant(tenant string) {
+	c.Spec.Tenant = tenant
+}
+
+//+kubebuilder:object:root=true
+
+// ClusterList contains a list of Cluster
+type ClusterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Cluster `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Cluster{}, &ClusterList{})
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const ClusterTypeKind = "ClusterType"
+
+// ClusterTypeModel is the name of the Netbox model in webhook payloads
+const ClusterTypeModel = "clustertype"
+
+// ClusterTypeSpec defines the desired state of Netbox Cluster Type
+type ClusterTypeSpec struct {
+	// Name of the cluster type, defaults to the name of the resource
+	// +kubebuilder:validation:MaxLength=100
+	// +kubebuilder:validation:Optional
+	Name string `json:"name,omitempty"`
+
+	// Slug of the cluster type, defaults to the name in lower case with dashes
+	// +kubebuilder:validation:MaxLength=100
+	// +kubebuilder:validation:Pattern=`^[-a-zA-Z0-9_]+$`
+	// +kubebuilder:validation:Optional
+	Slug string `json:"slug,omitempty"`
+
+	// +kubebuilder:validation:MaxLength=200
+	// +kubebuilder:validation:Optional
+	Description string `json:"description,omitempty"`
+
+	// What happens to the Netbox cluster type when this resource is deleted.
+	// Defaults to the controller's default deletion policy
+	// +kubebuilder:validation:Optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="ID",type=string,JSONPath=`.status.id`
+// +kubebuilder:printcolumn:name="Name",type=string,JSONPath=`.spec.name`
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+
+// ClusterType is the Schema for the clustertypes API. Netbox can't tag cluster types,
+// so an existing cluster type with the same name is adopted
+type ClusterType struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterTypeSpec `json:"spec,omitempty"`
+	Status ObjectStatus    `json:"status,omitempty"`
+}
+
+// ClusterTypeName returns the name of the cluster type in Netbox
+func (t *ClusterType) ClusterTypeName() string {
+	if t.Spec.Name != "" {
+		return t.Spec.Name
+	}
+	return t.Name
+}
+
+// GetObjectStatus returns the status of the cluster type
+func (t *ClusterType) GetObjectStatus() *ObjectStatus {
+	return &t.Status
+}
+
+// GetDeletionPolicy returns the deletion policy of the cluster type
+func (t *ClusterType) GetDeletionPolicy() DeletionPolicy {
+	return t.Spec.DeletionPolicy
+}
+
+//+kubebuilder:object:root=true
+
+// ClusterTypeList contains a list of ClusterType
+type ClusterTypeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterType `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterType{}, &ClusterTypeList{})
+}
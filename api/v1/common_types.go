@@ -0,0 +1,88 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DeletionPolicy defines what happens to the Netbox object when its resource is deleted
+// +kubebuilder:validation:Enum=Delete;Orphan
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyDelete removes the object from Netbox
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+	// DeletionPolicyOrphan leaves the object in Netbox and removes the managed-by tag
+	DeletionPolicyOrphan DeletionPolicy = "Orphan"
+)
+
+// ObjectState is the state of the Netbox object of a resource
+type ObjectState string
+
+const (
+	ObjectReadyState ObjectState = "Ready"
+	// ObjectPendingState is set while the objects the resource refers to don't exist yet
+	ObjectPendingState ObjectState = "Pending"
+	ObjectFailedState  ObjectState = "Failed"
+	// ObjectBlockedState is set while the deletion of the resource waits for the children of its Netbox object
+	ObjectBlockedState ObjectState = "Blocked"
+)
+
+// ObjectStatus defines the observed state of the resources backed by a Netbox object
+type ObjectStatus struct {
+	ID    *int64      `json:"id,omitempty"`
+	State ObjectState `json:"state,omitempty"`
+	// Message explains the state, e.g. the missing references of a Pending object
+	// +kubebuilder:validation:Optional
+	Message string `json:"message,omitempty"`
+	// +kubebuilder:validation:Optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// ObjectFinalizer is set on all resources, so that their Netbox objects are removed first
+const ObjectFinalizer = DeviceFinalizer
+
+// NodeLabel is set on the resources synced from a Kubernetes Node to the name of the Node
+const NodeLabel = "netbox.networkop.co.uk/node"
+
+// Object is implemented by the kinds reconciled by the generic object controller
+// +kubebuilder:object:generate=false
+type Object interface {
+	client.Object
+	GetObjectStatus() *ObjectStatus
+	GetDeletionPolicy() DeletionPolicy
+}
+
+// Tenanted is implemented by the kinds with a Netbox tenant
+// +kubebuilder:object:generate=false
+type Tenanted interface {
+	client.Object
+	GetTenant() string
+	SetTenant(tenant string)
+}
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9.-]+`)
+
+// ResourceName joins the parts into a valid resource name, e.g. leaf-01 and Ethernet1/1 into leaf-01-ethernet1-1
+func ResourceName(parts ...string) string {
+	name := invalidNameChars.ReplaceAllString(strings.ToLower(strings.Join(parts, "-")), "-")
+	return strings.Trim(name, "-.")
+}
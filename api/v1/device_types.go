@@ -24,8 +24,16 @@ import (
 type DeviceState string
 
 const DeviceKind = "Device"
+
+// DeviceModel is the name of the Netbox model in webhook payloads
+const DeviceModel = "device"
 const DeviceFinalizer = "finalizers.netbox.networkop.co.uk"
+
+// IDAnnotation records the ID of an existing Netbox object, so that it is adopted instead of recreated
+const IDAnnotation = "netbox.networkop.co.uk/id"
 const DeviceReadyState DeviceState = "Ready"
+const DevicePendingState DeviceState = "Pending"
+const DeviceFailedState DeviceState = "Failed"
 
 // DeviceSpec defines the desired state of Netbox Device
 type DeviceSpec struct {
@@ -46,12 +54,60 @@ type DeviceSpec struct {
 	// +kubebuilder:validation:MaxLength=63
 	// +required
 	Role string `json:"role,omitempty"`
+
+	// Name of the Netbox Platform of the device
+	// +kubebuilder:validation:MaxLength=100
+	// +kubebuilder:validation:Optional
+	Platform string `json:"platform,omitempty"`
+
+	// Netbox status of the device, Netbox defaults to active
+	// +kubebuilder:validation:Enum=offline;active;planned;staged;failed;inventory;decommissioning
+	// +kubebuilder:validation:Optional
+	Status string `json:"status,omitempty"`
+
+	// Name of an existing Netbox Tenant
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Optional
+	Tenant string `json:"tenant,omitempty"`
+
+	// Name of an existing Netbox Rack in the site
+	// +kubebuilder:validation:MaxLength=100
+	// +kubebuilder:validation:Optional
+	Rack string `json:"rack,omitempty"`
+
+	// Lowest rack unit occupied by the device. Devices must fit into the rack
+	// and can't overlap other devices on the same face
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	Position int64 `json:"position,omitempty"`
+
+	// Rack face the device is mounted on, defaults to front for devices with a position
+	// +kubebuilder:validation:Enum=front;rear
+	// +kubebuilder:validation:Optional
+	Face string `json:"face,omitempty"`
+
+	// Slugs of existing Netbox Tags. If set, they replace the tags of the Netbox device
+	// +kubebuilder:validation:Optional
+	Tags []string `json:"tags,omitempty"`
+
+	// Adds the interfaces, console ports and power ports of the device type templates that
+	// are missing on the Netbox device. Netbox only copies the templates when devices are created
+	// +kubebuilder:validation:Optional
+	SyncComponents bool `json:"syncComponents,omitempty"`
+
+	// What happens to the Netbox device when this resource is deleted.
+	// Defaults to the controller's default deletion policy
+	// +kubebuilder:validation:Optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
 }
 
 // DeviceStatus defines the observed state of Device
 type DeviceStatus struct {
 	ID    *int64      `json:"id,omitempty"`
 	State DeviceState `json:"state,omitempty"`
+	// Message explains a Failed state, e.g. a device overlapping another device in the rack
+	// +kubebuilder:validation:Optional
+	Message string `json:"message,omitempty"`
 	// +kubebuilder:validation:Optional
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 }
@@ -71,6 +127,21 @@ type Device struct {
 	Status DeviceStatus `json:"status,omitempty"`
 }
 
+// GetDeletionPolicy returns the deletion policy of the device
+func (d *Device) GetDeletionPolicy() DeletionPolicy {
+	return d.Spec.DeletionPolicy
+}
+
+// GetTenant returns the tenant of the device
+func (d *Device) GetTenant() string {
+	return d.Spec.Tenant
+}
+
+// SetTenant sets the tenant of the device
+func (d *Device) SetTenant(tenant string) {
+	d.Spec.Tenant = tenant
+}
+
 //+kubebuilder:object:root=true
 
 // DeviceList contains a list of Device
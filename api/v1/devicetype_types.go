@@ -0,0 +1,208 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const DeviceTypeKind = "DeviceType"
+
+// DeviceTypeModel is the name of the Netbox model in webhook payloads
+const DeviceTypeModel = "devicetype"
+
+// DeviceTypeSpec defines the desired state of Netbox DeviceType
+type DeviceTypeSpec struct {
+	// Model of the device type, defaults to the name of the resource. Devices refer to their type by model
+	// +kubebuilder:validation:MaxLength=100
+	// +kubebuilder:validation:Optional
+	Model string `json:"model,omitempty"`
+
+	// Slug of the device type, defaults to the model in lower case with dashes
+	// +kubebuilder:validation:MaxLength=100
+	// +kubebuilder:validation:Pattern=`^[-a-zA-Z0-9_]+$`
+	// +kubebuilder:validation:Optional
+	Slug string `json:"slug,omitempty"`
+
+	// Name of an existing Netbox Manufacturer
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=100
+	// +required
+	Manufacturer string `json:"manufacturer"`
+
+	// +kubebuilder:validation:MaxLength=50
+	// +kubebuilder:validation:Optional
+	PartNumber string `json:"part_number,omitempty"`
+
+	// Height of the device type in rack units, Netbox defaults to 1
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=32767
+	// +kubebuilder:validation:Optional
+	UHeight *int64 `json:"u_height,omitempty"`
+
+	// Devices of the type consume both faces of the rack, Netbox defaults to true
+	// +kubebuilder:validation:Optional
+	IsFullDepth *bool `json:"is_full_depth,omitempty"`
+
+	// Interface templates, which Netbox copies to the devices created with the type
+	// +kubebuilder:validation:Optional
+	Interfaces []InterfaceTemplate `json:"interfaces,omitempty"`
+
+	// Console port templates, which Netbox copies to the devices created with the type
+	// +kubebuilder:validation:Optional
+	ConsolePorts []ConsolePortTemplate `json:"console_ports,omitempty"`
+
+	// Power port templates, which Netbox copies to the devices created with the type
+	// +kubebuilder:validation:Optional
+	PowerPorts []PowerPortTemplate `json:"power_ports,omitempty"`
+
+	// Slugs of existing Netbox Tags. If set, they replace the tags of the Netbox device type
+	// +kubebuilder:validation:Optional
+	Tags []string `json:"tags,omitempty"`
+
+	// What happens to the Netbox device type when this resource is deleted.
+	// Defaults to the controller's default deletion policy
+	// +kubebuilder:validation:Optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+// InterfaceTemplate is an interface of every device of a device type
+type InterfaceTemplate struct {
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=64
+	// +required
+	Name string `json:"name"`
+
+	// Netbox interface type, e.g. virtual, 1000base-t or 100gbase-x-qsfp28
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=50
+	// +required
+	Type string `json:"type"`
+
+	// The interface is only used for out-of-band management
+	// +kubebuilder:validation:Optional
+	MgmtOnly bool `json:"mgmt_only,omitempty"`
+
+	// +kubebuilder:validation:MaxLength=64
+	// +kubebuilder:validation:Optional
+	Label string `json:"label,omitempty"`
+
+	// +kubebuilder:validation:MaxLength=200
+	// +kubebuilder:validation:Optional
+	Description string `json:"description,omitempty"`
+}
+
+// ConsolePortTemplate is a console port of every device of a device type
+type ConsolePortTemplate struct {
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=64
+	// +required
+	Name string `json:"name"`
+
+	// Netbox console port type, e.g. rj-45 or usb-c
+	// +kubebuilder:validation:MaxLength=50
+	// +kubebuilder:validation:Optional
+	Type string `json:"type,omitempty"`
+
+	// +kubebuilder:validation:MaxLength=64
+	// +kubebuilder:validation:Optional
+	Label string `json:"label,omitempty"`
+
+	// +kubebuilder:validation:MaxLength=200
+	// +kubebuilder:validation:Optional
+	Description string `json:"description,omitempty"`
+}
+
+// PowerPortTemplate is a power port of every device of a device type
+type PowerPortTemplate struct {
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=64
+	// +required
+	Name string `json:"name"`
+
+	// Netbox power port type, e.g. iec-60320-c14
+	// +kubebuilder:validation:MaxLength=50
+	// +kubebuilder:validation:Optional
+	Type string `json:"type,omitempty"`
+
+	// Maximum power draw in watts
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=32767
+	// +kubebuilder:validation:Optional
+	MaximumDraw int64 `json:"maximum_draw,omitempty"`
+
+	// Allocated power draw in watts
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=32767
+	// +kubebuilder:validation:Optional
+	AllocatedDraw int64 `json:"allocated_draw,omitempty"`
+
+	// +kubebuilder:validation:MaxLength=64
+	// +kubebuilder:validation:Optional
+	Label string `json:"label,omitempty"`
+
+	// +kubebuilder:validation:MaxLength=200
+	// +kubebuilder:validation:Optional
+	Description string `json:"description,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="ID",type=string,JSONPath=`.status.id`
+// +kubebuilder:printcolumn:name="Model",type=string,JSONPath=`.spec.model`
+// +kubebuilder:printcolumn:name="Manufacturer",type=string,JSONPath=`.spec.manufacturer`
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+
+// DeviceType is the Schema for the devicetypes API. The templates of the spec are created
+// and updated in Netbox, templates missing from the spec are left in place
+type DeviceType struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DeviceTypeSpec `json:"spec,omitempty"`
+	Status ObjectStatus   `json:"status,omitempty"`
+}
+
+// DeviceTypeModelName returns the model of the device type in Netbox
+func (t *DeviceType) DeviceTypeModelName() string {
+	if t.Spec.Model != "" {
+		return t.Spec.Model
+	}
+	return t.Name
+}
+
+// GetObjectStatus returns the status of the device type
+func (t *DeviceType) GetObjectStatus() *ObjectStatus {
+	return &t.Status
+}
+
+// GetDeletionPolicy returns the deletion policy of the device type
+func (t *DeviceType) GetDeletionPolicy() DeletionPolicy {
+	return t.Spec.DeletionPolicy
+}
+
+//+kubebuilder:object:root=true
+
+// DeviceTypeList contains a list of DeviceType
+type DeviceTypeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DeviceType `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DeviceType{}, &DeviceTypeList{})
+}
@@ -0,0 +1,410 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const FabricKind = "Fabric"
+
+// FabricLabel is set on the resources generated from a Fabric to the name of the Fabric
+const FabricLabel = "netbox.networkop.co.uk/fabric"
+
+// FabricTier describes the devices of one tier of a fabric
+type FabricTier struct {
+	// Number of devices in the tier
+	// +kubebuilder:validation:Minimum=1
+	// +required
+	Count int `json:"count"`
+
+	// Format of the device names, with the index of the device starting from 1, e.g. leaf-%02d
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	NamePattern string `json:"name_pattern"`
+
+	// Name of an existing Netbox Device Type
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	// +required
+	DeviceType string `json:"device_type"`
+
+	// Name of an existing Netbox Device Role
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	// +required
+	Role string `json:"role"`
+
+	// Format of the names of the fabric ports, with the port number, e.g. swp%d.
+	// Leaves connect to spines on consecutive ports, in the order of the spines
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	PortPattern string `json:"port_pattern"`
+
+	// Number of the first fabric port, defaults to 1
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Optional
+	FirstPort *int `json:"first_port,omitempty"`
+}
+
+// FabricSpec defines the desired state of a spine and leaf fabric
+type FabricSpec struct {
+	// Name of an existing Netbox Site of all devices
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	// +required
+	Site string `json:"site"`
+
+	// +required
+	Spines FabricTier `json:"spines"`
+
+	// +required
+	Leaves FabricTier `json:"leaves"`
+
+	// Netbox interface type of the fabric ports, defaults to other
+	// +kubebuilder:validation:MaxLength=50
+	// +kubebuilder:validation:Optional
+	UplinkType string `json:"uplink_type,omitempty"`
+
+	// Netbox cable type of the fabric links
+	// +kubebuilder:validation:Enum=cat3;cat5;cat5e;cat6;cat6a;cat7;cat7a;cat8;dac-active;dac-passive;mrj21-trunk;coaxial;mmf;mmf-om1;mmf-om2;mmf-om3;mmf-om4;mmf-om5;smf;smf-os1;smf-os2;aoc;power
+	// +kubebuilder:validation:Optional
+	CableType string `json:"cable_type,omitempty"`
+
+	// Prefix of the loopback addresses, assigned to the spines and then the leaves,
+	// starting from the second address of the prefix
+	// +kubebuilder:validation:Optional
+	LoopbackPrefix string `json:"loopback_prefix,omitempty"`
+
+	// Name of the loopback interface, defaults to lo
+	// +kubebuilder:validation:MaxLength=64
+	// +kubebuilder:validation:Optional
+	LoopbackInterface string `json:"loopback_interface,omitempty"`
+
+	// Prefix split into a /31 or /127 for each fabric link, the spine gets the first address
+	// +kubebuilder:validation:Optional
+	P2PPrefix string `json:"p2p_prefix,omitempty"`
+
+	// Netbox status of the devices, Netbox defaults to active
+	// +kubebuilder:validation:Enum=offline;active;planned;staged;failed;inventory;decommissioning
+	// +kubebuilder:validation:Optional
+	Status string `json:"status,omitempty"`
+
+	// Name of an existing Netbox Tenant of the devices and addresses
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Optional
+	Tenant string `json:"tenant,omitempty"`
+
+	// Slugs of existing Netbox Tags, set on all generated objects
+	// +kubebuilder:validation:Optional
+	Tags []string `json:"tags,omitempty"`
+
+	// Deletion policy of all generated resources
+	// +kubebuilder:validation:Optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+// FabricStatus defines the observed state of Fabric
+type FabricStatus struct {
+	State ObjectState `json:"state,omitempty"`
+	// Message explains the state, e.g. why the fabric could not be expanded
+	// +kubebuilder:validation:Optional
+	Message string `json:"message,omitempty"`
+	// Number of generated resources
+	// +kubebuilder:validation:Optional
+	Resources int `json:"resources,omitempty"`
+	// Number of generated resources that are Ready
+	// +kubebuilder:validation:Optional
+	Ready int `json:"ready,omitempty"`
+	// +kubebuilder:validation:Optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Site",type=string,JSONPath=`.spec.site`
+// +kubebuilder:printcolumn:name="Spines",type=integer,JSONPath=`.spec.spines.count`
+// +kubebuilder:printcolumn:name="Leaves",type=integer,JSONPath=`.spec.leaves.count`
+// +kubebuilder:printcolumn:name="Ready",type=integer,JSONPath=`.status.ready`
+// +kubebuilder:printcolumn:name="Resources",type=integer,JSONPath=`.status.resources`
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+
+// Fabric is the Schema for the fabrics API. A fabric is expanded into the Devices,
+// Interfaces, Cables and IPAddresses of a spine and leaf topology, which it owns
+type Fabric struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   FabricSpec   `json:"spec,omitempty"`
+	Status FabricStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// FabricList contains a list of Fabric
+type FabricList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Fabric `json:"items"`
+}
+
+// Expand returns the resources of the fabric: the devices, their fabric and loopback
+// interfaces, the cables between leaves and spines and the IP addresses, in this order
+func (f *Fabric) Expand() ([]client.Object, error) {
+	spines, err := f.Spec.Spines.names()
+	if err != nil {
+		return nil, fmt.Errorf("invalid spines: %w", err)
+	}
+	leaves, err := f.Spec.Leaves.names()
+	if err != nil {
+		return nil, fmt.Errorf("invalid leaves: %w", err)
+	}
+
+	var devices, interfaces, cables, addresses []client.Object
+
+	tiers := []struct {
+		tier  FabricTier
+		names []string
+	}{{f.Spec.Spines, spines}, {f.Spec.Leaves, leaves}}
+	for _, t := range tiers {
+		for _, name := range t.names {
+			devices = append(devices, &Device{
+				TypeMeta:   f.typeMeta(DeviceKind),
+				ObjectMeta: f.childMeta(name),
+				Spec: DeviceSpec{
+					Site:           f.Spec.Site,
+					DeviceType:     t.tier.DeviceType,
+					Role:           t.tier.Role,
+					Status:         f.Spec.Status,
+					Tenant:         f.Spec.Tenant,
+					Tags:           f.tags(),
+					DeletionPolicy: f.Spec.DeletionPolicy,
+				},
+			})
+		}
+	}
+
+	if f.Spec.LoopbackPrefix != "" {
+		prefix, err := fabricPrefix(f.Spec.LoopbackPrefix, len(spines)+len(leaves)+1)
+		if err != nil {
+			return nil, fmt.Errorf("invalid loopback_prefix: %w", err)
+		}
+		lo := f.Spec.LoopbackInterface
+		if lo == "" {
+			lo = "lo"
+		}
+		for i, device := range append(append([]string{}, spines...), leaves...) {
+			intf := f.newInterface(device, lo, "virtual")
+			interfaces = append(interfaces, intf)
+			addresses = append(addresses, f.newIPAddress(intf, prefix.host(i+1, prefix.bits), "loopback"))
+		}
+	}
+
+	var p2p *fabricNet
+	if f.Spec.P2PPrefix != "" {
+		if p2p, err = fabricPrefix(f.Spec.P2PPrefix, 2*len(spines)*len(leaves)); err != nil {
+			return nil, fmt.Errorf("invalid p2p_prefix: %w", err)
+		}
+	}
+
+	uplink := f.Spec.UplinkType
+	if uplink == "" {
+		uplink = "other"
+	}
+	for l, leaf := range leaves {
+		for s, spine := range spines {
+			leafIntf := f.newInterface(leaf, f.Spec.Leaves.port(s), uplink)
+			spineIntf := f.newInterface(spine, f.Spec.Spines.port(l), uplink)
+			interfaces = append(interfaces, leafIntf, spineIntf)
+
+			cables = append(cables, &Cable{
+				TypeMeta:   f.typeMeta(CableKind),
+				ObjectMeta: f.childMeta(ResourceName(leaf, spine)),
+				Spec: CableSpec{
+					TerminationA:   CableTermination{Device: leaf, Interface: leafIntf.Spec.Name},
+					TerminationB:   CableTermination{Device: spine, Interface: spineIntf.Spec.Name},
+					Type:           f.Spec.CableType,
+					Tags:           f.tags(),
+					DeletionPolicy: f.Spec.DeletionPolicy,
+				},
+			})
+
+			if p2p != nil {
+				link := 2 * (l*len(spines) + s)
+				addresses = append(addresses,
+					f.newIPAddress(spineIntf, p2p.host(link, p2p.linkBits()), ""),
+					f.newIPAddress(leafIntf, p2p.host(link+1, p2p.linkBits()), ""),
+				)
+			}
+		}
+	}
+
+	objects := append(devices, interfaces...)
+	objects = append(objects, cables...)
+	objects = append(objects, addresses...)
+	if err := uniqueNames(objects); err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+// uniqueNames checks that the names of the objects are valid resource names and that no two
+// objects of the same kind share a name, e.g. because the name patterns of the tiers overlap
+func uniqueNames(objects []client.Object) error {
+	seen := map[string]bool{}
+	for _, o := range objects {
+		kind, name := o.GetObjectKind().GroupVersionKind().Kind, o.GetName()
+		if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+			return fmt.Errorf("%s name %q is not a valid resource name: %s", kind, name, strings.Join(errs, ", "))
+		}
+		if seen[kind+"/"+name] {
+			return fmt.Errorf("%s %q is generated more than once", kind, name)
+		}
+		seen[kind+"/"+name] = true
+	}
+	return nil
+}
+
+func (f *Fabric) typeMeta(kind string) metav1.TypeMeta {
+	return metav1.TypeMeta{APIVersion: GroupVersion.String(), Kind: kind}
+}
+
+func (f *Fabric) childMeta(name string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      name,
+		Namespace: f.Namespace,
+		Labels:    map[string]string{FabricLabel: f.Name},
+	}
+}
+
+func (f *Fabric) tags() []string {
+	if len(f.Spec.Tags) == 0 {
+		return nil
+	}
+	return append([]string{}, f.Spec.Tags...)
+}
+
+func (f *Fabric) newInterface(device, name, intfType string) *Interface {
+	return &Interface{
+		TypeMeta:   f.typeMeta(InterfaceKind),
+		ObjectMeta: f.childMeta(ResourceName(device, name)),
+		Spec: InterfaceSpec{
+			Device:         device,
+			Name:           name,
+			Type:           intfType,
+			Tags:           f.tags(),
+			DeletionPolicy: f.Spec.DeletionPolicy,
+		},
+	}
+}
+
+// newIPAddress returns the address of the interface, named after the interface
+func (f *Fabric) newIPAddress(intf *Interface, address, role string) *IPAddress {
+	return &IPAddress{
+		TypeMeta:   f.typeMeta(IPAddressKind),
+		ObjectMeta: f.childMeta(intf.Name),
+		Spec: IPAddressSpec{
+			Address:        address,
+			Status:         "active",
+			Role:           role,
+			Interface:      &InterfaceReference{Device: intf.Spec.Device, Name: intf.Spec.Name},
+			Tenant:         f.Spec.Tenant,
+			Tags:           f.tags(),
+			DeletionPolicy: f.Spec.DeletionPolicy,
+		},
+	}
+}
+
+func (t FabricTier) names() ([]string, error) {
+	if t.Count < 1 {
+		return nil, fmt.Errorf("count must be at least 1")
+	}
+	if !strings.Contains(t.NamePattern, "%") {
+		return nil, fmt.Errorf("name_pattern %q has no index", t.NamePattern)
+	}
+	if !strings.Contains(t.PortPattern, "%") {
+		return nil, fmt.Errorf("port_pattern %q has no port number", t.PortPattern)
+	}
+	names := make([]string, t.Count)
+	for i := range names {
+		names[i] = fmt.Sprintf(t.NamePattern, i+1)
+	}
+	return names, nil
+}
+
+// port returns the name of the fabric port with the index
+func (t FabricTier) port(i int) string {
+	first := 1
+	if t.FirstPort != nil {
+		first = *t.FirstPort
+	}
+	return fmt.Sprintf(t.PortPattern, first+i)
+}
+
+// fabricNet is a prefix the fabric addresses are taken from
+// +kubebuilder:object:generate=false
+type fabricNet struct {
+	base *big.Int
+	bits int
+	ipv4 bool
+}
+
+// fabricPrefix parses the prefix and checks that it has room for the number of addresses
+func fabricPrefix(prefix string, addresses int) (*fabricNet, error) {
+	_, ipNet, err := net.ParseCIDR(prefix)
+	if err != nil {
+		return nil, err
+	}
+	ones, bits := ipNet.Mask.Size()
+	size := new(big.Int).Lsh(big.NewInt(1), uint(bits-ones))
+	if size.Cmp(big.NewInt(int64(addresses))) < 0 {
+		return nil, fmt.Errorf("%s is too small for %d addresses", prefix, addresses)
+	}
+	ip := ipNet.IP
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	return &fabricNet{base: new(big.Int).SetBytes(ip), bits: bits, ipv4: len(ip) == net.IPv4len}, nil
+}
+
+// linkBits returns the mask length of point-to-point links
+func (n *fabricNet) linkBits() int {
+	return n.bits - 1
+}
+
+// host returns the address with the offset from the start of the prefix and the mask length
+func (n *fabricNet) host(offset, maskBits int) string {
+	addr := new(big.Int).Add(n.base, big.NewInt(int64(offset))).Bytes()
+	size := net.IPv6len
+	if n.ipv4 {
+		size = net.IPv4len
+	}
+	ip := make(net.IP, size)
+	copy(ip[size-len(addr):], addr)
+	return fmt.Sprintf("%s/%d", ip, maskBits)
+}
+
+func init() {
+	SchemeBuilder.Register(&Fabric{}, &FabricList{})
+}
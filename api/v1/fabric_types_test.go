@@ -0,0 +1,143 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testFabric(loopbacks, p2p string) *Fabric {
+	return &Fabric{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod-1", Namespace: "lab"},
+		Spec: FabricSpec{
+			Site:           "lab",
+			Spines:         FabricTier{Count: 2, NamePattern: "spine-%02d", DeviceType: "cEOS", Role: "spine", PortPattern: "Ethernet%d"},
+			Leaves:         FabricTier{Count: 3, NamePattern: "leaf-%02d", DeviceType: "cEOS", Role: "leaf", PortPattern: "swp%d"},
+			LoopbackPrefix: loopbacks,
+			P2PPrefix:      p2p,
+		},
+	}
+}
+
+func TestFabricExpand(t *testing.T) {
+	objects, err := testFabric("10.0.0.0/29", "2001:db8::/64").Expand()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counts := map[string]int{}
+	addresses := map[string]string{}
+	for _, o := range objects {
+		counts[o.GetObjectKind().GroupVersionKind().Kind]++
+		if o.GetNamespace() != "lab" || o.GetLabels()[FabricLabel] != "pod-1" {
+			t.Errorf("unexpected metadata of %s: %+v", o.GetName(), o)
+		}
+		if a, ok := o.(*IPAddress); ok {
+			addresses[a.Name] = a.Spec.Address
+		}
+	}
+
+	// 5 loopbacks and 2 interfaces for each of the 6 links
+	want := map[string]int{"Device": 5, "Interface": 17, "Cable": 6, "IPAddress": 17}
+	for kind, n := range want {
+		if counts[kind] != n {
+			t.Errorf("got %d %s, want %d", counts[kind], kind, n)
+		}
+	}
+
+	for name, address := range map[string]string{
+		"spine-01-lo":        "10.0.0.1/32",
+		"leaf-03-lo":         "10.0.0.5/32",
+		"spine-01-ethernet1": "2001:db8::/127",
+		"leaf-01-swp1":       "2001:db8::1/127",
+		"spine-02-ethernet3": "2001:db8::a/127",
+		"leaf-03-swp2":       "2001:db8::b/127",
+	} {
+		if addresses[name] != address {
+			t.Errorf("address %s: got %q, want %q", name, addresses[name], address)
+		}
+	}
+
+	if _, ok := objects[0].(*Device); !ok {
+		t.Errorf("devices must come first, got %T", objects[0])
+	}
+	if _, ok := objects[len(objects)-1].(*IPAddress); !ok {
+		t.Errorf("addresses must come last, got %T", objects[len(objects)-1])
+	}
+}
+
+func TestFabricExpandErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		fabric  *Fabric
+		mutate  func(f *Fabric)
+		wantErr string
+	}{
+		{name: "small loopback prefix", fabric: testFabric("10.0.0.0/30", ""), wantErr: "too small"},
+		{name: "small p2p prefix", fabric: testFabric("", "10.1.0.0/29"), wantErr: "too small"},
+		{name: "invalid prefix", fabric: testFabric("10.0.0.0", ""), wantErr: "invalid loopback_prefix"},
+		{
+			name:    "no index",
+			fabric:  testFabric("", ""),
+			mutate:  func(f *Fabric) { f.Spec.Leaves.NamePattern = "leaf" },
+			wantErr: "has no index",
+		},
+		{
+			name:   "same names in both tiers",
+			fabric: testFabric("", ""),
+			mutate: func(f *Fabric) {
+				f.Spec.Spines.NamePattern = "switch-%d"
+				f.Spec.Leaves.NamePattern = "switch-%d"
+			},
+			wantErr: `Device "switch-1" is generated more than once`,
+		},
+		{
+			// port b-1 of spine a-1 and port 1 of leaf a-1-b are both interface a-1-b-1
+			name:   "same interface names of different devices",
+			fabric: testFabric("", ""),
+			mutate: func(f *Fabric) {
+				f.Spec.Spines = FabricTier{Count: 1, NamePattern: "a-%d", DeviceType: "cEOS", Role: "spine", PortPattern: "b-%d"}
+				f.Spec.Leaves = FabricTier{Count: 1, NamePattern: "a-%d-b", DeviceType: "cEOS", Role: "leaf", PortPattern: "%d"}
+			},
+			wantErr: `Interface "a-1-b-1" is generated more than once`,
+		},
+		{
+			name:    "invalid device name",
+			fabric:  testFabric("", ""),
+			mutate:  func(f *Fabric) { f.Spec.Spines.NamePattern = "Spine_%d" },
+			wantErr: `Device name "Spine_1" is not a valid resource name`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mutate != nil {
+				tt.mutate(tt.fabric)
+			}
+			objects, err := tt.fabric.Expand()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected an error containing %q, got %v", tt.wantErr, err)
+			}
+			if objects != nil {
+				t.Errorf("expected no objects, got %d", len(objects))
+			}
+		})
+	}
+}
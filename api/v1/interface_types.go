@@ -0,0 +1,134 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const InterfaceKind = "Interface"
+
+// InterfaceModel is the name of the Netbox model in webhook payloads
+const InterfaceModel = "interface"
+
+// InterfaceSpec defines the desired state of Netbox Interface
+type InterfaceSpec struct {
+	// Name of an existing Netbox Device
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=64
+	// +required
+	Device string `json:"device"`
+
+	// Name of the interface on the device, defaults to the name of the resource
+	// +kubebuilder:validation:MaxLength=64
+	// +kubebuilder:validation:Optional
+	Name string `json:"name,omitempty"`
+
+	// Netbox interface type, e.g. virtual, 1000base-t or 100gbase-x-qsfp28
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=50
+	// +required
+	Type string `json:"type"`
+
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65536
+	// +kubebuilder:validation:Optional
+	MTU int64 `json:"mtu,omitempty"`
+
+	// The interface is only used for out-of-band management
+	// +kubebuilder:validation:Optional
+	MgmtOnly bool `json:"mgmt_only,omitempty"`
+
+	// +kubebuilder:validation:MaxLength=64
+	// +kubebuilder:validation:Optional
+	Label string `json:"label,omitempty"`
+
+	// +kubebuilder:validation:MaxLength=200
+	// +kubebuilder:validation:Optional
+	Description string `json:"description,omitempty"`
+
+	// 802.1Q mode of the interface. Defaults to tagged if tagged VLANs are set,
+	// and to access if only the untagged VLAN is set
+	// +kubebuilder:validation:Enum=access;tagged;tagged-all
+	// +kubebuilder:validation:Optional
+	Mode string `json:"mode,omitempty"`
+
+	// Untagged (native) VLAN of the interface
+	// +kubebuilder:validation:Optional
+	UntaggedVLAN *VLANReference `json:"untagged_vlan,omitempty"`
+
+	// Tagged VLANs of the interface. If set, they replace the tagged VLANs of the Netbox interface
+	// +kubebuilder:validation:Optional
+	TaggedVLANs []VLANReference `json:"tagged_vlans,omitempty"`
+
+	// Slugs of existing Netbox Tags. If set, they replace the tags of the Netbox interface
+	// +kubebuilder:validation:Optional
+	Tags []string `json:"tags,omitempty"`
+
+	// What happens to the Netbox interface when this resource is deleted.
+	// Defaults to the controller's default deletion policy
+	// +kubebuilder:validation:Optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="ID",type=string,JSONPath=`.status.id`
+// +kubebuilder:printcolumn:name="Device",type=string,JSONPath=`.spec.device`
+// +kubebuilder:printcolumn:name="Name",type=string,JSONPath=`.spec.name`
+// +kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.type`
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+
+// Interface is the Schema for the interfaces API
+type Interface struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   InterfaceSpec `json:"spec,omitempty"`
+	Status ObjectStatus  `json:"status,omitempty"`
+}
+
+// InterfaceName returns the name of the interface on the device
+func (i *Interface) InterfaceName() string {
+	if i.Spec.Name != "" {
+		return i.Spec.Name
+	}
+	return i.Name
+}
+
+// GetObjectStatus returns the status of the interface
+func (i *Interface) GetObjectStatus() *ObjectStatus {
+	return &i.Status
+}
+
+// GetDeletionPolicy returns the deletion policy of the interface
+func (i *Interface) GetDeletionPolicy() DeletionPolicy {
+	return i.Spec.DeletionPolicy
+}
+
+//+kubebuilder:object:root=true
+
+// InterfaceList contains a list of Interface
+type InterfaceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Interface `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Interface{}, &InterfaceList{})
+}
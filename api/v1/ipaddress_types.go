@@ -0,0 +1,155 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const IPAddressKind = "IPAddress"
+
+// IPAddressModel is the name of the Netbox model in webhook payloads
+const IPAddressModel = "ipaddress"
+
+// InterfaceReference refers to an interface of a Netbox device
+type InterfaceReference struct {
+	// Name of an existing Netbox Device
+	// +kubebuilder:validation:MaxLength=64
+	// +required
+	Device string `json:"device"`
+
+	// Name of an interface of the device
+	// +kubebuilder:validation:MaxLength=64
+	// +required
+	Name string `json:"name"`
+}
+
+// VMInterfaceReference refers to an interface of a Netbox virtual machine
+type VMInterfaceReference struct {
+	// Name of an existing Netbox Virtual Machine
+	// +kubebuilder:validation:MaxLength=64
+	// +required
+	VirtualMachine string `json:"virtual_machine"`
+
+	// Name of an interface of the virtual machine
+	// +kubebuilder:validation:MaxLength=64
+	// +required
+	Name string `json:"name"`
+}
+
+// IPAddressSpec defines the desired state of Netbox IP Address
+type IPAddressSpec struct {
+	// IPv4 or IPv6 address with its mask, e.g. 10.0.0.1/32
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	Address string `json:"address"`
+
+	// Name of an existing Netbox VRF. Addresses without a VRF are in the global table
+	// +kubebuilder:validation:MaxLength=100
+	// +kubebuilder:validation:Optional
+	VRF string `json:"vrf,omitempty"`
+
+	// Netbox status of the address, Netbox defaults to active
+	// +kubebuilder:validation:Enum=active;reserved;deprecated;dhcp;slaac
+	// +kubebuilder:validation:Optional
+	Status string `json:"status,omitempty"`
+
+	// +kubebuilder:validation:Enum=loopback;secondary;anycast;vip;vrrp;hsrp;glbp;carp
+	// +kubebuilder:validation:Optional
+	Role string `json:"role,omitempty"`
+
+	// Interface the address is assigned to
+	// +kubebuilder:validation:Optional
+	Interface *InterfaceReference `json:"interface,omitempty"`
+
+	// Virtual machine interface the address is assigned to, instead of a device interface
+	// +kubebuilder:validation:Optional
+	VMInterface *VMInterfaceReference `json:"vm_interface,omitempty"`
+
+	// +kubebuilder:validation:MaxLength=255
+	// +kubebuilder:validation:Optional
+	DNSName string `json:"dns_name,omitempty"`
+
+	// +kubebuilder:validation:MaxLength=200
+	// +kubebuilder:validation:Optional
+	Description string `json:"description,omitempty"`
+
+	// Name of an existing Netbox Tenant
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Optional
+	Tenant string `json:"tenant,omitempty"`
+
+	// Slugs of existing Netbox Tags. If set, they replace the tags of the Netbox address
+	// +kubebuilder:validation:Optional
+	Tags []string `json:"tags,omitempty"`
+
+	// What happens to the Netbox address when this resource is deleted.
+	// Defaults to the controller's default deletion policy
+	// +kubebuilder:validation:Optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="ID",type=string,JSONPath=`.status.id`
+// +kubebuilder:printcolumn:name="Address",type=string,JSONPath=`.spec.address`
+// +kubebuilder:printcolumn:name="VRF",type=string,JSONPath=`.spec.vrf`
+// +kubebuilder:printcolumn:name="Device",type=string,JSONPath=`.spec.interface.device`
+// +kubebuilder:printcolumn:name="Interface",type=string,JSONPath=`.spec.interface.name`
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+
+// IPAddress is the Schema for the ipaddresses API
+type IPAddress struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   IPAddressSpec `json:"spec,omitempty"`
+	Status ObjectStatus  `json:"status,omitempty"`
+}
+
+// GetObjectStatus returns the status of the address
+func (a *IPAddress) GetObjectStatus() *ObjectStatus {
+	return &a.Status
+}
+
+// GetDeletionPolicy returns the deletion policy of the address
+func (a *IPAddress) GetDeletionPolicy() DeletionPolicy {
+	return a.Spec.DeletionPolicy
+}
+
+// GetTenant returns the tenant of the address
+func (a *IPAddress) GetTenant() string {
+	return a.Spec.Tenant
+}
+
+// SetTenant sets the tenant of the address
+func (a *IPAddress) SetTenant(tenant string) {
+	a.Spec.Tenant = tenant
+}
+
+//+kubebuilder:object:root=true
+
+// IPAddressList contains a list of IPAddress
+type IPAddressList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []IPAddress `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&IPAddress{}, &IPAddressList{})
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const LocationKind = "Location"
+
+// LocationModel is the name of the Netbox model in webhook payloads
+const LocationModel = "location"
+
+// LocationSpec defines the desired state of Netbox Location
+type LocationSpec struct {
+	// Name of an existing Netbox Site
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	// +required
+	Site string `json:"site"`
+
+	// Name of the location, defaults to the name of the resource
+	// +kubebuilder:validation:MaxLength=100
+	// +kubebuilder:validation:Optional
+	Name string `json:"name,omitempty"`
+
+	// Slug of the location, defaults to the name in lower case with dashes
+	// +kubebuilder:validation:MaxLength=100
+	// +kubebuilder:validation:Pattern=`^[-a-zA-Z0-9_]+$`
+	// +kubebuilder:validation:Optional
+	Slug string `json:"slug,omitempty"`
+
+	// Name of the parent Netbox Location in the same site
+	// +kubebuilder:validation:MaxLength=100
+	// +kubebuilder:validation:Optional
+	Parent string `json:"parent,omitempty"`
+
+	// +kubebuilder:validation:MaxLength=200
+	// +kubebuilder:validation:Optional
+	Description string `json:"description,omitempty"`
+
+	// What happens to the Netbox location when this resource is deleted.
+	// Defaults to the controller's default deletion policy
+	// +kubebuilder:validation:Optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="ID",type=string,JSONPath=`.status.id`
+// +kubebuilder:printcolumn:name="Site",type=string,JSONPath=`.spec.site`
+// +kubebuilder:printcolumn:name="Parent",type=string,JSONPath=`.spec.parent`
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+
+// Location is the Schema for the locations API. Netbox can't tag locations,
+// so an existing location with the same name is adopted
+type Location struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   LocationSpec `json:"spec,omitempty"`
+	Status ObjectStatus `json:"status,omitempty"`
+}
+
+// LocationName returns the name of the location in Netbox
+func (l *Location) LocationName() string {
+	if l.Spec.Name != "" {
+		return l.Spec.Name
+	}
+	return l.Name
+}
+
+// GetObjectStatus returns the status of the location
+func (l *Location) GetObjectStatus() *ObjectStatus {
+	return &l.Status
+}
+
+// GetDeletionPolicy returns the deletion policy of the location
+func (l *Location) GetDeletionPolicy() DeletionPolicy {
+	return l.Spec.DeletionPolicy
+}
+
+//+kubebuilder:object:root=true
+
+// LocationList contains a list of Location
+type LocationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Location `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Location{}, &LocationList{})
+}
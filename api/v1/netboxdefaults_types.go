@@ -0,0 +1,141 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const NetboxDefaultsKind = "NetboxDefaults"
+
+// DeviceDefaults are the values of the Device fields that are left unset
+type DeviceDefaults struct {
+	// +kubebuilder:validation:Optional
+	Site string `json:"site,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	DeviceType string `json:"device_type,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Role string `json:"role,omitempty"`
+
+	// +kubebuilder:validation:Enum=offline;active;planned;staged;failed;inventory;decommissioning
+	// +kubebuilder:validation:Optional
+	Status string `json:"status,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Tenant string `json:"tenant,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Tags []string `json:"tags,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+// NetboxDefaultsSpec defines the defaults and the namespaces they apply to
+type NetboxDefaultsSpec struct {
+	// Namespaces the defaults apply to. Defaults without namespaces apply to all namespaces,
+	// and are overridden by the defaults that list the namespace of the object
+	// +kubebuilder:validation:Optional
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Device DeviceDefaults `json:"device,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Cluster
+
+// NetboxDefaults is the Schema for the netboxdefaults API
+type NetboxDefaults struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec NetboxDefaultsSpec `json:"spec,omitempty"`
+}
+
+// AppliesTo returns true if the defaults apply to objects in the namespace
+func (d *NetboxDefaults) AppliesTo(namespace string) bool {
+	if len(d.Spec.Namespaces) == 0 {
+		return true
+	}
+	for _, ns := range d.Spec.Namespaces {
+		if ns == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+//+kubebuilder:object:root=true
+
+// NetboxDefaultsList contains a list of NetboxDefaults
+type NetboxDefaultsList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NetboxDefaults `json:"items"`
+}
+
+// ApplyDefaults fills the unset fields of the device from the defaults that apply to its namespace.
+// Defaults listing the namespace take precedence over cluster-wide defaults, ties are broken by name
+func (d *Device) ApplyDefaults(defaults []NetboxDefaults) {
+	applicable := []NetboxDefaults{}
+	for _, nd := range defaults {
+		if nd.AppliesTo(d.Namespace) {
+			applicable = append(applicable, nd)
+		}
+	}
+	sort.SliceStable(applicable, func(i, j int) bool {
+		iScoped, jScoped := len(applicable[i].Spec.Namespaces) > 0, len(applicable[j].Spec.Namespaces) > 0
+		if iScoped != jScoped {
+			return iScoped
+		}
+		return applicable[i].Name < applicable[j].Name
+	})
+
+	for _, nd := range applicable {
+		dd := nd.Spec.Device
+		if d.Spec.Site == "" {
+			d.Spec.Site = dd.Site
+		}
+		if d.Spec.DeviceType == "" {
+			d.Spec.DeviceType = dd.DeviceType
+		}
+		if d.Spec.Role == "" {
+			d.Spec.Role = dd.Role
+		}
+		if d.Spec.Status == "" {
+			d.Spec.Status = dd.Status
+		}
+		if d.Spec.Tenant == "" {
+			d.Spec.Tenant = dd.Tenant
+		}
+		if len(d.Spec.Tags) == 0 && len(dd.Tags) > 0 {
+			d.Spec.Tags = append([]string{}, dd.Tags...)
+		}
+		if d.Spec.DeletionPolicy == "" {
+			d.Spec.DeletionPolicy = dd.DeletionPolicy
+		}
+	}
+}
+
+func init() {
+	SchemeBuilder.Register(&NetboxDefaults{}, &NetboxDefaultsList{})
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const PlatformKind = "Platform"
+
+// PlatformModel is the name of the Netbox model in webhook payloads
+const PlatformModel = "platform"
+
+// PlatformSpec defines the desired state of Netbox Platform
+type PlatformSpec struct {
+	// Name of the platform, defaults to the name of the resource
+	// +kubebuilder:validation:MaxLength=100
+	// +kubebuilder:validation:Optional
+	Name string `json:"name,omitempty"`
+
+	// Slug of the platform, defaults to the name in lower case with dashes
+	// +kubebuilder:validation:MaxLength=100
+	// +kubebuilder:validation:Pattern=`^[-a-zA-Z0-9_]+$`
+	// +kubebuilder:validation:Optional
+	Slug string `json:"slug,omitempty"`
+
+	// Name of an existing Netbox Manufacturer, if the platform is specific to one
+	// +kubebuilder:validation:MaxLength=100
+	// +kubebuilder:validation:Optional
+	Manufacturer string `json:"manufacturer,omitempty"`
+
+	// Name of the NAPALM driver used to manage the devices of the platform, e.g. eos
+	// +kubebuilder:validation:MaxLength=50
+	// +kubebuilder:validation:Optional
+	NapalmDriver string `json:"napalm_driver,omitempty"`
+
+	// +kubebuilder:validation:MaxLength=200
+	// +kubebuilder:validation:Optional
+	Description string `json:"description,omitempty"`
+
+	// What happens to the Netbox platform when this resource is deleted.
+	// Defaults to the controller's default deletion policy
+	// +kubebuilder:validation:Optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="ID",type=string,JSONPath=`.status.id`
+// +kubebuilder:printcolumn:name="Name",type=string,JSONPath=`.spec.name`
+// +kubebuilder:printcolumn:name="Manufacturer",type=string,JSONPath=`.spec.manufacturer`
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+
+// Platform is the Schema for the platforms API. Netbox can't tag platforms,
+// so an existing platform with the same name is adopted
+type Platform struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PlatformSpec `json:"spec,omitempty"`
+	Status ObjectStatus `json:"status,omitempty"`
+}
+
+// PlatformName returns the name of the platform in Netbox
+func (p *Platform) PlatformName() string {
+	if p.Spec.Name != "" {
+		return p.Spec.Name
+	}
+	return p.Name
+}
+
+// GetObjectStatus returns the status of the platform
+func (p *Platform) GetObjectStatus() *ObjectStatus {
+	return &p.Status
+}
+
+// GetDeletionPolicy returns the deletion policy of the platform
+func (p *Platform) GetDeletionPolicy() DeletionPolicy {
+	return p.Spec.DeletionPolicy
+}
+
+//+kubebuilder:object:root=true
+
+// PlatformList contains a list of Platform
+type PlatformList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Platform `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Platform{}, &PlatformList{})
+}
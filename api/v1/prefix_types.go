@@ -0,0 +1,122 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const PrefixKind = "Prefix"
+
+// PrefixModel is the name of the Netbox model in webhook payloads
+const PrefixModel = "prefix"
+
+// PrefixSpec defines the desired state of Netbox Prefix
+type PrefixSpec struct {
+	// IPv4 or IPv6 network with mask, e.g. 10.0.0.0/24
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	Prefix string `json:"prefix"`
+
+	// Name of an existing Netbox VRF. Prefixes without a VRF are in the global table
+	// +kubebuilder:validation:MaxLength=100
+	// +kubebuilder:validation:Optional
+	VRF string `json:"vrf,omitempty"`
+
+	// Name of an existing Netbox Site
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Optional
+	Site string `json:"site,omitempty"`
+
+	// Netbox status of the prefix, Netbox defaults to active
+	// +kubebuilder:validation:Enum=container;active;reserved;deprecated
+	// +kubebuilder:validation:Optional
+	Status string `json:"status,omitempty"`
+
+	// Name of an existing Netbox Tenant
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Optional
+	Tenant string `json:"tenant,omitempty"`
+
+	// All IP addresses within the prefix are considered usable
+	// +kubebuilder:validation:Optional
+	IsPool bool `json:"is_pool,omitempty"`
+
+	// +kubebuilder:validation:MaxLength=200
+	// +kubebuilder:validation:Optional
+	Description string `json:"description,omitempty"`
+
+	// Slugs of existing Netbox Tags. If set, they replace the tags of the Netbox prefix
+	// +kubebuilder:validation:Optional
+	Tags []string `json:"tags,omitempty"`
+
+	// What happens to the Netbox prefix when this resource is deleted.
+	// Defaults to the controller's default deletion policy
+	// +kubebuilder:validation:Optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="ID",type=string,JSONPath=`.status.id`
+// +kubebuilder:printcolumn:name="Prefix",type=string,JSONPath=`.spec.prefix`
+// +kubebuilder:printcolumn:name="VRF",type=string,JSONPath=`.spec.vrf`
+// +kubebuilder:printcolumn:name="Site",type=string,JSONPath=`.spec.site`
+// +kubebuilder:printcolumn:name="Tenant",type=string,JSONPath=`.spec.tenant`
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+
+// Prefix is the Schema for the prefixes API
+type Prefix struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PrefixSpec   `json:"spec,omitempty"`
+	Status ObjectStatus `json:"status,omitempty"`
+}
+
+// GetObjectStatus returns the status of the prefix
+func (p *Prefix) GetObjectStatus() *ObjectStatus {
+	return &p.Status
+}
+
+// GetDeletionPolicy returns the deletion policy of the prefix
+func (p *Prefix) GetDeletionPolicy() DeletionPolicy {
+	return p.Spec.DeletionPolicy
+}
+
+// GetTenant returns the tenant of the prefix
+func (p *Prefix) GetTenant() string {
+	return p.Spec.Tenant
+}
+
+// SetTenant sets the tenant of the prefix
+func (p *Prefix) SetTenant(tenant string) {
+	p.Spec.Tenant = tenant
+}
+
+//+kubebuilder:object:root=true
+
+// PrefixList contains a list of Prefix
+type PrefixList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Prefix `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Prefix{}, &PrefixList{})
+}
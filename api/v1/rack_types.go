@@ -0,0 +1,133 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const RackKind = "Rack"
+
+// RackModel is the name of the Netbox model in webhook payloads
+const RackModel = "rack"
+
+// RackSpec defines the desired state of Netbox Rack
+type RackSpec struct {
+	// Name of an existing Netbox Site
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	// +required
+	Site string `json:"site"`
+
+	// Name of the rack, defaults to the name of the resource
+	// +kubebuilder:validation:MaxLength=100
+	// +kubebuilder:validation:Optional
+	Name string `json:"name,omitempty"`
+
+	// Name of an existing Netbox Location in the site
+	// +kubebuilder:validation:MaxLength=100
+	// +kubebuilder:validation:Optional
+	Location string `json:"location,omitempty"`
+
+	// Name of an existing Netbox Rack Role
+	// +kubebuilder:validation:MaxLength=100
+	// +kubebuilder:validation:Optional
+	Role string `json:"role,omitempty"`
+
+	// Netbox status of the rack, Netbox defaults to active
+	// +kubebuilder:validation:Enum=reserved;available;planned;active;deprecated
+	// +kubebuilder:validation:Optional
+	Status string `json:"status,omitempty"`
+
+	// Height of the rack in rack units, Netbox defaults to 42
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:validation:Optional
+	UHeight int64 `json:"u_height,omitempty"`
+
+	// Name of an existing Netbox Tenant
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Optional
+	Tenant string `json:"tenant,omitempty"`
+
+	// Slugs of existing Netbox Tags. If set, they replace the tags of the Netbox rack
+	// +kubebuilder:validation:Optional
+	Tags []string `json:"tags,omitempty"`
+
+	// What happens to the Netbox rack when this resource is deleted.
+	// Defaults to the controller's default deletion policy
+	// +kubebuilder:validation:Optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="ID",type=string,JSONPath=`.status.id`
+// +kubebuilder:printcolumn:name="Site",type=string,JSONPath=`.spec.site`
+// +kubebuilder:printcolumn:name="Location",type=string,JSONPath=`.spec.location`
+// +kubebuilder:printcolumn:name="Height",type=integer,JSONPath=`.spec.u_height`
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+
+// Rack is the Schema for the racks API
+type Rack struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RackSpec     `json:"spec,omitempty"`
+	Status ObjectStatus `json:"status,omitempty"`
+}
+
+// RackName returns the name of the rack in Netbox
+func (r *Rack) RackName() string {
+	if r.Spec.Name != "" {
+		return r.Spec.Name
+	}
+	return r.Name
+}
+
+// GetObjectStatus returns the status of the rack
+func (r *Rack) GetObjectStatus() *ObjectStatus {
+	return &r.Status
+}
+
+// GetDeletionPolicy returns the deletion policy of the rack
+func (r *Rack) GetDeletionPolicy() DeletionPolicy {
+	return r.Spec.DeletionPolicy
+}
+
+// GetTenant returns the tenant of the rack
+func (r *Rack) GetTenant() string {
+	return r.Spec.Tenant
+}
+
+// SetTenant sets the tenant of the rack
+func (r *Rack) SetTenant(tenant string) {
+	r.Spec.Tenant = tenant
+}
+
+//+kubebuilder:object:root=true
+
+// RackList contains a list of Rack
+type RackList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Rack `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Rack{}, &RackList{})
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const RegionKind = "Region"
+
+// RegionModel is the name of the Netbox model in webhook payloads
+const RegionModel = "region"
+
+// RegionSpec defines the desired state of Netbox Region
+type RegionSpec struct {
+	// Name of the region, defaults to the name of the resource
+	// +kubebuilder:validation:MaxLength=100
+	// +kubebuilder:validation:Optional
+	Name string `json:"name,omitempty"`
+
+	// Slug of the region, defaults to the name in lower case with dashes
+	// +kubebuilder:validation:MaxLength=100
+	// +kubebuilder:validation:Pattern=`^[-a-zA-Z0-9_]+$`
+	// +kubebuilder:validation:Optional
+	Slug string `json:"slug,omitempty"`
+
+	// Name of the parent Netbox Region
+	// +kubebuilder:validation:MaxLength=100
+	// +kubebuilder:validation:Optional
+	Parent string `json:"parent,omitempty"`
+
+	// +kubebuilder:validation:MaxLength=200
+	// +kubebuilder:validation:Optional
+	Description string `json:"description,omitempty"`
+
+	// What happens to the Netbox region when this resource is deleted.
+	// Defaults to the controller's default deletion policy
+	// +kubebuilder:validation:Optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="ID",type=string,JSONPath=`.status.id`
+// +kubebuilder:printcolumn:name="Name",type=string,JSONPath=`.spec.name`
+// +kubebuilder:printcolumn:name="Parent",type=string,JSONPath=`.spec.parent`
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+
+// Region is the Schema for the regions API. Netbox can't tag regions,
+// so an existing region with the same name is adopted
+type Region struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RegionSpec   `json:"spec,omitempty"`
+	Status ObjectStatus `json:"status,omitempty"`
+}
+
+// RegionName returns the name of the region in Netbox
+func (r *Region) RegionName() string {
+	if r.Spec.Name != "" {
+		return r.Spec.Name
+	}
+	return r.Name
+}
+
+// GetObjectStatus returns the status of the region
+func (r *Region) GetObjectStatus() *ObjectStatus {
+	return &r.Status
+}
+
+// GetDeletionPolicy returns the deletion policy of the region
+func (r *Region) GetDeletionPolicy() DeletionPolicy {
+	return r.Spec.DeletionPolicy
+}
+
+//+kubebuilder:object:root=true
+
+// RegionList contains a list of Region
+type RegionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Region `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Region{}, &RegionList{})
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const RouteTargetKind = "RouteTarget"
+
+// RouteTargetModel is the name of the Netbox model in webhook payloads
+const RouteTargetModel = "routetarget"
+
+// RouteTargetSpec defines the desired state of Netbox Route Target
+type RouteTargetSpec struct {
+	// Route target value in RFC 4360 format, e.g. 65000:100. Defaults to the name of the resource
+	// +kubebuilder:validation:MaxLength=21
+	// +kubebuilder:validation:Optional
+	Name string `json:"name,omitempty"`
+
+	// Name of an existing Netbox Tenant
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Optional
+	Tenant string `json:"tenant,omitempty"`
+
+	// +kubebuilder:validation:MaxLength=200
+	// +kubebuilder:validation:Optional
+	Description string `json:"description,omitempty"`
+
+	// Slugs of existing Netbox Tags. If set, they replace the tags of the Netbox route target
+	// +kubebuilder:validation:Optional
+	Tags []string `json:"tags,omitempty"`
+
+	// What happens to the Netbox route target when this resource is deleted.
+	// Defaults to the controller's default deletion policy
+	// +kubebuilder:validation:Optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="ID",type=string,JSONPath=`.status.id`
+// +kubebuilder:printcolumn:name="Name",type=string,JSONPath=`.spec.name`
+// +kubebuilder:printcolumn:name="Tenant",type=string,JSONPath=`.spec.tenant`
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+
+// RouteTarget is the Schema for the routetargets API
+type RouteTarget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RouteTargetSpec `json:"spec,omitempty"`
+	Status ObjectStatus    `json:"status,omitempty"`
+}
+
+// RouteTargetName returns the name of the route target in Netbox
+func (r *RouteTarget) RouteTargetName() string {
+	if r.Spec.Name != "" {
+		return r.Spec.Name
+	}
+	return r.Name
+}
+
+// GetObjectStatus returns the status of the route target
+func (r *RouteTarget) GetObjectStatus() *ObjectStatus {
+	return &r.Status
+}
+
+// GetDeletionPolicy returns the deletion policy of the route target
+func (r *RouteTarget) GetDeletionPolicy() DeletionPolicy {
+	return r.Spec.DeletionPolicy
+}
+
+// GetTenant returns the tenant of the route target
+func (r *RouteTarget) GetTenant() string {
+	return r.Spec.Tenant
+}
+
+// SetTenant sets the tenant of the route target
+func (r *RouteTarget) SetTenant(tenant string) {
+	r.Spec.Tenant = tenant
+}
+
+//+kubebuilder:object:root=true
+
+// RouteTargetList contains a list of RouteTarget
+type RouteTargetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RouteTarget `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RouteTarget{}, &RouteTargetList{})
+}
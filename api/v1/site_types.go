@@ -0,0 +1,140 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const SiteKind = "Site"
+
+// SiteModel is the name of the Netbox model in webhook payloads
+const SiteModel = "site"
+
+// SiteSpec defines the desired state of Netbox Site
+type SiteSpec struct {
+	// Name of the site, defaults to the name of the resource
+	// +kubebuilder:validation:MaxLength=100
+	// +kubebuilder:validation:Optional
+	Name string `json:"name,omitempty"`
+
+	// Slug of the site, defaults to the name in lower case with dashes
+	// +kubebuilder:validation:MaxLength=100
+	// +kubebuilder:validation:Pattern=`^[-a-zA-Z0-9_]+$`
+	// +kubebuilder:validation:Optional
+	Slug string `json:"slug,omitempty"`
+
+	// Netbox status of the site, Netbox defaults to active
+	// +kubebuilder:validation:Enum=planned;staging;active;decommissioning;retired
+	// +kubebuilder:validation:Optional
+	Status string `json:"status,omitempty"`
+
+	// Name of an existing Netbox Tenant
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Optional
+	Tenant string `json:"tenant,omitempty"`
+
+	// Name of the Netbox Region of the site
+	// +kubebuilder:validation:MaxLength=100
+	// +kubebuilder:validation:Optional
+	Region string `json:"region,omitempty"`
+
+	// Name of the Netbox Site Group of the site
+	// +kubebuilder:validation:MaxLength=100
+	// +kubebuilder:validation:Optional
+	Group string `json:"group,omitempty"`
+
+	// Local facility ID or description
+	// +kubebuilder:validation:MaxLength=50
+	// +kubebuilder:validation:Optional
+	Facility string `json:"facility,omitempty"`
+
+	// Time zone of the site, e.g. Europe/London
+	// +kubebuilder:validation:Optional
+	TimeZone string `json:"time_zone,omitempty"`
+
+	// +kubebuilder:validation:MaxLength=200
+	// +kubebuilder:validation:Optional
+	Description string `json:"description,omitempty"`
+
+	// Slugs of existing Netbox Tags. If set, they replace the tags of the Netbox site
+	// +kubebuilder:validation:Optional
+	Tags []string `json:"tags,omitempty"`
+
+	// What happens to the Netbox site when this resource is deleted.
+	// Defaults to the controller's default deletion policy
+	// +kubebuilder:validation:Optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="ID",type=string,JSONPath=`.status.id`
+// +kubebuilder:printcolumn:name="Name",type=string,JSONPath=`.spec.name`
+// +kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.spec.status`
+// +kubebuilder:printcolumn:name="Tenant",type=string,JSONPath=`.spec.tenant`
+// +kubebuilder:printcolumn:name="Region",type=string,JSONPath=`.spec.region`
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+
+// Site is the Schema for the sites API
+type Site struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SiteSpec     `json:"spec,omitempty"`
+	Status ObjectStatus `json:"status,omitempty"`
+}
+
+// SiteName returns the name of the site in Netbox
+func (s *Site) SiteName() string {
+	if s.Spec.Name != "" {
+		return s.Spec.Name
+	}
+	return s.Name
+}
+
+// GetObjectStatus returns the status of the site
+func (s *Site) GetObjectStatus() *ObjectStatus {
+	return &s.Status
+}
+
+// GetDeletionPolicy returns the deletion policy of the site
+func (s *Site) GetDeletionPolicy() DeletionPolicy {
+	return s.Spec.DeletionPolicy
+}
+
+// GetTenant returns the tenant of the site
+func (s *Site) GetTenant() string {
+	return s.Spec.Tenant
+}
+
+// SetTenant sets the tenant of the site
+func (s *Site) SetTenant(tenant string) {
+	s.Spec.Tenant = tenant
+}
+
+//+kubebuilder:object:root=true
+
+// SiteList contains a list of Site
+type SiteList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Site `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Site{}, &SiteList{})
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const TenantGroupKind = "TenantGroup"
+
+// TenantGroupModel is the name of the Netbox model in webhook payloads
+const TenantGroupModel = "tenantgroup"
+
+// TenantGroupSpec defines the desired state of Netbox Tenant Group
+type TenantGroupSpec struct {
+	// Name of the tenant group, defaults to the name of the resource
+	// +kubebuilder:validation:MaxLength=100
+	// +kubebuilder:validation:Optional
+	Name string `json:"name,omitempty"`
+
+	// Slug of the tenant group, defaults to the name in lower case with dashes
+	// +kubebuilder:validation:MaxLength=100
+	// +kubebuilder:validation:Pattern=`^[-a-zA-Z0-9_]+$`
+	// +kubebuilder:validation:Optional
+	Slug string `json:"slug,omitempty"`
+
+	// Name of the parent Netbox Tenant Group
+	// +kubebuilder:validation:MaxLength=100
+	// +kubebuilder:validation:Optional
+	Parent string `json:"parent,omitempty"`
+
+	// +kubebuilder:validation:MaxLength=200
+	// +kubebuilder:validation:Optional
+	Description string `json:"description,omitempty"`
+
+	// What happens to the Netbox tenant group when this resource is deleted.
+	// Defaults to the controller's default deletion policy
+	// +kubebuilder:validation:Optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="ID",type=string,JSONPath=`.status.id`
+// +kubebuilder:printcolumn:name="Name",type=string,JSONPath=`.spec.name`
+// +kubebuilder:printcolumn:name="Parent",type=string,JSONPath=`.spec.parent`
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+
+// TenantGroup is the Schema for the tenantgroups API. Netbox can't tag tenant groups,
+// so an existing tenant group with the same name is adopted
+type TenantGroup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   TenantGroupSpec `json:"spec,omitempty"`
+	Status ObjectStatus    `json:"status,omitempty"`
+}
+
+// TenantGroupName returns the name of the tenant group in Netbox
+func (g *TenantGroup) TenantGroupName() string {
+	if g.Spec.Name != "" {
+		return g.Spec.Name
+	}
+	return g.Name
+}
+
+// GetObjectStatus returns the status of the tenant group
+func (g *TenantGroup) GetObjectStatus() *ObjectStatus {
+	return &g.Status
+}
+
+// GetDeletionPolicy returns the deletion policy of the tenant group
+func (g *TenantGroup) GetDeletionPolicy() DeletionPolicy {
+	return g.Spec.DeletionPolicy
+}
+
+//+kubebuilder:object:root=true
+
+// TenantGroupList contains a list of TenantGroup
+type TenantGroupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []TenantGroup `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&TenantGroup{}, &TenantGroupList{})
+}
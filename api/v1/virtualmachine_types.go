@@ -0,0 +1,141 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const VirtualMachineKind = "VirtualMachine"
+
+// VirtualMachineModel is the name of the Netbox model in webhook payloads
+const VirtualMachineModel = "virtualmachine"
+
+// VirtualMachineSpec defines the desired state of Netbox Virtual Machine
+type VirtualMachineSpec struct {
+	// Name of the virtual machine, defaults to the name of the resource
+	// +kubebuilder:validation:MaxLength=64
+	// +kubebuilder:validation:Optional
+	Name string `json:"name,omitempty"`
+
+	// Name of an existing Netbox Cluster
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=100
+	// +required
+	Cluster string `json:"cluster"`
+
+	// Netbox status of the virtual machine, Netbox defaults to active
+	// +kubebuilder:validation:Enum=offline;active;planned;staged;failed;decommissioning
+	// +kubebuilder:validation:Optional
+	Status string `json:"status,omitempty"`
+
+	// Name of an existing Netbox Device Role that applies to virtual machines
+	// +kubebuilder:validation:MaxLength=100
+	// +kubebuilder:validation:Optional
+	Role string `json:"role,omitempty"`
+
+	// Name of an existing Netbox Platform
+	// +kubebuilder:validation:MaxLength=100
+	// +kubebuilder:validation:Optional
+	Platform string `json:"platform,omitempty"`
+
+	// Name of an existing Netbox Tenant
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Optional
+	Tenant string `json:"tenant,omitempty"`
+
+	// Number of virtual CPUs
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	VCPUs int64 `json:"vcpus,omitempty"`
+
+	// Memory in MB
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	Memory int64 `json:"memory,omitempty"`
+
+	// Disk size in GB
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	Disk int64 `json:"disk,omitempty"`
+
+	// Slugs of existing Netbox Tags. If set, they replace the tags of the Netbox virtual machine
+	// +kubebuilder:validation:Optional
+	Tags []string `json:"tags,omitempty"`
+
+	// What happens to the Netbox virtual machine when this resource is deleted.
+	// Defaults to the controller's default deletion policy
+	// +kubebuilder:validation:Optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="ID",type=string,JSONPath=`.status.id`
+// +kubebuilder:printcolumn:name="Cluster",type=string,JSONPath=`.spec.cluster`
+// +kubebuilder:printcolumn:name="VCPUs",type=integer,JSONPath=`.spec.vcpus`
+// +kubebuilder:printcolumn:name="Memory",type=integer,JSONPath=`.spec.memory`
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+
+// VirtualMachine is the Schema for the virtualmachines API
+type VirtualMachine struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VirtualMachineSpec `json:"spec,omitempty"`
+	Status ObjectStatus       `json:"status,omitempty"`
+}
+
+// VirtualMachineName returns the name of the virtual machine in Netbox
+func (v *VirtualMachine) VirtualMachineName() string {
+	if v.Spec.Name != "" {
+		return v.Spec.Name
+	}
+	return v.Name
+}
+
+// GetObjectStatus returns the status of the virtual machine
+func (v *VirtualMachine) GetObjectStatus() *ObjectStatus {
+	return &v.Status
+}
+
+// GetDeletionPolicy returns the deletion policy of the virtual machine
+func (v *VirtualMachine) GetDeletionPolicy() DeletionPolicy {
+	return v.Spec.DeletionPolicy
+}
+
+// GetTenant returns the tenant of the virtual machine
+func (v *VirtualMachine) GetTenant() string {
+	return v.Spec.Tenant
+}
+
+// SetTenant sets the tenant of the virtual machine
+func (v *VirtualMachine) SetTenant(tenant string) {
+	v.Spec.Tenant = tenant
+}
+
+//+kubebuilder:object:root=true
+
+// VirtualMachineList contains a list of VirtualMachine
+type VirtualMachineList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VirtualMachine `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&VirtualMachine{}, &VirtualMachineList{})
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const VLANKind = "VLAN"
+
+// VLANModel is the name of the Netbox model in webhook payloads
+const VLANModel = "vlan"
+
+// VLANSpec defines the desired state of Netbox VLAN
+type VLANSpec struct {
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=4094
+	// +required
+	VID int64 `json:"vid"`
+
+	// Name of the VLAN, defaults to the name of the resource
+	// +kubebuilder:validation:MaxLength=64
+	// +kubebuilder:validation:Optional
+	Name string `json:"name,omitempty"`
+
+	// Name of an existing Netbox Site. VLANs without a site and a group are global
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Optional
+	Site string `json:"site,omitempty"`
+
+	// Name of an existing Netbox VLAN Group
+	// +kubebuilder:validation:MaxLength=100
+	// +kubebuilder:validation:Optional
+	Group string `json:"group,omitempty"`
+
+	// Netbox status of the VLAN, Netbox defaults to active
+	// +kubebuilder:validation:Enum=active;reserved;deprecated
+	// +kubebuilder:validation:Optional
+	Status string `json:"status,omitempty"`
+
+	// Name of an existing Netbox Tenant
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Optional
+	Tenant string `json:"tenant,omitempty"`
+
+	// +kubebuilder:validation:MaxLength=200
+	// +kubebuilder:validation:Optional
+	Description string `json:"description,omitempty"`
+
+	// Slugs of existing Netbox Tags. If set, they replace the tags of the Netbox VLAN
+	// +kubebuilder:validation:Optional
+	Tags []string `json:"tags,omitempty"`
+
+	// What happens to the Netbox VLAN when this resource is deleted.
+	// Defaults to the controller's default deletion policy
+	// +kubebuilder:validation:Optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+// VLANReference refers to a Netbox VLAN by VID or name. VLANs of the group are matched if the
+// group is set, otherwise VLANs of the site of the device, then global VLANs
+type VLANReference struct {
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=4094
+	// +kubebuilder:validation:Optional
+	VID int64 `json:"vid,omitempty"`
+
+	// Name of the VLAN, used if the VID is not set
+	// +kubebuilder:validation:MaxLength=64
+	// +kubebuilder:validation:Optional
+	Name string `json:"name,omitempty"`
+
+	// Name of an existing Netbox VLAN Group
+	// +kubebuilder:validation:MaxLength=100
+	// +kubebuilder:validation:Optional
+	Group string `json:"group,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="ID",type=string,JSONPath=`.status.id`
+// +kubebuilder:printcolumn:name="VID",type=integer,JSONPath=`.spec.vid`
+// +kubebuilder:printcolumn:name="Site",type=string,JSONPath=`.spec.site`
+// +kubebuilder:printcolumn:name="Group",type=string,JSONPath=`.spec.group`
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+
+// VLAN is the Schema for the vlans API
+type VLAN struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VLANSpec     `json:"spec,omitempty"`
+	Status ObjectStatus `json:"status,omitempty"`
+}
+
+// VLANName returns the name of the VLAN in Netbox
+func (v *VLAN) VLANName() string {
+	if v.Spec.Name != "" {
+		return v.Spec.Name
+	}
+	return v.Name
+}
+
+// GetObjectStatus returns the status of the VLAN
+func (v *VLAN) GetObjectStatus() *ObjectStatus {
+	return &v.Status
+}
+
+// GetDeletionPolicy returns the deletion policy of the VLAN
+func (v *VLAN) GetDeletionPolicy() DeletionPolicy {
+	return v.Spec.DeletionPolicy
+}
+
+// GetTenant returns the tenant of the VLAN
+func (v *VLAN) GetTenant() string {
+	return v.Spec.Tenant
+}
+
+// SetTenant sets the tenant of the VLAN
+func (v *VLAN) SetTenant(tenant string) {
+	v.Spec.Tenant = tenant
+}
+
+//+kubebuilder:object:root=true
+
+// VLANList contains a list of VLAN
+type VLANList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VLAN `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&VLAN{}, &VLANList{})
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const VMInterfaceKind = "VMInterface"
+
+// VMInterfaceModel is the name of the Netbox model in webhook payloads
+const VMInterfaceModel = "vminterface"
+
+// VMInterfaceSpec defines the desired state of Netbox VM Interface
+type VMInterfaceSpec struct {
+	// Name of an existing Netbox Virtual Machine
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=64
+	// +required
+	VirtualMachine string `json:"virtual_machine"`
+
+	// Name of the interface on the virtual machine, defaults to the name of the resource
+	// +kubebuilder:validation:MaxLength=64
+	// +kubebuilder:validation:Optional
+	Name string `json:"name,omitempty"`
+
+	// Disables the interface if false, Netbox defaults to true
+	// +kubebuilder:validation:Optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65536
+	// +kubebuilder:validation:Optional
+	MTU int64 `json:"mtu,omitempty"`
+
+	// +kubebuilder:validation:Pattern=`^([0-9A-Fa-f]{2}:){5}[0-9A-Fa-f]{2}$`
+	// +kubebuilder:validation:Optional
+	MACAddress string `json:"mac_address,omitempty"`
+
+	// +kubebuilder:validation:MaxLength=200
+	// +kubebuilder:validation:Optional
+	Description string `json:"description,omitempty"`
+
+	// Slugs of existing Netbox Tags. If set, they replace the tags of the Netbox interface
+	// +kubebuilder:validation:Optional
+	Tags []string `json:"tags,omitempty"`
+
+	// What happens to the Netbox interface when this resource is deleted.
+	// Defaults to the controller's default deletion policy
+	// +kubebuilder:validation:Optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="ID",type=string,JSONPath=`.status.id`
+// +kubebuilder:printcolumn:name="Virtual Machine",type=string,JSONPath=`.spec.virtual_machine`
+// +kubebuilder:printcolumn:name="Name",type=string,JSONPath=`.spec.name`
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+
+// VMInterface is the Schema for the vminterfaces API
+type VMInterface struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VMInterfaceSpec `json:"spec,omitempty"`
+	Status ObjectStatus    `json:"status,omitempty"`
+}
+
+// VMInterfaceName returns the name of the interface on the virtual machine
+func (i *VMInterface) VMInterfaceName() string {
+	if i.Spec.Name != "" {
+		return i.Spec.Name
+	}
+	return i.Name
+}
+
+// GetObjectStatus returns the status of the interface
+func (i *VMInterface) GetObjectStatus() *ObjectStatus {
+	return &i.Status
+}
+
+// GetDeletionPolicy returns the deletion policy of the interface
+func (i *VMInterface) GetDeletionPolicy() DeletionPolicy {
+	return i.Spec.DeletionPolicy
+}
+
+//+kubebuilder:object:root=true
+
+// VMInterfaceList contains a list of VMInterface
+type VMInterfaceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VMInterface `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&VMInterface{}, &VMInterfaceList{})
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const VRFKind = "VRF"
+
+// VRFModel is the name of the Netbox model in webhook payloads
+const VRFModel = "vrf"
+
+// VRFSpec defines the desired state of Netbox VRF
+type VRFSpec struct {
+	// Name of the VRF, defaults to the name of the resource
+	// +kubebuilder:validation:MaxLength=100
+	// +kubebuilder:validation:Optional
+	Name string `json:"name,omitempty"`
+
+	// Route distinguisher in RFC 4364 format, e.g. 65000:100
+	// +kubebuilder:validation:MaxLength=21
+	// +kubebuilder:validation:Optional
+	RD string `json:"rd,omitempty"`
+
+	// Name of an existing Netbox Tenant
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Optional
+	Tenant string `json:"tenant,omitempty"`
+
+	// Prevent duplicate prefixes and IP addresses within the VRF, Netbox defaults to true
+	// +kubebuilder:validation:Optional
+	EnforceUnique *bool `json:"enforce_unique,omitempty"`
+
+	// Names of existing Netbox Route Targets imported by the VRF. If set, they replace the import targets of the Netbox VRF
+	// +kubebuilder:validation:Optional
+	ImportTargets []string `json:"import_targets,omitempty"`
+
+	// Names of existing Netbox Route Targets exported by the VRF. If set, they replace the export targets of the Netbox VRF
+	// +kubebuilder:validation:Optional
+	ExportTargets []string `json:"export_targets,omitempty"`
+
+	// +kubebuilder:validation:MaxLength=200
+	// +kubebuilder:validation:Optional
+	Description string `json:"description,omitempty"`
+
+	// Slugs of existing Netbox Tags. If set, they replace the tags of the Netbox VRF
+	// +kubebuilder:validation:Optional
+	Tags []string `json:"tags,omitempty"`
+
+	// What happens to the Netbox VRF when this resource is deleted.
+	// Defaults to the controller's default deletion policy
+	// +kubebuilder:validation:Optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="ID",type=string,JSONPath=`.status.id`
+// +kubebuilder:printcolumn:name="Name",type=string,JSONPath=`.spec.name`
+// +kubebuilder:printcolumn:name="RD",type=string,JSONPath=`.spec.rd`
+// +kubebuilder:printcolumn:name="Tenant",type=string,JSONPath=`.spec.tenant`
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+
+// VRF is the Schema for the vrfs API
+type VRF struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VRFSpec      `json:"spec,omitempty"`
+	Status ObjectStatus `json:"status,omitempty"`
+}
+
+// VRFName returns the name of the VRF in Netbox
+func (v *VRF) VRFName() string {
+	if v.Spec.Name != "" {
+		return v.Spec.Name
+	}
+	return v.Name
+}
+
+// GetObjectStatus returns the status of the VRF
+func (v *VRF) GetObjectStatus() *ObjectStatus {
+	return &v.Status
+}
+
+// GetDeletionPolicy returns the deletion policy of the VRF
+func (v *VRF) GetDeletionPolicy() DeletionPolicy {
+	return v.Spec.DeletionPolicy
+}
+
+// GetTenant returns the tenant of the VRF
+func (v *VRF) GetTenant() string {
+	return v.Spec.Tenant
+}
+
+// SetTenant sets the tenant of the VRF
+func (v *VRF) SetTenant(tenant string) {
+	v.Spec.Tenant = tenant
+}
+
+//+kubebuilder:object:root=true
+
+// VRFList contains a list of VRF
+type VRFList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VRF `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&VRF{}, &VRFList{})
+}
@@ -5,10 +5,13 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 
 	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/apimachinery/pkg/util/yaml"
@@ -19,9 +22,76 @@ type action string
 const (
 	ApplyAction  action = "apply"
 	DeleteAction action = "delete"
+	OrphanAction action = "orphan"
 )
 
-func Action(c *Cli, a action, fn string) error {
+// Action processes every object of the manifest file, after filling their unset fields from the defaults
+func Action(c *Cli, a action, fn string, defaults ...netboxv1.NetboxDefaults) error {
+	return decodeManifest(fn, func(obj runtime.Object) error {
+		switch o := obj.(type) {
+		case *netboxv1.NetboxDefaults:
+			log.Debugf("skipping %s %q, defaults are read with --defaults", netboxv1.NetboxDefaultsKind, o.Name)
+			return nil
+		case *netboxv1.Device:
+			applyDefaults(o, defaults)
+		case *netboxv1.Fabric:
+			return actionFabric(c, a, o, defaults)
+		}
+		return actionObject(c, a, obj)
+	})
+}
+
+// actionFabric processes the resources generated from the fabric, which
+// are deleted in the reverse order, e.g. addresses before their interfaces
+func actionFabric(c *Cli, a action, f *netboxv1.Fabric, defaults []netboxv1.NetboxDefaults) error {
+	objects, err := f.Expand()
+	if err != nil {
+		return fmt.Errorf("invalid fabric %q: %s", f.Name, err)
+	}
+	if a != ApplyAction {
+		for i, j := 0, len(objects)-1; i < j; i, j = i+1, j-1 {
+			objects[i], objects[j] = objects[j], objects[i]
+		}
+	}
+
+	for _, obj := range objects {
+		if dev, ok := obj.(*netboxv1.Device); ok {
+			applyDefaults(dev, defaults)
+		}
+		if err := actionObject(c, a, obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadDefaults reads the NetboxDefaults from a manifest file
+func loadDefaults(fn string) ([]netboxv1.NetboxDefaults, error) {
+	defaults := []netboxv1.NetboxDefaults{}
+	err := decodeManifest(fn, func(obj runtime.Object) error {
+		d, ok := obj.(*netboxv1.NetboxDefaults)
+		if !ok {
+			return fmt.Errorf("unexpected kind %q in %s, expected %s", obj.GetObjectKind().GroupVersionKind().Kind, fn, netboxv1.NetboxDefaultsKind)
+		}
+		defaults = append(defaults, *d)
+		return nil
+	})
+	return defaults, err
+}
+
+// applyDefaults fills the device the same way as the mutating webhook, objects
+// without a namespace are in the default namespace, same as with kubectl
+func applyDefaults(dev *netboxv1.Device, defaults []netboxv1.NetboxDefaults) {
+	namespace := dev.Namespace
+	if namespace == "" {
+		dev.Namespace = metav1.NamespaceDefault
+	}
+	dev.ApplyDefaults(defaults)
+	dev.Namespace = namespace
+}
+
+// decodeManifest calls fn for every object of the manifest file, including the items of lists
+func decodeManifest(fn string, process func(runtime.Object) error) error {
 
 	f, err := os.Open(fn)
 	if os.IsNotExist(err) {
@@ -57,19 +127,58 @@ func Action(c *Cli, a action, fn string) error {
 		}
 		log.Debugf("Obj: %+v, GVK: %+v", obj, gvk)
 
-		switch a {
-		case ApplyAction:
-			if err := c.netbox.Apply(c.ctx, obj); err != nil {
-				return fmt.Errorf("failed to apply netbox configuration: %s", err)
+		// lists, e.g. the output of 'nbctl get -o yaml', are processed item by item
+		objects := []runtime.Object{obj}
+		if meta.IsListType(obj) {
+			if objects, err = meta.ExtractList(obj); err != nil {
+				return fmt.Errorf("unable to extract items from %q: %v", fn, err)
+			}
+		}
+
+		for _, o := range objects {
+			if o.GetObjectKind().GroupVersionKind().Empty() {
+				o.GetObjectKind().SetGroupVersionKind(gvk.GroupVersion().WithKind(strings.TrimSuffix(gvk.Kind, "List")))
 			}
-		case DeleteAction:
-			if err := c.netbox.Delete(c.ctx, obj); err != nil {
-				return fmt.Errorf("failed to apply netbox configuration: %s", err)
+			if err := process(o); err != nil {
+				return err
 			}
-		default:
-			return fmt.Errorf("unexpected action: %s", a)
 		}
+	}
+
+}
 
+func actionObject(c *Cli, a action, obj runtime.Object) error {
+	switch a {
+	case ApplyAction:
+		result, err := c.netbox.Apply(c.ctx, obj)
+		if err != nil {
+			return fmt.Errorf("failed to apply netbox configuration: %s", err)
+		}
+		if m, err := meta.Accessor(obj); err == nil {
+			kind := obj.GetObjectKind().GroupVersionKind().Kind
+			fmt.Fprintf(c.Out, "%s/%s %s\n", strings.ToLower(kind), m.GetName(), result.Operation)
+		}
+	case DeleteAction, OrphanAction:
+		if a == OrphanAction || deletionPolicy(obj) == netboxv1.DeletionPolicyOrphan {
+			if err := c.netbox.Orphan(c.ctx, obj); err != nil {
+				return fmt.Errorf("failed to orphan netbox object: %s", err)
+			}
+			return nil
+		}
+		if err := c.netbox.Delete(c.ctx, obj); err != nil {
+			return fmt.Errorf("failed to apply netbox configuration: %s", err)
+		}
+	default:
+		return fmt.Errorf("unexpected action: %s", a)
 	}
+	return nil
+}
 
+func deletionPolicy(obj runtime.Object) netboxv1.DeletionPolicy {
+	if o, ok := obj.(interface {
+		GetDeletionPolicy() netboxv1.DeletionPolicy
+	}); ok {
+		return o.GetDeletionPolicy()
+	}
+	return netboxv1.DeletionPolicyDelete
 }
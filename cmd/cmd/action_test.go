@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestApplyDefaults(t *testing.T) {
+	defaults, err := loadDefaults(filepath.Join("..", "..", "config", "samples", "netbox_defaults.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		namespace string
+		spec      netboxv1.DeviceSpec
+		want      netboxv1.DeviceSpec
+	}{
+		{
+			name: "cluster-wide defaults",
+			spec: netboxv1.DeviceSpec{DeviceType: "SN3420", Role: "leaf"},
+			want: netboxv1.DeviceSpec{Site: "CITC", DeviceType: "SN3420", Role: "leaf", Status: "active", Tags: []string{"production"}},
+		},
+		{
+			name:      "namespace defaults take precedence",
+			namespace: "lab",
+			spec:      netboxv1.DeviceSpec{DeviceType: "SN3420", Role: "leaf"},
+			want:      netboxv1.DeviceSpec{Site: "CITC", DeviceType: "SN3420", Role: "leaf", Status: "planned", Tenant: "lab", Tags: []string{"production"}},
+		},
+		{
+			name: "set fields are kept",
+			spec: netboxv1.DeviceSpec{Site: "LAB", DeviceType: "SN3420", Role: "leaf", Status: "staged", Tags: []string{"edge"}},
+			want: netboxv1.DeviceSpec{Site: "LAB", DeviceType: "SN3420", Role: "leaf", Status: "staged", Tags: []string{"edge"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dev := &netboxv1.Device{
+				ObjectMeta: metav1.ObjectMeta{Name: "leaf-99", Namespace: tt.namespace},
+				Spec:       tt.spec,
+			}
+			applyDefaults(dev, defaults)
+			if !reflect.DeepEqual(dev.Spec, tt.want) {
+				t.Errorf("got %+v, want %+v", dev.Spec, tt.want)
+			}
+			if dev.Namespace != tt.namespace {
+				t.Errorf("namespace changed to %q", dev.Namespace)
+			}
+		})
+	}
+}
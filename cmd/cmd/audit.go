@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/networkop/declarative-netbox/netbox"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// auditEntry is a change of a managed object made outside of declarative-netbox
+type auditEntry struct {
+	Time   time.Time `json:"time"`
+	User   string    `json:"user"`
+	Action string    `json:"action"`
+	Object string    `json:"object"`
+	// Diff are the fields changed in Netbox
+	Diff []netbox.FieldDiff `json:"diff"`
+	// Drift are the fields of the Netbox object that no longer match the manifest
+	Drift []netbox.FieldDiff `json:"drift,omitempty"`
+}
+
+func NewAuditCommand(cli *Cli) *cobra.Command {
+	var since time.Duration
+	var users []string
+	var manifest, format string
+	var chunkSize int64
+
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "List changes to managed Netbox objects that were not made by nbctl or the controller",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch format {
+			case "", "json", "yaml":
+			default:
+				return fmt.Errorf("unsupported output format %q, expected one of: json|yaml", format)
+			}
+
+			if len(users) == 0 {
+				user, err := cli.netbox.TokenUser(cli.ctx, NewAuthData().Token)
+				if err != nil {
+					return fmt.Errorf("failed to find the user of the API token, set it with --user: %s", err)
+				}
+				log.Debugf("excluding changes made by %s", user)
+				users = []string{user}
+			}
+
+			desired := map[string]runtime.Object{}
+			if manifest != "" {
+				err := decodeManifest(manifest, func(obj runtime.Object) error {
+					desired[objectRef(obj)] = obj
+					return nil
+				})
+				if err != nil {
+					return err
+				}
+			}
+
+			changes, err := cli.netbox.Audit(cli.ctx, netbox.AuditOptions{
+				Since:        time.Now().Add(-since),
+				ExcludeUsers: users,
+				ChunkSize:    chunkSize,
+			})
+			if err != nil {
+				return err
+			}
+
+			entries := []interface{}{}
+			for _, c := range changes {
+				entry := auditEntry{
+					Time:   c.Time,
+					User:   c.User,
+					Action: c.Action,
+					Object: strings.ToLower(c.Kind) + "/" + c.Name,
+					Diff:   c.Diff(),
+				}
+				if obj, ok := desired[entry.Object]; ok && c.Postchange != nil {
+					if entry.Drift, err = cli.netbox.Drift(cli.ctx, obj, c.Postchange); err != nil {
+						return err
+					}
+				}
+				entries = append(entries, entry)
+			}
+
+			switch format {
+			case "json":
+				_, err = io.Copy(cli.Out, printJson(entries))
+			case "yaml":
+				_, err = io.Copy(cli.Out, printYaml(entries))
+			default:
+				err = printAudit(cli.Out, entries)
+			}
+			return err
+		},
+	}
+
+	cmd.Flags().DurationVar(&since, "since", time.Hour*24, "only changes newer than this duration, e.g. 1h or 168h")
+	cmd.Flags().StringSliceVar(&users, "user", nil, "users whose changes are ignored, defaults to the user of the API token")
+	cmd.Flags().StringVarP(&manifest, "filename", "f", "", "manifest the changed objects are compared with")
+	cmd.Flags().StringVarP(&format, "output", "o", "", "json|yaml")
+	cmd.Flags().Int64Var(&chunkSize, "chunk-size", netbox.DefaultChunkSize, "number of changes requested from Netbox per page")
+	return cmd
+}
+
+// objectRef returns the kind/name reference of a manifest object, e.g. device/leaf-99
+func objectRef(obj runtime.Object) string {
+	name := ""
+	if m, err := meta.Accessor(obj); err == nil {
+		name = m.GetName()
+	}
+	return strings.ToLower(obj.GetObjectKind().GroupVersionKind().Kind) + "/" + name
+}
+
+func printAudit(w io.Writer, entries []interface{}) error {
+	for _, e := range entries {
+		entry := e.(auditEntry)
+		if _, err := fmt.Fprintf(w, "%s  %s  %s  %s\n", entry.Time.UTC().Format(time.RFC3339), entry.User, entry.Action, entry.Object); err != nil {
+			return err
+		}
+		for _, d := range entry.Diff {
+			fmt.Fprintf(w, "    %s: %v -> %v\n", d.Field, d.Old, d.New)
+		}
+		for _, d := range entry.Drift {
+			fmt.Fprintf(w, "    drift %s: manifest %v, netbox %v\n", d.Field, d.Old, d.New)
+		}
+	}
+	return nil
+}
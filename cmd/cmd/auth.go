@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/networkop/declarative-netbox/netbox"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -15,8 +16,9 @@ const (
 )
 
 type AuthData struct {
-	Token      string `json:"token"`
-	Server     string `json:"server"`
+	Token      string                  `json:"token"`
+	Server     string                  `json:"server"`
+	Transport  netbox.TransportOptions `json:"transport,omitempty"`
 	configFile string
 }
 
@@ -59,9 +61,10 @@ func readAuth(path string) *AuthData {
 	return result
 }
 
-func (c *AuthData) SaveAuth(s, t string) error {
+func (c *AuthData) SaveAuth(s, t string, opts netbox.TransportOptions) error {
 	c.Token = t
 	c.Server = s
+	c.Transport = opts
 
 	log.Debugf("Saving token data in %s", c.configFile)
 	bytes, err := json.Marshal(c)
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var cableKind = objectKind{
+	name:    "cable",
+	kind:    netboxv1.CableKind,
+	filters: []string{"site", "tenant", "tag", "status"},
+	newObject: func(name string) netboxv1.Object {
+		return &netboxv1.Cable{
+			ObjectMeta: v1.ObjectMeta{
+				Name: name,
+			},
+		}
+	},
+	newList: func() runtime.Object {
+		return &netboxv1.CableList{}
+	},
+	table: cableTable,
+}
+
+func cableTable(objects []netboxv1.Object, wide bool) tableData {
+	data := tableData{
+		headers: []string{"Name", "ID", "A", "B", "Type", "Status"},
+	}
+	if wide {
+		data.headers = append(data.headers, "State", "Deletion Policy")
+	}
+
+	for _, o := range objects {
+		c := o.(*netboxv1.Cable)
+		row := []interface{}{c.Name, objectID(c), terminationName(c.Spec.TerminationA), terminationName(c.Spec.TerminationB), c.Spec.Type, c.Spec.Status}
+		if wide {
+			row = append(row, c.Status.State, c.Spec.DeletionPolicy)
+		}
+		data.rows = append(data.rows, row)
+	}
+
+	return data
+}
+
+// terminationName shows a cable end as device/port or circuit/side
+func terminationName(t netboxv1.CableTermination) string {
+	switch {
+	case t.Interface != "":
+		return t.Device + "/" + t.Interface
+	case t.FrontPort != "":
+		return t.Device + "/" + t.FrontPort
+	case t.RearPort != "":
+		return t.Device + "/" + t.RearPort
+	case t.Circuit != "":
+		side := t.TermSide
+		if side == "" {
+			side = "A"
+		}
+		return t.Circuit + "/" + side
+	}
+	return ""
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCablePrintCommand(t *testing.T) {
+	id := int64(3)
+	cables := []netboxv1.Object{
+		&netboxv1.Cable{
+			ObjectMeta: metav1.ObjectMeta{Name: "leaf-99-spine-01"},
+			Spec: netboxv1.CableSpec{
+				TerminationA: netboxv1.CableTermination{Device: "leaf-99", Interface: "swp51"},
+				TerminationB: netboxv1.CableTermination{Device: "spine-01", Interface: "swp1"},
+				Type:         "cat6",
+			},
+			Status: netboxv1.ObjectStatus{ID: &id},
+		},
+		&netboxv1.Cable{
+			ObjectMeta: metav1.ObjectMeta{Name: "uplink"},
+			Spec: netboxv1.CableSpec{
+				TerminationA: netboxv1.CableTermination{Device: "spine-01", RearPort: "rp1"},
+				TerminationB: netboxv1.CableTermination{Circuit: "CID-100"},
+			},
+		},
+	}
+
+	tests := []struct {
+		output printFlags
+		want   string
+	}{
+		{output: printFlags{format: "name"}, want: "cable/leaf-99-spine-01\ncable/uplink\n"},
+		{output: printFlags{format: "csv"}, want: "Name,ID,A,B,Type,Status\nleaf-99-spine-01,3,leaf-99/swp51,spine-01/swp1,cat6,\nuplink,,spine-01/rp1,CID-100/A,,\n"},
+		{output: printFlags{format: "yaml"}, want: "kind: CableList"},
+		{output: printFlags{format: "jsonpath={.items[*].spec.termination_a.device}"}, want: "leaf-99 spine-01\n"},
+	}
+
+	for _, tt := range tests {
+		var b bytes.Buffer
+		if err := objectPrintCommand(&b, cableKind, cables, tt.output); err != nil {
+			t.Fatalf("format %q: %s", tt.output.format, err)
+		}
+		if !strings.Contains(b.String(), tt.want) {
+			t.Errorf("format %q: got %q, want %q", tt.output.format, b.String(), tt.want)
+		}
+	}
+}
@@ -3,11 +3,9 @@ package cmd
 import (
 	"context"
 	"io"
-	"net/url"
 	"os"
 
 	"github.com/go-logr/logr"
-	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/networkop/declarative-netbox/netbox"
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -25,6 +23,10 @@ type Resource struct {
 	Apply  func() *cobra.Command
 	Delete func() *cobra.Command
 	Get    func() *cobra.Command
+	// Each streams the objects matching the name (all if empty) and options
+	Each func(name string, opts netbox.ListOptions, fn func(interface{}) error) error
+	// Filters are the server-side filters supported by Each, see deviceFilters
+	Filters []string
 }
 
 type CliOption func(cli *Cli) error
@@ -41,14 +43,15 @@ func NewCli(opts ...CliOption) (*Cli, error) {
 	}
 
 	auth := NewAuthData()
+	if auth.Server == "" {
+		return &cli, nil
+	}
 
-	url, err := url.Parse(auth.Server)
+	nb, err := netbox.NewNetboxServer(auth.Server, auth.Token, netbox.WithTransport(auth.Transport))
 	if err != nil {
 		return nil, err
 	}
-
-	client.DefaultSchemes = []string{url.Scheme}
-	cli.netbox = netbox.NewNetboxServer(url.Host, auth.Token)
+	cli.netbox = nb
 
 	return &cli, nil
 }
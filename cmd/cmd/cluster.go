@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var clusterKind = objectKind{
+	name:    "cluster",
+	kind:    netboxv1.ClusterKind,
+	filters: []string{"site", "tenant", "tag"},
+	// clusters are looked up by their name in Netbox, e.g. 'nbctl get cluster vcenter-1'
+	newObject: func(name string) netboxv1.Object {
+		return &netboxv1.Cluster{
+			ObjectMeta: v1.ObjectMeta{
+				Name: name,
+			},
+		}
+	},
+	newList: func() runtime.Object {
+		return &netboxv1.ClusterList{}
+	},
+	table: clusterTable,
+}
+
+func clusterTable(objects []netboxv1.Object, wide bool) tableData {
+	data := tableData{
+		headers: []string{"Name", "ID", "Cluster", "Type", "Site"},
+	}
+	if wide {
+		data.headers = append(data.headers, "Tenant", "State", "Deletion Policy")
+	}
+
+	for _, o := range objects {
+		c := o.(*netboxv1.Cluster)
+		row := []interface{}{c.Name, objectID(c), c.ClusterName(), c.Spec.Type, c.Spec.Site}
+		if wide {
+			row = append(row, c.Spec.Tenant, c.Status.State, c.Spec.DeletionPolicy)
+		}
+		data.rows = append(data.rows, row)
+	}
+
+	return data
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var clusterTypeKind = objectKind{
+	name: "clustertype",
+	kind: netboxv1.ClusterTypeKind,
+	// cluster types are looked up by their name in Netbox, e.g. 'nbctl get clustertype VMware'
+	newObject: func(name string) netboxv1.Object {
+		return &netboxv1.ClusterType{
+			ObjectMeta: v1.ObjectMeta{
+				Name: name,
+			},
+		}
+	},
+	newList: func() runtime.Object {
+		return &netboxv1.ClusterTypeList{}
+	},
+	table: clusterTypeTable,
+}
+
+func clusterTypeTable(objects []netboxv1.Object, wide bool) tableData {
+	data := tableData{
+		headers: []string{"Name", "ID", "Cluster Type", "Slug"},
+	}
+	if wide {
+		data.headers = append(data.headers, "Description", "State", "Deletion Policy")
+	}
+
+	for _, o := range objects {
+		t := o.(*netboxv1.ClusterType)
+		row := []interface{}{t.Name, objectID(t), t.ClusterTypeName(), t.Spec.Slug}
+		if wide {
+			row = append(row, t.Spec.Description, t.Status.State, t.Spec.DeletionPolicy)
+		}
+		data.rows = append(data.rows, row)
+	}
+
+	return data
+}
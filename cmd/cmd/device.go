@@ -1,28 +1,30 @@
 package cmd
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
 	"io"
-	"os"
-	"strings"
+	"strconv"
 
-	"github.com/jedib0t/go-pretty/v6/table"
 	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
-	log "github.com/sirupsen/logrus"
+	"github.com/networkop/declarative-netbox/netbox"
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v3"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func NewDeviceResource(c *Cli) *Resource {
 
 	resource := &Resource{
-		Name: "device",
+		Name:    "device",
+		Filters: deviceFilters,
 		Get: func() *cobra.Command {
 			return DeviceGetCommand(c)
 		},
+		Each: func(name string, opts netbox.ListOptions, fn func(interface{}) error) error {
+			return c.netbox.Each(c.ctx, &netboxv1.Device{
+				ObjectMeta: v1.ObjectMeta{
+					Name: name,
+				},
+			}, opts, fn)
+		},
 	}
 
 	return resource
@@ -30,7 +32,9 @@ func NewDeviceResource(c *Cli) *Resource {
 
 func DeviceGetCommand(c *Cli) *cobra.Command {
 	//var quiet bool
-	var format string
+	var output printFlags
+	var filters listFlags
+	var watch watchFlags
 	cmd := &cobra.Command{
 		Use:     "device",
 		Aliases: []string{"device", "devices"},
@@ -41,84 +45,108 @@ func DeviceGetCommand(c *Cli) *cobra.Command {
 			if len(args) == 1 {
 				name = args[0]
 			}
-			devices, err := c.netbox.Get(c.ctx, &netboxv1.Device{
-				ObjectMeta: v1.ObjectMeta{
-					Name: name,
-				},
-			})
+			opts, err := filters.listOptions()
+			if err != nil {
+				return err
+			}
+			get := func() ([]netboxv1.Device, error) {
+				return c.netbox.Get(c.ctx, &netboxv1.Device{
+					ObjectMeta: v1.ObjectMeta{
+						Name: name,
+					},
+				}, opts)
+			}
+
+			if watch.watch {
+				return deviceWatch(c, get, output, watch)
+			}
+
+			devices, err := get()
 			if err != nil {
 				return err
 			}
-			devicePrintCommand(devices, format)
-			return nil
+			return devicePrintCommand(c.Out, devices, output)
 		},
 	}
-	cmd.PersistentFlags().StringVarP(&format, "output", "o", "", strings.Join(allowedFormats(), "|"))
+	output.addFlags(cmd.PersistentFlags())
+	filters.addFlags(cmd.Flags(), deviceFilters)
+	watch.addFlags(cmd.Flags())
 	//cmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Only output IDs")
 	return cmd
 }
 
-func devicePrintCommand(devices []netboxv1.Device, format string) {
-	switch format {
-	case "yaml":
-		io.Copy(os.Stdout, devicePrintYaml(devices))
-	case "json":
-		io.Copy(os.Stdout, devicePrintJson(devices))
-	default:
-		devicePrintTable(devices)
+func devicePrintCommand(w io.Writer, devices []netboxv1.Device, output printFlags) error {
+	names := []string{}
+	for _, d := range devices {
+		names = append(names, "device/"+d.Name)
 	}
-}
 
-func devicePrintTable(devices []netboxv1.Device) {
-	tw := table.NewWriter()
-	tw.AppendHeader(table.Row{"Name", "ID", "Type", "Role", "Site"})
+	return output.print(w, deviceList(devices), names, func(wide bool) tableData {
+		return deviceTable(devices, wide)
+	})
+}
 
-	for _, d := range devices {
-		tw.AppendRow(table.Row{d.Name, *d.Status.ID, d.Spec.DeviceType, d.Spec.Role, d.Spec.Site})
+func deviceWatch(c *Cli, get func() ([]netboxv1.Device, error), output printFlags, watch watchFlags) error {
+	w, err := newWatcher(c.Out, output, watch.events)
+	if err != nil {
+		return err
 	}
 
-	fmt.Println(tw.Render())
-}
+	return w.run(c.ctx, watch.interval, func() ([]watchObject, error) {
+		devices, err := get()
+		if err != nil {
+			return nil, err
+		}
 
-func devicePrintJson(devices []netboxv1.Device) io.Reader {
-	var b bytes.Buffer
-	jsonEncoder := json.NewEncoder(&b)
-	jsonEncoder.SetIndent("", "  ")
-	for _, d := range devices {
-		if err := jsonEncoder.Encode(d); err != nil {
-			log.Errorf("failed to encode json %s", err)
-			continue
+		objects := []watchObject{}
+		for _, d := range devices {
+			d := d
+			objects = append(objects, watchObject{
+				key:    strconv.FormatInt(*d.Status.ID, 10),
+				name:   "device/" + d.Name,
+				object: d,
+				table: func(wide bool) tableData {
+					return deviceTable([]netboxv1.Device{d}, wide)
+				},
+			})
 		}
+		return objects, nil
+	})
+}
+
+func deviceList(devices []netboxv1.Device) *netboxv1.DeviceList {
+	return &netboxv1.DeviceList{
+		TypeMeta: v1.TypeMeta{
+			Kind:       netboxv1.DeviceKind + "List",
+			APIVersion: netboxv1.GroupVersion.String(),
+		},
+		Items: devices,
 	}
-	return &b
 }
 
-func devicePrintYaml(devices []netboxv1.Device) io.Reader {
-	var b bytes.Buffer
+func deviceTable(devices []netboxv1.Device, wide bool) tableData {
+	data := tableData{
+		headers: []string{"Name", "ID", "Type", "Role", "Site"},
+	}
+	if wide {
+		data.headers = append(data.headers, "Platform", "Rack", "Position", "State", "Deletion Policy")
+	}
 
-	yamlEncoder := yaml.NewEncoder(&b)
-	yamlEncoder.SetIndent(2)
 	for _, d := range devices {
-		var jsonObj interface{}
-		b, err := json.Marshal(d)
-		if err != nil {
-			log.Errorf("failed to marshal json %s", err)
-			continue
+		id := ""
+		if d.Status.ID != nil {
+			id = strconv.FormatInt(*d.Status.ID, 10)
 		}
-
-		if err := json.Unmarshal(b, &jsonObj); err != nil {
-			log.Errorf("failed to unmarshal json %s", err)
-			continue
-		}
-		if err := yamlEncoder.Encode(jsonObj); err != nil {
-			log.Errorf("failed to encode yaml %s", err)
-			continue
+		row := []interface{}{d.Name, id, d.Spec.DeviceType, d.Spec.Role, d.Spec.Site}
+		if wide {
+			position := ""
+			if d.Spec.Position != 0 {
+				position = strconv.FormatInt(d.Spec.Position, 10)
+			}
+			row = append(row, d.Spec.Platform, d.Spec.Rack, position, d.Status.State, d.Spec.DeletionPolicy)
 		}
+		data.rows = append(data.rows, row)
 	}
 
-	return &b
-}
-
-func allowedFormats() []string {
-	return []string{"json", "yaml"}
+	return data
 }
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testDevices() []netboxv1.Device {
+	id := int64(1)
+	return []netboxv1.Device{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "leaf-99"},
+			Spec:       netboxv1.DeviceSpec{Site: "CITC", Role: "leaf", DeviceType: "SN3420"},
+			Status:     netboxv1.DeviceStatus{ID: &id, State: netboxv1.DeviceReadyState},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "spine-01"},
+			Spec:       netboxv1.DeviceSpec{Site: "CITC", Role: "spine", DeviceType: "SN3700"},
+		},
+	}
+}
+
+func TestDevicePrintCommand(t *testing.T) {
+	tests := []struct {
+		output printFlags
+		want   string
+	}{
+		{output: printFlags{format: "name"}, want: "device/leaf-99\ndevice/spine-01\n"},
+		{output: printFlags{format: "csv"}, want: "Name,ID,Type,Role,Site\nleaf-99,1,SN3420,leaf,CITC\nspine-01,,SN3700,spine,CITC\n"},
+		{output: printFlags{format: "csv", noHeaders: true}, want: "leaf-99,1,SN3420,leaf,CITC\nspine-01,,SN3700,spine,CITC\n"},
+		{output: printFlags{format: "jsonpath={.items[*].metadata.name}"}, want: "leaf-99 spine-01\n"},
+		{output: printFlags{format: "go-template={{range .items}}{{.spec.role}} {{end}}"}, want: "leaf spine "},
+		{output: printFlags{format: "json"}, want: `"kind": "DeviceList"`},
+		{output: printFlags{format: "yaml"}, want: "kind: DeviceList"},
+		{output: printFlags{format: "wide"}, want: "DELETION POLICY"},
+	}
+
+	for _, tt := range tests {
+		var b bytes.Buffer
+		if err := devicePrintCommand(&b, testDevices(), tt.output); err != nil {
+			t.Fatalf("format %q: %s", tt.output.format, err)
+		}
+		if !strings.Contains(b.String(), tt.want) {
+			t.Errorf("format %q: got %q, want %q", tt.output.format, b.String(), tt.want)
+		}
+	}
+
+	if err := devicePrintCommand(&bytes.Buffer{}, testDevices(), printFlags{format: "xml"}); err == nil {
+		t.Errorf("expected an error for an unsupported format")
+	}
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"strconv"
+
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var deviceTypeKind = objectKind{
+	name:    "devicetype",
+	kind:    netboxv1.DeviceTypeKind,
+	filters: []string{"tag"},
+	// device types are looked up by their model in Netbox, e.g. 'nbctl get devicetype dcs-7050sx3-48yc8'
+	newObject: func(name string) netboxv1.Object {
+		return &netboxv1.DeviceType{
+			ObjectMeta: v1.ObjectMeta{
+				Name: name,
+			},
+		}
+	},
+	newList: func() runtime.Object {
+		return &netboxv1.DeviceTypeList{}
+	},
+	table: deviceTypeTable,
+}
+
+func deviceTypeTable(objects []netboxv1.Object, wide bool) tableData {
+	data := tableData{
+		headers: []string{"Name", "ID", "Model", "Manufacturer"},
+	}
+	if wide {
+		data.headers = append(data.headers, "Slug", "Height", "Full Depth", "State", "Deletion Policy")
+	}
+
+	for _, o := range objects {
+		t := o.(*netboxv1.DeviceType)
+		row := []interface{}{t.Name, objectID(t), t.DeviceTypeModelName(), t.Spec.Manufacturer}
+		if wide {
+			height, fullDepth := "", ""
+			if t.Spec.UHeight != nil {
+				height = strconv.FormatInt(*t.Spec.UHeight, 10)
+			}
+			if t.Spec.IsFullDepth != nil {
+				fullDepth = strconv.FormatBool(*t.Spec.IsFullDepth)
+			}
+			row = append(row, t.Spec.Slug, height, fullDepth, t.Status.State, t.Spec.DeletionPolicy)
+		}
+		data.rows = append(data.rows, row)
+	}
+
+	return data
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func NewExportCommand(cli *Cli) *cobra.Command {
+	var format string
+	var allKinds bool
+	var filters listFlags
+
+	cmd := &cobra.Command{
+		Use:   "export [KIND [NAME]] | --all-kinds",
+		Short: "Export existing Netbox objects as apply-ready manifests",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kinds := []string{}
+			name := ""
+
+			switch {
+			case allKinds && len(args) > 0:
+				return fmt.Errorf("--all-kinds cannot be used together with a kind")
+			case allKinds:
+				kinds = resourceOrder
+			case len(args) == 0:
+				return cmd.Help()
+			case len(args) > 2:
+				return fmt.Errorf("expected at most a kind and a name, got %d arguments", len(args))
+			default:
+				kinds = append(kinds, resourceName(args[0]))
+				if len(args) == 2 {
+					name = args[1]
+				}
+			}
+
+			opts, err := filters.listOptions()
+			if err != nil {
+				return err
+			}
+
+			var encode objectEncoder
+			switch format {
+			case "json":
+				encode = newJsonEncoder(cli.Out)
+			case "yaml", "":
+				encode = newYamlEncoder(cli.Out)
+			default:
+				return fmt.Errorf("unsupported output format %q, expected one of: json|yaml", format)
+			}
+
+			// objects are written as they are received, so that large exports are not buffered
+			resources := GetResources(cli)
+			for _, kind := range kinds {
+				r, ok := resources[kind]
+				if !ok || r.Each == nil {
+					return fmt.Errorf("unsupported kind %q", kind)
+				}
+				// with --all-kinds, the kinds that can't be filtered as requested are left out
+				if unsupported := filters.unsupported(r.Filters); len(unsupported) > 0 {
+					if allKinds {
+						continue
+					}
+					return fmt.Errorf("%s can't be filtered by %s", pluralName(kind), strings.Join(unsupported, ", "))
+				}
+				err := r.Each(name, opts, func(o interface{}) error {
+					exported, err := exportObject(o)
+					if err != nil {
+						return err
+					}
+					return encode(exported)
+				})
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "output", "o", "yaml", "json|yaml")
+	cmd.Flags().BoolVar(&allKinds, "all-kinds", false, "export every supported kind in dependency order")
+	filters.addFlags(cmd.Flags(), deviceFilters)
+	return cmd
+}
+
+// resourceName maps kind aliases (e.g. "devices", "ipaddresses" or "Device") to a resource name
+func resourceName(kind string) string {
+	kind = strings.ToLower(kind)
+	if strings.HasSuffix(kind, "sses") || strings.HasSuffix(kind, "xes") {
+		return strings.TrimSuffix(kind, "es")
+	}
+	return strings.TrimSuffix(kind, "s")
+}
+
+// pluralName returns the plural of a resource name, e.g. "ipaddresses"
+func pluralName(name string) string {
+	if strings.HasSuffix(name, "s") || strings.HasSuffix(name, "x") {
+		return name + "es"
+	}
+	return name + "s"
+}
+
+// exportObject removes the fields assigned by the server, so that
+// applying the exported manifest is a no-op
+func exportObject(object interface{}) (map[string]interface{}, error) {
+	b, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]interface{}{}
+	if err := json.Unmarshal(b, &result); err != nil {
+		return nil, err
+	}
+
+	delete(result, "status")
+	if meta, ok := result["metadata"].(map[string]interface{}); ok {
+		for _, field := range []string{"creationTimestamp", "resourceVersion", "uid", "generation", "managedFields"} {
+			delete(meta, field)
+		}
+	}
+
+	return result, nil
+}
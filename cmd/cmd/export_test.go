@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"testing"
+
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestExportObject(t *testing.T) {
+	id := int64(1)
+	exported, err := exportObject(netboxv1.Device{
+		TypeMeta:   metav1.TypeMeta{Kind: netboxv1.DeviceKind, APIVersion: netboxv1.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Name: "leaf-99"},
+		Spec:       netboxv1.DeviceSpec{Site: "CITC", Role: "leaf", DeviceType: "SN3420"},
+		Status:     netboxv1.DeviceStatus{ID: &id, State: netboxv1.DeviceReadyState},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := exported["status"]; ok {
+		t.Errorf("status was not stripped: %v", exported)
+	}
+	meta := exported["metadata"].(map[string]interface{})
+	if _, ok := meta["creationTimestamp"]; ok {
+		t.Errorf("creationTimestamp was not stripped: %v", meta)
+	}
+	if meta["name"] != "leaf-99" || exported["kind"] != netboxv1.DeviceKind {
+		t.Errorf("unexpected object: %v", exported)
+	}
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/networkop/declarative-netbox/netbox"
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// deviceFilters are the server-side filters of devices, other kinds support a subset of them
+var deviceFilters = []string{"site", "role", "type", "tenant", "tag", "status"}
+
+// listFlags are the server-side filters shared by the commands that list Netbox objects
+type listFlags struct {
+	opts     netbox.ListOptions
+	selector string
+}
+
+// addFlags registers --q, -l and the paging flags, and the filters among deviceFilters that the kind supports
+func (f *listFlags) addFlags(fs *pflag.FlagSet, filters []string) {
+	for _, filter := range filters {
+		switch filter {
+		case "site":
+			fs.StringVar(&f.opts.Site, "site", "", "only objects in this site")
+		case "role":
+			fs.StringVar(&f.opts.Role, "role", "", "only objects with this role")
+		case "type":
+			fs.StringVar(&f.opts.DeviceType, "type", "", "only devices of this device type (model)")
+		case "tenant":
+			fs.StringVar(&f.opts.Tenant, "tenant", "", "only objects of this tenant")
+		case "tag":
+			fs.StringSliceVar(&f.opts.Tags, "tag", nil, "only objects with this tag (slug)")
+		case "status":
+			fs.StringVar(&f.opts.Status, "status", "", "only objects with this status, e.g. active or planned")
+		}
+	}
+	fs.StringVar(&f.opts.Query, "q", "", "free-text search")
+	fs.StringVarP(&f.selector, "selector", "l", "", "selector (key=value,...) matching tags (tag=slug) or custom fields")
+	fs.Int64Var(&f.opts.Limit, "limit", 0, "maximum number of objects to return, 0 returns all objects")
+	fs.Int64Var(&f.opts.ChunkSize, "chunk-size", netbox.DefaultChunkSize, "number of objects requested from Netbox per page")
+}
+
+// unsupported returns the filters that are set but not among the filters of a kind
+func (f *listFlags) unsupported(filters []string) []string {
+	set := map[string]bool{
+		"site":   f.opts.Site != "",
+		"role":   f.opts.Role != "",
+		"type":   f.opts.DeviceType != "",
+		"tenant": f.opts.Tenant != "",
+		"tag":    len(f.opts.Tags) > 0,
+		"status": f.opts.Status != "",
+	}
+	for _, filter := range filters {
+		delete(set, filter)
+	}
+
+	unsupported := []string{}
+	for _, filter := range deviceFilters {
+		if set[filter] {
+			unsupported = append(unsupported, "--"+filter)
+		}
+	}
+	return unsupported
+}
+
+// listOptions returns the filters with the selector merged in
+func (f *listFlags) listOptions() (netbox.ListOptions, error) {
+	opts := f.opts
+	if f.selector == "" {
+		return opts, nil
+	}
+
+	selector, err := labels.ConvertSelectorToLabelsMap(f.selector)
+	if err != nil {
+		return opts, fmt.Errorf("failed to parse selector %q: %s", f.selector, err)
+	}
+
+	opts.Tags = append([]string{}, opts.Tags...)
+	opts.CustomFields = map[string]string{}
+	for k, v := range selector {
+		if k == "tag" {
+			opts.Tags = append(opts.Tags, v)
+			continue
+		}
+		opts.CustomFields[k] = v
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestKindFilterFlags(t *testing.T) {
+	tests := []struct {
+		kind objectKind
+		want map[string]bool
+	}{
+		{kind: regionKind, want: map[string]bool{"site": false, "tenant": false, "tag": false, "q": true}},
+		{kind: vlanGroupKind, want: map[string]bool{"site": true, "tenant": false, "tag": false, "q": true}},
+		{kind: vlanKind, want: map[string]bool{"site": true, "tenant": true, "tag": true, "role": false, "type": false}},
+		{kind: virtualMachineKind, want: map[string]bool{"site": true, "role": true, "type": false, "status": true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kind.name, func(t *testing.T) {
+			cmd := objectGetCommand(&Cli{}, tt.kind)
+			for flag, want := range tt.want {
+				if got := cmd.Flags().Lookup(flag) != nil; got != want {
+					t.Errorf("expected flag --%s registered %v, got %v", flag, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestUnsupportedFilters(t *testing.T) {
+	var filters listFlags
+	filters.opts.Site = "CITC"
+	filters.opts.Tags = []string{"lab"}
+	filters.opts.Query = "leaf"
+
+	tests := []struct {
+		name    string
+		filters []string
+		want    []string
+	}{
+		{name: "device", filters: deviceFilters, want: []string{}},
+		{name: "vlan", filters: vlanKind.filters, want: []string{}},
+		{name: "vlangroup", filters: vlanGroupKind.filters, want: []string{"--tag"}},
+		{name: "region", filters: regionKind.filters, want: []string{"--site", "--tag"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := filters.unsupported(tt.filters); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
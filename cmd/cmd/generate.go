@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func NewGenerateCommand(cli *Cli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate manifests from a compact description",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(newGenerateFabricCommand(cli))
+	return cmd
+}
+
+func newGenerateFabricCommand(cli *Cli) *cobra.Command {
+	var fileName, format string
+	cmd := &cobra.Command{
+		Use:   "fabric -f FILENAME",
+		Short: "Print the Devices, Interfaces, Cables and IPAddresses of the Fabrics in a file",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if fileName == "" {
+				return cmd.Help()
+			}
+
+			var encode objectEncoder
+			switch format {
+			case "json":
+				encode = newJsonEncoder(cli.Out)
+			case "yaml", "":
+				encode = newYamlEncoder(cli.Out)
+			default:
+				return fmt.Errorf("unsupported output format %q, expected one of: json|yaml", format)
+			}
+
+			return decodeManifest(fileName, func(obj runtime.Object) error {
+				f, ok := obj.(*netboxv1.Fabric)
+				if !ok {
+					return fmt.Errorf("unexpected kind %q in %s, expected %s", obj.GetObjectKind().GroupVersionKind().Kind, fileName, netboxv1.FabricKind)
+				}
+				objects, err := f.Expand()
+				if err != nil {
+					return fmt.Errorf("invalid fabric %q: %s", f.Name, err)
+				}
+				for _, o := range objects {
+					generated, err := exportObject(o)
+					if err != nil {
+						return err
+					}
+					if err := encode(generated); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		},
+	}
+
+	cmd.Flags().StringVarP(&fileName, "filename", "f", "", "file with the Fabrics")
+	cmd.Flags().StringVarP(&format, "output", "o", "yaml", "json|yaml")
+	return cmd
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"strconv"
+
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var interfaceKind = objectKind{
+	name:    "interface",
+	kind:    netboxv1.InterfaceKind,
+	filters: []string{"site", "tag"},
+	// interfaces are looked up by their name in Netbox, e.g. 'nbctl get interface swp1'
+	newObject: func(name string) netboxv1.Object {
+		return &netboxv1.Interface{
+			ObjectMeta: v1.ObjectMeta{
+				Name: name,
+			},
+		}
+	},
+	newList: func() runtime.Object {
+		return &netboxv1.InterfaceList{}
+	},
+	table: interfaceTable,
+}
+
+func interfaceTable(objects []netboxv1.Object, wide bool) tableData {
+	data := tableData{
+		headers: []string{"Name", "ID", "Device", "Interface", "Type"},
+	}
+	if wide {
+		data.headers = append(data.headers, "MTU", "State", "Deletion Policy")
+	}
+
+	for _, o := range objects {
+		i := o.(*netboxv1.Interface)
+		row := []interface{}{i.Name, objectID(i), i.Spec.Device, i.InterfaceName(), i.Spec.Type}
+		if wide {
+			mtu := ""
+			if i.Spec.MTU != 0 {
+				mtu = strconv.FormatInt(i.Spec.MTU, 10)
+			}
+			row = append(row, mtu, i.Status.State, i.Spec.DeletionPolicy)
+		}
+		data.rows = append(data.rows, row)
+	}
+
+	return data
+}
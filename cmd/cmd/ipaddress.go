@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var ipAddressKind = objectKind{
+	name:    "ipaddress",
+	kind:    netboxv1.IPAddressKind,
+	filters: []string{"tenant", "tag", "status"},
+	// addresses are looked up by the address, e.g. 'nbctl get ipaddress 10.0.0.1/32'
+	newObject: func(name string) netboxv1.Object {
+		return &netboxv1.IPAddress{
+			Spec: netboxv1.IPAddressSpec{
+				Address: name,
+			},
+		}
+	},
+	newList: func() runtime.Object {
+		return &netboxv1.IPAddressList{}
+	},
+	table: ipAddressTable,
+}
+
+func ipAddressTable(objects []netboxv1.Object, wide bool) tableData {
+	data := tableData{
+		headers: []string{"Name", "ID", "Address", "VRF", "Interface", "Status", "Role"},
+	}
+	if wide {
+		data.headers = append(data.headers, "DNS Name", "Tenant", "State", "Deletion Policy")
+	}
+
+	for _, o := range objects {
+		a := o.(*netboxv1.IPAddress)
+		intf := ""
+		if a.Spec.Interface != nil {
+			intf = a.Spec.Interface.Device + "/" + a.Spec.Interface.Name
+		}
+		if a.Spec.VMInterface != nil {
+			intf = a.Spec.VMInterface.VirtualMachine + "/" + a.Spec.VMInterface.Name
+		}
+		row := []interface{}{a.Name, objectID(a), a.Spec.Address, a.Spec.VRF, intf, a.Spec.Status, a.Spec.Role}
+		if wide {
+			row = append(row, a.Spec.DNSName, a.Spec.Tenant, a.Status.State, a.Spec.DeletionPolicy)
+		}
+		data.rows = append(data.rows, row)
+	}
+
+	return data
+}
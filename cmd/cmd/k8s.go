@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+func NewK8sCommand(cli *Cli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "k8s",
+		Short: "Manage Netbox resources in a Kubernetes cluster",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(NewK8sImportCommand(cli))
+	return cmd
+}
+
+// NewK8sImportCommand creates Device resources for existing Netbox devices. Each resource
+// carries the Netbox ID annotation, so the controller adopts the device instead of recreating it
+func NewK8sImportCommand(cli *Cli) *cobra.Command {
+	var namespace, policy string
+	var dryRun bool
+	var filters listFlags
+
+	cmd := &cobra.Command{
+		Use:   "import device [NAME]",
+		Short: "Import existing Netbox devices as Kubernetes resources",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if resourceName(args[0]) != "device" {
+				return fmt.Errorf("unsupported kind %q", args[0])
+			}
+			switch netboxv1.DeletionPolicy(policy) {
+			case netboxv1.DeletionPolicyDelete, netboxv1.DeletionPolicyOrphan:
+			default:
+				return fmt.Errorf("unexpected deletion policy %q, must be Delete or Orphan", policy)
+			}
+			name := ""
+			if len(args) == 2 {
+				name = args[1]
+			}
+
+			opts, err := filters.listOptions()
+			if err != nil {
+				return err
+			}
+
+			devices, err := cli.netbox.Get(cli.ctx, &netboxv1.Device{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: name,
+				},
+			}, opts)
+			if err != nil {
+				return err
+			}
+
+			objects := []*netboxv1.Device{}
+			for _, d := range devices {
+				if errs := validation.IsDNS1123Subdomain(d.Name); len(errs) > 0 {
+					log.Warnf("skipping device %q, not a valid resource name: %s", d.Name, strings.Join(errs, ", "))
+					continue
+				}
+				objects = append(objects, importDevice(d, namespace, netboxv1.DeletionPolicy(policy)))
+			}
+
+			if dryRun {
+				printable := []interface{}{}
+				for _, o := range objects {
+					printable = append(printable, o)
+				}
+				_, err := io.Copy(cli.Out, printYaml(printable))
+				return err
+			}
+
+			return createImported(cli, namespace, objects)
+		},
+	}
+
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "namespace to create the resources in")
+	cmd.Flags().StringVar(&policy, "deletion-policy", string(netboxv1.DeletionPolicyOrphan), "deletion policy of the imported resources (Delete or Orphan), Orphan keeps the devices in Netbox")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "only print the resources that would be created")
+	filters.addFlags(cmd.Flags(), deviceFilters)
+	return cmd
+}
+
+// importDevice returns the resource of a Netbox device, adopting it with the ID annotation.
+// The spec keeps everything read from Netbox, including the rack placement and the platform
+func importDevice(d netboxv1.Device, namespace string, policy netboxv1.DeletionPolicy) *netboxv1.Device {
+	spec := *d.Spec.DeepCopy()
+	spec.DeletionPolicy = policy
+
+	return &netboxv1.Device{
+		TypeMeta: d.TypeMeta,
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      d.Name,
+			Namespace: namespace,
+			Annotations: map[string]string{
+				netboxv1.IDAnnotation: strconv.FormatInt(*d.Status.ID, 10),
+			},
+		},
+		Spec: spec,
+	}
+}
+
+// createImported creates the resources that don't exist yet, skipping any
+// device that is already managed by a resource with the same name or Netbox ID
+func createImported(cli *Cli, namespace string, devices []*netboxv1.Device) error {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load kubeconfig: %s", err)
+	}
+
+	scheme, err := netboxv1.SchemeBuilder.Build()
+	if err != nil {
+		return fmt.Errorf("failed to build scheme for netboxv1")
+	}
+
+	k8s, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		return err
+	}
+
+	var existing netboxv1.DeviceList
+	if err := k8s.List(cli.ctx, &existing); err != nil {
+		return err
+	}
+
+	// devices can be managed from any namespace
+	managed := map[string]string{}
+	for _, d := range existing.Items {
+		owner := d.Namespace + "/" + d.Name
+		if id, ok := d.Annotations[netboxv1.IDAnnotation]; ok {
+			managed[id] = owner
+		}
+		if d.Status.ID != nil {
+			managed[strconv.FormatInt(*d.Status.ID, 10)] = owner
+		}
+	}
+
+	for _, d := range devices {
+		if owner, ok := managed[d.Annotations[netboxv1.IDAnnotation]]; ok {
+			fmt.Fprintf(cli.Out, "device/%s skipped, already managed by %s\n", d.Name, owner)
+			continue
+		}
+
+		err := k8s.Create(cli.ctx, d)
+		switch {
+		case apierrors.IsAlreadyExists(err):
+			fmt.Fprintf(cli.Out, "device/%s skipped, already exists\n", d.Name)
+		case err != nil:
+			return fmt.Errorf("failed to create device %q: %s", d.Name, err)
+		default:
+			fmt.Fprintf(cli.Out, "device/%s imported\n", d.Name)
+		}
+	}
+
+	return nil
+}
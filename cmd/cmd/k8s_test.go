@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestImportDevice(t *testing.T) {
+	id := int64(7)
+	d := netboxv1.Device{
+		ObjectMeta: metav1.ObjectMeta{Name: "leaf-99"},
+		Spec: netboxv1.DeviceSpec{
+			Site:       "CITC",
+			Role:       "leaf",
+			DeviceType: "SN3420",
+			Platform:   "cumulus",
+			Status:     "active",
+			Tenant:     "team-a",
+			Rack:       "r1",
+			Position:   40,
+			Face:       "rear",
+			Tags:       []string{"lab"},
+		},
+		Status: netboxv1.DeviceStatus{ID: &id, State: netboxv1.DeviceReadyState},
+	}
+
+	got := importDevice(d, "team-a", netboxv1.DeletionPolicyOrphan)
+
+	want := d.Spec
+	want.DeletionPolicy = netboxv1.DeletionPolicyOrphan
+	if !reflect.DeepEqual(got.Spec, want) {
+		t.Errorf("expected spec %+v, got %+v", want, got.Spec)
+	}
+	if got.Namespace != "team-a" || got.Annotations[netboxv1.IDAnnotation] != "7" {
+		t.Errorf("expected the ID annotation in namespace team-a, got %s/%s %v", got.Namespace, got.Name, got.Annotations)
+	}
+	if got.Status.ID != nil {
+		t.Errorf("expected no status, got ID %d", *got.Status.ID)
+	}
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var locationKind = objectKind{
+	name:    "location",
+	kind:    netboxv1.LocationKind,
+	filters: []string{"site"},
+	// locations are looked up by their name in Netbox, e.g. 'nbctl get location "Row 1"'
+	newObject: func(name string) netboxv1.Object {
+		return &netboxv1.Location{
+			ObjectMeta: v1.ObjectMeta{
+				Name: name,
+			},
+		}
+	},
+	newList: func() runtime.Object {
+		return &netboxv1.LocationList{}
+	},
+	table: locationTable,
+}
+
+func locationTable(objects []netboxv1.Object, wide bool) tableData {
+	data := tableData{
+		headers: []string{"Name", "ID", "Site", "Location", "Parent"},
+	}
+	if wide {
+		data.headers = append(data.headers, "Slug", "State", "Deletion Policy")
+	}
+
+	for _, o := range objects {
+		l := o.(*netboxv1.Location)
+		row := []interface{}{l.Name, objectID(l), l.Spec.Site, l.LocationName(), l.Spec.Parent}
+		if wide {
+			row = append(row, l.Spec.Slug, l.Status.State, l.Spec.DeletionPolicy)
+		}
+		data.rows = append(data.rows, row)
+	}
+
+	return data
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"io"
+	"strconv"
+
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	"github.com/networkop/declarative-netbox/netbox"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// objectKind describes how the kinds handled by the generic Netbox lifecycle are listed and printed
+type objectKind struct {
+	// name is the resource name, e.g. "cable"
+	name string
+	kind string
+	// filters are the server-side filters of the kind, see deviceFilters
+	filters []string
+	// newObject returns an object with the name, which is empty to list all objects
+	newObject func(name string) netboxv1.Object
+	newList   func() runtime.Object
+	table     func(objects []netboxv1.Object, wide bool) tableData
+}
+
+func NewObjectResource(c *Cli, k objectKind) *Resource {
+	return &Resource{
+		Name:    k.name,
+		Filters: k.filters,
+		Get: func() *cobra.Command {
+			return objectGetCommand(c, k)
+		},
+		Each: func(name string, opts netbox.ListOptions, fn func(interface{}) error) error {
+			return c.netbox.Each(c.ctx, k.newObject(name), opts, fn)
+		},
+	}
+}
+
+func objectGetCommand(c *Cli, k objectKind) *cobra.Command {
+	var output printFlags
+	var filters listFlags
+	var watch watchFlags
+	cmd := &cobra.Command{
+		Use:     k.name,
+		Aliases: []string{pluralName(k.name)},
+		Short:   "Get " + pluralName(k.name),
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			name := ""
+			if len(args) == 1 {
+				name = args[0]
+			}
+			opts, err := filters.listOptions()
+			if err != nil {
+				return err
+			}
+			get := func() ([]netboxv1.Object, error) {
+				objects := []netboxv1.Object{}
+				err := c.netbox.Each(c.ctx, k.newObject(name), opts, func(o interface{}) error {
+					objects = append(objects, o.(netboxv1.Object))
+					return nil
+				})
+				return objects, err
+			}
+
+			if watch.watch {
+				return objectWatch(c, k, get, output, watch)
+			}
+
+			objects, err := get()
+			if err != nil {
+				return err
+			}
+			return objectPrintCommand(c.Out, k, objects, output)
+		},
+	}
+	output.addFlags(cmd.PersistentFlags())
+	filters.addFlags(cmd.Flags(), k.filters)
+	watch.addFlags(cmd.Flags())
+	return cmd
+}
+
+func objectPrintCommand(w io.Writer, k objectKind, objects []netboxv1.Object, output printFlags) error {
+	names := []string{}
+	for _, o := range objects {
+		names = append(names, k.name+"/"+o.GetName())
+	}
+
+	list, err := objectList(k, objects)
+	if err != nil {
+		return err
+	}
+
+	return output.print(w, list, names, func(wide bool) tableData {
+		return k.table(objects, wide)
+	})
+}
+
+func objectWatch(c *Cli, k objectKind, get func() ([]netboxv1.Object, error), output printFlags, watch watchFlags) error {
+	w, err := newWatcher(c.Out, output, watch.events)
+	if err != nil {
+		return err
+	}
+
+	return w.run(c.ctx, watch.interval, func() ([]watchObject, error) {
+		objects, err := get()
+		if err != nil {
+			return nil, err
+		}
+
+		result := []watchObject{}
+		for _, o := range objects {
+			o := o
+			result = append(result, watchObject{
+				key:    objectID(o),
+				name:   k.name + "/" + o.GetName(),
+				object: o,
+				table: func(wide bool) tableData {
+					return k.table([]netboxv1.Object{o}, wide)
+				},
+			})
+		}
+		return result, nil
+	})
+}
+
+// objectList wraps the objects in the list kind, e.g. CableList
+func objectList(k objectKind, objects []netboxv1.Object) (runtime.Object, error) {
+	list := k.newList()
+	list.GetObjectKind().SetGroupVersionKind(netboxv1.GroupVersion.WithKind(k.kind + "List"))
+
+	items := []runtime.Object{}
+	for _, o := range objects {
+		items = append(items, o)
+	}
+	if err := meta.SetList(list, items); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// objectID returns the Netbox ID of the object, empty if it doesn't exist in Netbox
+func objectID(o netboxv1.Object) string {
+	if id := o.GetObjectStatus().ID; id != nil {
+		return strconv.FormatInt(*id, 10)
+	}
+	return ""
+}
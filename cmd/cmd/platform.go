@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var platformKind = objectKind{
+	name: "platform",
+	kind: netboxv1.PlatformKind,
+	// platforms are looked up by their name in Netbox, e.g. 'nbctl get platform eos'
+	newObject: func(name string) netboxv1.Object {
+		return &netboxv1.Platform{
+			ObjectMeta: v1.ObjectMeta{
+				Name: name,
+			},
+		}
+	},
+	newList: func() runtime.Object {
+		return &netboxv1.PlatformList{}
+	},
+	table: platformTable,
+}
+
+func platformTable(objects []netboxv1.Object, wide bool) tableData {
+	data := tableData{
+		headers: []string{"Name", "ID", "Platform", "Manufacturer"},
+	}
+	if wide {
+		data.headers = append(data.headers, "Slug", "NAPALM Driver", "State", "Deletion Policy")
+	}
+
+	for _, o := range objects {
+		p := o.(*netboxv1.Platform)
+		row := []interface{}{p.Name, objectID(p), p.PlatformName(), p.Spec.Manufacturer}
+		if wide {
+			row = append(row, p.Spec.Slug, p.Spec.NapalmDriver, p.Status.State, p.Spec.DeletionPolicy)
+		}
+		data.rows = append(data.rows, row)
+	}
+
+	return data
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var prefixKind = objectKind{
+	name:    "prefix",
+	kind:    netboxv1.PrefixKind,
+	filters: []string{"site", "tenant", "tag", "status"},
+	// prefixes are looked up by the prefix, e.g. 'nbctl get prefix 10.0.0.0/24'
+	newObject: func(name string) netboxv1.Object {
+		return &netboxv1.Prefix{
+			Spec: netboxv1.PrefixSpec{
+				Prefix: name,
+			},
+		}
+	},
+	newList: func() runtime.Object {
+		return &netboxv1.PrefixList{}
+	},
+	table: prefixTable,
+}
+
+func prefixTable(objects []netboxv1.Object, wide bool) tableData {
+	data := tableData{
+		headers: []string{"Name", "ID", "Prefix", "VRF", "Site", "Status"},
+	}
+	if wide {
+		data.headers = append(data.headers, "Tenant", "Pool", "State", "Deletion Policy")
+	}
+
+	for _, o := range objects {
+		p := o.(*netboxv1.Prefix)
+		row := []interface{}{p.Name, objectID(p), p.Spec.Prefix, p.Spec.VRF, p.Spec.Site, p.Spec.Status}
+		if wide {
+			row = append(row, p.Spec.Tenant, p.Spec.IsPool, p.Status.State, p.Spec.DeletionPolicy)
+		}
+		data.rows = append(data.rows, row)
+	}
+
+	return data
+}
@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// objectEncoder writes objects one at a time, so that large outputs don't need to be buffered
+type objectEncoder func(object interface{}) error
+
+// newYamlEncoder writes each object as a separate YAML document
+func newYamlEncoder(w io.Writer) objectEncoder {
+	yamlEncoder := yaml.NewEncoder(w)
+	yamlEncoder.SetIndent(2)
+
+	return func(o interface{}) error {
+		var jsonObj interface{}
+		b, err := json.Marshal(o)
+		if err != nil {
+			return err
+		}
+
+		if err := json.Unmarshal(b, &jsonObj); err != nil {
+			return err
+		}
+		return yamlEncoder.Encode(jsonObj)
+	}
+}
+
+// newJsonEncoder writes each object as a separate JSON document
+func newJsonEncoder(w io.Writer) objectEncoder {
+	jsonEncoder := json.NewEncoder(w)
+	jsonEncoder.SetIndent("", "  ")
+
+	return func(o interface{}) error {
+		return jsonEncoder.Encode(o)
+	}
+}
+
+// printYaml encodes each object as a separate YAML document
+func printYaml(objects []interface{}) io.Reader {
+	var b bytes.Buffer
+
+	encode := newYamlEncoder(&b)
+	for _, o := range objects {
+		if err := encode(o); err != nil {
+			log.Errorf("failed to encode yaml %s", err)
+			continue
+		}
+	}
+
+	return &b
+}
+
+// printJson encodes each object as a separate JSON document
+func printJson(objects []interface{}) io.Reader {
+	var b bytes.Buffer
+
+	encode := newJsonEncoder(&b)
+	for _, o := range objects {
+		if err := encode(o); err != nil {
+			log.Errorf("failed to encode json %s", err)
+			continue
+		}
+	}
+
+	return &b
+}
+
+// printFlags are the output options shared by the get commands
+type printFlags struct {
+	format    string
+	noHeaders bool
+}
+
+func (p *printFlags) addFlags(fs *pflag.FlagSet) {
+	fs.StringVarP(&p.format, "output", "o", "", strings.Join(allowedFormats(), "|"))
+	fs.BoolVar(&p.noHeaders, "no-headers", false, "don't print headers in table, wide and csv output")
+}
+
+// tableData holds the columns shown for a kind in table, wide and csv output
+type tableData struct {
+	headers []string
+	rows    [][]interface{}
+}
+
+// print writes the list in the requested format. The list is used for the structured
+// formats, names are used by '-o name' and table returns the columns for the table formats
+func (p *printFlags) print(w io.Writer, list interface{}, names []string, table func(wide bool) tableData) error {
+	switch {
+	case p.format == "yaml":
+		return newYamlEncoder(w)(list)
+	case p.format == "json":
+		return newJsonEncoder(w)(list)
+	case p.format == "name":
+		for _, name := range names {
+			fmt.Fprintln(w, name)
+		}
+		return nil
+	case strings.HasPrefix(p.format, "jsonpath="):
+		return printJsonPath(w, list, strings.TrimPrefix(p.format, "jsonpath="))
+	case strings.HasPrefix(p.format, "go-template="):
+		return printTemplate(w, list, strings.TrimPrefix(p.format, "go-template="))
+	case p.format == "csv":
+		return printCSV(w, table(false), p.noHeaders)
+	case p.format == "wide":
+		return printTable(w, table(true), p.noHeaders)
+	case p.format == "":
+		return printTable(w, table(false), p.noHeaders)
+	default:
+		return fmt.Errorf("unsupported output format %q, expected one of: %s", p.format, strings.Join(allowedFormats(), "|"))
+	}
+}
+
+func printTable(w io.Writer, data tableData, noHeaders bool) error {
+	tw := table.NewWriter()
+	if !noHeaders {
+		header := table.Row{}
+		for _, h := range data.headers {
+			header = append(header, h)
+		}
+		tw.AppendHeader(header)
+	}
+
+	for _, row := range data.rows {
+		tw.AppendRow(table.Row(row))
+	}
+
+	_, err := fmt.Fprintln(w, tw.Render())
+	return err
+}
+
+func printCSV(w io.Writer, data tableData, noHeaders bool) error {
+	cw := csv.NewWriter(w)
+	if !noHeaders {
+		if err := cw.Write(data.headers); err != nil {
+			return err
+		}
+	}
+
+	for _, row := range data.rows {
+		record := []string{}
+		for _, v := range row {
+			record = append(record, fmt.Sprint(v))
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// toUnstructured converts an object to maps and slices, the way kubectl templates see it
+func toUnstructured(object interface{}) (interface{}, error) {
+	b, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+
+	var result interface{}
+	if err := json.Unmarshal(b, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func printJsonPath(w io.Writer, object interface{}, expression string) error {
+	jp := jsonpath.New("output")
+	if err := jp.Parse(expression); err != nil {
+		return fmt.Errorf("failed to parse jsonpath %q: %s", expression, err)
+	}
+
+	data, err := toUnstructured(object)
+	if err != nil {
+		return err
+	}
+
+	if err := jp.Execute(w, data); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w)
+	return err
+}
+
+func printTemplate(w io.Writer, object interface{}, text string) error {
+	tmpl, err := template.New("output").Parse(text)
+	if err != nil {
+		return fmt.Errorf("failed to parse go-template %q: %s", text, err)
+	}
+
+	data, err := toUnstructured(object)
+	if err != nil {
+		return err
+	}
+
+	return tmpl.Execute(w, data)
+}
+
+func allowedFormats() []string {
+	return []string{"json", "yaml", "wide", "name", "csv", "jsonpath=...", "go-template=..."}
+}
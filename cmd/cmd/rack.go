@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"strconv"
+
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var rackKind = objectKind{
+	name:    "rack",
+	kind:    netboxv1.RackKind,
+	filters: []string{"site", "tenant", "tag", "status"},
+	// racks are looked up by their name in Netbox, e.g. 'nbctl get rack r1'
+	newObject: func(name string) netboxv1.Object {
+		return &netboxv1.Rack{
+			ObjectMeta: v1.ObjectMeta{
+				Name: name,
+			},
+		}
+	},
+	newList: func() runtime.Object {
+		return &netboxv1.RackList{}
+	},
+	table: rackTable,
+}
+
+func rackTable(objects []netboxv1.Object, wide bool) tableData {
+	data := tableData{
+		headers: []string{"Name", "ID", "Site", "Rack", "Location", "Status"},
+	}
+	if wide {
+		data.headers = append(data.headers, "Units", "Role", "Tenant", "State", "Deletion Policy")
+	}
+
+	for _, o := range objects {
+		r := o.(*netboxv1.Rack)
+		row := []interface{}{r.Name, objectID(r), r.Spec.Site, r.RackName(), r.Spec.Location, r.Spec.Status}
+		if wide {
+			units := ""
+			if r.Spec.UHeight != 0 {
+				units = strconv.FormatInt(r.Spec.UHeight, 10)
+			}
+			row = append(row, units, r.Spec.Role, r.Spec.Tenant, r.Status.State, r.Spec.DeletionPolicy)
+		}
+		data.rows = append(data.rows, row)
+	}
+
+	return data
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var regionKind = objectKind{
+	name: "region",
+	kind: netboxv1.RegionKind,
+	// regions are looked up by their name in Netbox, e.g. 'nbctl get region emea'
+	newObject: func(name string) netboxv1.Object {
+		return &netboxv1.Region{
+			ObjectMeta: v1.ObjectMeta{
+				Name: name,
+			},
+		}
+	},
+	newList: func() runtime.Object {
+		return &netboxv1.RegionList{}
+	},
+	table: regionTable,
+}
+
+func regionTable(objects []netboxv1.Object, wide bool) tableData {
+	data := tableData{
+		headers: []string{"Name", "ID", "Region", "Parent"},
+	}
+	if wide {
+		data.headers = append(data.headers, "Slug", "State", "Deletion Policy")
+	}
+
+	for _, o := range objects {
+		r := o.(*netboxv1.Region)
+		row := []interface{}{r.Name, objectID(r), r.RegionName(), r.Spec.Parent}
+		if wide {
+			row = append(row, r.Spec.Slug, r.Status.State, r.Spec.DeletionPolicy)
+		}
+		data.rows = append(data.rows, row)
+	}
+
+	return data
+}
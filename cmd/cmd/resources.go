@@ -1,9 +1,57 @@
 package cmd
 
+// resourceOrder lists the resources in dependency order, so that the
+// output of 'nbctl export --all-kinds' can be applied from top to bottom
+var resourceOrder = []string{
+	"region",
+	"sitegroup",
+	"tenantgroup",
+	"tenant",
+	"site",
+	"location",
+	"rack",
+	"vlangroup",
+	"vlan",
+	"routetarget",
+	"vrf",
+	"prefix",
+	"platform",
+	"devicetype",
+	"clustertype",
+	"cluster",
+	"virtualmachine",
+	"device",
+	"interface",
+	"vminterface",
+	"cable",
+	"ipaddress",
+}
+
 func GetResources(c *Cli) map[string]*Resource {
 	resources := make(map[string]*Resource)
 
+	resources["region"] = NewObjectResource(c, regionKind)
+	resources["sitegroup"] = NewObjectResource(c, siteGroupKind)
+	resources["tenantgroup"] = NewObjectResource(c, tenantGroupKind)
+	resources["tenant"] = NewObjectResource(c, tenantKind)
+	resources["site"] = NewObjectResource(c, siteKind)
+	resources["location"] = NewObjectResource(c, locationKind)
+	resources["rack"] = NewObjectResource(c, rackKind)
+	resources["vlangroup"] = NewObjectResource(c, vlanGroupKind)
+	resources["vlan"] = NewObjectResource(c, vlanKind)
+	resources["routetarget"] = NewObjectResource(c, routeTargetKind)
+	resources["vrf"] = NewObjectResource(c, vrfKind)
+	resources["prefix"] = NewObjectResource(c, prefixKind)
+	resources["platform"] = NewObjectResource(c, platformKind)
+	resources["devicetype"] = NewObjectResource(c, deviceTypeKind)
+	resources["clustertype"] = NewObjectResource(c, clusterTypeKind)
+	resources["cluster"] = NewObjectResource(c, clusterKind)
+	resources["virtualmachine"] = NewObjectResource(c, virtualMachineKind)
 	resources["device"] = NewDeviceResource(c)
+	resources["interface"] = NewObjectResource(c, interfaceKind)
+	resources["vminterface"] = NewObjectResource(c, vmInterfaceKind)
+	resources["cable"] = NewObjectResource(c, cableKind)
+	resources["ipaddress"] = NewObjectResource(c, ipAddressKind)
 
 	return resources
 }
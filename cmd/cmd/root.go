@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
 	"github.com/networkop/declarative-netbox/netbox"
 	"github.com/sirupsen/logrus"
 	log "github.com/sirupsen/logrus"
@@ -15,6 +16,15 @@ import (
 
 var debug bool
 
+// commands that can run before a Netbox server is configured
+var noLoginCommands = map[string]bool{
+	"login":      true,
+	"help":       true,
+	"completion": true,
+	"generate":   true,
+	"fabric":     true,
+}
+
 func addGlobalFlags(fs *pflag.FlagSet) {
 	fs.BoolVarP(&debug, "debug", "d", debug, "Enable debug-level logging")
 }
@@ -37,18 +47,22 @@ func Execute(version, gitCommit string) error {
 	var cli *Cli
 	cli, err := NewCli(cliOpts...)
 	if err != nil {
-		logrus.Error("Error initializing CLI: %s", err)
+		logrus.Errorf("Error initializing CLI: %s", err)
 		os.Exit(1)
 	}
 
 	root := &cobra.Command{
 		Use:   "nbctl [command]",
 		Short: "Unofficial CLI client for netbox",
-		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			if debug {
 				log.SetLevel(logrus.DebugLevel)
 			}
 
+			if cli.netbox == nil && !noLoginCommands[cmd.Name()] {
+				return fmt.Errorf("no Netbox server configured, run 'nbctl login' first")
+			}
+			return nil
 		},
 		Version: fmt.Sprintf("version: %q, commit: %q", version, gitCommit),
 	}
@@ -60,6 +74,10 @@ func Execute(version, gitCommit string) error {
 		NewGetCommand(cli),
 		NewApplyCommand(cli),
 		NewDeleteCommand(cli),
+		NewExportCommand(cli),
+		NewGenerateCommand(cli),
+		NewK8sCommand(cli),
+		NewAuditCommand(cli),
 		NewAuthCommand(cli),
 	)
 
@@ -67,6 +85,7 @@ func Execute(version, gitCommit string) error {
 }
 
 func NewAuthCommand(c *Cli) *cobra.Command {
+	var opts netbox.TransportOptions
 
 	cmd := &cobra.Command{
 		Use:   "login <server> <token>",
@@ -75,11 +94,11 @@ func NewAuthCommand(c *Cli) *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 
 			auth := NewAuthData()
-			if err := auth.SaveAuth(args[0], args[1]); err != nil {
+			if err := auth.SaveAuth(args[0], args[1], opts); err != nil {
 				return err
 			}
 
-			if err := netbox.AuthCheck(args[0], args[1]); err != nil {
+			if err := netbox.AuthCheck(args[0], args[1], opts); err != nil {
 				return err
 			}
 
@@ -87,6 +106,13 @@ func NewAuthCommand(c *Cli) *cobra.Command {
 			return nil
 		},
 	}
+
+	cmd.Flags().StringVar(&opts.CAFile, "ca-file", "", "PEM bundle used to verify the Netbox server certificate")
+	cmd.Flags().StringVar(&opts.CertFile, "cert-file", "", "client certificate for mutual TLS")
+	cmd.Flags().StringVar(&opts.KeyFile, "key-file", "", "client key for mutual TLS")
+	cmd.Flags().BoolVar(&opts.InsecureSkipVerify, "insecure-skip-verify", false, "do not verify the Netbox server certificate")
+	cmd.Flags().StringVar(&opts.Proxy, "proxy", "", "HTTP proxy URL (defaults to HTTP_PROXY/HTTPS_PROXY)")
+	cmd.Flags().DurationVar(&opts.Timeout, "timeout", 0, "Netbox API request timeout")
 	return cmd
 }
 
@@ -111,7 +137,7 @@ func NewGetCommand(cli *Cli) *cobra.Command {
 }
 
 func NewApplyCommand(cli *Cli) *cobra.Command {
-	var fileName string
+	var fileName, defaultsFile string
 	cmd := &cobra.Command{
 		Use:   "apply -f FILENAME",
 		Short: "Apply a configuration to a resource by filename",
@@ -121,7 +147,14 @@ func NewApplyCommand(cli *Cli) *cobra.Command {
 				cmd.Help()
 				return nil
 			}
-			if err := Action(cli, ApplyAction, fileName); err != nil {
+			var defaults []netboxv1.NetboxDefaults
+			if defaultsFile != "" {
+				var err error
+				if defaults, err = loadDefaults(defaultsFile); err != nil {
+					return err
+				}
+			}
+			if err := Action(cli, ApplyAction, fileName, defaults...); err != nil {
 				return err
 			}
 			return nil
@@ -129,11 +162,13 @@ func NewApplyCommand(cli *Cli) *cobra.Command {
 	}
 
 	cmd.PersistentFlags().StringVarP(&fileName, "filename", "f", "", "filename to apply")
+	cmd.PersistentFlags().StringVar(&defaultsFile, "defaults", "", "file with NetboxDefaults that fill the unset fields, same as the controller's mutating webhook")
 	return cmd
 }
 
 func NewDeleteCommand(cli *Cli) *cobra.Command {
-	var fileName string
+	var fileName, defaultsFile string
+	var orphan bool
 	cmd := &cobra.Command{
 		Use:   "delete -f FILENAME",
 		Short: "Delete a configuration by filename",
@@ -143,7 +178,18 @@ func NewDeleteCommand(cli *Cli) *cobra.Command {
 				cmd.Help()
 				return nil
 			}
-			if err := Action(cli, DeleteAction, fileName); err != nil {
+			a := DeleteAction
+			if orphan {
+				a = OrphanAction
+			}
+			var defaults []netboxv1.NetboxDefaults
+			if defaultsFile != "" {
+				var err error
+				if defaults, err = loadDefaults(defaultsFile); err != nil {
+					return err
+				}
+			}
+			if err := Action(cli, a, fileName, defaults...); err != nil {
 				return err
 			}
 			return nil
@@ -151,5 +197,7 @@ func NewDeleteCommand(cli *Cli) *cobra.Command {
 	}
 
 	cmd.PersistentFlags().StringVarP(&fileName, "filename", "f", "", "filename to apply")
+	cmd.PersistentFlags().BoolVar(&orphan, "orphan", false, "leave the objects in Netbox and only remove the managed tag")
+	cmd.PersistentFlags().StringVar(&defaultsFile, "defaults", "", "file with NetboxDefaults that fill the unset fields, same as the controller's mutating webhook")
 	return cmd
 }
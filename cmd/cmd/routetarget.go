@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var routeTargetKind = objectKind{
+	name:    "routetarget",
+	kind:    netboxv1.RouteTargetKind,
+	filters: []string{"tenant", "tag"},
+	// route targets are looked up by their value, e.g. 'nbctl get routetarget 65000:100'
+	newObject: func(name string) netboxv1.Object {
+		return &netboxv1.RouteTarget{
+			Spec: netboxv1.RouteTargetSpec{
+				Name: name,
+			},
+		}
+	},
+	newList: func() runtime.Object {
+		return &netboxv1.RouteTargetList{}
+	},
+	table: routeTargetTable,
+}
+
+func routeTargetTable(objects []netboxv1.Object, wide bool) tableData {
+	data := tableData{
+		headers: []string{"Name", "ID", "Route Target", "Tenant"},
+	}
+	if wide {
+		data.headers = append(data.headers, "Description", "State", "Deletion Policy")
+	}
+
+	for _, o := range objects {
+		r := o.(*netboxv1.RouteTarget)
+		row := []interface{}{r.Name, objectID(r), r.RouteTargetName(), r.Spec.Tenant}
+		if wide {
+			row = append(row, r.Spec.Description, r.Status.State, r.Spec.DeletionPolicy)
+		}
+		data.rows = append(data.rows, row)
+	}
+
+	return data
+}
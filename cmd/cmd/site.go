@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var siteKind = objectKind{
+	name:    "site",
+	kind:    netboxv1.SiteKind,
+	filters: []string{"tenant", "tag", "status"},
+	// sites are looked up by their name in Netbox, e.g. 'nbctl get site CITC'
+	newObject: func(name string) netboxv1.Object {
+		return &netboxv1.Site{
+			ObjectMeta: v1.ObjectMeta{
+				Name: name,
+			},
+		}
+	},
+	newList: func() runtime.Object {
+		return &netboxv1.SiteList{}
+	},
+	table: siteTable,
+}
+
+func siteTable(objects []netboxv1.Object, wide bool) tableData {
+	data := tableData{
+		headers: []string{"Name", "ID", "Site", "Status", "Tenant"},
+	}
+	if wide {
+		data.headers = append(data.headers, "Slug", "Region", "Group", "Facility", "Time Zone", "State", "Deletion Policy")
+	}
+
+	for _, o := range objects {
+		s := o.(*netboxv1.Site)
+		row := []interface{}{s.Name, objectID(s), s.SiteName(), s.Spec.Status, s.Spec.Tenant}
+		if wide {
+			row = append(row, s.Spec.Slug, s.Spec.Region, s.Spec.Group, s.Spec.Facility, s.Spec.TimeZone, s.Status.State, s.Spec.DeletionPolicy)
+		}
+		data.rows = append(data.rows, row)
+	}
+
+	return data
+}
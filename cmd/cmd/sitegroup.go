@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var siteGroupKind = objectKind{
+	name: "sitegroup",
+	kind: netboxv1.SiteGroupKind,
+	// site groups are looked up by their name in Netbox, e.g. 'nbctl get sitegroup edge'
+	newObject: func(name string) netboxv1.Object {
+		return &netboxv1.SiteGroup{
+			ObjectMeta: v1.ObjectMeta{
+				Name: name,
+			},
+		}
+	},
+	newList: func() runtime.Object {
+		return &netboxv1.SiteGroupList{}
+	},
+	table: siteGroupTable,
+}
+
+func siteGroupTable(objects []netboxv1.Object, wide bool) tableData {
+	data := tableData{
+		headers: []string{"Name", "ID", "Site Group", "Parent"},
+	}
+	if wide {
+		data.headers = append(data.headers, "Slug", "State", "Deletion Policy")
+	}
+
+	for _, o := range objects {
+		g := o.(*netboxv1.SiteGroup)
+		row := []interface{}{g.Name, objectID(g), g.SiteGroupName(), g.Spec.Parent}
+		if wide {
+			row = append(row, g.Spec.Slug, g.Status.State, g.Spec.DeletionPolicy)
+		}
+		data.rows = append(data.rows, row)
+	}
+
+	return data
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var tenantKind = objectKind{
+	name:    "tenant",
+	kind:    netboxv1.TenantKind,
+	filters: []string{"tag"},
+	// tenants are looked up by their name in Netbox, e.g. 'nbctl get tenant team-a'
+	newObject: func(name string) netboxv1.Object {
+		return &netboxv1.Tenant{
+			ObjectMeta: v1.ObjectMeta{
+				Name: name,
+			},
+		}
+	},
+	newList: func() runtime.Object {
+		return &netboxv1.TenantList{}
+	},
+	table: tenantTable,
+}
+
+func tenantTable(objects []netboxv1.Object, wide bool) tableData {
+	data := tableData{
+		headers: []string{"Name", "ID", "Tenant", "Group"},
+	}
+	if wide {
+		data.headers = append(data.headers, "Slug", "State", "Deletion Policy")
+	}
+
+	for _, o := range objects {
+		t := o.(*netboxv1.Tenant)
+		row := []interface{}{t.Name, objectID(t), t.TenantName(), t.Spec.Group}
+		if wide {
+			row = append(row, t.Spec.Slug, t.Status.State, t.Spec.DeletionPolicy)
+		}
+		data.rows = append(data.rows, row)
+	}
+
+	return data
+}
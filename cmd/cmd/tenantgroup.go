@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var tenantGroupKind = objectKind{
+	name: "tenantgroup",
+	kind: netboxv1.TenantGroupKind,
+	// tenant groups are looked up by their name in Netbox, e.g. 'nbctl get tenantgroup customers'
+	newObject: func(name string) netboxv1.Object {
+		return &netboxv1.TenantGroup{
+			ObjectMeta: v1.ObjectMeta{
+				Name: name,
+			},
+		}
+	},
+	newList: func() runtime.Object {
+		return &netboxv1.TenantGroupList{}
+	},
+	table: tenantGroupTable,
+}
+
+func tenantGroupTable(objects []netboxv1.Object, wide bool) tableData {
+	data := tableData{
+		headers: []string{"Name", "ID", "Tenant Group", "Parent"},
+	}
+	if wide {
+		data.headers = append(data.headers, "Slug", "State", "Deletion Policy")
+	}
+
+	for _, o := range objects {
+		g := o.(*netboxv1.TenantGroup)
+		row := []interface{}{g.Name, objectID(g), g.TenantGroupName(), g.Spec.Parent}
+		if wide {
+			row = append(row, g.Spec.Slug, g.Status.State, g.Spec.DeletionPolicy)
+		}
+		data.rows = append(data.rows, row)
+	}
+
+	return data
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"strconv"
+
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var virtualMachineKind = objectKind{
+	name:    "virtualmachine",
+	kind:    netboxv1.VirtualMachineKind,
+	filters: []string{"site", "role", "tenant", "tag", "status"},
+	// virtual machines are looked up by their name in Netbox, e.g. 'nbctl get virtualmachine web-1'
+	newObject: func(name string) netboxv1.Object {
+		return &netboxv1.VirtualMachine{
+			ObjectMeta: v1.ObjectMeta{
+				Name: name,
+			},
+		}
+	},
+	newList: func() runtime.Object {
+		return &netboxv1.VirtualMachineList{}
+	},
+	table: virtualMachineTable,
+}
+
+func virtualMachineTable(objects []netboxv1.Object, wide bool) tableData {
+	data := tableData{
+		headers: []string{"Name", "ID", "Virtual Machine", "Cluster", "Status", "Role"},
+	}
+	if wide {
+		data.headers = append(data.headers, "Platform", "Tenant", "vCPUs", "Memory", "Disk", "State", "Deletion Policy")
+	}
+
+	for _, o := range objects {
+		v := o.(*netboxv1.VirtualMachine)
+		row := []interface{}{v.Name, objectID(v), v.VirtualMachineName(), v.Spec.Cluster, v.Spec.Status, v.Spec.Role}
+		if wide {
+			resources := []interface{}{}
+			for _, r := range []int64{v.Spec.VCPUs, v.Spec.Memory, v.Spec.Disk} {
+				value := ""
+				if r != 0 {
+					value = strconv.FormatInt(r, 10)
+				}
+				resources = append(resources, value)
+			}
+			row = append(row, v.Spec.Platform, v.Spec.Tenant)
+			row = append(row, resources...)
+			row = append(row, v.Status.State, v.Spec.DeletionPolicy)
+		}
+		data.rows = append(data.rows, row)
+	}
+
+	return data
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var vlanKind = objectKind{
+	name:    "vlan",
+	kind:    netboxv1.VLANKind,
+	filters: []string{"site", "tenant", "tag", "status"},
+	// VLANs are looked up by their name in Netbox, e.g. 'nbctl get vlan servers'
+	newObject: func(name string) netboxv1.Object {
+		return &netboxv1.VLAN{
+			ObjectMeta: v1.ObjectMeta{
+				Name: name,
+			},
+		}
+	},
+	newList: func() runtime.Object {
+		return &netboxv1.VLANList{}
+	},
+	table: vlanTable,
+}
+
+func vlanTable(objects []netboxv1.Object, wide bool) tableData {
+	data := tableData{
+		headers: []string{"Name", "ID", "VID", "VLAN", "Site", "Group"},
+	}
+	if wide {
+		data.headers = append(data.headers, "Status", "Tenant", "State", "Deletion Policy")
+	}
+
+	for _, o := range objects {
+		v := o.(*netboxv1.VLAN)
+		row := []interface{}{v.Name, objectID(v), v.Spec.VID, v.VLANName(), v.Spec.Site, v.Spec.Group}
+		if wide {
+			row = append(row, v.Spec.Status, v.Spec.Tenant, v.Status.State, v.Spec.DeletionPolicy)
+		}
+		data.rows = append(data.rows, row)
+	}
+
+	return data
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var vlanGroupKind = objectKind{
+	name:    "vlangroup",
+	kind:    netboxv1.VLANGroupKind,
+	filters: []string{"site"},
+	// VLAN groups are looked up by their name in Netbox, e.g. 'nbctl get vlangroup pod-1'
+	newObject: func(name string) netboxv1.Object {
+		return &netboxv1.VLANGroup{
+			ObjectMeta: v1.ObjectMeta{
+				Name: name,
+			},
+		}
+	},
+	newList: func() runtime.Object {
+		return &netboxv1.VLANGroupList{}
+	},
+	table: vlanGroupTable,
+}
+
+func vlanGroupTable(objects []netboxv1.Object, wide bool) tableData {
+	data := tableData{
+		headers: []string{"Name", "ID", "Group", "Site"},
+	}
+	if wide {
+		data.headers = append(data.headers, "Slug", "State", "Deletion Policy")
+	}
+
+	for _, o := range objects {
+		g := o.(*netboxv1.VLANGroup)
+		row := []interface{}{g.Name, objectID(g), g.VLANGroupName(), g.Spec.Site}
+		if wide {
+			row = append(row, g.Spec.Slug, g.Status.State, g.Spec.DeletionPolicy)
+		}
+		data.rows = append(data.rows, row)
+	}
+
+	return data
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"strconv"
+
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var vmInterfaceKind = objectKind{
+	name:    "vminterface",
+	kind:    netboxv1.VMInterfaceKind,
+	filters: []string{"tag"},
+	// VM interfaces are looked up by their name in Netbox, e.g. 'nbctl get vminterface eth0'
+	newObject: func(name string) netboxv1.Object {
+		return &netboxv1.VMInterface{
+			ObjectMeta: v1.ObjectMeta{
+				Name: name,
+			},
+		}
+	},
+	newList: func() runtime.Object {
+		return &netboxv1.VMInterfaceList{}
+	},
+	table: vmInterfaceTable,
+}
+
+func vmInterfaceTable(objects []netboxv1.Object, wide bool) tableData {
+	data := tableData{
+		headers: []string{"Name", "ID", "Virtual Machine", "Interface", "Enabled"},
+	}
+	if wide {
+		data.headers = append(data.headers, "MTU", "MAC Address", "State", "Deletion Policy")
+	}
+
+	for _, o := range objects {
+		i := o.(*netboxv1.VMInterface)
+		enabled := i.Spec.Enabled == nil || *i.Spec.Enabled
+		row := []interface{}{i.Name, objectID(i), i.Spec.VirtualMachine, i.VMInterfaceName(), enabled}
+		if wide {
+			mtu := ""
+			if i.Spec.MTU != 0 {
+				mtu = strconv.FormatInt(i.Spec.MTU, 10)
+			}
+			row = append(row, mtu, i.Spec.MACAddress, i.Status.State, i.Spec.DeletionPolicy)
+		}
+		data.rows = append(data.rows, row)
+	}
+
+	return data
+}
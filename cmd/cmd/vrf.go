@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"strings"
+
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var vrfKind = objectKind{
+	name:    "vrf",
+	kind:    netboxv1.VRFKind,
+	filters: []string{"tenant", "tag"},
+	// VRFs are looked up by their name in Netbox, e.g. 'nbctl get vrf customer-a'
+	newObject: func(name string) netboxv1.Object {
+		return &netboxv1.VRF{
+			ObjectMeta: v1.ObjectMeta{
+				Name: name,
+			},
+		}
+	},
+	newList: func() runtime.Object {
+		return &netboxv1.VRFList{}
+	},
+	table: vrfTable,
+}
+
+func vrfTable(objects []netboxv1.Object, wide bool) tableData {
+	data := tableData{
+		headers: []string{"Name", "ID", "VRF", "RD", "Tenant"},
+	}
+	if wide {
+		data.headers = append(data.headers, "Import Targets", "Export Targets", "State", "Deletion Policy")
+	}
+
+	for _, o := range objects {
+		v := o.(*netboxv1.VRF)
+		row := []interface{}{v.Name, objectID(v), v.VRFName(), v.Spec.RD, v.Spec.Tenant}
+		if wide {
+			row = append(row, strings.Join(v.Spec.ImportTargets, ","), strings.Join(v.Spec.ExportTargets, ","), v.Status.State, v.Spec.DeletionPolicy)
+		}
+		data.rows = append(data.rows, row)
+	}
+
+	return data
+}
@@ -0,0 +1,258 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/pflag"
+)
+
+const (
+	watchAdded    = "ADDED"
+	watchModified = "MODIFIED"
+	watchDeleted  = "DELETED"
+)
+
+// watchEvent is printed for every change when --output-watch-events is set,
+// following the format of 'kubectl get -w --output-watch-events'
+type watchEvent struct {
+	Type   string      `json:"type"`
+	Object interface{} `json:"object"`
+}
+
+// watchObject is a single object returned by the watched list
+type watchObject struct {
+	// key uniquely identifies the object, e.g. its Netbox ID
+	key string
+	// name is printed by '-o name', e.g. device/leaf-99
+	name   string
+	object interface{}
+	table  func(wide bool) tableData
+}
+
+type watchFlags struct {
+	watch    bool
+	events   bool
+	interval time.Duration
+}
+
+func (f *watchFlags) addFlags(fs *pflag.FlagSet) {
+	fs.BoolVarP(&f.watch, "watch", "w", false, "after listing the objects, watch Netbox for changes")
+	fs.BoolVar(&f.events, "output-watch-events", false, "output the event type (ADDED, MODIFIED, DELETED) of each change")
+	fs.DurationVar(&f.interval, "watch-interval", time.Second*5, "how often Netbox is polled for changes")
+}
+
+// watcher polls Netbox and prints the differences between consecutive lists
+type watcher struct {
+	out    io.Writer
+	output printFlags
+	events bool
+
+	encode  objectEncoder
+	table   bool
+	cw      *csv.Writer
+	headers bool
+
+	// pending holds the table rows of the current poll, widths the column widths
+	// computed from the first poll. A stream can't be realigned once printed, so later
+	// rows are padded to the same widths and longer values push the next columns out
+	pending [][]string
+	widths  []int
+}
+
+func newWatcher(out io.Writer, output printFlags, events bool) (*watcher, error) {
+	w := &watcher{
+		out:     out,
+		output:  output,
+		events:  events,
+		headers: !output.noHeaders,
+	}
+
+	switch {
+	case output.format == "json":
+		w.encode = newJsonEncoder(out)
+	case output.format == "yaml":
+		w.encode = newYamlEncoder(out)
+	case output.format == "csv":
+		w.cw = csv.NewWriter(out)
+	case output.format == "" || output.format == "wide":
+		w.table = true
+	case output.format == "name",
+		strings.HasPrefix(output.format, "jsonpath="),
+		strings.HasPrefix(output.format, "go-template="):
+	default:
+		return nil, fmt.Errorf("unsupported output format %q, expected one of: %s", output.format, strings.Join(allowedFormats(), "|"))
+	}
+
+	return w, nil
+}
+
+// run lists the objects every interval until the context is cancelled.
+// Objects seen for the first time are ADDED, changed objects are MODIFIED
+// and objects missing from the latest list are DELETED
+func (w *watcher) run(ctx context.Context, interval time.Duration, list func() ([]watchObject, error)) error {
+	seen := map[string]watchObject{}
+
+	for {
+		objects, err := list()
+		if err != nil {
+			log.Warnf("failed to list objects, retrying in %s: %s", interval, err)
+		} else {
+			current := map[string]watchObject{}
+			for _, o := range objects {
+				current[o.key] = o
+
+				prev, ok := seen[o.key]
+				switch {
+				case !ok:
+					err = w.emit(watchAdded, o)
+				case !reflect.DeepEqual(prev.object, o.object):
+					err = w.emit(watchModified, o)
+				}
+				if err != nil {
+					return err
+				}
+			}
+
+			for key, o := range seen {
+				if _, ok := current[key]; !ok {
+					if err := w.emit(watchDeleted, o); err != nil {
+						return err
+					}
+				}
+			}
+			seen = current
+
+			if err := w.flush(); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+func (w *watcher) emit(eventType string, o watchObject) error {
+	var data interface{} = o.object
+	if w.events {
+		data = watchEvent{Type: eventType, Object: o.object}
+	}
+
+	switch {
+	case w.encode != nil:
+		return w.encode(data)
+	case w.output.format == "name":
+		if w.events {
+			_, err := fmt.Fprintf(w.out, "%s %s\n", eventType, o.name)
+			return err
+		}
+		_, err := fmt.Fprintln(w.out, o.name)
+		return err
+	case strings.HasPrefix(w.output.format, "jsonpath="):
+		return printJsonPath(w.out, data, strings.TrimPrefix(w.output.format, "jsonpath="))
+	case strings.HasPrefix(w.output.format, "go-template="):
+		return printTemplate(w.out, data, strings.TrimPrefix(w.output.format, "go-template="))
+	}
+
+	table := o.table(w.output.format == "wide")
+
+	var header []string
+	if w.headers {
+		header = table.headers
+		if w.events {
+			header = append([]string{"Event"}, header...)
+		}
+		w.headers = false
+	}
+
+	records := [][]string{}
+	for _, row := range table.rows {
+		record := []string{}
+		if w.events {
+			record = append(record, eventType)
+		}
+		for _, v := range row {
+			record = append(record, fmt.Sprint(v))
+		}
+		records = append(records, record)
+	}
+
+	if w.cw != nil {
+		if header != nil {
+			records = append([][]string{header}, records...)
+		}
+		return w.cw.WriteAll(records)
+	}
+
+	if header != nil {
+		upper := []string{}
+		for _, h := range header {
+			upper = append(upper, strings.ToUpper(h))
+		}
+		w.pending = append(w.pending, upper)
+	}
+	w.pending = append(w.pending, records...)
+	return nil
+}
+
+// tablePadding separates the columns of the watch stream
+const tablePadding = 3
+
+// flush prints the table rows of the current poll. The column widths are computed from the
+// first poll that returned rows and kept for the rest of the stream
+func (w *watcher) flush() error {
+	if !w.table || len(w.pending) == 0 {
+		return nil
+	}
+	if w.widths == nil {
+		if w.events {
+			// every event type can follow the first poll
+			w.widths = []int{len(watchModified)}
+		}
+		for _, row := range w.pending {
+			for i, v := range row {
+				if i == len(w.widths) {
+					w.widths = append(w.widths, 0)
+				}
+				if n := utf8.RuneCountInString(v); n > w.widths[i] {
+					w.widths[i] = n
+				}
+			}
+		}
+	}
+
+	for _, row := range w.pending {
+		line := strings.Builder{}
+		for i, v := range row {
+			line.WriteString(v)
+			if i == len(row)-1 {
+				break
+			}
+			width := 0
+			if i < len(w.widths) {
+				width = w.widths[i]
+			}
+			pad := width - utf8.RuneCountInString(v)
+			if pad < 0 {
+				pad = 0
+			}
+			line.WriteString(strings.Repeat(" ", pad+tablePadding))
+		}
+		if _, err := fmt.Fprintln(w.out, line.String()); err != nil {
+			return err
+		}
+	}
+	w.pending = nil
+	return nil
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWatcherTableWidths(t *testing.T) {
+	var out bytes.Buffer
+	w, err := newWatcher(&out, printFlags{}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	object := func(name, site string) watchObject {
+		return watchObject{
+			key:  name,
+			name: "device/" + name,
+			table: func(wide bool) tableData {
+				return tableData{headers: []string{"Name", "Site"}, rows: [][]interface{}{{name, site}}}
+			},
+		}
+	}
+
+	// the first poll sets the widths, the later events are printed one by one
+	if err := w.emit(watchAdded, object("leaf-1", "lon1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.emit(watchAdded, object("spine-1", "lon1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.flush(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.emit(watchModified, object("leaf-2", "lon1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.flush(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.emit(watchDeleted, object("border-leaf-1", "lon1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "EVENT      NAME      SITE\n" +
+		"ADDED      leaf-1    lon1\n" +
+		"ADDED      spine-1   lon1\n" +
+		"MODIFIED   leaf-2    lon1\n" +
+		"DELETED    border-leaf-1   lon1\n"
+	if out.String() != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, out.String())
+	}
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	"github.com/networkop/declarative-netbox/netbox"
+)
+
+const (
+	deviceValidationPath = "/validate-netbox-networkop-co-uk-v1-device"
+	deviceDefaultingPath = "/mutate-netbox-networkop-co-uk-v1-device"
+)
+
+//+kubebuilder:webhook:path=/validate-netbox-networkop-co-uk-v1-device,mutating=false,failurePolicy=fail,sideEffects=None,groups=netbox.networkop.co.uk,resources=devices,verbs=create;update,versions=v1,name=vdevice.kb.io,admissionReviewVersions=v1
+
+// DeviceValidator rejects Devices that break the naming policy or the tenant of their
+// namespace, or would take over an existing unmanaged Netbox device. Devices referring
+// to missing Netbox objects are allowed with a warning
+type DeviceValidator struct {
+	netbox      *netbox.NetboxServer
+	namePattern *regexp.Regexp
+	tenants     NamespaceTenants
+	decoder     *admission.Decoder
+}
+
+type DeviceValidatorOptions struct {
+	NetboxURL       string
+	NetboxToken     string
+	NetboxTransport netbox.TransportOptions
+	NetboxRetry     netbox.RetryOptions
+	// NamePattern is a regular expression that device names must match, if set
+	NamePattern string
+	// Tenants restricts the tenants of the devices in their namespaces
+	Tenants NamespaceTenants
+}
+
+// SetupWebhookWithManager registers the validating webhook with the webhook server of the manager
+func (v *DeviceValidator) SetupWebhookWithManager(mgr ctrl.Manager, opts DeviceValidatorOptions) error {
+	if opts.NamePattern != "" {
+		pattern, err := regexp.Compile(opts.NamePattern)
+		if err != nil {
+			return fmt.Errorf("invalid device name pattern %q: %w", opts.NamePattern, err)
+		}
+		v.namePattern = pattern
+	}
+	v.tenants = opts.Tenants
+
+	nb, err := netbox.NewNetboxServer(opts.NetboxURL, opts.NetboxToken, netbox.WithTransport(opts.NetboxTransport), netbox.WithRetry(opts.NetboxRetry))
+	if err != nil {
+		return err
+	}
+	v.netbox = nb
+
+	mgr.GetWebhookServer().Register(deviceValidationPath, &webhook.Admission{Handler: v})
+	return nil
+}
+
+// InjectDecoder implements admission.DecoderInjector
+func (v *DeviceValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
+
+func (v *DeviceValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	log := log.FromContext(ctx).WithValues("device", req.Name, "namespace", req.Namespace)
+	ctx = ctrl.LoggerInto(ctx, log)
+
+	var dev netboxv1.Device
+	if err := v.decoder.Decode(req, &dev); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if req.Operation == admissionv1.Update {
+		var old netboxv1.Device
+		if err := v.decoder.DecodeRaw(req.OldObject, &old); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		// finalizer and annotation updates, e.g. while the device is being deleted, are not checked against Netbox
+		if !dev.DeletionTimestamp.IsZero() || reflect.DeepEqual(old.Spec, dev.Spec) {
+			return admission.Allowed("")
+		}
+	}
+
+	// only new devices and spec changes are checked, devices that no longer match a tightened policy can still be deleted
+	if v.namePattern != nil && !v.namePattern.MatchString(dev.Name) {
+		return admission.Denied(fmt.Sprintf("device name %q does not match the naming policy %q", dev.Name, v.namePattern))
+	}
+
+	// in Force mode, the tenant is set by the mutating webhook before the device is validated
+	if err := v.tenants.scope(&dev, req.Namespace); err != nil {
+		return admission.Denied(err.Error())
+	}
+
+	// devices are matched by name, the existing Netbox device must belong to the tenant of the namespace
+	err := v.tenants.owns(ctx, v.netbox, &dev, req.Namespace)
+	if err == nil {
+		err = v.netbox.Validate(ctx, &dev)
+	}
+	switch {
+	case err == nil:
+		return admission.Allowed("")
+	case IsTenantMismatch(err), netbox.IsUnmanagedConflict(err), netbox.IsConflict(err):
+		return admission.Denied(err.Error())
+	case netbox.IsReferenceNotFound(err):
+		// the referenced objects may be applied together with the device, which is Pending until they exist
+		return admission.Allowed("").WithWarnings(fmt.Sprintf("device is Pending until its references exist: %s", err))
+	default:
+		// Netbox being unavailable must not block changes to the resources, the controller retries them
+		log.Error(err, "failed to validate device against Netbox")
+		return admission.Allowed("").WithWarnings(fmt.Sprintf("device was not validated against Netbox: %s", err))
+	}
+}
+
+//+kubebuilder:webhook:path=/mutate-netbox-networkop-co-uk-v1-device,mutating=true,failurePolicy=fail,sideEffects=None,groups=netbox.networkop.co.uk,resources=devices,verbs=create;update,versions=v1,name=mdevice.kb.io,admissionReviewVersions=v1
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=netboxdefaults,verbs=get;list;watch
+
+// DeviceDefaulter fills the unset fields of Devices from the NetboxDefaults that apply to their
+// namespace, and sets the tenant of the namespace if the tenants are forced
+type DeviceDefaulter struct {
+	Client  client.Reader
+	Tenants NamespaceTenants
+	decoder *admission.Decoder
+}
+
+// SetupWebhookWithManager registers the mutating webhook with the webhook server of the manager
+func (d *DeviceDefaulter) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	mgr.GetWebhookServer().Register(deviceDefaultingPath, &webhook.Admission{Handler: d})
+	return nil
+}
+
+// InjectDecoder implements admission.DecoderInjector
+func (d *DeviceDefaulter) InjectDecoder(decoder *admission.Decoder) error {
+	d.decoder = decoder
+	return nil
+}
+
+func (d *DeviceDefaulter) Handle(ctx context.Context, req admission.Request) admission.Response {
+	var dev netboxv1.Device
+	if err := d.decoder.Decode(req, &dev); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if !dev.DeletionTimestamp.IsZero() {
+		return admission.Allowed("")
+	}
+
+	var defaults netboxv1.NetboxDefaultsList
+	if err := d.Client.List(ctx, &defaults); err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	// the namespace is not set in the object of create requests that rely on the namespace of the request
+	namespace := dev.Namespace
+	if namespace == "" {
+		dev.Namespace = req.Namespace
+	}
+	dev.ApplyDefaults(defaults.Items)
+	dev.Namespace = namespace
+
+	if d.Tenants.Mode == TenantModeForce {
+		// Force mode never returns an error
+		_ = d.Tenants.scope(&dev, req.Namespace)
+	}
+
+	marshaled, err := json.Marshal(&dev)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshaled)
+}
@@ -0,0 +1,273 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	"github.com/networkop/declarative-netbox/netbox"
+)
+
+// fakeDeviceNetbox serves the role leaf, the device type 7050, the site lon1, the tenants
+// tenant-a and tenant-b, and the device leaf-01 of tenant-b, which is not managed
+func fakeDeviceNetbox(t *testing.T) *httptest.Server {
+	page := func(results ...string) string {
+		return fmt.Sprintf(`{"count": %d, "results": [%s]}`, len(results), strings.Join(results, ", "))
+	}
+	leaf1 := `{"id": 6, "name": "leaf-01", "tenant": {"id": 5, "name": "tenant-b", "slug": "tenant-b"}}`
+	// responses are keyed by the path and the name, or model, in the query
+	responses := map[string]string{
+		"/api/dcim/device-roles/?leaf":   page(`{"id": 1, "name": "leaf", "slug": "leaf"}`),
+		"/api/dcim/device-types/?7050":   page(`{"id": 2, "model": "7050", "slug": "7050"}`),
+		"/api/dcim/sites/?lon1":          page(`{"id": 3, "name": "lon1", "slug": "lon1"}`),
+		"/api/tenancy/tenants/?tenant-a": page(`{"id": 4, "name": "tenant-a", "slug": "tenant-a"}`),
+		"/api/tenancy/tenants/?tenant-b": page(`{"id": 5, "name": "tenant-b", "slug": "tenant-b"}`),
+		"/api/dcim/devices/?leaf-01":     page(leaf1),
+		"/api/dcim/devices/6/?":          leaf1,
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != http.MethodGet {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		name := r.URL.Query().Get("name")
+		if name == "" {
+			name = r.URL.Query().Get("model")
+		}
+		response, ok := responses[r.URL.Path+"?"+name]
+		if !ok {
+			response = page()
+		}
+		fmt.Fprint(w, response)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func testNetbox(t *testing.T, url string) *netbox.NetboxServer {
+	nb, err := netbox.NewNetboxServer(url, "token", netbox.WithRetry(netbox.RetryOptions{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return nb
+}
+
+func testDevice(name, site, tenant string) *netboxv1.Device {
+	return &netboxv1.Device{
+		TypeMeta:   metav1.TypeMeta{APIVersion: netboxv1.GroupVersion.String(), Kind: netboxv1.DeviceKind},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "team-a"},
+		Spec:       netboxv1.DeviceSpec{Site: site, DeviceType: "7050", Role: "leaf", Tenant: tenant},
+	}
+}
+
+// admissionRequest returns a request for the device, an update of old if it is set
+func admissionRequest(t *testing.T, dev, old *netboxv1.Device) admission.Request {
+	raw := func(obj runtime.Object) runtime.RawExtension {
+		data, err := json.Marshal(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return runtime.RawExtension{Raw: data}
+	}
+	req := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		Operation: admissionv1.Create,
+		Name:      dev.Name,
+		Namespace: dev.Namespace,
+		Object:    raw(dev),
+	}}
+	if old != nil {
+		req.Operation = admissionv1.Update
+		req.OldObject = raw(old)
+	}
+	return req
+}
+
+func TestDeviceValidatorHandle(t *testing.T) {
+	ctx := context.Background()
+
+	decoder, err := admission.NewDecoder(testScheme(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the closed server stands in for an unreachable Netbox
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	unreachable.Close()
+
+	deleting := testDevice("spine-01", "lon1", "tenant-a")
+	deleting.Finalizers = []string{netboxv1.DeviceFinalizer}
+	now := metav1.Now()
+	deleting.DeletionTimestamp = &now
+	finalized := deleting.DeepCopy()
+	finalized.Finalizers = nil
+
+	tests := []struct {
+		name        string
+		dev, old    *netboxv1.Device
+		mode        TenantMode
+		unreachable bool
+		allowed     bool
+		warning     bool
+	}{
+		{name: "name pattern", dev: testDevice("spine-01", "lon1", "tenant-a")},
+		{name: "finalizer removal of a device breaking the name pattern", dev: finalized, old: deleting, allowed: true},
+		{name: "new device", dev: testDevice("leaf-02", "lon1", "tenant-a"), allowed: true},
+		{name: "other tenant", dev: testDevice("leaf-02", "lon1", "tenant-b"), mode: TenantModeReject},
+		{name: "forced tenant", dev: testDevice("leaf-02", "lon1", "tenant-b"), mode: TenantModeForce, allowed: true},
+		{name: "existing device of other tenant", dev: testDevice("leaf-01", "lon1", "tenant-a"), mode: TenantModeForce},
+		{name: "missing site", dev: testDevice("leaf-02", "lon2", "tenant-a"), allowed: true, warning: true},
+		{name: "netbox unreachable", dev: testDevice("leaf-02", "lon1", "tenant-a"), unreachable: true, allowed: true, warning: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := fakeDeviceNetbox(t).URL
+			if tt.unreachable {
+				url = unreachable.URL
+			}
+			v := &DeviceValidator{
+				netbox:      testNetbox(t, url),
+				namePattern: regexp.MustCompile(`^leaf-[0-9]+$`),
+				decoder:     decoder,
+			}
+			if tt.mode != "" {
+				v.tenants = NamespaceTenants{Tenants: map[string]string{"team-a": "tenant-a"}, Mode: tt.mode}
+			}
+
+			resp := v.Handle(ctx, admissionRequest(t, tt.dev, tt.old))
+			if resp.Allowed != tt.allowed {
+				t.Errorf("expected allowed %v, got %v: %v", tt.allowed, resp.Allowed, resp.Result)
+			}
+			if (len(resp.Warnings) > 0) != tt.warning {
+				t.Errorf("expected warning %v, got %q", tt.warning, resp.Warnings)
+			}
+		})
+	}
+}
+
+func TestDeviceValidatorUnmanagedConflict(t *testing.T) {
+	decoder, err := admission.NewDecoder(testScheme(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := &DeviceValidator{netbox: testNetbox(t, fakeDeviceNetbox(t).URL), decoder: decoder}
+
+	// leaf-01 exists in Netbox without the managed tag
+	resp := v.Handle(context.Background(), admissionRequest(t, testDevice("leaf-01", "lon1", "tenant-b"), nil))
+	if resp.Allowed || resp.Result == nil || !strings.Contains(string(resp.Result.Reason), "not managed by declarative-netbox") {
+		t.Errorf("expected an unmanaged conflict, got %v", resp.Result)
+	}
+
+	adopted := testDevice("leaf-01", "lon1", "tenant-b")
+	adopted.Annotations = map[string]string{netboxv1.IDAnnotation: "6"}
+	if resp := v.Handle(context.Background(), admissionRequest(t, adopted, nil)); !resp.Allowed {
+		t.Errorf("expected the adopted device to be allowed, got %v", resp.Result)
+	}
+}
+
+func TestDeviceDefaulterHandle(t *testing.T) {
+	decoder, err := admission.NewDecoder(testScheme(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defaults := func(name string, device netboxv1.DeviceDefaults, namespaces ...string) *netboxv1.NetboxDefaults {
+		return &netboxv1.NetboxDefaults{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       netboxv1.NetboxDefaultsSpec{Namespaces: namespaces, Device: device},
+		}
+	}
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(
+		defaults("global", netboxv1.DeviceDefaults{Site: "lon1", Role: "leaf", Tenant: "tenant-c"}),
+		defaults("team-a", netboxv1.DeviceDefaults{Site: "lon2", DeviceType: "7050"}, "team-a"),
+		defaults("team-b", netboxv1.DeviceDefaults{Site: "lon3"}, "team-b"),
+	).Build()
+
+	tests := []struct {
+		name    string
+		dev     *netboxv1.Device
+		tenants NamespaceTenants
+		want    netboxv1.DeviceSpec
+	}{
+		{
+			name: "namespaced defaults first",
+			dev:  testDevice("leaf-01", "", ""),
+			want: netboxv1.DeviceSpec{Site: "lon2", DeviceType: "7050", Role: "leaf", Tenant: "tenant-c"},
+		},
+		{
+			name: "set fields are kept",
+			dev:  &netboxv1.Device{ObjectMeta: metav1.ObjectMeta{Name: "leaf-01", Namespace: "team-a"}, Spec: netboxv1.DeviceSpec{Site: "lon4", Tenant: "tenant-a"}},
+			want: netboxv1.DeviceSpec{Site: "lon4", DeviceType: "7050", Role: "leaf", Tenant: "tenant-a"},
+		},
+		{
+			name:    "forced tenant",
+			dev:     testDevice("leaf-01", "", ""),
+			tenants: NamespaceTenants{Tenants: map[string]string{"team-a": "tenant-a"}, Mode: TenantModeForce},
+			want:    netboxv1.DeviceSpec{Site: "lon2", DeviceType: "7050", Role: "leaf", Tenant: "tenant-a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &DeviceDefaulter{Client: c, Tenants: tt.tenants, decoder: decoder}
+
+			req := admissionRequest(t, tt.dev, nil)
+			resp := d.Handle(context.Background(), req)
+			if !resp.Allowed {
+				t.Fatalf("expected the device to be allowed, got %v", resp.Result)
+			}
+
+			// the patches only add the unset fields of the spec
+			got := tt.dev.Spec
+			for _, p := range resp.Patches {
+				value, _ := p.Value.(string)
+				switch p.Path {
+				case "/spec/site":
+					got.Site = value
+				case "/spec/device_type":
+					got.DeviceType = value
+				case "/spec/role":
+					got.Role = value
+				case "/spec/tenant":
+					got.Tenant = value
+				default:
+					t.Errorf("unexpected patch %s %s", p.Operation, p.Path)
+				}
+			}
+			if got.Site != tt.want.Site || got.DeviceType != tt.want.DeviceType || got.Role != tt.want.Role || got.Tenant != tt.want.Tenant {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
@@ -18,13 +18,26 @@ package controllers
 
 import (
 	"context"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/go-logr/logr"
 	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
@@ -34,13 +47,28 @@ import (
 // DeviceReconciler reconciles a Device object
 type DeviceReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
-	netbox *netbox.NetboxServer
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	netbox   *netbox.NetboxServer
+	// default deletion policy for devices that don't set one
+	deletionPolicy netboxv1.DeletionPolicy
+	tenants        NamespaceTenants
+	// devices enqueued by Netbox webhooks, which are re-applied even if their spec hasn't changed
+	webhookEvents chan event.GenericEvent
+	resync        sync.Map
 }
 
-type DeviceReconcilerOptions struct {
-	NetboxURL   string
-	NetboxToken string
+// ReconcilerOptions configure the Device controller and the object controllers
+type ReconcilerOptions struct {
+	NetboxURL       string
+	NetboxToken     string
+	NetboxTransport netbox.TransportOptions
+	NetboxRetry     netbox.RetryOptions
+	DeletionPolicy  netboxv1.DeletionPolicy
+	// Webhook receives the Netbox webhooks for the resources, if set
+	Webhook *WebhookReceiver
+	// Tenants restricts the tenants of the resources in their namespaces
+	Tenants NamespaceTenants
 }
 
 var retryInterval = time.Second * 5
@@ -48,6 +76,7 @@ var retryInterval = time.Second * 5
 //+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=devices,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=devices/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=devices/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -82,23 +111,24 @@ func (r *DeviceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		return r.reconcileDelete(ctx, dev)
 	}
 
-	// checking if the spec has changed
-	if dev.Status.ObservedGeneration == dev.Generation {
+	// checking if the spec has changed or the device was changed in Netbox,
+	// failed devices are retried even if their spec hasn't changed
+	_, resync := r.resync.LoadAndDelete(req.NamespacedName)
+	if dev.Status.ObservedGeneration == dev.Generation && dev.Status.State == netboxv1.DeviceReadyState && !resync {
 		log.V(1).Info("Requed object after status update. Doing nothing")
 		return ctrl.Result{}, nil
 	}
 
 	// handle create/update
+	observed := dev.Status.DeepCopy()
 	dev, result, err := r.reconcile(ctx, dev)
 
-	// Generation only changes after updates to spec
-	if dev.Status.ObservedGeneration != dev.Generation {
-		dev.Status.ObservedGeneration = dev.Generation
+	dev.Status.ObservedGeneration = dev.Generation
+	if !equality.Semantic.DeepEqual(observed, &dev.Status) {
 		if err := r.Client.Status().Update(ctx, &dev); err != nil {
 			log.Error(err, "unable to update Device status")
 			return ctrl.Result{}, err
 		}
-
 	}
 
 	log.V(1).Info("Reconciliation finished", "req", req)
@@ -110,10 +140,46 @@ func (r *DeviceReconciler) reconcile(ctx context.Context, dev netboxv1.Device) (
 	log := logr.FromContext(ctx)
 	log.V(1).Info("reconcile", "dev", dev)
 
-	if err := r.netbox.Apply(ctx, &dev); err != nil {
-		log.Error(err, "failed to r.netbox.Apply, retrying")
+	// a forced tenant is applied to Netbox, the status update doesn't change the spec
+	err := r.tenants.scope(&dev, dev.Namespace)
+	if err == nil {
+		err = r.tenants.owns(ctx, r.netbox, &dev, dev.Namespace)
+	}
+	if IsTenantMismatch(err) {
+		recordOutcome(netboxv1.DeviceKind, outcomeFailed)
+		r.Recorder.Event(&dev, corev1.EventTypeWarning, "TenantRejected", err.Error())
+		dev.Status.State = netboxv1.DeviceFailedState
+		dev.Status.Message = err.Error()
+		return dev, ctrl.Result{}, nil
+	}
+
+	var result *netbox.Result
+	if err == nil {
+		result, err = r.netbox.Apply(ctx, &dev)
+	}
+	if err != nil {
+		recordOutcome(netboxv1.DeviceKind, outcomeFailed)
+		r.recordError(&dev, "ApplyFailed", err)
+		dev.Status.State = netboxv1.DeviceFailedState
+		dev.Status.Message = err.Error()
+		if netbox.IsReferenceNotFound(err) {
+			// the site, rack or type may be applied together with the device
+			log.V(1).Info("waiting for the referenced objects", "reason", err.Error())
+			dev.Status.State = netboxv1.DevicePendingState
+		} else {
+			log.Error(err, "failed to r.netbox.Apply, retrying")
+		}
 		return dev, ctrl.Result{RequeueAfter: retryInterval}, nil
 	}
+	recordOutcome(netboxv1.DeviceKind, string(result.Operation))
+	dev.Status.Message = ""
+
+	switch result.Operation {
+	case netbox.OperationCreated:
+		r.Recorder.Eventf(&dev, corev1.EventTypeNormal, "Created", "Created device in Netbox with ID %d", *dev.Status.ID)
+	case netbox.OperationUpdated:
+		r.Recorder.Eventf(&dev, corev1.EventTypeNormal, "Updated", "Updated device %d in Netbox, changed fields: %s", *dev.Status.ID, strings.Join(result.Changed, ", "))
+	}
 
 	return dev, ctrl.Result{}, nil
 }
@@ -122,9 +188,38 @@ func (r *DeviceReconciler) reconcileDelete(ctx context.Context, dev netboxv1.Dev
 	log := logr.FromContext(ctx)
 	log.V(1).Info("reconcileDelete", "dev", dev)
 
-	if err := r.netbox.Delete(ctx, &dev); err != nil {
-		log.Error(err, "failed to r.netbox.Delete, retrying")
+	policy := dev.GetDeletionPolicy()
+	if policy == "" {
+		policy = r.deletionPolicy
+	}
+
+	// the Netbox devices of other tenants are left unchanged
+	if err := r.tenants.owns(ctx, r.netbox, &dev, dev.Namespace); IsTenantMismatch(err) {
+		recordOutcome(netboxv1.DeviceKind, outcomeFailed)
+		r.Recorder.Eventf(&dev, corev1.EventTypeWarning, "TenantRejected", "Left device in Netbox unchanged: %s", err)
+	} else if err != nil {
+		log.Error(err, "failed to check the tenant in Netbox, retrying")
+		recordOutcome(netboxv1.DeviceKind, outcomeFailed)
+		r.recordError(&dev, "DeleteFailed", err)
 		return ctrl.Result{RequeueAfter: retryInterval}, err
+	} else if policy == netboxv1.DeletionPolicyOrphan {
+		if err := r.netbox.Orphan(ctx, &dev); err != nil {
+			log.Error(err, "failed to r.netbox.Orphan, retrying")
+			recordOutcome(netboxv1.DeviceKind, outcomeFailed)
+			r.recordError(&dev, "OrphanFailed", err)
+			return ctrl.Result{RequeueAfter: retryInterval}, err
+		}
+		recordOutcome(netboxv1.DeviceKind, outcomeOrphaned)
+		r.Recorder.Event(&dev, corev1.EventTypeNormal, "Orphaned", "Left device in Netbox and removed the managed tag")
+	} else {
+		if err := r.netbox.Delete(ctx, &dev); err != nil {
+			log.Error(err, "failed to r.netbox.Delete, retrying")
+			recordOutcome(netboxv1.DeviceKind, outcomeFailed)
+			r.recordError(&dev, "DeleteFailed", err)
+			return ctrl.Result{RequeueAfter: retryInterval}, err
+		}
+		recordOutcome(netboxv1.DeviceKind, outcomeDeleted)
+		r.Recorder.Event(&dev, corev1.EventTypeNormal, "Deleted", "Deleted device from Netbox")
 	}
 
 	// Remove finalizer to allow for the resource to be cleaned up
@@ -138,15 +233,125 @@ func (r *DeviceReconciler) reconcileDelete(ctx context.Context, dev netboxv1.Dev
 	return ctrl.Result{}, nil
 }
 
+// recordError emits a Warning event, using a specific reason for the errors that need user action
+func (r *DeviceReconciler) recordError(dev *netboxv1.Device, reason string, err error) {
+	switch {
+	case netbox.IsReferenceNotFound(err):
+		reason = "ReferenceNotFound"
+	case netbox.IsConflict(err), netbox.IsUnmanagedConflict(err):
+		reason = "Conflict"
+	case netbox.IsAuthFailed(err):
+		reason = "AuthFailed"
+	}
+	r.Recorder.Event(dev, corev1.EventTypeWarning, reason, err.Error())
+}
+
 // SetupWithManager sets up the controller with the Manager.
-func (r *DeviceReconciler) SetupWithManager(mgr ctrl.Manager, opts DeviceReconcilerOptions) error {
+func (r *DeviceReconciler) SetupWithManager(mgr ctrl.Manager, opts ReconcilerOptions) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &netboxv1.Device{}, netboxIDField, deviceNetboxID); err != nil {
+		return err
+	}
+
+	r.webhookEvents = make(chan event.GenericEvent, 100)
 	if err := ctrl.NewControllerManagedBy(mgr).
 		For(&netboxv1.Device{}).
+		Watches(&source.Channel{Source: r.webhookEvents}, &handler.EnqueueRequestForObject{}).
+		Watches(&source.Kind{Type: &netboxv1.DeviceType{}}, handler.EnqueueRequestsFromMapFunc(r.devicesOfType),
+			builder.WithPredicates(predicate.Funcs{
+				CreateFunc:  func(event.CreateEvent) bool { return false },
+				UpdateFunc:  deviceTypeApplied,
+				DeleteFunc:  func(event.DeleteEvent) bool { return false },
+				GenericFunc: func(event.GenericEvent) bool { return false },
+			})).
 		Complete(r); err != nil {
 		return err
 	}
 
-	r.netbox = netbox.NewNetboxServer(opts.NetboxURL, opts.NetboxToken)
+	if opts.Webhook != nil {
+		opts.Webhook.Register(netboxv1.DeviceModel, func() client.ObjectList {
+			return &netboxv1.DeviceList{}
+		}, r.enqueueWebhook)
+	}
+
+	nb, err := netbox.NewNetboxServer(opts.NetboxURL, opts.NetboxToken, netbox.WithTransport(opts.NetboxTransport), netbox.WithRetry(opts.NetboxRetry))
+	if err != nil {
+		return err
+	}
+	r.netbox = nb
+
+	r.deletionPolicy = opts.DeletionPolicy
+	if r.deletionPolicy == "" {
+		r.deletionPolicy = netboxv1.DeletionPolicyDelete
+	}
+	r.tenants = opts.Tenants
+
+	return metrics.Registry.Register(&objectsCollector{client: mgr.GetClient()})
+}
+
+// enqueueWebhook marks a device changed in Netbox for resync and enqueues it. The event is
+// dropped if the queue is full, the device is then corrected by the next periodic resync
+func (r *DeviceReconciler) enqueueWebhook(obj client.Object) {
+	r.resync.Store(client.ObjectKeyFromObject(obj), true)
+	select {
+	case r.webhookEvents <- event.GenericEvent{Object: obj}:
+	default:
+		ctrl.Log.WithName("webhook").Info("dropped webhook event, queue is full", "name", obj.GetName(), "namespace", obj.GetNamespace())
+	}
+}
+
+// deviceTypeApplied passes the status updates of device types that have just been applied to
+// Netbox, after which the devices syncing their components may miss new templates
+func deviceTypeApplied(e event.UpdateEvent) bool {
+	old, ok := e.ObjectOld.(*netboxv1.DeviceType)
+	if !ok {
+		return false
+	}
+	deviceType, ok := e.ObjectNew.(*netboxv1.DeviceType)
+	if !ok {
+		return false
+	}
+	applied := func(t *netboxv1.DeviceType) bool {
+		return t.Status.State == netboxv1.ObjectReadyState && t.Status.ObservedGeneration == deviceType.Generation
+	}
+	return applied(deviceType) && !applied(old)
+}
 
+// devicesOfType marks the devices of the device type that sync their components for resync
+func (r *DeviceReconciler) devicesOfType(obj client.Object) []reconcile.Request {
+	deviceType, ok := obj.(*netboxv1.DeviceType)
+	if !ok {
+		return nil
+	}
+
+	var devices netboxv1.DeviceList
+	if err := r.List(context.Background(), &devices); err != nil {
+		ctrl.Log.WithName("devicetype").Error(err, "unable to list devices", "name", deviceType.Name)
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, dev := range devices.Items {
+		if !dev.Spec.SyncComponents || dev.Spec.DeviceType != deviceType.DeviceTypeModelName() {
+			continue
+		}
+		key := client.ObjectKeyFromObject(&dev)
+		r.resync.Store(key, true)
+		requests = append(requests, reconcile.Request{NamespacedName: key})
+	}
+	return requests
+}
+
+// deviceNetboxID indexes devices by their Netbox ID, taken from the status or the ID annotation of adopted devices
+func deviceNetboxID(obj client.Object) []string {
+	dev, ok := obj.(*netboxv1.Device)
+	if !ok {
+		return nil
+	}
+	if dev.Status.ID != nil {
+		return []string{strconv.FormatInt(*dev.Status.ID, 10)}
+	}
+	if id, ok := dev.Annotations[netboxv1.IDAnnotation]; ok {
+		return []string{id}
+	}
 	return nil
 }
@@ -0,0 +1,220 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+)
+
+// FabricReconciler expands Fabrics into Devices, Interfaces, Cables and IPAddresses. The generated
+// resources are owned by the Fabric, so deleting the Fabric deletes them and their Netbox objects
+type FabricReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+}
+
+// fabricChildLists are the kinds of the resources generated from a Fabric
+var fabricChildLists = []func() client.ObjectList{
+	func() client.ObjectList { return &netboxv1.DeviceList{} },
+	func() client.ObjectList { return &netboxv1.InterfaceList{} },
+	func() client.ObjectList { return &netboxv1.CableList{} },
+	func() client.ObjectList { return &netboxv1.IPAddressList{} },
+}
+
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=fabrics,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=fabrics/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=fabrics/finalizers,verbs=update
+
+func (r *FabricReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	log.V(1).Info("Reconcile", "req", req)
+
+	var fabric netboxv1.Fabric
+	if err := r.Get(ctx, req.NamespacedName, &fabric); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	// the generated resources are removed by the garbage collector
+	if !fabric.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	observed := fabric.Status.DeepCopy()
+	err := r.reconcile(ctx, &fabric)
+	if err != nil {
+		log.Error(err, "failed to reconcile fabric")
+		r.Recorder.Event(&fabric, corev1.EventTypeWarning, "ReconcileFailed", err.Error())
+		fabric.Status.State = netboxv1.ObjectFailedState
+		fabric.Status.Message = err.Error()
+	}
+
+	fabric.Status.ObservedGeneration = fabric.Generation
+	if !equality.Semantic.DeepEqual(observed, &fabric.Status) {
+		if err := r.Status().Update(ctx, &fabric); err != nil {
+			log.Error(err, "unable to update status")
+			return ctrl.Result{}, err
+		}
+	}
+
+	log.V(1).Info("Reconciliation finished", "req", req)
+
+	// failures to apply the resources are retried, invalid fabrics wait for their spec to change
+	if err != nil && fabric.Status.Resources > 0 {
+		return ctrl.Result{RequeueAfter: retryInterval}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// reconcile creates or updates the generated resources, removes the ones that are
+// no longer generated and counts the resources that are Ready
+func (r *FabricReconciler) reconcile(ctx context.Context, fabric *netboxv1.Fabric) error {
+	log := log.FromContext(ctx)
+
+	children, err := fabric.Expand()
+	if err != nil {
+		fabric.Status.Resources = 0
+		return err
+	}
+	fabric.Status.Resources = len(children)
+
+	ready := 0
+	wanted := map[string]bool{}
+	for _, desired := range children {
+		child := desired.DeepCopyObject().(client.Object)
+		op, err := controllerutil.CreateOrUpdate(ctx, r.Client, child, func() error {
+			return r.mutateChild(fabric, child, desired)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to apply %s %q: %w", desired.GetObjectKind().GroupVersionKind().Kind, desired.GetName(), err)
+		}
+		if op != controllerutil.OperationResultNone {
+			log.V(1).Info("applied fabric resource", "kind", desired.GetObjectKind().GroupVersionKind().Kind, "name", desired.GetName(), "operation", op)
+		}
+		wanted[childKey(desired)] = true
+		if childReady(child) {
+			ready++
+		}
+	}
+
+	if err := r.prune(ctx, fabric, wanted); err != nil {
+		return err
+	}
+
+	fabric.Status.Ready = ready
+	fabric.Status.Message = ""
+	fabric.Status.State = netboxv1.ObjectReadyState
+	if ready < len(children) {
+		fabric.Status.State = netboxv1.ObjectPendingState
+		fabric.Status.Message = fmt.Sprintf("%d of %d resources are ready", ready, len(children))
+	}
+	return nil
+}
+
+// mutateChild sets the spec, the fabric label and the owner of an existing or new resource
+func (r *FabricReconciler) mutateChild(fabric *netboxv1.Fabric, child, desired client.Object) error {
+	switch c := child.(type) {
+	case *netboxv1.Device:
+		c.Spec = desired.(*netboxv1.Device).Spec
+	case *netboxv1.Interface:
+		c.Spec = desired.(*netboxv1.Interface).Spec
+	case *netboxv1.Cable:
+		c.Spec = desired.(*netboxv1.Cable).Spec
+	case *netboxv1.IPAddress:
+		c.Spec = desired.(*netboxv1.IPAddress).Spec
+	default:
+		return fmt.Errorf("unexpected fabric resource %T", child)
+	}
+
+	labels := child.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[netboxv1.FabricLabel] = fabric.Name
+	child.SetLabels(labels)
+
+	return controllerutil.SetControllerReference(fabric, child, r.Scheme)
+}
+
+// prune deletes the resources of the fabric that are no longer generated, e.g. after reducing the number of leaves
+func (r *FabricReconciler) prune(ctx context.Context, fabric *netboxv1.Fabric, wanted map[string]bool) error {
+	log := log.FromContext(ctx)
+
+	for _, newList := range fabricChildLists {
+		list := newList()
+		if err := r.List(ctx, list, client.InNamespace(fabric.Namespace), client.MatchingLabels{netboxv1.FabricLabel: fabric.Name}); err != nil {
+			return err
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			child := item.(client.Object)
+			if wanted[childKey(child)] || !metav1.IsControlledBy(child, fabric) {
+				continue
+			}
+			if err := r.Delete(ctx, child); client.IgnoreNotFound(err) != nil {
+				return err
+			}
+			log.V(1).Info("deleted fabric resource", "kind", fmt.Sprintf("%T", child), "name", child.GetName())
+		}
+	}
+	return nil
+}
+
+// childKey identifies a generated resource by its Go type and name
+func childKey(obj client.Object) string {
+	return fmt.Sprintf("%T/%s", obj, obj.GetName())
+}
+
+func childReady(obj client.Object) bool {
+	switch o := obj.(type) {
+	case *netboxv1.Device:
+		return o.Status.State == netboxv1.DeviceReadyState && o.Status.ObservedGeneration == o.Generation
+	case netboxv1.Object:
+		status := o.GetObjectStatus()
+		return status.State == netboxv1.ObjectReadyState && status.ObservedGeneration == o.GetGeneration()
+	}
+	return false
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *FabricReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&netboxv1.Fabric{}).
+		Owns(&netboxv1.Device{}).
+		Owns(&netboxv1.Interface{}).
+		Owns(&netboxv1.Cable{}).
+		Owns(&netboxv1.IPAddress{}).
+		Complete(r)
+}
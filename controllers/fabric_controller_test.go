@@ -0,0 +1,156 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+)
+
+func testScheme(t *testing.T) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	if err := netboxv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	return scheme
+}
+
+func testFabric(name string) *netboxv1.Fabric {
+	return &netboxv1.Fabric{
+		TypeMeta:   metav1.TypeMeta{APIVersion: netboxv1.GroupVersion.String(), Kind: netboxv1.FabricKind},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "lab", UID: types.UID(name + "-uid")},
+	}
+}
+
+// fabricChild returns a resource of the fabric, owned by it if owner is set
+func fabricChild(t *testing.T, scheme *runtime.Scheme, obj client.Object, fabric, owner *netboxv1.Fabric) client.Object {
+	obj.SetNamespace("lab")
+	obj.SetLabels(map[string]string{netboxv1.FabricLabel: fabric.Name})
+	if owner != nil {
+		if err := controllerutil.SetControllerReference(owner, obj, scheme); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return obj
+}
+
+func TestFabricMutateChild(t *testing.T) {
+	scheme := testScheme(t)
+	fabric := testFabric("pod-1")
+	r := &FabricReconciler{Scheme: scheme}
+
+	desired := &netboxv1.Device{
+		ObjectMeta: metav1.ObjectMeta{Name: "leaf-01", Namespace: "lab"},
+		Spec:       netboxv1.DeviceSpec{Site: "lab", Role: "leaf", DeviceType: "cEOS"},
+	}
+
+	t.Run("existing resource", func(t *testing.T) {
+		child := &netboxv1.Device{
+			ObjectMeta: metav1.ObjectMeta{Name: "leaf-01", Namespace: "lab", Labels: map[string]string{"team": "net"}},
+			Spec:       netboxv1.DeviceSpec{Site: "old", Role: "spine", DeviceType: "cEOS"},
+		}
+		if err := r.mutateChild(fabric, child, desired); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(child.Spec, desired.Spec) {
+			t.Errorf("expected spec %+v, got %+v", desired.Spec, child.Spec)
+		}
+		if child.Labels["team"] != "net" || child.Labels[netboxv1.FabricLabel] != "pod-1" {
+			t.Errorf("unexpected labels %v", child.Labels)
+		}
+		if !metav1.IsControlledBy(child, fabric) {
+			t.Errorf("expected the fabric to control the resource, got %v", child.OwnerReferences)
+		}
+	})
+
+	t.Run("owned by another fabric", func(t *testing.T) {
+		child := fabricChild(t, scheme, &netboxv1.Device{ObjectMeta: metav1.ObjectMeta{Name: "leaf-01"}}, fabric, testFabric("pod-2"))
+		err := r.mutateChild(fabric, child, desired)
+		if _, ok := err.(*controllerutil.AlreadyOwnedError); !ok {
+			t.Errorf("expected an AlreadyOwnedError, got %v", err)
+		}
+	})
+
+	t.Run("unexpected kind", func(t *testing.T) {
+		child := &netboxv1.Site{ObjectMeta: metav1.ObjectMeta{Name: "lab"}}
+		if err := r.mutateChild(fabric, child, child); err == nil {
+			t.Errorf("expected an error")
+		}
+	})
+}
+
+func TestFabricPrune(t *testing.T) {
+	ctx := context.Background()
+	scheme := testScheme(t)
+	fabric := testFabric("pod-1")
+	other := testFabric("pod-2")
+
+	children := []struct {
+		obj     client.Object
+		deleted bool
+	}{
+		{obj: fabricChild(t, scheme, &netboxv1.Device{ObjectMeta: metav1.ObjectMeta{Name: "leaf-01"}}, fabric, fabric)},
+		{obj: fabricChild(t, scheme, &netboxv1.Interface{ObjectMeta: metav1.ObjectMeta{Name: "leaf-01-swp1"}}, fabric, fabric)},
+		// no longer generated
+		{obj: fabricChild(t, scheme, &netboxv1.Device{ObjectMeta: metav1.ObjectMeta{Name: "leaf-04"}}, fabric, fabric), deleted: true},
+		{obj: fabricChild(t, scheme, &netboxv1.Interface{ObjectMeta: metav1.ObjectMeta{Name: "leaf-04-swp1"}}, fabric, fabric), deleted: true},
+		{obj: fabricChild(t, scheme, &netboxv1.Cable{ObjectMeta: metav1.ObjectMeta{Name: "leaf-04-spine-01"}}, fabric, fabric), deleted: true},
+		{obj: fabricChild(t, scheme, &netboxv1.IPAddress{ObjectMeta: metav1.ObjectMeta{Name: "leaf-04-lo"}}, fabric, fabric), deleted: true},
+		// a Device and an Interface may share a name
+		{obj: fabricChild(t, scheme, &netboxv1.Device{ObjectMeta: metav1.ObjectMeta{Name: "leaf-01-swp1"}}, fabric, fabric), deleted: true},
+		// labelled by hand, not owned by the fabric
+		{obj: fabricChild(t, scheme, &netboxv1.Device{ObjectMeta: metav1.ObjectMeta{Name: "leaf-05"}}, fabric, nil)},
+		// owned by another fabric
+		{obj: fabricChild(t, scheme, &netboxv1.Device{ObjectMeta: metav1.ObjectMeta{Name: "leaf-06"}}, other, other)},
+	}
+	builder := fake.NewClientBuilder().WithScheme(scheme)
+	for _, child := range children {
+		builder = builder.WithObjects(child.obj.DeepCopyObject().(client.Object))
+	}
+	c := builder.Build()
+	r := &FabricReconciler{Client: c, Scheme: scheme}
+
+	wanted := map[string]bool{
+		childKey(&netboxv1.Device{ObjectMeta: metav1.ObjectMeta{Name: "leaf-01"}}):         true,
+		childKey(&netboxv1.Interface{ObjectMeta: metav1.ObjectMeta{Name: "leaf-01-swp1"}}): true,
+	}
+	if err := r.prune(ctx, fabric, wanted); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, child := range children {
+		obj := child.obj.DeepCopyObject().(client.Object)
+		err := c.Get(ctx, client.ObjectKeyFromObject(obj), obj)
+		if child.deleted && !apierrors.IsNotFound(err) {
+			t.Errorf("expected %T %s to be deleted, got %v", obj, obj.GetName(), err)
+		}
+		if !child.deleted && err != nil {
+			t.Errorf("expected %T %s to be kept, got %v", obj, obj.GetName(), err)
+		}
+	}
+}
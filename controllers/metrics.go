@@ -0,0 +1,124 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	"github.com/networkop/declarative-netbox/netbox"
+)
+
+const (
+	outcomeDeleted  = "deleted"
+	outcomeOrphaned = "orphaned"
+	outcomeFailed   = "failed"
+)
+
+var (
+	reconcileOutcomes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "netbox_reconcile_total",
+			Help: "Number of reconciliations by kind and outcome (created, updated, unchanged, deleted, orphaned, failed)",
+		},
+		[]string{"kind", "outcome"},
+	)
+
+	objectsDesc = prometheus.NewDesc(
+		"netbox_objects",
+		"Number of objects by kind and status state",
+		[]string{"kind", "state"}, nil,
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(reconcileOutcomes)
+	metrics.Registry.MustRegister(netbox.Collectors()...)
+}
+
+func recordOutcome(kind, outcome string) {
+	reconcileOutcomes.WithLabelValues(kind, outcome).Inc()
+}
+
+// objectsCollector counts the objects in each state at scrape time, reading from the informer cache
+type objectsCollector struct {
+	client client.Reader
+}
+
+func (c *objectsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- objectsDesc
+}
+
+func (c *objectsCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	var devices netboxv1.DeviceList
+	if err := c.client.List(ctx, &devices); err != nil {
+		ch <- prometheus.NewInvalidMetric(objectsDesc, err)
+		return
+	}
+
+	states := map[netboxv1.DeviceState]int{}
+	for _, d := range devices.Items {
+		state := d.Status.State
+		if state == "" {
+			state = netboxv1.DevicePendingState
+		}
+		states[state]++
+	}
+
+	for state, count := range states {
+		ch <- prometheus.MustNewConstMetric(objectsDesc, prometheus.GaugeValue, float64(count), netboxv1.DeviceKind, string(state))
+	}
+
+	for _, kind := range objectKinds {
+		list := kind.newList()
+		if err := c.client.List(ctx, list); err != nil {
+			ch <- prometheus.NewInvalidMetric(objectsDesc, err)
+			return
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			ch <- prometheus.NewInvalidMetric(objectsDesc, err)
+			return
+		}
+
+		states := map[netboxv1.ObjectState]int{}
+		for _, item := range items {
+			obj, ok := item.(netboxv1.Object)
+			if !ok {
+				continue
+			}
+			state := obj.GetObjectStatus().State
+			if state == "" {
+				state = netboxv1.ObjectPendingState
+			}
+			states[state]++
+		}
+
+		for state, count := range states {
+			ch <- prometheus.MustNewConstMetric(objectsDesc, prometheus.GaugeValue, float64(count), kind.kind, string(state))
+		}
+	}
+}
@@ -0,0 +1,485 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+)
+
+// NodeSyncKind is the kind of the resource synced from each Node
+type NodeSyncKind string
+
+const (
+	NodeSyncDevice         NodeSyncKind = "Device"
+	NodeSyncVirtualMachine NodeSyncKind = "VirtualMachine"
+)
+
+// NodeFields are the fields of the resources synced from a Node
+type NodeFields struct {
+	Site       string
+	Role       string
+	DeviceType string
+	Cluster    string
+	Tenant     string
+}
+
+// DefaultNodeLabels are the node labels holding the fields of the synced resources
+var DefaultNodeLabels = NodeFields{
+	Site:       "netbox.networkop.co.uk/site",
+	Role:       "netbox.networkop.co.uk/role",
+	DeviceType: "netbox.networkop.co.uk/device-type",
+	Cluster:    "netbox.networkop.co.uk/cluster",
+	Tenant:     "netbox.networkop.co.uk/tenant",
+}
+
+// DefaultNodeInterface is the name of the interface the internal IPs of a node are assigned to.
+// Nodes don't report their NICs, so the addresses get an interface of their own
+const DefaultNodeInterface = "k8s"
+
+// ParseNodeFields parses a comma separated list of field=value pairs into fields, e.g.
+// site=topology.kubernetes.io/zone. The fields are site, role, device_type, cluster and tenant
+func ParseNodeFields(s string, fields *NodeFields) error {
+	for _, pair := range strings.Split(s, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 || parts[1] == "" {
+			return fmt.Errorf("unexpected node field %q, must be field=value", pair)
+		}
+		switch parts[0] {
+		case "site":
+			fields.Site = parts[1]
+		case "role":
+			fields.Role = parts[1]
+		case "device_type":
+			fields.DeviceType = parts[1]
+		case "cluster":
+			fields.Cluster = parts[1]
+		case "tenant":
+			fields.Tenant = parts[1]
+		default:
+			return fmt.Errorf("unexpected node field %q, must be one of site, role, device_type, cluster or tenant", parts[0])
+		}
+	}
+	return nil
+}
+
+// NodeSyncOptions configure the NodeSyncReconciler
+type NodeSyncOptions struct {
+	// Namespace of the resources synced from the Nodes
+	Namespace string
+	Kind      NodeSyncKind
+	// Labels are the node labels holding the fields of the synced resources
+	Labels NodeFields
+	// Defaults are the fields of the nodes without the label
+	Defaults NodeFields
+	// Interface is the name of the virtual interface holding the internal IPs, defaults to DefaultNodeInterface
+	Interface string
+}
+
+// fields returns the fields of the resources synced from the node
+func (o NodeSyncOptions) fields(node *corev1.Node) NodeFields {
+	value := func(label, def string) string {
+		if v := node.Labels[label]; label != "" && v != "" {
+			return v
+		}
+		return def
+	}
+	return NodeFields{
+		Site:       value(o.Labels.Site, o.Defaults.Site),
+		Role:       value(o.Labels.Role, o.Defaults.Role),
+		DeviceType: value(o.Labels.DeviceType, o.Defaults.DeviceType),
+		Cluster:    value(o.Labels.Cluster, o.Defaults.Cluster),
+		Tenant:     value(o.Labels.Tenant, o.Defaults.Tenant),
+	}
+}
+
+// resources returns the resources synced from the node: a Device or a VirtualMachine named after
+// the node, which is offline while the node is cordoned, its virtual interface and an IPAddress
+// assigned to the interface for each internal IP
+func (o NodeSyncOptions) resources(node *corev1.Node) ([]client.Object, error) {
+	fields := o.fields(node)
+	status := "active"
+	if node.Spec.Unschedulable {
+		status = "offline"
+	}
+	intf := o.Interface
+	if intf == "" {
+		intf = DefaultNodeInterface
+	}
+	intfMeta := metav1.ObjectMeta{
+		Name:      netboxv1.ResourceName(node.Name, intf),
+		Namespace: o.Namespace,
+	}
+
+	var resources []client.Object
+	switch o.Kind {
+	case NodeSyncVirtualMachine:
+		if fields.Cluster == "" {
+			return nil, fmt.Errorf("node %q has no cluster, set the %s label or a default cluster", node.Name, o.Labels.Cluster)
+		}
+		vm := &netboxv1.VirtualMachine{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       netboxv1.VirtualMachineKind,
+				APIVersion: netboxv1.GroupVersion.String(),
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      node.Name,
+				Namespace: o.Namespace,
+			},
+			Spec: netboxv1.VirtualMachineSpec{
+				Cluster: fields.Cluster,
+				Status:  status,
+				Role:    fields.Role,
+				Tenant:  fields.Tenant,
+			},
+		}
+		// Netbox counts memory in MB and disk in GB
+		if cpu := node.Status.Capacity.Cpu(); !cpu.IsZero() {
+			vm.Spec.VCPUs = cpu.Value()
+		}
+		if memory := node.Status.Capacity.Memory(); !memory.IsZero() {
+			vm.Spec.Memory = memory.Value() / (1 << 20)
+		}
+		if disk := node.Status.Capacity.StorageEphemeral(); !disk.IsZero() {
+			vm.Spec.Disk = disk.Value() / (1 << 30)
+		}
+		resources = append(resources, vm, &netboxv1.VMInterface{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       netboxv1.VMInterfaceKind,
+				APIVersion: netboxv1.GroupVersion.String(),
+			},
+			ObjectMeta: intfMeta,
+			Spec: netboxv1.VMInterfaceSpec{
+				VirtualMachine: node.Name,
+				Name:           intf,
+			},
+		})
+	default:
+		resources = append(resources, &netboxv1.Device{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       netboxv1.DeviceKind,
+				APIVersion: netboxv1.GroupVersion.String(),
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      node.Name,
+				Namespace: o.Namespace,
+			},
+			Spec: netboxv1.DeviceSpec{
+				Site:       fields.Site,
+				Role:       fields.Role,
+				DeviceType: fields.DeviceType,
+				Status:     status,
+				Tenant:     fields.Tenant,
+			},
+		}, &netboxv1.Interface{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       netboxv1.InterfaceKind,
+				APIVersion: netboxv1.GroupVersion.String(),
+			},
+			ObjectMeta: intfMeta,
+			Spec: netboxv1.InterfaceSpec{
+				Device: node.Name,
+				Name:   intf,
+				Type:   "virtual",
+			},
+		})
+	}
+
+	for _, addr := range node.Status.Addresses {
+		if addr.Type != corev1.NodeInternalIP {
+			continue
+		}
+		ip := net.ParseIP(addr.Address)
+		if ip == nil {
+			continue
+		}
+		mask := "/32"
+		if ip.To4() == nil {
+			mask = "/128"
+		}
+		address := &netboxv1.IPAddress{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       netboxv1.IPAddressKind,
+				APIVersion: netboxv1.GroupVersion.String(),
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      netboxv1.ResourceName(node.Name, addr.Address),
+				Namespace: o.Namespace,
+			},
+			Spec: netboxv1.IPAddressSpec{
+				Address:     ip.String() + mask,
+				Status:      "active",
+				Description: fmt.Sprintf("Internal IP of node %s", node.Name),
+				Tenant:      fields.Tenant,
+			},
+		}
+		if o.Kind == NodeSyncVirtualMachine {
+			address.Spec.VMInterface = &netboxv1.VMInterfaceReference{VirtualMachine: node.Name, Name: intf}
+		} else {
+			address.Spec.Interface = &netboxv1.InterfaceReference{Device: node.Name, Name: intf}
+		}
+		resources = append(resources, address)
+	}
+	return resources, nil
+}
+
+// NodeSyncReconciler syncs Kubernetes Nodes into Devices or VirtualMachines and IPAddresses.
+// The resources of deleted nodes are decommissioned rather than deleted, so that Netbox
+// keeps a record of them
+type NodeSyncReconciler struct {
+	client.Client
+	Recorder record.EventRecorder
+	Options  NodeSyncOptions
+}
+
+// nodeResourceLists are the kinds of the resources synced from a Node
+var nodeResourceLists = []func() client.ObjectList{
+	func() client.ObjectList { return &netboxv1.DeviceList{} },
+	func() client.ObjectList { return &netboxv1.VirtualMachineList{} },
+	func() client.ObjectList { return &netboxv1.IPAddressList{} },
+}
+
+//+kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
+
+func (r *NodeSyncReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	log.V(1).Info("Reconcile", "req", req)
+
+	var node corev1.Node
+	if err := r.Get(ctx, req.NamespacedName, &node); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		if err := r.decommission(ctx, req.Name); err != nil {
+			log.Error(err, "failed to decommission node resources")
+			return ctrl.Result{RequeueAfter: retryInterval}, nil
+		}
+		return ctrl.Result{}, nil
+	}
+
+	resources, err := r.Options.resources(&node)
+	if err != nil {
+		// nodes without the required labels wait for them to be set
+		r.Recorder.Event(&node, corev1.EventTypeWarning, "NodeSyncFailed", err.Error())
+		return ctrl.Result{}, nil
+	}
+	if err := r.sync(ctx, node.Name, resources); err != nil {
+		log.Error(err, "failed to sync node")
+		r.Recorder.Event(&node, corev1.EventTypeWarning, "NodeSyncFailed", err.Error())
+		return ctrl.Result{RequeueAfter: retryInterval}, nil
+	}
+
+	log.V(1).Info("Reconciliation finished", "req", req)
+
+	return ctrl.Result{}, nil
+}
+
+// sync creates or updates the resources of the node and removes the addresses the node no longer has
+func (r *NodeSyncReconciler) sync(ctx context.Context, node string, resources []client.Object) error {
+	log := log.FromContext(ctx)
+
+	wanted := map[string]bool{}
+	for _, desired := range resources {
+		res := desired.DeepCopyObject().(client.Object)
+		op, err := controllerutil.CreateOrUpdate(ctx, r.Client, res, func() error {
+			return mutateNodeResource(node, res, desired)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to apply %s %q: %w", desired.GetObjectKind().GroupVersionKind().Kind, desired.GetName(), err)
+		}
+		if op != controllerutil.OperationResultNone {
+			log.V(1).Info("applied node resource", "kind", desired.GetObjectKind().GroupVersionKind().Kind, "name", desired.GetName(), "operation", op)
+		}
+		wanted[childKey(desired)] = true
+	}
+
+	var addrs netboxv1.IPAddressList
+	if err := r.List(ctx, &addrs, client.InNamespace(r.Options.Namespace), client.MatchingLabels{netboxv1.NodeLabel: node}); err != nil {
+		return err
+	}
+	for i := range addrs.Items {
+		addr := &addrs.Items[i]
+		if wanted[childKey(addr)] {
+			continue
+		}
+		if err := r.Delete(ctx, addr); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		log.V(1).Info("deleted node address", "name", addr.Name)
+	}
+	return nil
+}
+
+// mutateNodeResource sets the spec and the node label of an existing or new resource. Fields of
+// devices that are not synced from the node are left to the defaulting webhook
+func mutateNodeResource(node string, res, desired client.Object) error {
+	if res.GetResourceVersion() != "" && res.GetLabels()[netboxv1.NodeLabel] != node {
+		return fmt.Errorf("%s %q already exists and is not synced from node %q", desired.GetObjectKind().GroupVersionKind().Kind, res.GetName(), node)
+	}
+
+	switch r := res.(type) {
+	case *netboxv1.Device:
+		spec := desired.(*netboxv1.Device).Spec
+		r.Spec.Status = spec.Status
+		for _, f := range []struct {
+			value string
+			field *string
+		}{
+			{spec.Site, &r.Spec.Site},
+			{spec.Role, &r.Spec.Role},
+			{spec.DeviceType, &r.Spec.DeviceType},
+			{spec.Tenant, &r.Spec.Tenant},
+		} {
+			if f.value != "" {
+				*f.field = f.value
+			}
+		}
+	case *netboxv1.VirtualMachine:
+		r.Spec = desired.(*netboxv1.VirtualMachine).Spec
+	case *netboxv1.Interface:
+		spec := desired.(*netboxv1.Interface).Spec
+		r.Spec.Device, r.Spec.Name, r.Spec.Type = spec.Device, spec.Name, spec.Type
+	case *netboxv1.VMInterface:
+		spec := desired.(*netboxv1.VMInterface).Spec
+		r.Spec.VirtualMachine, r.Spec.Name = spec.VirtualMachine, spec.Name
+	case *netboxv1.IPAddress:
+		r.Spec = desired.(*netboxv1.IPAddress).Spec
+	default:
+		return fmt.Errorf("unexpected node resource %T", res)
+	}
+
+	labels := res.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[netboxv1.NodeLabel] = node
+	res.SetLabels(labels)
+	return nil
+}
+
+// decommission marks the devices and virtual machines of a deleted node as decommissioning
+// and its addresses as deprecated
+func (r *NodeSyncReconciler) decommission(ctx context.Context, node string) error {
+	log := log.FromContext(ctx)
+
+	for _, newList := range nodeResourceLists {
+		list := newList()
+		if err := r.List(ctx, list, client.InNamespace(r.Options.Namespace), client.MatchingLabels{netboxv1.NodeLabel: node}); err != nil {
+			return err
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			res := item.(client.Object)
+			patch := client.MergeFrom(res.DeepCopyObject().(client.Object))
+			if !decommissionNodeResource(res) {
+				continue
+			}
+			if err := r.Patch(ctx, res, patch); client.IgnoreNotFound(err) != nil {
+				return err
+			}
+			log.V(1).Info("decommissioned node resource", "kind", fmt.Sprintf("%T", res), "name", res.GetName())
+		}
+	}
+	return nil
+}
+
+// decommissionNodeResource sets the status of a resource of a deleted node, it returns false if it is already set
+func decommissionNodeResource(res client.Object) bool {
+	var status *string
+	var want string
+	switch r := res.(type) {
+	case *netboxv1.Device:
+		status, want = &r.Spec.Status, "decommissioning"
+	case *netboxv1.VirtualMachine:
+		status, want = &r.Spec.Status, "decommissioning"
+	case *netboxv1.IPAddress:
+		status, want = &r.Spec.Status, "deprecated"
+	default:
+		return false
+	}
+	if *status == want {
+		return false
+	}
+	*status = want
+	return true
+}
+
+// nodeChanged filters out the periodic status updates of nodes that don't change their resources
+func nodeChanged(e event.UpdateEvent) bool {
+	old, ok := e.ObjectOld.(*corev1.Node)
+	if !ok {
+		return true
+	}
+	node, ok := e.ObjectNew.(*corev1.Node)
+	if !ok {
+		return true
+	}
+	return !equality.Semantic.DeepEqual(old.Labels, node.Labels) ||
+		old.Spec.Unschedulable != node.Spec.Unschedulable ||
+		!equality.Semantic.DeepEqual(old.Status.Addresses, node.Status.Addresses) ||
+		!equality.Semantic.DeepEqual(old.Status.Capacity, node.Status.Capacity)
+}
+
+// nodeOf maps the resources synced from a Node to the Node, so that changes to them are reverted
+// and the resources of nodes deleted while the controller was down are decommissioned on start
+func (r *NodeSyncReconciler) nodeOf(obj client.Object) []reconcile.Request {
+	node, ok := obj.GetLabels()[netboxv1.NodeLabel]
+	if !ok || obj.GetNamespace() != r.Options.Namespace {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: node}}}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *NodeSyncReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	b := ctrl.NewControllerManagedBy(mgr).
+		Named("nodesync").
+		For(&corev1.Node{}, builder.WithPredicates(predicate.Funcs{UpdateFunc: nodeChanged}))
+	for _, obj := range []client.Object{&netboxv1.Device{}, &netboxv1.VirtualMachine{}, &netboxv1.Interface{}, &netboxv1.VMInterface{}, &netboxv1.IPAddress{}} {
+		b = b.Watches(&source.Kind{Type: obj}, handler.EnqueueRequestsFromMapFunc(r.nodeOf))
+	}
+	return b.Complete(r)
+}
@@ -0,0 +1,351 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+)
+
+func TestParseNodeFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    NodeFields
+		wantErr bool
+	}{
+		{name: "empty", s: "", want: DefaultNodeLabels},
+		{
+			name: "some fields",
+			s:    "site=topology.kubernetes.io/zone, tenant=team,",
+			want: NodeFields{
+				Site:       "topology.kubernetes.io/zone",
+				Role:       DefaultNodeLabels.Role,
+				DeviceType: DefaultNodeLabels.DeviceType,
+				Cluster:    DefaultNodeLabels.Cluster,
+				Tenant:     "team",
+			},
+		},
+		{
+			name: "all fields",
+			s:    "site=a,role=b,device_type=c,cluster=d,tenant=e",
+			want: NodeFields{Site: "a", Role: "b", DeviceType: "c", Cluster: "d", Tenant: "e"},
+		},
+		{name: "no value", s: "site=", wantErr: true},
+		{name: "no separator", s: "site", wantErr: true},
+		{name: "unknown field", s: "rack=r1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields := DefaultNodeLabels
+			err := ParseNodeFields(tt.s, &fields)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %+v", fields)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fields != tt.want {
+				t.Errorf("expected %+v, got %+v", tt.want, fields)
+			}
+		})
+	}
+}
+
+func testNode(labels map[string]string, addresses ...corev1.NodeAddress) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1", Labels: labels},
+		Status: corev1.NodeStatus{
+			Addresses: addresses,
+			Capacity: corev1.ResourceList{
+				corev1.ResourceCPU:              resource.MustParse("4"),
+				corev1.ResourceMemory:           resource.MustParse("8Gi"),
+				corev1.ResourceEphemeralStorage: resource.MustParse("100Gi"),
+			},
+		},
+	}
+}
+
+func TestNodeSyncResources(t *testing.T) {
+	opts := NodeSyncOptions{
+		Namespace: "nodes",
+		Kind:      NodeSyncDevice,
+		Labels:    DefaultNodeLabels,
+		Defaults:  NodeFields{Site: "lab", Role: "k8s-node", DeviceType: "server"},
+	}
+	addresses := []corev1.NodeAddress{
+		{Type: corev1.NodeInternalIP, Address: "10.0.0.1"},
+		{Type: corev1.NodeInternalIP, Address: "2001:db8::1"},
+		{Type: corev1.NodeExternalIP, Address: "192.0.2.1"},
+		{Type: corev1.NodeInternalIP, Address: "not-an-ip"},
+		{Type: corev1.NodeHostName, Address: "node-1"},
+	}
+
+	t.Run("device", func(t *testing.T) {
+		// labels take precedence over the defaults, empty labels fall back to them
+		node := testNode(map[string]string{
+			DefaultNodeLabels.Site:   "dc1",
+			DefaultNodeLabels.Role:   "",
+			DefaultNodeLabels.Tenant: "team",
+		}, addresses...)
+		resources, err := opts.resources(node)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(resources) != 4 {
+			t.Fatalf("expected a device, an interface and 2 addresses, got %d resources", len(resources))
+		}
+
+		device := resources[0].(*netboxv1.Device)
+		want := netboxv1.DeviceSpec{Site: "dc1", Role: "k8s-node", DeviceType: "server", Status: "active", Tenant: "team"}
+		if device.Name != "node-1" || device.Namespace != "nodes" || !reflect.DeepEqual(device.Spec, want) {
+			t.Errorf("unexpected device %s/%s %+v", device.Namespace, device.Name, device.Spec)
+		}
+
+		intf := resources[1].(*netboxv1.Interface)
+		if intf.Name != "node-1-k8s" || intf.Spec.Device != "node-1" || intf.Spec.Name != DefaultNodeInterface || intf.Spec.Type != "virtual" {
+			t.Errorf("unexpected interface %s %+v", intf.Name, intf.Spec)
+		}
+
+		for i, want := range map[int]string{2: "10.0.0.1/32", 3: "2001:db8::1/128"} {
+			addr := resources[i].(*netboxv1.IPAddress)
+			if addr.Spec.Address != want || addr.Spec.Tenant != "team" {
+				t.Errorf("unexpected address %s %+v", addr.Name, addr.Spec)
+			}
+			if addr.Spec.Interface == nil || *addr.Spec.Interface != (netboxv1.InterfaceReference{Device: "node-1", Name: DefaultNodeInterface}) {
+				t.Errorf("address %s is not assigned to the node interface: %+v", addr.Name, addr.Spec.Interface)
+			}
+		}
+		if name := resources[3].GetName(); name != "node-1-2001-db8-1" {
+			t.Errorf("unexpected name of the IPv6 address %q", name)
+		}
+	})
+
+	t.Run("cordoned", func(t *testing.T) {
+		node := testNode(nil)
+		node.Spec.Unschedulable = true
+		resources, err := opts.resources(node)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status := resources[0].(*netboxv1.Device).Spec.Status; status != "offline" {
+			t.Errorf("expected a cordoned node to be offline, got %q", status)
+		}
+	})
+
+	vmOpts := opts
+	vmOpts.Kind = NodeSyncVirtualMachine
+	vmOpts.Interface = "eth0"
+
+	t.Run("virtual machine without a cluster", func(t *testing.T) {
+		_, err := vmOpts.resources(testNode(nil))
+		if err == nil || !strings.Contains(err.Error(), DefaultNodeLabels.Cluster) {
+			t.Errorf("expected an error naming the cluster label, got %v", err)
+		}
+	})
+
+	t.Run("virtual machine", func(t *testing.T) {
+		resources, err := vmOpts.resources(testNode(map[string]string{DefaultNodeLabels.Cluster: "k8s"}, addresses[0]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(resources) != 3 {
+			t.Fatalf("expected a virtual machine, an interface and an address, got %d resources", len(resources))
+		}
+
+		vm := resources[0].(*netboxv1.VirtualMachine)
+		want := netboxv1.VirtualMachineSpec{Cluster: "k8s", Status: "active", Role: "k8s-node", VCPUs: 4, Memory: 8192, Disk: 100}
+		if !reflect.DeepEqual(vm.Spec, want) {
+			t.Errorf("expected %+v, got %+v", want, vm.Spec)
+		}
+
+		intf := resources[1].(*netboxv1.VMInterface)
+		if intf.Name != "node-1-eth0" || intf.Spec.VirtualMachine != "node-1" || intf.Spec.Name != "eth0" {
+			t.Errorf("unexpected interface %s %+v", intf.Name, intf.Spec)
+		}
+
+		addr := resources[2].(*netboxv1.IPAddress)
+		if addr.Spec.Interface != nil || addr.Spec.VMInterface == nil ||
+			*addr.Spec.VMInterface != (netboxv1.VMInterfaceReference{VirtualMachine: "node-1", Name: "eth0"}) {
+			t.Errorf("address is not assigned to the VM interface: %+v", addr.Spec)
+		}
+	})
+}
+
+func TestMutateNodeResource(t *testing.T) {
+	t.Run("device", func(t *testing.T) {
+		res := &netboxv1.Device{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1", ResourceVersion: "1", Labels: map[string]string{netboxv1.NodeLabel: "node-1"}},
+			Spec:       netboxv1.DeviceSpec{Site: "old", Role: "k8s-node", DeviceType: "server", Rack: "r1", Tenant: "team"},
+		}
+		desired := &netboxv1.Device{Spec: netboxv1.DeviceSpec{Site: "dc1", Status: "offline"}}
+		if err := mutateNodeResource("node-1", res, desired); err != nil {
+			t.Fatal(err)
+		}
+		// fields that are not synced or empty keep their values
+		want := netboxv1.DeviceSpec{Site: "dc1", Role: "k8s-node", DeviceType: "server", Rack: "r1", Tenant: "team", Status: "offline"}
+		if !reflect.DeepEqual(res.Spec, want) {
+			t.Errorf("expected %+v, got %+v", want, res.Spec)
+		}
+	})
+
+	t.Run("new interface", func(t *testing.T) {
+		res := &netboxv1.Interface{ObjectMeta: metav1.ObjectMeta{Name: "node-1-k8s"}}
+		desired := &netboxv1.Interface{Spec: netboxv1.InterfaceSpec{Device: "node-1", Name: "k8s", Type: "virtual"}}
+		if err := mutateNodeResource("node-1", res, desired); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(res.Spec, desired.Spec) || res.Labels[netboxv1.NodeLabel] != "node-1" {
+			t.Errorf("unexpected interface %v %+v", res.Labels, res.Spec)
+		}
+	})
+
+	t.Run("not synced from the node", func(t *testing.T) {
+		res := &netboxv1.IPAddress{ObjectMeta: metav1.ObjectMeta{Name: "node-1-10-0-0-1", ResourceVersion: "1"}}
+		desired := &netboxv1.IPAddress{TypeMeta: metav1.TypeMeta{Kind: netboxv1.IPAddressKind}}
+		if err := mutateNodeResource("node-1", res, desired); err == nil {
+			t.Errorf("expected an error")
+		}
+	})
+
+	t.Run("unexpected kind", func(t *testing.T) {
+		res := &netboxv1.Site{}
+		if err := mutateNodeResource("node-1", res, res); err == nil {
+			t.Errorf("expected an error")
+		}
+	})
+}
+
+func TestDecommissionNodeResource(t *testing.T) {
+	tests := []struct {
+		name       string
+		res        client.Object
+		want       bool
+		wantStatus string
+	}{
+		{name: "device", res: &netboxv1.Device{Spec: netboxv1.DeviceSpec{Status: "active"}}, want: true, wantStatus: "decommissioning"},
+		{name: "decommissioned device", res: &netboxv1.Device{Spec: netboxv1.DeviceSpec{Status: "decommissioning"}}, wantStatus: "decommissioning"},
+		{name: "virtual machine", res: &netboxv1.VirtualMachine{Spec: netboxv1.VirtualMachineSpec{Status: "offline"}}, want: true, wantStatus: "decommissioning"},
+		{name: "address", res: &netboxv1.IPAddress{Spec: netboxv1.IPAddressSpec{Status: "active"}}, want: true, wantStatus: "deprecated"},
+		{name: "interface", res: &netboxv1.Interface{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decommissionNodeResource(tt.res); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+			var status string
+			switch r := tt.res.(type) {
+			case *netboxv1.Device:
+				status = r.Spec.Status
+			case *netboxv1.VirtualMachine:
+				status = r.Spec.Status
+			case *netboxv1.IPAddress:
+				status = r.Spec.Status
+			}
+			if status != tt.wantStatus {
+				t.Errorf("expected status %q, got %q", tt.wantStatus, status)
+			}
+		})
+	}
+}
+
+func TestNodeSyncDecommission(t *testing.T) {
+	ctx := context.Background()
+	scheme := testScheme(t)
+
+	synced := func(obj client.Object, node string) client.Object {
+		obj.SetNamespace("nodes")
+		obj.SetLabels(map[string]string{netboxv1.NodeLabel: node})
+		return obj
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		synced(&netboxv1.Device{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}, Spec: netboxv1.DeviceSpec{Status: "active"}}, "node-1"),
+		synced(&netboxv1.IPAddress{ObjectMeta: metav1.ObjectMeta{Name: "node-1-10-0-0-1"}, Spec: netboxv1.IPAddressSpec{Status: "active"}}, "node-1"),
+		synced(&netboxv1.Device{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}, Spec: netboxv1.DeviceSpec{Status: "active"}}, "node-2"),
+	).Build()
+	r := &NodeSyncReconciler{Client: c, Options: NodeSyncOptions{Namespace: "nodes"}}
+
+	if err := r.decommission(ctx, "node-1"); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, want := range map[string]string{"node-1": "decommissioning", "node-2": "active"} {
+		var device netboxv1.Device
+		if err := c.Get(ctx, client.ObjectKey{Namespace: "nodes", Name: name}, &device); err != nil {
+			t.Fatal(err)
+		}
+		if device.Spec.Status != want {
+			t.Errorf("device %s: expected status %q, got %q", name, want, device.Spec.Status)
+		}
+	}
+	var addr netboxv1.IPAddress
+	if err := c.Get(ctx, client.ObjectKey{Namespace: "nodes", Name: "node-1-10-0-0-1"}, &addr); err != nil {
+		t.Fatal(err)
+	}
+	if addr.Spec.Status != "deprecated" {
+		t.Errorf("expected the address to be deprecated, got %q", addr.Spec.Status)
+	}
+}
+
+func TestNodeChanged(t *testing.T) {
+	node := testNode(map[string]string{DefaultNodeLabels.Site: "dc1"}, corev1.NodeAddress{Type: corev1.NodeInternalIP, Address: "10.0.0.1"})
+
+	tests := []struct {
+		name   string
+		mutate func(n *corev1.Node)
+		want   bool
+	}{
+		{
+			name: "heartbeat",
+			mutate: func(n *corev1.Node) {
+				n.ResourceVersion = "2"
+				n.Status.Conditions = []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue, LastHeartbeatTime: metav1.Now()}}
+			},
+		},
+		{name: "labels", mutate: func(n *corev1.Node) { n.Labels[DefaultNodeLabels.Site] = "dc2" }, want: true},
+		{name: "cordoned", mutate: func(n *corev1.Node) { n.Spec.Unschedulable = true }, want: true},
+		{name: "addresses", mutate: func(n *corev1.Node) { n.Status.Addresses[0].Address = "10.0.0.2" }, want: true},
+		{name: "capacity", mutate: func(n *corev1.Node) { n.Status.Capacity[corev1.ResourceCPU] = resource.MustParse("8") }, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated := node.DeepCopy()
+			tt.mutate(updated)
+			if got := nodeChanged(event.UpdateEvent{ObjectOld: node, ObjectNew: updated}); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,506 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/go-logr/logr"
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	"github.com/networkop/declarative-netbox/netbox"
+)
+
+// objectKind describes a kind reconciled by ObjectReconciler
+type objectKind struct {
+	kind string
+	// model is the name of the Netbox model in webhook payloads
+	model     string
+	newObject func() netboxv1.Object
+	newList   func() client.ObjectList
+}
+
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=regions,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=regions/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=regions/finalizers,verbs=update
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=sitegroups,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=sitegroups/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=sitegroups/finalizers,verbs=update
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=tenantgroups,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=tenantgroups/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=tenantgroups/finalizers,verbs=update
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=tenants,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=tenants/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=tenants/finalizers,verbs=update
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=sites,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=sites/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=sites/finalizers,verbs=update
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=locations,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=locations/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=locations/finalizers,verbs=update
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=racks,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=racks/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=racks/finalizers,verbs=update
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=vlangroups,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=vlangroups/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=vlangroups/finalizers,verbs=update
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=vlans,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=vlans/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=vlans/finalizers,verbs=update
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=routetargets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=routetargets/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=routetargets/finalizers,verbs=update
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=vrfs,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=vrfs/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=vrfs/finalizers,verbs=update
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=prefixes,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=prefixes/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=prefixes/finalizers,verbs=update
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=platforms,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=platforms/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=platforms/finalizers,verbs=update
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=devicetypes,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=devicetypes/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=devicetypes/finalizers,verbs=update
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=clustertypes,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=clustertypes/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=clustertypes/finalizers,verbs=update
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=clusters,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=clusters/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=clusters/finalizers,verbs=update
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=virtualmachines,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=virtualmachines/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=virtualmachines/finalizers,verbs=update
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=interfaces,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=interfaces/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=interfaces/finalizers,verbs=update
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=vminterfaces,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=vminterfaces/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=vminterfaces/finalizers,verbs=update
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=cables,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=cables/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=cables/finalizers,verbs=update
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=ipaddresses,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=ipaddresses/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=netbox.networkop.co.uk,resources=ipaddresses/finalizers,verbs=update
+
+// objectKinds are the kinds reconciled by ObjectReconciler
+var objectKinds = []objectKind{
+	{
+		kind:      netboxv1.RegionKind,
+		model:     netboxv1.RegionModel,
+		newObject: func() netboxv1.Object { return &netboxv1.Region{} },
+		newList:   func() client.ObjectList { return &netboxv1.RegionList{} },
+	},
+	{
+		kind:      netboxv1.SiteGroupKind,
+		model:     netboxv1.SiteGroupModel,
+		newObject: func() netboxv1.Object { return &netboxv1.SiteGroup{} },
+		newList:   func() client.ObjectList { return &netboxv1.SiteGroupList{} },
+	},
+	{
+		kind:      netboxv1.TenantGroupKind,
+		model:     netboxv1.TenantGroupModel,
+		newObject: func() netboxv1.Object { return &netboxv1.TenantGroup{} },
+		newList:   func() client.ObjectList { return &netboxv1.TenantGroupList{} },
+	},
+	{
+		kind:      netboxv1.TenantKind,
+		model:     netboxv1.TenantModel,
+		newObject: func() netboxv1.Object { return &netboxv1.Tenant{} },
+		newList:   func() client.ObjectList { return &netboxv1.TenantList{} },
+	},
+	{
+		kind:      netboxv1.SiteKind,
+		model:     netboxv1.SiteModel,
+		newObject: func() netboxv1.Object { return &netboxv1.Site{} },
+		newList:   func() client.ObjectList { return &netboxv1.SiteList{} },
+	},
+	{
+		kind:      netboxv1.LocationKind,
+		model:     netboxv1.LocationModel,
+		newObject: func() netboxv1.Object { return &netboxv1.Location{} },
+		newList:   func() client.ObjectList { return &netboxv1.LocationList{} },
+	},
+	{
+		kind:      netboxv1.RackKind,
+		model:     netboxv1.RackModel,
+		newObject: func() netboxv1.Object { return &netboxv1.Rack{} },
+		newList:   func() client.ObjectList { return &netboxv1.RackList{} },
+	},
+	{
+		kind:      netboxv1.VLANGroupKind,
+		model:     netboxv1.VLANGroupModel,
+		newObject: func() netboxv1.Object { return &netboxv1.VLANGroup{} },
+		newList:   func() client.ObjectList { return &netboxv1.VLANGroupList{} },
+	},
+	{
+		kind:      netboxv1.VLANKind,
+		model:     netboxv1.VLANModel,
+		newObject: func() netboxv1.Object { return &netboxv1.VLAN{} },
+		newList:   func() client.ObjectList { return &netboxv1.VLANList{} },
+	},
+	{
+		kind:      netboxv1.RouteTargetKind,
+		model:     netboxv1.RouteTargetModel,
+		newObject: func() netboxv1.Object { return &netboxv1.RouteTarget{} },
+		newList:   func() client.ObjectList { return &netboxv1.RouteTargetList{} },
+	},
+	{
+		kind:      netboxv1.VRFKind,
+		model:     netboxv1.VRFModel,
+		newObject: func() netboxv1.Object { return &netboxv1.VRF{} },
+		newList:   func() client.ObjectList { return &netboxv1.VRFList{} },
+	},
+	{
+		kind:      netboxv1.PrefixKind,
+		model:     netboxv1.PrefixModel,
+		newObject: func() netboxv1.Object { return &netboxv1.Prefix{} },
+		newList:   func() client.ObjectList { return &netboxv1.PrefixList{} },
+	},
+	{
+		kind:      netboxv1.PlatformKind,
+		model:     netboxv1.PlatformModel,
+		newObject: func() netboxv1.Object { return &netboxv1.Platform{} },
+		newList:   func() client.ObjectList { return &netboxv1.PlatformList{} },
+	},
+	{
+		kind:      netboxv1.DeviceTypeKind,
+		model:     netboxv1.DeviceTypeModel,
+		newObject: func() netboxv1.Object { return &netboxv1.DeviceType{} },
+		newList:   func() client.ObjectList { return &netboxv1.DeviceTypeList{} },
+	},
+	{
+		kind:      netboxv1.ClusterTypeKind,
+		model:     netboxv1.ClusterTypeModel,
+		newObject: func() netboxv1.Object { return &netboxv1.ClusterType{} },
+		newList:   func() client.ObjectList { return &netboxv1.ClusterTypeList{} },
+	},
+	{
+		kind:      netboxv1.ClusterKind,
+		model:     netboxv1.ClusterModel,
+		newObject: func() netboxv1.Object { return &netboxv1.Cluster{} },
+		newList:   func() client.ObjectList { return &netboxv1.ClusterList{} },
+	},
+	{
+		kind:      netboxv1.VirtualMachineKind,
+		model:     netboxv1.VirtualMachineModel,
+		newObject: func() netboxv1.Object { return &netboxv1.VirtualMachine{} },
+		newList:   func() client.ObjectList { return &netboxv1.VirtualMachineList{} },
+	},
+	{
+		kind:      netboxv1.InterfaceKind,
+		model:     netboxv1.InterfaceModel,
+		newObject: func() netboxv1.Object { return &netboxv1.Interface{} },
+		newList:   func() client.ObjectList { return &netboxv1.InterfaceList{} },
+	},
+	{
+		kind:      netboxv1.VMInterfaceKind,
+		model:     netboxv1.VMInterfaceModel,
+		newObject: func() netboxv1.Object { return &netboxv1.VMInterface{} },
+		newList:   func() client.ObjectList { return &netboxv1.VMInterfaceList{} },
+	},
+	{
+		kind:      netboxv1.CableKind,
+		model:     netboxv1.CableModel,
+		newObject: func() netboxv1.Object { return &netboxv1.Cable{} },
+		newList:   func() client.ObjectList { return &netboxv1.CableList{} },
+	},
+	{
+		kind:      netboxv1.IPAddressKind,
+		model:     netboxv1.IPAddressModel,
+		newObject: func() netboxv1.Object { return &netboxv1.IPAddress{} },
+		newList:   func() client.ObjectList { return &netboxv1.IPAddressList{} },
+	},
+}
+
+// ObjectReconciler reconciles the resources of one of the objectKinds. Unlike devices,
+// resources referring to Netbox objects that don't exist yet are Pending until they do
+type ObjectReconciler struct {
+	client.Client
+	Recorder record.EventRecorder
+	kind     objectKind
+	netbox   *netbox.NetboxServer
+	// default deletion policy for resources that don't set one
+	deletionPolicy netboxv1.DeletionPolicy
+	tenants        NamespaceTenants
+	// resources enqueued by Netbox webhooks, which are re-applied even if their spec hasn't changed
+	webhookEvents chan event.GenericEvent
+	resync        sync.Map
+}
+
+// SetupObjectReconcilers sets up a controller for each of the objectKinds
+func SetupObjectReconcilers(mgr ctrl.Manager, opts ReconcilerOptions) error {
+	nb, err := netbox.NewNetboxServer(opts.NetboxURL, opts.NetboxToken, netbox.WithTransport(opts.NetboxTransport), netbox.WithRetry(opts.NetboxRetry))
+	if err != nil {
+		return err
+	}
+
+	for _, kind := range objectKinds {
+		r := &ObjectReconciler{
+			Client:         mgr.GetClient(),
+			Recorder:       mgr.GetEventRecorderFor(strings.ToLower(kind.kind) + "-controller"),
+			kind:           kind,
+			netbox:         nb,
+			deletionPolicy: opts.DeletionPolicy,
+			tenants:        opts.Tenants,
+		}
+		if r.deletionPolicy == "" {
+			r.deletionPolicy = netboxv1.DeletionPolicyDelete
+		}
+		if err := r.SetupWithManager(mgr, opts.Webhook); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ObjectReconciler) SetupWithManager(mgr ctrl.Manager, webhook *WebhookReceiver) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), r.kind.newObject(), netboxIDField, objectNetboxID); err != nil {
+		return err
+	}
+
+	r.webhookEvents = make(chan event.GenericEvent, 100)
+	if err := ctrl.NewControllerManagedBy(mgr).
+		For(r.kind.newObject()).
+		Watches(&source.Channel{Source: r.webhookEvents}, &handler.EnqueueRequestForObject{}).
+		Complete(r); err != nil {
+		return err
+	}
+
+	if webhook != nil {
+		webhook.Register(r.kind.model, r.kind.newList, r.enqueueWebhook)
+	}
+	return nil
+}
+
+func (r *ObjectReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	log.V(1).Info("Reconcile", "req", req)
+
+	obj := r.kind.newObject()
+	if err := r.Get(ctx, req.NamespacedName, obj); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if !controllerutil.ContainsFinalizer(obj, netboxv1.ObjectFinalizer) {
+		controllerutil.AddFinalizer(obj, netboxv1.ObjectFinalizer)
+		if err := r.Update(ctx, obj); err != nil {
+			log.Error(err, "unable to register finalizer")
+			return ctrl.Result{}, err
+		}
+	}
+
+	if !obj.GetDeletionTimestamp().IsZero() {
+		return r.reconcileDelete(ctx, obj)
+	}
+
+	// Pending and Failed resources are retried even if their spec hasn't changed
+	status := obj.GetObjectStatus()
+	_, resync := r.resync.LoadAndDelete(req.NamespacedName)
+	if status.ObservedGeneration == obj.GetGeneration() && status.State == netboxv1.ObjectReadyState && !resync {
+		log.V(1).Info("Requed object after status update. Doing nothing")
+		return ctrl.Result{}, nil
+	}
+
+	observed := status.DeepCopy()
+	result := r.reconcile(ctx, obj)
+
+	status.ObservedGeneration = obj.GetGeneration()
+	if !equality.Semantic.DeepEqual(observed, status) {
+		if err := r.Status().Update(ctx, obj); err != nil {
+			log.Error(err, "unable to update status")
+			return ctrl.Result{}, err
+		}
+	}
+
+	log.V(1).Info("Reconciliation finished", "req", req)
+
+	return result, nil
+}
+
+func (r *ObjectReconciler) reconcile(ctx context.Context, obj netboxv1.Object) ctrl.Result {
+	log := logr.FromContext(ctx)
+	status := obj.GetObjectStatus()
+	name := strings.ToLower(r.kind.kind)
+
+	// a forced tenant is applied to Netbox, the status update doesn't change the spec
+	err := r.tenants.scope(obj, obj.GetNamespace())
+	if err == nil {
+		err = r.tenants.owns(ctx, r.netbox, obj, obj.GetNamespace())
+	}
+	if IsTenantMismatch(err) {
+		recordOutcome(r.kind.kind, outcomeFailed)
+		r.Recorder.Event(obj, corev1.EventTypeWarning, "TenantRejected", err.Error())
+		status.Message = err.Error()
+		status.State = netboxv1.ObjectFailedState
+		return ctrl.Result{}
+	}
+
+	var result *netbox.Result
+	if err == nil {
+		result, err = r.netbox.Apply(ctx, obj)
+	}
+	if err != nil {
+		recordOutcome(r.kind.kind, outcomeFailed)
+		r.recordError(obj, "ApplyFailed", err)
+		status.Message = err.Error()
+		status.State = netboxv1.ObjectFailedState
+		if netbox.IsReferenceNotFound(err) {
+			log.V(1).Info("waiting for the referenced objects", "reason", err.Error())
+			status.State = netboxv1.ObjectPendingState
+		} else {
+			log.Error(err, "failed to r.netbox.Apply, retrying")
+		}
+		return ctrl.Result{RequeueAfter: retryInterval}
+	}
+	recordOutcome(r.kind.kind, string(result.Operation))
+
+	switch result.Operation {
+	case netbox.OperationCreated:
+		r.Recorder.Eventf(obj, corev1.EventTypeNormal, "Created", "Created %s in Netbox with ID %d", name, *status.ID)
+	case netbox.OperationUpdated:
+		r.Recorder.Eventf(obj, corev1.EventTypeNormal, "Updated", "Updated %s %d in Netbox, changed fields: %s", name, *status.ID, strings.Join(result.Changed, ", "))
+	}
+
+	return ctrl.Result{}
+}
+
+func (r *ObjectReconciler) reconcileDelete(ctx context.Context, obj netboxv1.Object) (ctrl.Result, error) {
+	log := logr.FromContext(ctx)
+	name := strings.ToLower(r.kind.kind)
+
+	policy := obj.GetDeletionPolicy()
+	if policy == "" {
+		policy = r.deletionPolicy
+	}
+
+	// the Netbox objects of other tenants are left unchanged
+	if err := r.tenants.owns(ctx, r.netbox, obj, obj.GetNamespace()); IsTenantMismatch(err) {
+		recordOutcome(r.kind.kind, outcomeFailed)
+		r.Recorder.Eventf(obj, corev1.EventTypeWarning, "TenantRejected", "Left %s in Netbox unchanged: %s", name, err)
+	} else if err != nil {
+		log.Error(err, "failed to check the tenants in Netbox, retrying")
+		recordOutcome(r.kind.kind, outcomeFailed)
+		r.recordError(obj, "DeleteFailed", err)
+		return ctrl.Result{RequeueAfter: retryInterval}, err
+	} else if policy == netboxv1.DeletionPolicyOrphan {
+		if err := r.netbox.Orphan(ctx, obj); err != nil {
+			log.Error(err, "failed to r.netbox.Orphan, retrying")
+			recordOutcome(r.kind.kind, outcomeFailed)
+			r.recordError(obj, "OrphanFailed", err)
+			return ctrl.Result{RequeueAfter: retryInterval}, err
+		}
+		recordOutcome(r.kind.kind, outcomeOrphaned)
+		if r.netbox.Untaggable(obj) {
+			r.Recorder.Eventf(obj, corev1.EventTypeNormal, "Orphaned", "Left %s in Netbox and cleared the %s custom field", name, netbox.CreatedFieldName)
+		} else {
+			r.Recorder.Eventf(obj, corev1.EventTypeNormal, "Orphaned", "Left %s in Netbox and removed the managed tag", name)
+		}
+	} else {
+		if err := r.netbox.Delete(ctx, obj); netbox.IsHasChildren(err) {
+			return r.blockDelete(ctx, obj, err)
+		} else if err != nil {
+			log.Error(err, "failed to r.netbox.Delete, retrying")
+			recordOutcome(r.kind.kind, outcomeFailed)
+			r.recordError(obj, "DeleteFailed", err)
+			return ctrl.Result{RequeueAfter: retryInterval}, err
+		}
+		recordOutcome(r.kind.kind, outcomeDeleted)
+		r.Recorder.Eventf(obj, corev1.EventTypeNormal, "Deleted", "Deleted %s from Netbox", name)
+	}
+
+	controllerutil.RemoveFinalizer(obj, netboxv1.ObjectFinalizer)
+	if err := r.Update(ctx, obj); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	log.V(1).Info("Delete Reconciliation finished", "name", obj.GetName())
+
+	return ctrl.Result{}, nil
+}
+
+// blockDelete keeps a resource whose Netbox object still has children, which are checked again
+// until they are gone. The status explains what the deletion is waiting for
+func (r *ObjectReconciler) blockDelete(ctx context.Context, obj netboxv1.Object, err error) (ctrl.Result, error) {
+	log := logr.FromContext(ctx)
+	log.V(1).Info("deletion is blocked by children", "reason", err.Error())
+
+	status := obj.GetObjectStatus()
+	if status.State != netboxv1.ObjectBlockedState || status.Message != err.Error() {
+		r.Recorder.Event(obj, corev1.EventTypeWarning, "DeletionBlocked", err.Error())
+		status.State = netboxv1.ObjectBlockedState
+		status.Message = err.Error()
+		if err := r.Status().Update(ctx, obj); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{RequeueAfter: retryInterval}, nil
+}
+
+// recordError emits a Warning event, using a specific reason for the errors that need user action
+func (r *ObjectReconciler) recordError(obj netboxv1.Object, reason string, err error) {
+	switch {
+	case netbox.IsReferenceNotFound(err):
+		reason = "ReferenceNotFound"
+	case netbox.IsConflict(err), netbox.IsUnmanagedConflict(err):
+		reason = "Conflict"
+	case netbox.IsAuthFailed(err):
+		reason = "AuthFailed"
+	}
+	r.Recorder.Event(obj, corev1.EventTypeWarning, reason, err.Error())
+}
+
+// enqueueWebhook marks a resource changed in Netbox for resync and enqueues it, same as for devices
+func (r *ObjectReconciler) enqueueWebhook(obj client.Object) {
+	r.resync.Store(client.ObjectKeyFromObject(obj), true)
+	select {
+	case r.webhookEvents <- event.GenericEvent{Object: obj}:
+	default:
+		ctrl.Log.WithName("webhook").Info("dropped webhook event, queue is full", "kind", r.kind.kind, "name", obj.GetName(), "namespace", obj.GetNamespace())
+	}
+}
+
+// objectNetboxID indexes resources by their Netbox ID, taken from the status or the ID annotation of adopted objects
+func objectNetboxID(obj client.Object) []string {
+	o, ok := obj.(netboxv1.Object)
+	if !ok {
+		return nil
+	}
+	if id := o.GetObjectStatus().ID; id != nil {
+		return []string{strconv.FormatInt(*id, 10)}
+	}
+	if id, ok := o.GetAnnotations()[netboxv1.IDAnnotation]; ok {
+		return []string{id}
+	}
+	return nil
+}
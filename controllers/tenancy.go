@@ -0,0 +1,149 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	"github.com/networkop/declarative-netbox/netbox"
+)
+
+// TenantMode is what happens to resources whose tenant doesn't match the tenant of their namespace
+type TenantMode string
+
+const (
+	// TenantModeReject fails the resources with another tenant or without a tenant
+	TenantModeReject TenantMode = "Reject"
+	// TenantModeForce replaces the tenant of the resources with the tenant of their namespace
+	TenantModeForce TenantMode = "Force"
+)
+
+// NamespaceTenants maps namespaces to the Netbox tenant of their resources, so that
+// teams sharing a cluster can only manage the Netbox objects of their own tenant
+type NamespaceTenants struct {
+	// Tenants maps namespace names to tenant names
+	Tenants map[string]string
+	Mode    TenantMode
+}
+
+// ParseNamespaceTenants parses a comma separated list of namespace=tenant pairs
+func ParseNamespaceTenants(s string) (map[string]string, error) {
+	tenants := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("unexpected namespace tenant %q, must be namespace=tenant", pair)
+		}
+		if _, ok := tenants[parts[0]]; ok {
+			return nil, fmt.Errorf("namespace %q is mapped to more than one tenant", parts[0])
+		}
+		tenants[parts[0]] = parts[1]
+	}
+	return tenants, nil
+}
+
+// TenantMismatchError is returned for resources whose tenant is not the tenant of their namespace
+type TenantMismatchError struct {
+	Namespace string
+	Tenant    string
+	Want      string
+	// Owner is set if Tenant owns the Netbox objects changed by the resource, instead of being set in its spec
+	Owner bool
+	// Kind is set for the kinds that can't be managed in namespaces with a tenant, e.g. TenantGroup
+	Kind string
+}
+
+func (e *TenantMismatchError) Error() string {
+	switch {
+	case e.Kind != "":
+		return fmt.Sprintf("%s resources are not allowed in namespace %q, which is limited to tenant %q", e.Kind, e.Namespace, e.Want)
+	case e.Owner && e.Tenant == "":
+		return fmt.Sprintf("the Netbox objects changed by the resource have no tenant, resources in namespace %q can only change the objects of tenant %q", e.Namespace, e.Want)
+	case e.Owner:
+		return fmt.Sprintf("the Netbox objects changed by the resource belong to tenant %q, resources in namespace %q can only change the objects of tenant %q", e.Tenant, e.Namespace, e.Want)
+	case e.Tenant == "":
+		return fmt.Sprintf("resources in namespace %q must set tenant %q", e.Namespace, e.Want)
+	}
+	return fmt.Sprintf("tenant %q is not allowed in namespace %q, resources in the namespace must set tenant %q", e.Tenant, e.Namespace, e.Want)
+}
+
+// IsTenantMismatch returns true if err is caused by a tenant outside of the namespace
+func IsTenantMismatch(err error) bool {
+	var mismatchErr *TenantMismatchError
+	return errors.As(err, &mismatchErr)
+}
+
+// scope checks the tenant of a resource in the namespace. In Force mode, the tenant of the
+// namespace is set in the spec instead. Tenants must be the tenant of the namespace and tenant
+// groups, which are shared by tenants, are rejected. Other kinds without a tenant and
+// namespaces without a tenant are not checked
+func (t NamespaceTenants) scope(obj client.Object, namespace string) error {
+	want, ok := t.Tenants[namespace]
+	if !ok {
+		return nil
+	}
+
+	switch o := obj.(type) {
+	case *netboxv1.Tenant:
+		if name := o.TenantName(); name != want {
+			return &TenantMismatchError{Namespace: namespace, Tenant: name, Want: want}
+		}
+		return nil
+	case *netboxv1.TenantGroup:
+		return &TenantMismatchError{Namespace: namespace, Want: want, Kind: netboxv1.TenantGroupKind}
+	case netboxv1.Tenanted:
+		if o.GetTenant() == want {
+			return nil
+		}
+		if t.Mode == TenantModeForce {
+			o.SetTenant(want)
+			return nil
+		}
+		return &TenantMismatchError{Namespace: namespace, Tenant: o.GetTenant(), Want: want}
+	}
+	return nil
+}
+
+// owns checks that the existing Netbox objects changed by a resource, including the devices and
+// virtual machines of interfaces, cables and IP addresses, belong to the tenant of its namespace.
+// Resources are matched to Netbox objects by name, so this keeps teams from changing each
+// other's objects. Namespaces without a tenant are not checked
+func (t NamespaceTenants) owns(ctx context.Context, nb *netbox.NetboxServer, obj client.Object, namespace string) error {
+	want, ok := t.Tenants[namespace]
+	if !ok {
+		return nil
+	}
+	tenants, err := nb.Tenants(ctx, obj)
+	if err != nil {
+		return err
+	}
+	for _, tenant := range tenants {
+		if tenant != want {
+			return &TenantMismatchError{Namespace: namespace, Tenant: tenant, Want: want, Owner: true}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+)
+
+func TestParseNamespaceTenants(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "empty", s: "", want: map[string]string{}},
+		{name: "pairs", s: "team-a=tenant-a, team-b=tenant-b,", want: map[string]string{"team-a": "tenant-a", "team-b": "tenant-b"}},
+		{name: "no tenant", s: "team-a=", wantErr: true},
+		{name: "no separator", s: "team-a", wantErr: true},
+		{name: "duplicate namespace", s: "team-a=tenant-a,team-a=tenant-b", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseNamespaceTenants(tt.s)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNamespaceTenantsScope(t *testing.T) {
+	site := func(tenant string) *netboxv1.Site {
+		return &netboxv1.Site{ObjectMeta: metav1.ObjectMeta{Name: "lon1"}, Spec: netboxv1.SiteSpec{Tenant: tenant}}
+	}
+	tenant := func(name string) *netboxv1.Tenant {
+		return &netboxv1.Tenant{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	}
+	group := &netboxv1.TenantGroup{ObjectMeta: metav1.ObjectMeta{Name: "teams"}}
+	region := &netboxv1.Region{ObjectMeta: metav1.ObjectMeta{Name: "emea"}}
+
+	tests := []struct {
+		name      string
+		mode      TenantMode
+		namespace string
+		obj       client.Object
+		// wantTenant is the tenant of the site after scope
+		wantTenant string
+		wantErr    bool
+	}{
+		{name: "unmapped namespace", mode: TenantModeReject, namespace: "other", obj: site("tenant-b"), wantTenant: "tenant-b"},
+		{name: "same tenant", mode: TenantModeReject, namespace: "team-a", obj: site("tenant-a"), wantTenant: "tenant-a"},
+		{name: "other tenant", mode: TenantModeReject, namespace: "team-a", obj: site("tenant-b"), wantErr: true},
+		{name: "no tenant", mode: TenantModeReject, namespace: "team-a", obj: site(""), wantErr: true},
+		{name: "forced other tenant", mode: TenantModeForce, namespace: "team-a", obj: site("tenant-b"), wantTenant: "tenant-a"},
+		{name: "forced no tenant", mode: TenantModeForce, namespace: "team-a", obj: site(""), wantTenant: "tenant-a"},
+		{name: "own tenant", mode: TenantModeReject, namespace: "team-a", obj: tenant("tenant-a")},
+		{name: "other tenant resource", mode: TenantModeReject, namespace: "team-a", obj: tenant("tenant-b"), wantErr: true},
+		{name: "forced other tenant resource", mode: TenantModeForce, namespace: "team-a", obj: tenant("tenant-b"), wantErr: true},
+		{name: "tenant group", mode: TenantModeForce, namespace: "team-a", obj: group, wantErr: true},
+		{name: "tenant group in unmapped namespace", mode: TenantModeReject, namespace: "other", obj: group},
+		{name: "kind without tenant", mode: TenantModeReject, namespace: "team-a", obj: region},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tenants := NamespaceTenants{Tenants: map[string]string{"team-a": "tenant-a"}, Mode: tt.mode}
+
+			err := tenants.scope(tt.obj, tt.namespace)
+			if tt.wantErr {
+				if !IsTenantMismatch(err) {
+					t.Errorf("expected a tenant mismatch, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s, ok := tt.obj.(*netboxv1.Site); ok && s.Spec.Tenant != tt.wantTenant {
+				t.Errorf("expected tenant %q, got %q", tt.wantTenant, s.Spec.Tenant)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// netboxIDField indexes the resources by the ID of their Netbox object
+	netboxIDField = "netbox.id"
+	// webhookSignatureHeader carries the HMAC-SHA512 of the payload, signed with the webhook secret
+	webhookSignatureHeader = "X-Hook-Signature"
+	// maxWebhookPayload limits the size of the accepted payloads
+	maxWebhookPayload = 1 << 20
+)
+
+// webhookPayload is the body of the Netbox webhooks, only the fields used to find the resources are decoded
+type webhookPayload struct {
+	Event string `json:"event"`
+	Model string `json:"model"`
+	Data  struct {
+		ID int64 `json:"id"`
+	} `json:"data"`
+}
+
+// webhookHandler is called for every resource owning the object of a Netbox webhook
+type webhookHandler func(obj client.Object)
+
+type webhookModel struct {
+	newList func() client.ObjectList
+	handler webhookHandler
+}
+
+// WebhookReceiver accepts Netbox webhooks and hands the resources owning the
+// changed objects to their controllers, so that out-of-band edits are corrected
+type WebhookReceiver struct {
+	Client client.Reader
+	Addr   string
+	Secret []byte
+
+	mu     sync.RWMutex
+	models map[string]webhookModel
+}
+
+// Register maps a Netbox model (e.g. "device") to its resource list and the handler of its controller
+func (w *WebhookReceiver) Register(model string, newList func() client.ObjectList, handler webhookHandler) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.models == nil {
+		w.models = map[string]webhookModel{}
+	}
+	w.models[model] = webhookModel{newList: newList, handler: handler}
+}
+
+// Start implements manager.Runnable. The receiver only runs on the leader, where the controllers are running
+func (w *WebhookReceiver) Start(ctx context.Context) error {
+	log := log.FromContext(ctx).WithName("webhook")
+
+	srv := &http.Server{
+		Addr:    w.Addr,
+		Handler: w,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.Error(err, "failed to shut down the webhook receiver")
+		}
+	}()
+
+	log.Info("starting Netbox webhook receiver", "addr", w.Addr)
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+func (w *WebhookReceiver) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	log := log.FromContext(req.Context()).WithName("webhook")
+
+	if req.Method != http.MethodPost {
+		http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(rw, req.Body, maxWebhookPayload))
+	if err != nil {
+		http.Error(rw, "failed to read payload", http.StatusBadRequest)
+		return
+	}
+
+	if !w.validSignature(body, req.Header.Get(webhookSignatureHeader)) {
+		log.Info("rejected webhook with an invalid signature", "remote", req.RemoteAddr)
+		http.Error(rw, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	var payload webhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		http.Error(rw, "failed to decode payload", http.StatusBadRequest)
+		return
+	}
+
+	w.mu.RLock()
+	model, ok := w.models[payload.Model]
+	w.mu.RUnlock()
+	if !ok || payload.Data.ID == 0 {
+		log.V(1).Info("ignoring webhook", "model", payload.Model, "event", payload.Event)
+		rw.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	list := model.newList()
+	if err := w.Client.List(req.Context(), list, client.MatchingFields{netboxIDField: strconv.FormatInt(payload.Data.ID, 10)}); err != nil {
+		log.Error(err, "failed to list resources", "model", payload.Model, "id", payload.Data.ID)
+		http.Error(rw, "failed to list resources", http.StatusInternalServerError)
+		return
+	}
+
+	objects, err := meta.ExtractList(list)
+	if err != nil {
+		http.Error(rw, "failed to list resources", http.StatusInternalServerError)
+		return
+	}
+
+	for _, o := range objects {
+		if obj, ok := o.(client.Object); ok {
+			log.V(1).Info("enqueuing resource", "model", payload.Model, "event", payload.Event, "id", payload.Data.ID, "name", obj.GetName())
+			model.handler(obj)
+		}
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// validSignature compares the signature header with the HMAC-SHA512 of the payload, as computed by Netbox
+func (w *WebhookReceiver) validSignature(body []byte, signature string) bool {
+	expected, err := hex.DecodeString(signature)
+	if err != nil || len(expected) == 0 {
+		return false
+	}
+
+	mac := hmac.New(sha512.New, w.Secret)
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
@@ -0,0 +1,209 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+)
+
+// indexedReader serves the netbox.id field selector of the webhook receiver with the
+// index function of the controllers, which the fake client doesn't support
+type indexedReader struct {
+	client.Reader
+	index client.IndexerFunc
+}
+
+func (r indexedReader) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	listOpts := client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+	if err := r.Reader.List(ctx, list, client.InNamespace(listOpts.Namespace)); err != nil {
+		return err
+	}
+	if listOpts.FieldSelector == nil {
+		return nil
+	}
+	id, _ := listOpts.FieldSelector.RequiresExactMatch(netboxIDField)
+
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return err
+	}
+	var matched []runtime.Object
+	for _, item := range items {
+		for _, v := range r.index(item.(client.Object)) {
+			if v == id {
+				matched = append(matched, item)
+			}
+		}
+	}
+	return meta.SetList(list, matched)
+}
+
+func sign(secret, body string) string {
+	mac := hmac.New(sha512.New, []byte(secret))
+	mac.Write([]byte(body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestWebhookSignature(t *testing.T) {
+	w := &WebhookReceiver{Secret: []byte("secret")}
+	body := `{"event": "updated", "model": "device", "data": {"id": 5}}`
+
+	tests := []struct {
+		name      string
+		signature string
+		want      bool
+	}{
+		{name: "valid", signature: sign("secret", body), want: true},
+		{name: "missing"},
+		{name: "not hex", signature: "not-a-signature"},
+		{name: "wrong key", signature: sign("other", body)},
+		{name: "other payload", signature: sign("secret", body+" ")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := w.validSignature([]byte(body), tt.signature); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWebhookServeHTTP(t *testing.T) {
+	device := func(name string, id int64, annotation string) *netboxv1.Device {
+		dev := &netboxv1.Device{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "lab"}}
+		if id != 0 {
+			dev.Status.ID = &id
+		}
+		if annotation != "" {
+			dev.Annotations = map[string]string{netboxv1.IDAnnotation: annotation}
+		}
+		return dev
+	}
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(
+		device("leaf-01", 5, ""),
+		device("leaf-02", 0, "5"),
+		device("leaf-03", 6, ""),
+	).Build()
+
+	payload := func(model string, id int) string {
+		return `{"event": "updated", "model": "` + model + `", "data": {"id": ` + strconv.Itoa(id) + `}}`
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		signature  string
+		wantStatus int
+		want       []string
+	}{
+		{
+			name:       "valid",
+			body:       payload("device", 5),
+			signature:  sign("secret", payload("device", 5)),
+			wantStatus: http.StatusNoContent,
+			want:       []string{"leaf-01", "leaf-02"},
+		},
+		{
+			name:       "not a POST",
+			method:     http.MethodGet,
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:       "missing signature",
+			body:       payload("device", 5),
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "signature not hex",
+			body:       payload("device", 5),
+			signature:  "zz",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "wrong key",
+			body:       payload("device", 5),
+			signature:  sign("other", payload("device", 5)),
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "payload too large",
+			body:       strings.Repeat(" ", maxWebhookPayload+1),
+			signature:  sign("secret", strings.Repeat(" ", maxWebhookPayload+1)),
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unknown model",
+			body:       payload("site", 5),
+			signature:  sign("secret", payload("site", 5)),
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "no id",
+			body:       payload("device", 0),
+			signature:  sign("secret", payload("device", 0)),
+			wantStatus: http.StatusNoContent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var enqueued []string
+			w := &WebhookReceiver{Client: indexedReader{Reader: c, index: deviceNetboxID}, Secret: []byte("secret")}
+			w.Register("device", func() client.ObjectList { return &netboxv1.DeviceList{} }, func(obj client.Object) {
+				enqueued = append(enqueued, obj.GetName())
+			})
+
+			method := tt.method
+			if method == "" {
+				method = http.MethodPost
+			}
+			req := httptest.NewRequest(method, "/", strings.NewReader(tt.body))
+			if tt.signature != "" {
+				req.Header.Set(webhookSignatureHeader, tt.signature)
+			}
+			rec := httptest.NewRecorder()
+			w.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if !reflect.DeepEqual(enqueued, tt.want) {
+				t.Errorf("expected enqueued %v, got %v", tt.want, enqueued)
+			}
+		})
+	}
+}
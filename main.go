@@ -34,12 +34,15 @@ import (
 
 	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
 	"github.com/networkop/declarative-netbox/controllers"
+	"github.com/networkop/declarative-netbox/netbox"
 	//+kubebuilder:scaffold:imports
 )
 
 const (
 	netbox_api   = "NETBOX_API"
 	netbox_token = "NETBOX_TOKEN"
+	// secret shared with the Netbox webhooks, used to verify their signature
+	netbox_webhook_secret = "NETBOX_WEBHOOK_SECRET"
 )
 
 var (
@@ -58,12 +61,54 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var netboxTransport netbox.TransportOptions
+	netboxRetry := netbox.DefaultRetryOptions()
+	var deletionPolicy string
+	var webhookAddr string
+	var deviceNamePattern string
+	var namespaceTenants string
+	var tenantMode string
+	var nodeSync bool
+	var nodeSyncNamespace string
+	var nodeSyncKind string
+	var nodeSyncLabels string
+	var nodeSyncDefaults string
+	var nodeSyncInterface string
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&netboxTransport.CAFile, "netbox-ca-file", "", "PEM bundle used to verify the Netbox server certificate.")
+	flag.StringVar(&netboxTransport.CertFile, "netbox-cert-file", "", "Client certificate for mutual TLS with Netbox.")
+	flag.StringVar(&netboxTransport.KeyFile, "netbox-key-file", "", "Client key for mutual TLS with Netbox.")
+	flag.BoolVar(&netboxTransport.InsecureSkipVerify, "netbox-insecure-skip-verify", false, "Do not verify the Netbox server certificate.")
+	flag.StringVar(&netboxTransport.Proxy, "netbox-proxy", "", "HTTP proxy URL used to reach Netbox.")
+	flag.DurationVar(&netboxTransport.Timeout, "netbox-timeout", 0, "Netbox API request timeout.")
+	flag.Float64Var(&netboxRetry.QPS, "netbox-qps", netboxRetry.QPS, "Maximum sustained rate of Netbox API requests per second, 0 disables rate limiting.")
+	flag.IntVar(&netboxRetry.Burst, "netbox-burst", netboxRetry.Burst, "Maximum burst of Netbox API requests.")
+	flag.IntVar(&netboxRetry.MaxRetries, "netbox-max-retries", netboxRetry.MaxRetries, "Number of retries of failed Netbox API requests.")
+	flag.StringVar(&deletionPolicy, "default-deletion-policy", string(netboxv1.DeletionPolicyDelete),
+		"What happens to Netbox objects when their resources are deleted, unless set per object (Delete or Orphan).")
+	flag.StringVar(&webhookAddr, "netbox-webhook-bind-address", "",
+		"The address the Netbox webhook receiver binds to, empty disables the receiver. Requires the NETBOX_WEBHOOK_SECRET env var.")
+	flag.StringVar(&deviceNamePattern, "device-name-pattern", "",
+		"Regular expression that device names must match, enforced by the validating webhook.")
+	flag.StringVar(&namespaceTenants, "namespace-tenants", "",
+		"Comma separated list of namespace=tenant pairs, restricting the resources of a namespace to a Netbox tenant.")
+	flag.StringVar(&tenantMode, "namespace-tenant-mode", string(controllers.TenantModeReject),
+		"What happens to resources with another tenant than their namespace (Reject or Force).")
+	flag.BoolVar(&nodeSync, "node-sync", false, "Sync Kubernetes Nodes into Netbox devices or virtual machines.")
+	flag.StringVar(&nodeSyncNamespace, "node-sync-namespace", "default", "The namespace of the resources synced from Kubernetes Nodes.")
+	flag.StringVar(&nodeSyncKind, "node-sync-kind", string(controllers.NodeSyncDevice),
+		"The kind of the resources synced from Kubernetes Nodes (Device or VirtualMachine).")
+	flag.StringVar(&nodeSyncLabels, "node-sync-labels", "",
+		"Comma separated list of field=label pairs, overriding the node labels of the site, role, device_type, cluster and tenant of synced nodes.")
+	flag.StringVar(&nodeSyncDefaults, "node-sync-defaults", "",
+		"Comma separated list of field=value pairs, setting the site, role, device_type, cluster and tenant of synced nodes without the label.")
+	flag.StringVar(&nodeSyncInterface, "node-sync-interface", controllers.DefaultNodeInterface,
+		"The name of the virtual interface the internal IPs of synced nodes are assigned to.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -74,6 +119,48 @@ func main() {
 		log.Fatalf("NETBOX_TOKEN and NETBOX_API env vars must be provided")
 	}
 
+	switch netboxv1.DeletionPolicy(deletionPolicy) {
+	case netboxv1.DeletionPolicyDelete, netboxv1.DeletionPolicyOrphan:
+	default:
+		log.Fatalf("unexpected --default-deletion-policy %q, must be Delete or Orphan", deletionPolicy)
+	}
+
+	tenants, err := controllers.ParseNamespaceTenants(namespaceTenants)
+	if err != nil {
+		log.Fatalf("unexpected --namespace-tenants: %s", err)
+	}
+	switch controllers.TenantMode(tenantMode) {
+	case controllers.TenantModeReject, controllers.TenantModeForce:
+	default:
+		log.Fatalf("unexpected --namespace-tenant-mode %q, must be Reject or Force", tenantMode)
+	}
+	namespaceTenantOpts := controllers.NamespaceTenants{
+		Tenants: tenants,
+		Mode:    controllers.TenantMode(tenantMode),
+	}
+
+	nodeSyncOpts := controllers.NodeSyncOptions{
+		Namespace: nodeSyncNamespace,
+		Kind:      controllers.NodeSyncKind(nodeSyncKind),
+		Labels:    controllers.DefaultNodeLabels,
+		Interface: nodeSyncInterface,
+	}
+	switch nodeSyncOpts.Kind {
+	case controllers.NodeSyncDevice, controllers.NodeSyncVirtualMachine:
+	default:
+		log.Fatalf("unexpected --node-sync-kind %q, must be Device or VirtualMachine", nodeSyncKind)
+	}
+	if err := controllers.ParseNodeFields(nodeSyncLabels, &nodeSyncOpts.Labels); err != nil {
+		log.Fatalf("unexpected --node-sync-labels: %s", err)
+	}
+	if err := controllers.ParseNodeFields(nodeSyncDefaults, &nodeSyncOpts.Defaults); err != nil {
+		log.Fatalf("unexpected --node-sync-defaults: %s", err)
+	}
+
+	if webhookAddr != "" && os.Getenv(netbox_webhook_secret) == "" {
+		log.Fatalf("NETBOX_WEBHOOK_SECRET env var must be provided when --netbox-webhook-bind-address is set")
+	}
+
 	netboxAddr := os.Getenv(netbox_api)
 	netboxToken := os.Getenv(netbox_token)
 
@@ -92,16 +179,78 @@ func main() {
 		os.Exit(1)
 	}
 
+	var webhook *controllers.WebhookReceiver
+	if webhookAddr != "" {
+		webhook = &controllers.WebhookReceiver{
+			Client: mgr.GetClient(),
+			Addr:   webhookAddr,
+			Secret: []byte(os.Getenv(netbox_webhook_secret)),
+		}
+		if err := mgr.Add(webhook); err != nil {
+			setupLog.Error(err, "unable to set up Netbox webhook receiver")
+			os.Exit(1)
+		}
+	}
+
+	reconcilerOpts := controllers.ReconcilerOptions{
+		NetboxURL:       netboxAddr,
+		NetboxToken:     netboxToken,
+		NetboxTransport: netboxTransport,
+		NetboxRetry:     netboxRetry,
+		DeletionPolicy:  netboxv1.DeletionPolicy(deletionPolicy),
+		Webhook:         webhook,
+		Tenants:         namespaceTenantOpts,
+	}
 	if err = (&controllers.DeviceReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr, controllers.DeviceReconcilerOptions{
-		NetboxURL:   netboxAddr,
-		NetboxToken: netboxToken,
-	}); err != nil {
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("device-controller"),
+	}).SetupWithManager(mgr, reconcilerOpts); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Device")
 		os.Exit(1)
 	}
+	if err = controllers.SetupObjectReconcilers(mgr, reconcilerOpts); err != nil {
+		setupLog.Error(err, "unable to create object controllers")
+		os.Exit(1)
+	}
+	if err = (&controllers.FabricReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("fabric-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Fabric")
+		os.Exit(1)
+	}
+	if nodeSync {
+		if err = (&controllers.NodeSyncReconciler{
+			Client:   mgr.GetClient(),
+			Recorder: mgr.GetEventRecorderFor("nodesync-controller"),
+			Options:  nodeSyncOpts,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "NodeSync")
+			os.Exit(1)
+		}
+	}
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = (&controllers.DeviceValidator{}).SetupWebhookWithManager(mgr, controllers.DeviceValidatorOptions{
+			NetboxURL:       netboxAddr,
+			NetboxToken:     netboxToken,
+			NetboxTransport: netboxTransport,
+			NetboxRetry:     netboxRetry,
+			NamePattern:     deviceNamePattern,
+			Tenants:         namespaceTenantOpts,
+		}); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Device")
+			os.Exit(1)
+		}
+		if err = (&controllers.DeviceDefaulter{
+			Client:  mgr.GetClient(),
+			Tenants: namespaceTenantOpts,
+		}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Device")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -0,0 +1,286 @@
+package netbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/strfmt"
+	"github.com/netbox-community/go-netbox/netbox/client/users"
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+)
+
+// auditedTypes maps the Netbox content types recorded in the changelog to their kinds
+var auditedTypes = map[string]string{
+	"dcim.region":                   netboxv1.RegionKind,
+	"dcim.sitegroup":                netboxv1.SiteGroupKind,
+	"tenancy.tenantgroup":           netboxv1.TenantGroupKind,
+	"tenancy.tenant":                netboxv1.TenantKind,
+	"dcim.site":                     netboxv1.SiteKind,
+	"dcim.location":                 netboxv1.LocationKind,
+	"dcim.rack":                     netboxv1.RackKind,
+	"ipam.vlangroup":                netboxv1.VLANGroupKind,
+	"ipam.vlan":                     netboxv1.VLANKind,
+	"ipam.routetarget":              netboxv1.RouteTargetKind,
+	"ipam.vrf":                      netboxv1.VRFKind,
+	"ipam.prefix":                   netboxv1.PrefixKind,
+	"dcim.platform":                 netboxv1.PlatformKind,
+	"dcim.devicetype":               netboxv1.DeviceTypeKind,
+	"virtualization.clustertype":    netboxv1.ClusterTypeKind,
+	"virtualization.cluster":        netboxv1.ClusterKind,
+	"virtualization.virtualmachine": netboxv1.VirtualMachineKind,
+	"dcim.device":                   netboxv1.DeviceKind,
+	"dcim.interface":                netboxv1.InterfaceKind,
+	"virtualization.vminterface":    netboxv1.VMInterfaceKind,
+	"dcim.cable":                    netboxv1.CableKind,
+	"ipam.ipaddress":                netboxv1.IPAddressKind,
+}
+
+// nameFields are the changelog fields that name an object, in order of preference.
+// Prefixes and IP addresses have no name, and cables only an optional label
+var nameFields = []string{"name", "prefix", "address", "label"}
+
+// ignoredFields change on every update and are left out of the diffs
+var ignoredFields = map[string]bool{
+	"last_updated": true,
+}
+
+// AuditOptions selects the changes returned by Audit
+type AuditOptions struct {
+	// Since is the time of the oldest change
+	Since time.Time
+	// ExcludeUsers are the users whose changes are expected, e.g. the user of the declarative-netbox token
+	ExcludeUsers []string
+	// ChunkSize is the number of changes requested per page
+	ChunkSize int64
+}
+
+// Change is a change of a managed object recorded in the Netbox changelog
+type Change struct {
+	Time time.Time `json:"time"`
+	User string    `json:"user"`
+	// Action is one of create, update or delete
+	Action string `json:"action"`
+	Kind   string `json:"kind"`
+	ID     int64  `json:"id"`
+	Name   string `json:"name"`
+	// Prechange and Postchange hold the object data before and after the change,
+	// they are empty for created and deleted objects respectively
+	Prechange  map[string]interface{} `json:"prechange,omitempty"`
+	Postchange map[string]interface{} `json:"postchange,omitempty"`
+}
+
+// FieldDiff is a field that differs between two versions of an object
+type FieldDiff struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// Diff returns the fields changed by c
+func (c Change) Diff() []FieldDiff {
+	return diffData(c.Prechange, c.Postchange)
+}
+
+// Data returns the latest known data of the changed object
+func (c Change) Data() map[string]interface{} {
+	if c.Postchange != nil {
+		return c.Postchange
+	}
+	return c.Prechange
+}
+
+func diffData(old, new map[string]interface{}) []FieldDiff {
+	fields := map[string]bool{}
+	for k := range old {
+		fields[k] = true
+	}
+	for k := range new {
+		fields[k] = true
+	}
+
+	diff := []FieldDiff{}
+	for field := range fields {
+		if ignoredFields[field] || reflect.DeepEqual(old[field], new[field]) {
+			continue
+		}
+		diff = append(diff, FieldDiff{Field: field, Old: old[field], New: new[field]})
+	}
+	sort.Slice(diff, func(i, j int) bool { return diff[i].Field < diff[j].Field })
+	return diff
+}
+
+// objectChange is the changelog entry as returned by Netbox. go-netbox decodes
+// the pre- and post-change data as strings, while Netbox returns JSON objects
+type objectChange struct {
+	Time   strfmt.DateTime `json:"time"`
+	User   string          `json:"user_name"`
+	Action struct {
+		Value string `json:"value"`
+	} `json:"action"`
+	Type       string                 `json:"changed_object_type"`
+	ID         int64                  `json:"changed_object_id"`
+	Prechange  map[string]interface{} `json:"prechange_data"`
+	Postchange map[string]interface{} `json:"postchange_data"`
+}
+
+type objectChangeList struct {
+	Next    *string         `json:"next"`
+	Results []*objectChange `json:"results"`
+}
+
+// Audit returns the changes of managed objects, i.e. objects that had the managed tag or the
+// created custom field before or after the change, that were not made by one of the excluded users.
+// Objects without a name are named after their prefix, address, label or ID
+func (s *NetboxServer) Audit(ctx context.Context, opts AuditOptions) ([]Change, error) {
+	log := logr.FromContext(ctx)
+
+	changes := []Change{}
+	for contentType, kind := range auditedTypes {
+		err := paginate(ctx, opts.ChunkSize, 0, func(offset, limit int64) (int, bool, error) {
+			page, err := s.listObjectChanges(ctx, contentType, opts, offset, limit)
+			if err != nil {
+				return 0, false, err
+			}
+			log.V(1).Info("found object changes", "type", contentType, "count", len(page.Results), "offset", offset)
+
+			for _, c := range page.Results {
+				if !isManaged(c.Prechange) && !isManaged(c.Postchange) {
+					continue
+				}
+				change := Change{
+					Time:       time.Time(c.Time),
+					User:       c.User,
+					Action:     c.Action.Value,
+					Kind:       kind,
+					ID:         c.ID,
+					Prechange:  c.Prechange,
+					Postchange: c.Postchange,
+				}
+				change.Name = strconv.FormatInt(c.ID, 10)
+				for _, field := range nameFields {
+					if name, ok := change.Data()[field].(string); ok && name != "" {
+						change.Name = name
+						break
+					}
+				}
+				changes = append(changes, change)
+			}
+
+			return len(page.Results), page.Next != nil, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Time.Before(changes[j].Time) })
+	return changes, nil
+}
+
+func (s *NetboxServer) listObjectChanges(ctx context.Context, contentType string, opts AuditOptions, offset, limit int64) (*objectChangeList, error) {
+	result, err := s.Client.Transport.Submit(&runtime.ClientOperation{
+		ID:                 "extras_object-changes_list",
+		Method:             http.MethodGet,
+		PathPattern:        "/extras/object-changes/",
+		ProducesMediaTypes: []string{"application/json"},
+		ConsumesMediaTypes: []string{"application/json"},
+		Schemes:            []string{"http"},
+		Params: runtime.ClientRequestWriterFunc(func(r runtime.ClientRequest, _ strfmt.Registry) error {
+			if err := r.SetQueryParam("changed_object_type", contentType); err != nil {
+				return err
+			}
+			if err := r.SetQueryParam("time_after", opts.Since.UTC().Format(time.RFC3339)); err != nil {
+				return err
+			}
+			if len(opts.ExcludeUsers) > 0 {
+				if err := r.SetQueryParam("user_name__n", opts.ExcludeUsers...); err != nil {
+					return err
+				}
+			}
+			if err := r.SetQueryParam("offset", fmt.Sprint(offset)); err != nil {
+				return err
+			}
+			return r.SetQueryParam("limit", fmt.Sprint(limit))
+		}),
+		Reader: runtime.ClientResponseReaderFunc(func(response runtime.ClientResponse, consumer runtime.Consumer) (interface{}, error) {
+			if response.Code() != http.StatusOK {
+				return nil, runtime.NewAPIError("extras_object-changes_list", response.Message(), response.Code())
+			}
+			list := &objectChangeList{}
+			if err := consumer.Consume(response.Body(), list); err != nil {
+				return nil, err
+			}
+			return list, nil
+		}),
+		Context: ctx,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list object changes, %w", err)
+	}
+	return result.(*objectChangeList), nil
+}
+
+// isManaged returns true if the changelog data of an object includes the managed tag,
+// or the created custom field for the kinds without tags
+func isManaged(data map[string]interface{}) bool {
+	tags, _ := data["tags"].([]interface{})
+	for _, t := range tags {
+		if t == ManagedTagName {
+			return true
+		}
+	}
+	return hasCreatedField(data["custom_fields"])
+}
+
+// dataID returns the ID of a related object in the changelog data
+func dataID(v interface{}) (int64, bool) {
+	switch id := v.(type) {
+	case json.Number:
+		n, err := id.Int64()
+		return n, err == nil
+	case float64:
+		return int64(id), true
+	}
+	return 0, false
+}
+
+// TokenUser returns the name of the user that owns the API token
+func (s *NetboxServer) TokenUser(ctx context.Context, token string) (string, error) {
+	tokens, err := s.Client.Users.UsersTokensList(&users.UsersTokensListParams{
+		Key:     &token,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to UsersTokensList, %w", err)
+	}
+
+	for _, t := range tokens.Payload.Results {
+		if t.User != nil && t.User.Username != nil {
+			return *t.User.Username, nil
+		}
+	}
+	return "", fmt.Errorf("the owner of the API token was not found")
+}
+
+// Drift compares the desired state of object with the changelog data of its Netbox object
+func (s *NetboxServer) Drift(ctx context.Context, object interface{}, data map[string]interface{}) ([]FieldDiff, error) {
+	log := logr.FromContext(ctx)
+
+	switch o := object.(type) {
+	case *netboxv1.Device:
+		log.V(1).Info("identified type: Device")
+		return NewDevice(*s, o).drift(ctx, data)
+	default:
+		log.V(1).Info("identified type: default")
+		log.Error(fmt.Errorf("unknown object type"), fmt.Sprintf("%T", o))
+	}
+	return nil, nil
+}
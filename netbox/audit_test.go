@@ -0,0 +1,151 @@
+package netbox
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+const objectChanges = `{
+  "count": 3,
+  "next": null,
+  "results": [
+    {
+      "time": "2021-12-24T10:00:00Z",
+      "user_name": "alice",
+      "action": {"value": "update", "label": "Updated"},
+      "changed_object_type": "dcim.device",
+      "changed_object_id": 3,
+      "prechange_data": {"name": "leaf-99", "site": 1, "tags": ["declarative-netbox"], "last_updated": "a"},
+      "postchange_data": {"name": "leaf-99", "site": 2, "tags": ["declarative-netbox"], "last_updated": "b"}
+    },
+    {
+      "time": "2021-12-24T09:00:00Z",
+      "user_name": "bob",
+      "action": {"value": "delete", "label": "Deleted"},
+      "changed_object_type": "dcim.device",
+      "changed_object_id": 4,
+      "prechange_data": {"name": "spine-01", "tags": ["declarative-netbox"]},
+      "postchange_data": null
+    },
+    {
+      "time": "2021-12-24T11:00:00Z",
+      "user_name": "alice",
+      "action": {"value": "update", "label": "Updated"},
+      "changed_object_type": "dcim.device",
+      "changed_object_id": 5,
+      "prechange_data": {"name": "unmanaged", "site": 1, "tags": []},
+      "postchange_data": {"name": "unmanaged", "site": 2, "tags": []}
+    }
+  ]
+}`
+
+// regionChanges are the changes of a region created by declarative-netbox and of one made by hand
+const regionChanges = `{
+  "count": 2,
+  "next": null,
+  "results": [
+    {
+      "time": "2021-12-24T12:00:00Z",
+      "user_name": "alice",
+      "action": {"value": "update", "label": "Updated"},
+      "changed_object_type": "dcim.region",
+      "changed_object_id": 6,
+      "prechange_data": {"name": "emea", "description": "", "custom_fields": {"declarative_netbox": true}},
+      "postchange_data": {"name": "emea", "description": "Europe", "custom_fields": {"declarative_netbox": true}}
+    },
+    {
+      "time": "2021-12-24T12:00:00Z",
+      "user_name": "alice",
+      "action": {"value": "update", "label": "Updated"},
+      "changed_object_type": "dcim.region",
+      "changed_object_id": 7,
+      "prechange_data": {"name": "apac", "description": "", "custom_fields": {"declarative_netbox": null}},
+      "postchange_data": {"name": "apac", "description": "Asia", "custom_fields": {"declarative_netbox": null}}
+    }
+  ]
+}`
+
+const prefixChanges = `{
+  "count": 1,
+  "next": null,
+  "results": [
+    {
+      "time": "2021-12-24T13:00:00Z",
+      "user_name": "bob",
+      "action": {"value": "update", "label": "Updated"},
+      "changed_object_type": "ipam.prefix",
+      "changed_object_id": 8,
+      "prechange_data": {"prefix": "10.0.0.0/24", "status": "active", "tags": ["declarative-netbox"]},
+      "postchange_data": {"prefix": "10.0.0.0/24", "status": "reserved", "tags": ["declarative-netbox"]}
+    }
+  ]
+}`
+
+func TestAudit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/extras/object-changes/" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		q := r.URL.Query()
+		if got := q["user_name__n"]; !reflect.DeepEqual(got, []string{"nbctl", "controller"}) {
+			t.Errorf("user_name__n = %v", got)
+		}
+		if got := q.Get("time_after"); got != "2021-12-24T00:00:00Z" {
+			t.Errorf("time_after = %s", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch q.Get("changed_object_type") {
+		case "dcim.device":
+			w.Write([]byte(objectChanges))
+		case "dcim.region":
+			w.Write([]byte(regionChanges))
+		case "ipam.prefix":
+			w.Write([]byte(prefixChanges))
+		default:
+			w.Write([]byte(`{"count": 0, "next": null, "results": []}`))
+		}
+	}))
+	defer srv.Close()
+
+	s, err := NewNetboxServer(srv.URL, "token", WithRetry(RetryOptions{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := logr.NewContext(context.Background(), logr.Discard())
+	changes, err := s.Audit(ctx, AuditOptions{
+		Since:        time.Date(2021, 12, 24, 0, 0, 0, 0, time.UTC),
+		ExcludeUsers: []string{"nbctl", "controller"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(changes) != 4 {
+		t.Fatalf("got %d changes, want 4", len(changes))
+	}
+	if changes[2].Kind != "Region" || changes[2].Name != "emea" {
+		t.Errorf("unexpected third change %+v", changes[2])
+	}
+	if changes[3].Kind != "Prefix" || changes[3].Name != "10.0.0.0/24" {
+		t.Errorf("unexpected fourth change %+v", changes[3])
+	}
+	if changes[0].Name != "spine-01" || changes[0].Action != "delete" {
+		t.Errorf("unexpected first change %+v", changes[0])
+	}
+	if changes[1].Name != "leaf-99" || changes[1].User != "alice" {
+		t.Errorf("unexpected second change %+v", changes[1])
+	}
+
+	want := []FieldDiff{{Field: "site", Old: json.Number("1"), New: json.Number("2")}}
+	if got := changes[1].Diff(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,460 @@
+package netbox
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	"github.com/netbox-community/go-netbox/netbox/client/circuits"
+	"github.com/netbox-community/go-netbox/netbox/client/dcim"
+	"github.com/netbox-community/go-netbox/netbox/models"
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Netbox content types of the cable terminations
+const (
+	interfaceTermination = "dcim.interface"
+	frontPortTermination = "dcim.frontport"
+	rearPortTermination  = "dcim.rearport"
+	circuitTermination   = "circuits.circuittermination"
+)
+
+type Cable struct {
+	Data *netboxv1.Cable
+	NetboxServer
+
+	a, b *termination
+}
+
+// termination is a resolved end of a cable
+type termination struct {
+	Type string
+	ID   int64
+	// Name identifies the termination in errors, e.g. leaf-01/eth1
+	Name string
+	// Cable is the ID of the cable already connected to the termination, if any
+	Cable *int64
+}
+
+func NewCable(s NetboxServer, c *netboxv1.Cable) *Cable {
+	return &Cable{
+		Data:         c,
+		NetboxServer: s,
+	}
+}
+
+func (c *Cable) resource() netboxv1.Object {
+	return c.Data
+}
+
+func (c *Cable) typeName() string {
+	return "cable"
+}
+
+func (c *Cable) path() string {
+	return "/dcim/cables/"
+}
+
+func (c *Cable) tags() []string {
+	return c.Data.Spec.Tags
+}
+
+// resolve looks up both ends of the cable
+func (c *Cable) resolve(ctx context.Context) error {
+	log := logr.FromContext(ctx)
+
+	a, err := c.resolveTermination(ctx, c.Data.Spec.TerminationA)
+	if err != nil {
+		return err
+	}
+	log.V(1).Info("found termination A", "type", a.Type, "id", a.ID)
+
+	b, err := c.resolveTermination(ctx, c.Data.Spec.TerminationB)
+	if err != nil {
+		return err
+	}
+	log.V(1).Info("found termination B", "type", b.Type, "id", b.ID)
+
+	c.a, c.b = a, b
+	return nil
+}
+
+func (c *Cable) resolveTermination(ctx context.Context, t netboxv1.CableTermination) (*termination, error) {
+	switch {
+	case t.Interface != "":
+		name := t.Device + "/" + t.Interface
+		result, err := c.Client.Dcim.DcimInterfacesList(&dcim.DcimInterfacesListParams{
+			Device:  &t.Device,
+			Name:    &t.Interface,
+			Context: ctx,
+		}, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to DcimInterfacesList, %w", err)
+		}
+		if *result.Payload.Count != 1 {
+			return nil, &ReferenceNotFoundError{Type: "interface", Name: name}
+		}
+		intf := result.Payload.Results[0]
+		return newTermination(interfaceTermination, intf.ID, name, intf.Cable), nil
+	case t.FrontPort != "":
+		name := t.Device + "/" + t.FrontPort
+		result, err := c.Client.Dcim.DcimFrontPortsList(&dcim.DcimFrontPortsListParams{
+			Device:  &t.Device,
+			Name:    &t.FrontPort,
+			Context: ctx,
+		}, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to DcimFrontPortsList, %w", err)
+		}
+		if *result.Payload.Count != 1 {
+			return nil, &ReferenceNotFoundError{Type: "front port", Name: name}
+		}
+		port := result.Payload.Results[0]
+		return newTermination(frontPortTermination, port.ID, name, port.Cable), nil
+	case t.RearPort != "":
+		name := t.Device + "/" + t.RearPort
+		result, err := c.Client.Dcim.DcimRearPortsList(&dcim.DcimRearPortsListParams{
+			Device:  &t.Device,
+			Name:    &t.RearPort,
+			Context: ctx,
+		}, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to DcimRearPortsList, %w", err)
+		}
+		if *result.Payload.Count != 1 {
+			return nil, &ReferenceNotFoundError{Type: "rear port", Name: name}
+		}
+		port := result.Payload.Results[0]
+		return newTermination(rearPortTermination, port.ID, name, port.Cable), nil
+	case t.Circuit != "":
+		side := t.TermSide
+		if side == "" {
+			side = "A"
+		}
+		circuitID, err := c.resolveNameToID(ctx, t.Circuit, "circuit")
+		if err != nil {
+			return nil, err
+		}
+		name := t.Circuit + "/" + side
+		id := strconv.FormatInt(circuitID, 10)
+		result, err := c.Client.Circuits.CircuitsCircuitTerminationsList(&circuits.CircuitsCircuitTerminationsListParams{
+			CircuitID: &id,
+			TermSide:  &side,
+			Context:   ctx,
+		}, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to CircuitsCircuitTerminationsList, %w", err)
+		}
+		if *result.Payload.Count != 1 {
+			return nil, &ReferenceNotFoundError{Type: "circuit termination", Name: name}
+		}
+		term := result.Payload.Results[0]
+		return newTermination(circuitTermination, term.ID, name, term.Cable), nil
+	default:
+		return nil, fmt.Errorf("cable %q: a termination must set an interface, front port, rear port or circuit", c.Data.Name)
+	}
+}
+
+func newTermination(t string, id int64, name string, cable *models.NestedCable) *termination {
+	result := &termination{Type: t, ID: id, Name: name}
+	if cable != nil {
+		result.Cable = &cable.ID
+	}
+	return result
+}
+
+func (c *Cable) read(ctx context.Context, id int64) (*current, error) {
+	cable, err := c.Client.Dcim.DcimCablesRead(&dcim.DcimCablesReadParams{
+		ID:      id,
+		Context: ctx,
+	}, nil)
+	if IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to DcimCablesRead, %w", err)
+	}
+
+	return cableCurrent(cable.GetPayload()), nil
+}
+
+func cableCurrent(cable *models.Cable) *current {
+	return &current{ID: cable.ID, Tags: cable.Tags, model: cable}
+}
+
+// hosts implements attached, cables belong to the tenants of the devices at both ends.
+// Circuit terminations have no device
+func (c *Cable) hosts(cur *current) []host {
+	hosts := []host{
+		{typ: "device", name: c.Data.Spec.TerminationA.Device},
+		{typ: "device", name: c.Data.Spec.TerminationB.Device},
+	}
+	if cur != nil {
+		cable := cur.model.(*models.Cable)
+		hosts = append(hosts,
+			host{typ: "device", name: terminationFromModel(cable.TerminationaType, cable.Terminationa).Device},
+			host{typ: "device", name: terminationFromModel(cable.TerminationbType, cable.Terminationb).Device},
+		)
+	}
+	return hosts
+}
+
+// lookup returns the cable that already connects both ends
+func (c *Cable) lookup(ctx context.Context) (*current, error) {
+	if c.a.Cable == nil || c.b.Cable == nil || *c.a.Cable != *c.b.Cable {
+		return nil, nil
+	}
+	return c.read(ctx, *c.a.Cable)
+}
+
+// sameEnds returns true if the cable connects the resolved ends, in any order
+func (c *Cable) sameEnds(cable *models.Cable) bool {
+	is := func(t *termination, typ *string, id *int64) bool {
+		return typ != nil && id != nil && *typ == t.Type && *id == t.ID
+	}
+	return (is(c.a, cable.TerminationaType, cable.TerminationaID) && is(c.b, cable.TerminationbType, cable.TerminationbID)) ||
+		(is(c.a, cable.TerminationbType, cable.TerminationbID) && is(c.b, cable.TerminationaType, cable.TerminationaID))
+}
+
+func (c *Cable) diff(cur *current) []string {
+	cable := cur.model.(*models.Cable)
+	spec := c.Data.Spec
+
+	changed := []string{}
+	if !c.sameEnds(cable) {
+		changed = append(changed, "terminations")
+	}
+	if spec.Type != "" && cable.Type != spec.Type {
+		changed = append(changed, "type")
+	}
+	if spec.Status != "" && (cable.Status == nil || cable.Status.Value == nil || *cable.Status.Value != spec.Status) {
+		changed = append(changed, "status")
+	}
+	if spec.Color != "" && cable.Color != spec.Color {
+		changed = append(changed, "color")
+	}
+	if spec.Label != "" && cable.Label != spec.Label {
+		changed = append(changed, "label")
+	}
+	if spec.Length != 0 && (cable.Length == nil || *cable.Length != float64(spec.Length)) {
+		changed = append(changed, "length")
+	}
+	if spec.LengthUnit != "" && (cable.LengthUnit == nil || cable.LengthUnit.Value == nil || *cable.LengthUnit.Value != spec.LengthUnit) {
+		changed = append(changed, "length_unit")
+	}
+	return changed
+}
+
+// connected returns a ConflictError if an end is connected to a cable other than ours, which is 0 for new cables
+func (c *Cable) connected(ours int64) error {
+	for _, t := range []*termination{c.a, c.b} {
+		if t.Cable != nil && *t.Cable != ours {
+			return &ConflictError{
+				Type:   "cable",
+				Name:   c.Data.Name,
+				Reason: fmt.Sprintf("%s is already connected to cable %d", t.Name, *t.Cable),
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Cable) writable(tags []*models.NestedTag) *models.WritableCable {
+	spec := c.Data.Spec
+	cable := &models.WritableCable{
+		TerminationaType: &c.a.Type,
+		TerminationaID:   &c.a.ID,
+		TerminationbType: &c.b.Type,
+		TerminationbID:   &c.b.ID,
+		Type:             spec.Type,
+		Status:           spec.Status,
+		Color:            spec.Color,
+		Label:            spec.Label,
+		LengthUnit:       spec.LengthUnit,
+		Tags:             tags,
+	}
+	if spec.Length != 0 {
+		length := float64(spec.Length)
+		cable.Length = &length
+	}
+	return cable
+}
+
+func (c *Cable) create(ctx context.Context, tags []*models.NestedTag) (int64, error) {
+	log := logr.FromContext(ctx)
+
+	if err := c.connected(0); err != nil {
+		return 0, err
+	}
+
+	cable, err := c.Client.Dcim.DcimCablesCreate(&dcim.DcimCablesCreateParams{
+		Data:    c.writable(tags),
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to DcimCablesCreate, %w", err)
+	}
+	log.V(1).Info("created cable", "response", cable)
+
+	return cable.GetPayload().ID, nil
+}
+
+// update changes the cable in place. Netbox does not allow changing the ends
+// of a cable, so a cable with new ends is deleted and created again
+func (c *Cable) update(ctx context.Context, cur *current, tags []*models.NestedTag) (int64, error) {
+	log := logr.FromContext(ctx)
+
+	if !c.sameEnds(cur.model.(*models.Cable)) {
+		if err := c.connected(cur.ID); err != nil {
+			return 0, err
+		}
+		if err := c.deleteObject(ctx, c.path(), cur.ID); err != nil {
+			return 0, err
+		}
+		log.V(1).Info("deleted cable to change its terminations", "id", cur.ID)
+		c.a.Cable, c.b.Cable = nil, nil
+		return c.create(ctx, tags)
+	}
+
+	cable, err := c.Client.Dcim.DcimCablesUpdate(&dcim.DcimCablesUpdateParams{
+		Data:    c.writable(tags),
+		ID:      cur.ID,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to DcimCablesUpdate, %w", err)
+	}
+	log.V(1).Info("updated cable", "response", cable)
+
+	return cable.GetPayload().ID, nil
+}
+
+func (c *Cable) each(ctx context.Context, opts ListOptions, fn func(netboxv1.Object) error) error {
+	log := logr.FromContext(ctx)
+
+	params := &dcim.DcimCablesListParams{
+		Context: ctx,
+	}
+	if c.Data.Name != "" {
+		params.Label = &c.Data.Name
+	}
+	if opts.Status != "" {
+		params.Status = &opts.Status
+	}
+	if opts.Query != "" {
+		params.Q = &opts.Query
+	}
+	for _, f := range []struct {
+		name  string
+		t     string
+		param **string
+	}{
+		{opts.Site, "site", &params.SiteID},
+		{opts.Tenant, "tenant", &params.TenantID},
+	} {
+		if f.name == "" {
+			continue
+		}
+		id, err := c.resolveNameToID(ctx, f.name, f.t)
+		if err != nil {
+			return err
+		}
+		value := strconv.FormatInt(id, 10)
+		*f.param = &value
+	}
+
+	return paginate(ctx, opts.ChunkSize, opts.Limit, func(offset, limit int64) (int, bool, error) {
+		params.Offset = &offset
+		params.Limit = &limit
+
+		cables, err := c.Client.Dcim.DcimCablesList(params, c.withQuery(opts.query()))
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to DcimCablesList, %w", err)
+		}
+		log.V(1).Info("found cables", "count", cables.Payload.Count, "offset", offset)
+
+		for _, cable := range cables.Payload.Results {
+			if err := fn(cableFromModel(cable)); err != nil {
+				return 0, false, err
+			}
+		}
+
+		return len(cables.Payload.Results), cables.Payload.Next != nil, nil
+	})
+}
+
+// cableFromModel maps a Netbox cable to a Cable, which is named after the label or the ID of the cable
+func cableFromModel(cable *models.Cable) *netboxv1.Cable {
+	name := cable.Label
+	if name == "" {
+		name = fmt.Sprintf("cable-%d", cable.ID)
+	}
+
+	spec := netboxv1.CableSpec{
+		TerminationA: terminationFromModel(cable.TerminationaType, cable.Terminationa),
+		TerminationB: terminationFromModel(cable.TerminationbType, cable.Terminationb),
+		Type:         cable.Type,
+		Color:        cable.Color,
+		Label:        cable.Label,
+		Tags:         tagSlugs(cable.Tags),
+	}
+	if len(spec.Tags) == 0 {
+		spec.Tags = nil
+	}
+	if cable.Status != nil && cable.Status.Value != nil {
+		spec.Status = *cable.Status.Value
+	}
+	if cable.Length != nil {
+		spec.Length = int64(*cable.Length)
+	}
+	if cable.LengthUnit != nil && cable.LengthUnit.Value != nil {
+		spec.LengthUnit = *cable.LengthUnit.Value
+	}
+
+	id := cable.ID
+	return &netboxv1.Cable{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       netboxv1.CableKind,
+			APIVersion: netboxv1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: spec,
+		Status: netboxv1.ObjectStatus{
+			ID:    &id,
+			State: netboxv1.ObjectReadyState,
+		},
+	}
+}
+
+// terminationFromModel maps the nested termination object of a Netbox cable
+func terminationFromModel(t *string, data interface{}) netboxv1.CableTermination {
+	result := netboxv1.CableTermination{}
+	object, _ := data.(map[string]interface{})
+	if t == nil || object == nil {
+		return result
+	}
+
+	name, _ := object["name"].(string)
+	if device, ok := object["device"].(map[string]interface{}); ok {
+		result.Device, _ = device["name"].(string)
+	}
+
+	switch *t {
+	case interfaceTermination:
+		result.Interface = name
+	case frontPortTermination:
+		result.FrontPort = name
+	case rearPortTermination:
+		result.RearPort = name
+	case circuitTermination:
+		if circuit, ok := object["circuit"].(map[string]interface{}); ok {
+			result.Circuit, _ = circuit["cid"].(string)
+		}
+		result.TermSide, _ = object["term_side"].(string)
+	}
+	return result
+}
@@ -0,0 +1,101 @@
+package netbox
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/go-logr/logr"
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeCableNetbox serves the interface swp1 of leaf-01 and spine-01, the cables connected to
+// them are given by device/interface name. Created cables get ID 10
+func fakeCableNetbox(t *testing.T, cables map[string]int64) *fakeNetbox {
+	routes := []route{}
+	for i, device := range []string{"leaf-01", "spine-01"} {
+		cable := "null"
+		if id, ok := cables[device+"/swp1"]; ok {
+			cable = fmt.Sprintf(`{"id": %d}`, id)
+		}
+		routes = append(routes, onGet("/api/dcim/interfaces/",
+			page(fmt.Sprintf(`{"id": %d, "name": "swp1", "cable": %s}`, i+1, cable)), "device", device, "name", "swp1"))
+	}
+	return newFakeNetbox(t, append(routes,
+		onGet("/api/dcim/interfaces/", page()),
+		onPost("/api/dcim/cables/", `{"id": 10}`),
+	)...)
+}
+
+func testCable(a, b string) *netboxv1.Cable {
+	return &netboxv1.Cable{
+		ObjectMeta: metav1.ObjectMeta{Name: "leaf-01-spine-01"},
+		Spec: netboxv1.CableSpec{
+			TerminationA: netboxv1.CableTermination{Device: a, Interface: "swp1"},
+			TerminationB: netboxv1.CableTermination{Device: b, Interface: "swp1"},
+			Type:         "cat6",
+		},
+	}
+}
+
+func TestCableApply(t *testing.T) {
+	ctx := logr.NewContext(context.Background(), logr.Discard())
+
+	tests := []struct {
+		name    string
+		cable   *netboxv1.Cable
+		cables  map[string]int64
+		check   func(error) bool
+		created bool
+	}{
+		{
+			name:  "missing endpoint",
+			cable: testCable("leaf-01", "spine-02"),
+			check: IsReferenceNotFound,
+		},
+		{
+			name:   "connected endpoint",
+			cable:  testCable("leaf-01", "spine-01"),
+			cables: map[string]int64{"spine-01/swp1": 7},
+			check:  IsConflict,
+		},
+		{
+			name:    "free endpoints",
+			cable:   testCable("leaf-01", "spine-01"),
+			check:   func(err error) bool { return err == nil },
+			created: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := fakeCableNetbox(t, tt.cables)
+
+			_, err := f.netbox().Apply(ctx, tt.cable)
+			if !tt.check(err) {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			created := f.written(http.MethodPost, "/api/dcim/cables/")
+			if !tt.created {
+				if len(created) > 0 {
+					t.Errorf("unexpected cable created")
+				}
+				return
+			}
+
+			if len(created) != 1 {
+				t.Fatalf("expected one cable created, got %v", created)
+			}
+			data := created[0]
+			if data["termination_a_type"] != interfaceTermination || data["termination_b_id"] != float64(2) {
+				t.Errorf("unexpected terminations %v", data)
+			}
+			if tt.cable.Status.ID == nil || *tt.cable.Status.ID != 10 || tt.cable.Status.State != netboxv1.ObjectReadyState {
+				t.Errorf("unexpected status %+v", tt.cable.Status)
+			}
+		})
+	}
+}
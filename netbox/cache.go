@@ -0,0 +1,67 @@
+package netbox
+
+import (
+	"sync"
+	"time"
+)
+
+const defaultCacheTTL = time.Minute * 5
+
+// resolverCache remembers the IDs of objects referenced by name, e.g. sites and roles
+type resolverCache struct {
+	sync.Mutex
+	ttl     time.Duration
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	id      int64
+	expires time.Time
+}
+
+func newResolverCache(ttl time.Duration) *resolverCache {
+	return &resolverCache{
+		ttl:     ttl,
+		entries: make(map[string]cacheEntry),
+	}
+}
+
+func cacheKey(t, name string) string {
+	return t + "/" + name
+}
+
+func (c *resolverCache) get(t, name string) (int64, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	e, ok := c.entries[cacheKey(t, name)]
+	if ok && time.Now().Before(e.expires) {
+		resolverCacheRequests.WithLabelValues(t, "hit").Inc()
+		return e.id, true
+	}
+
+	resolverCacheRequests.WithLabelValues(t, "miss").Inc()
+	return 0, false
+}
+
+func (c *resolverCache) set(t, name string, id int64) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	c.entries[cacheKey(t, name)] = cacheEntry{
+		id:      id,
+		expires: time.Now().Add(c.ttl),
+	}
+}
+
+// forget drops the ID of an object, e.g. after it was deleted
+func (c *resolverCache) forget(t, name string) {
+	c.Lock()
+	defer c.Unlock()
+
+	delete(c.entries, cacheKey(t, name))
+}
@@ -0,0 +1,258 @@
+package netbox
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	"github.com/netbox-community/go-netbox/netbox/client/virtualization"
+	"github.com/netbox-community/go-netbox/netbox/models"
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type Cluster struct {
+	Data *netboxv1.Cluster
+	NetboxServer
+
+	typeID   int64
+	siteID   *int64
+	tenantID *int64
+}
+
+func NewCluster(s NetboxServer, c *netboxv1.Cluster) *Cluster {
+	return &Cluster{
+		Data:         c,
+		NetboxServer: s,
+	}
+}
+
+func (c *Cluster) resource() netboxv1.Object {
+	return c.Data
+}
+
+func (c *Cluster) typeName() string {
+	return "cluster"
+}
+
+func (c *Cluster) path() string {
+	return "/virtualization/clusters/"
+}
+
+func (c *Cluster) tags() []string {
+	return c.Data.Spec.Tags
+}
+
+// resolve looks up the cluster type, the site and the tenant
+func (c *Cluster) resolve(ctx context.Context) error {
+	spec := c.Data.Spec
+
+	typeID, err := c.lookupNameToID(ctx, spec.Type, "cluster type")
+	if err != nil {
+		return err
+	}
+	c.typeID = typeID
+
+	if spec.Site != "" {
+		id, err := c.resolveNameToID(ctx, spec.Site, "site")
+		if err != nil {
+			return err
+		}
+		c.siteID = &id
+	}
+
+	if spec.Tenant != "" {
+		id, err := c.resolveNameToID(ctx, spec.Tenant, "tenant")
+		if err != nil {
+			return err
+		}
+		c.tenantID = &id
+	}
+	return nil
+}
+
+func (c *Cluster) read(ctx context.Context, id int64) (*current, error) {
+	cluster, err := c.Client.Virtualization.VirtualizationClustersRead(&virtualization.VirtualizationClustersReadParams{
+		ID:      id,
+		Context: ctx,
+	}, nil)
+	if IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to VirtualizationClustersRead, %w", err)
+	}
+
+	return clusterCurrent(cluster.GetPayload()), nil
+}
+
+func clusterCurrent(cluster *models.Cluster) *current {
+	return &current{ID: cluster.ID, Tags: cluster.Tags, model: cluster}
+}
+
+// lookup returns the cluster with the same name, cluster names are unique in Netbox
+func (c *Cluster) lookup(ctx context.Context) (*current, error) {
+	name := c.Data.ClusterName()
+	result, err := c.Client.Virtualization.VirtualizationClustersList(&virtualization.VirtualizationClustersListParams{
+		Name:    &name,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to VirtualizationClustersList, %w", err)
+	}
+	if *result.Payload.Count == 0 {
+		return nil, nil
+	}
+	return clusterCurrent(result.Payload.Results[0]), nil
+}
+
+func (c *Cluster) diff(cur *current) []string {
+	cluster := cur.model.(*models.Cluster)
+
+	changed := []string{}
+	if cluster.Name == nil || *cluster.Name != c.Data.ClusterName() {
+		changed = append(changed, "name")
+	}
+	if cluster.Type == nil || cluster.Type.ID != c.typeID {
+		changed = append(changed, "type")
+	}
+	if c.siteID != nil && (cluster.Site == nil || cluster.Site.ID != *c.siteID) {
+		changed = append(changed, "site")
+	}
+	if c.tenantID != nil && (cluster.Tenant == nil || cluster.Tenant.ID != *c.tenantID) {
+		changed = append(changed, "tenant")
+	}
+	return changed
+}
+
+func (c *Cluster) writable(tags []*models.NestedTag) *models.WritableCluster {
+	name := c.Data.ClusterName()
+	return &models.WritableCluster{
+		Name:   &name,
+		Type:   &c.typeID,
+		Site:   c.siteID,
+		Tenant: c.tenantID,
+		Tags:   tags,
+	}
+}
+
+func (c *Cluster) create(ctx context.Context, tags []*models.NestedTag) (int64, error) {
+	log := logr.FromContext(ctx)
+
+	cluster, err := c.Client.Virtualization.VirtualizationClustersCreate(&virtualization.VirtualizationClustersCreateParams{
+		Data:    c.writable(tags),
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to VirtualizationClustersCreate, %w", err)
+	}
+	log.V(1).Info("created cluster", "response", cluster)
+
+	return cluster.GetPayload().ID, nil
+}
+
+func (c *Cluster) update(ctx context.Context, cur *current, tags []*models.NestedTag) (int64, error) {
+	log := logr.FromContext(ctx)
+
+	cluster, err := c.Client.Virtualization.VirtualizationClustersUpdate(&virtualization.VirtualizationClustersUpdateParams{
+		Data:    c.writable(tags),
+		ID:      cur.ID,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to VirtualizationClustersUpdate, %w", err)
+	}
+	log.V(1).Info("updated cluster", "response", cluster)
+
+	return cluster.GetPayload().ID, nil
+}
+
+func (c *Cluster) each(ctx context.Context, opts ListOptions, fn func(netboxv1.Object) error) error {
+	log := logr.FromContext(ctx)
+
+	params := &virtualization.VirtualizationClustersListParams{
+		Context: ctx,
+	}
+	if name := c.Data.ClusterName(); name != "" {
+		params.Name = &name
+	}
+	if opts.Query != "" {
+		params.Q = &opts.Query
+	}
+	for _, f := range []struct {
+		name  string
+		t     string
+		param **string
+	}{
+		{opts.Site, "site", &params.SiteID},
+		{opts.Tenant, "tenant", &params.TenantID},
+	} {
+		if f.name == "" {
+			continue
+		}
+		id, err := c.resolveNameToID(ctx, f.name, f.t)
+		if err != nil {
+			return err
+		}
+		value := strconv.FormatInt(id, 10)
+		*f.param = &value
+	}
+
+	return paginate(ctx, opts.ChunkSize, opts.Limit, func(offset, limit int64) (int, bool, error) {
+		params.Offset = &offset
+		params.Limit = &limit
+
+		clusters, err := c.Client.Virtualization.VirtualizationClustersList(params, c.withQuery(opts.query()))
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to VirtualizationClustersList, %w", err)
+		}
+		log.V(1).Info("found clusters", "count", clusters.Payload.Count, "offset", offset)
+
+		for _, cluster := range clusters.Payload.Results {
+			if err := fn(clusterFromModel(cluster)); err != nil {
+				return 0, false, err
+			}
+		}
+
+		return len(clusters.Payload.Results), clusters.Payload.Next != nil, nil
+	})
+}
+
+// clusterFromModel maps a Netbox cluster to a Cluster named after the cluster
+func clusterFromModel(cluster *models.Cluster) *netboxv1.Cluster {
+	spec := netboxv1.ClusterSpec{
+		Tags: tagSlugs(cluster.Tags),
+	}
+	if len(spec.Tags) == 0 {
+		spec.Tags = nil
+	}
+	if cluster.Name != nil {
+		spec.Name = *cluster.Name
+	}
+	if cluster.Type != nil && cluster.Type.Name != nil {
+		spec.Type = *cluster.Type.Name
+	}
+	if cluster.Site != nil && cluster.Site.Name != nil {
+		spec.Site = *cluster.Site.Name
+	}
+	if cluster.Tenant != nil && cluster.Tenant.Name != nil {
+		spec.Tenant = *cluster.Tenant.Name
+	}
+
+	id := cluster.ID
+	return &netboxv1.Cluster{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       netboxv1.ClusterKind,
+			APIVersion: netboxv1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: netboxv1.ResourceName(spec.Name),
+		},
+		Spec: spec,
+		Status: netboxv1.ObjectStatus{
+			ID:    &id,
+			State: netboxv1.ObjectReadyState,
+		},
+	}
+}
@@ -0,0 +1,211 @@
+package netbox
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/netbox-community/go-netbox/netbox/client/virtualization"
+	"github.com/netbox-community/go-netbox/netbox/models"
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type ClusterType struct {
+	Data *netboxv1.ClusterType
+	NetboxServer
+}
+
+func NewClusterType(s NetboxServer, t *netboxv1.ClusterType) *ClusterType {
+	return &ClusterType{
+		Data:         t,
+		NetboxServer: s,
+	}
+}
+
+func (t *ClusterType) resource() netboxv1.Object {
+	return t.Data
+}
+
+func (t *ClusterType) typeName() string {
+	return "cluster type"
+}
+
+func (t *ClusterType) path() string {
+	return "/virtualization/cluster-types/"
+}
+
+func (t *ClusterType) tags() []string {
+	return nil
+}
+
+// untaggable implements untaggable, Netbox 3.0 cluster types have no tags
+func (t *ClusterType) untaggable() {}
+
+func (t *ClusterType) resolve(ctx context.Context) error {
+	return nil
+}
+
+func (t *ClusterType) read(ctx context.Context, id int64) (*current, error) {
+	clusterType, err := t.Client.Virtualization.VirtualizationClusterTypesRead(&virtualization.VirtualizationClusterTypesReadParams{
+		ID:      id,
+		Context: ctx,
+	}, nil)
+	if IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to VirtualizationClusterTypesRead, %w", err)
+	}
+
+	return clusterTypeCurrent(clusterType.GetPayload()), nil
+}
+
+func clusterTypeCurrent(clusterType *models.ClusterType) *current {
+	return &current{ID: clusterType.ID, Created: hasCreatedField(clusterType.CustomFields), model: clusterType}
+}
+
+// lookup returns the cluster type with the same name, cluster type names are unique in Netbox
+func (t *ClusterType) lookup(ctx context.Context) (*current, error) {
+	name := t.Data.ClusterTypeName()
+	result, err := t.Client.Virtualization.VirtualizationClusterTypesList(&virtualization.VirtualizationClusterTypesListParams{
+		Name:    &name,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to VirtualizationClusterTypesList, %w", err)
+	}
+	if *result.Payload.Count == 0 {
+		return nil, nil
+	}
+	return clusterTypeCurrent(result.Payload.Results[0]), nil
+}
+
+func (t *ClusterType) slug() string {
+	if t.Data.Spec.Slug != "" {
+		return t.Data.Spec.Slug
+	}
+	return slugify(t.Data.ClusterTypeName())
+}
+
+func (t *ClusterType) diff(cur *current) []string {
+	clusterType := cur.model.(*models.ClusterType)
+	spec := t.Data.Spec
+
+	changed := []string{}
+	if clusterType.Name == nil || *clusterType.Name != t.Data.ClusterTypeName() {
+		changed = append(changed, "name")
+	}
+	if clusterType.Slug == nil || *clusterType.Slug != t.slug() {
+		changed = append(changed, "slug")
+	}
+	if spec.Description != "" && clusterType.Description != spec.Description {
+		changed = append(changed, "description")
+	}
+	return changed
+}
+
+// writable maps the spec to the cluster type, which has no separate writable model
+func (t *ClusterType) writable() *models.ClusterType {
+	name, slug := t.Data.ClusterTypeName(), t.slug()
+	return &models.ClusterType{
+		Name:        &name,
+		Slug:        &slug,
+		Description: t.Data.Spec.Description,
+	}
+}
+
+func (t *ClusterType) create(ctx context.Context, _ []*models.NestedTag) (int64, error) {
+	log := logr.FromContext(ctx)
+
+	data := t.writable()
+	data.CustomFields = createdCustomFields()
+	clusterType, err := t.Client.Virtualization.VirtualizationClusterTypesCreate(&virtualization.VirtualizationClusterTypesCreateParams{
+		Data:    data,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to VirtualizationClusterTypesCreate, %w", err)
+	}
+	log.V(1).Info("created cluster type", "response", clusterType)
+
+	return clusterType.GetPayload().ID, nil
+}
+
+func (t *ClusterType) update(ctx context.Context, cur *current, _ []*models.NestedTag) (int64, error) {
+	log := logr.FromContext(ctx)
+
+	clusterType, err := t.Client.Virtualization.VirtualizationClusterTypesUpdate(&virtualization.VirtualizationClusterTypesUpdateParams{
+		Data:    t.writable(),
+		ID:      cur.ID,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to VirtualizationClusterTypesUpdate, %w", err)
+	}
+	log.V(1).Info("updated cluster type", "response", clusterType)
+
+	return clusterType.GetPayload().ID, nil
+}
+
+func (t *ClusterType) each(ctx context.Context, opts ListOptions, fn func(netboxv1.Object) error) error {
+	log := logr.FromContext(ctx)
+
+	params := &virtualization.VirtualizationClusterTypesListParams{
+		Context: ctx,
+	}
+	if name := t.Data.ClusterTypeName(); name != "" {
+		params.Name = &name
+	}
+	if opts.Query != "" {
+		params.Q = &opts.Query
+	}
+
+	return paginate(ctx, opts.ChunkSize, opts.Limit, func(offset, limit int64) (int, bool, error) {
+		params.Offset = &offset
+		params.Limit = &limit
+
+		clusterTypes, err := t.Client.Virtualization.VirtualizationClusterTypesList(params, t.withQuery(opts.query()))
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to VirtualizationClusterTypesList, %w", err)
+		}
+		log.V(1).Info("found cluster types", "count", clusterTypes.Payload.Count, "offset", offset)
+
+		for _, clusterType := range clusterTypes.Payload.Results {
+			if err := fn(clusterTypeFromModel(clusterType)); err != nil {
+				return 0, false, err
+			}
+		}
+
+		return len(clusterTypes.Payload.Results), clusterTypes.Payload.Next != nil, nil
+	})
+}
+
+// clusterTypeFromModel maps a Netbox cluster type to a ClusterType named after the slug of the type
+func clusterTypeFromModel(clusterType *models.ClusterType) *netboxv1.ClusterType {
+	spec := netboxv1.ClusterTypeSpec{
+		Description: clusterType.Description,
+	}
+	if clusterType.Name != nil {
+		spec.Name = *clusterType.Name
+	}
+	if clusterType.Slug != nil {
+		spec.Slug = *clusterType.Slug
+	}
+
+	id := clusterType.ID
+	return &netboxv1.ClusterType{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       netboxv1.ClusterTypeKind,
+			APIVersion: netboxv1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: netboxv1.ResourceName(spec.Slug),
+		},
+		Spec: spec,
+		Status: netboxv1.ObjectStatus{
+			ID:    &id,
+			State: netboxv1.ObjectReadyState,
+		},
+	}
+}
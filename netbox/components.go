@@ -0,0 +1,255 @@
+package netbox
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/netbox-community/go-netbox/netbox/client/dcim"
+	"github.com/netbox-community/go-netbox/netbox/models"
+)
+
+// syncComponents adds the components of the device type templates that are missing on the
+// device, if the spec asks for it. Netbox copies the templates to new devices only, so existing
+// devices miss the templates added later. Components are matched by name and never updated
+func (d *Device) syncComponents(ctx context.Context, result *Result) (*Result, error) {
+	if !d.Data.Spec.SyncComponents || d.Data.Status.ID == nil {
+		return result, nil
+	}
+	deviceID := *d.Data.Status.ID
+
+	typeID, err := d.resolveNameToID(ctx, d.Data.Spec.DeviceType, "type")
+	if err != nil {
+		return nil, err
+	}
+
+	added := []string{}
+	for _, kind := range []componentKind{
+		d.interfaceKind(),
+		d.consolePortKind(),
+		d.powerPortKind(),
+	} {
+		ok, err := d.addComponents(ctx, deviceID, typeID, kind)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			added = append(added, kind.field)
+		}
+	}
+
+	if len(added) == 0 {
+		return result, nil
+	}
+	if result.Operation == OperationUnchanged {
+		result.Operation = OperationUpdated
+	}
+	result.Changed = append(result.Changed, added...)
+	return result, nil
+}
+
+// componentKind holds what differs between the component kinds of a device
+type componentKind struct {
+	// name is used in logs, field in the changed fields of the result
+	name, field string
+	// templates lists the templates of the device type with the ID
+	templates func(ctx context.Context, typeID int64) ([]component, error)
+	// components lists the components of the device with the ID
+	components func(ctx context.Context, deviceID int64) ([]component, error)
+	// create adds the component of a template to the device with the ID, it returns nil
+	// for templates that can't be copied
+	create func(ctx context.Context, deviceID int64, template interface{}) (interface{}, error)
+}
+
+// addComponents creates the components of the templates that the device doesn't have
+func (d *Device) addComponents(ctx context.Context, deviceID, typeID int64, kind componentKind) (bool, error) {
+	log := logr.FromContext(ctx)
+
+	templates, err := kind.templates(ctx, typeID)
+	if err != nil || len(templates) == 0 {
+		return false, err
+	}
+
+	components, err := kind.components(ctx, deviceID)
+	if err != nil {
+		return false, err
+	}
+	existing := map[string]bool{}
+	for _, c := range components {
+		existing[c.Name] = true
+	}
+
+	added := false
+	for _, template := range templates {
+		if existing[template.Name] {
+			continue
+		}
+		response, err := kind.create(ctx, deviceID, template.model)
+		if err != nil {
+			return false, err
+		}
+		if response == nil {
+			continue
+		}
+		log.V(1).Info("added "+kind.name+" from template", "response", response)
+		added = true
+	}
+	return added, nil
+}
+
+func (d *Device) interfaceKind() componentKind {
+	return componentKind{
+		name:      "interface",
+		field:     "interfaces",
+		templates: d.interfaceTemplates,
+		components: func(ctx context.Context, deviceID int64) ([]component, error) {
+			var components []component
+			params := &dcim.DcimInterfacesListParams{
+				DeviceID: int64String(deviceID),
+				Context:  ctx,
+			}
+			err := paginate(ctx, 0, 0, func(offset, limit int64) (int, bool, error) {
+				params.Offset = &offset
+				params.Limit = &limit
+				result, err := d.Client.Dcim.DcimInterfacesList(params, nil)
+				if err != nil {
+					return 0, false, fmt.Errorf("failed to DcimInterfacesList, %w", err)
+				}
+				for _, intf := range result.Payload.Results {
+					if intf.Name != nil {
+						components = append(components, component{ID: intf.ID, Name: *intf.Name, model: intf})
+					}
+				}
+				return len(result.Payload.Results), result.Payload.Next != nil, nil
+			})
+			return components, err
+		},
+		create: func(ctx context.Context, deviceID int64, model interface{}) (interface{}, error) {
+			template := model.(*models.InterfaceTemplate)
+			if template.Type == nil || template.Type.Value == nil {
+				return nil, nil
+			}
+			intf, err := d.Client.Dcim.DcimInterfacesCreate(&dcim.DcimInterfacesCreateParams{
+				Data: &models.WritableInterface{
+					Device:      &deviceID,
+					Name:        template.Name,
+					Type:        template.Type.Value,
+					MgmtOnly:    template.MgmtOnly,
+					Label:       template.Label,
+					Description: template.Description,
+					TaggedVlans: []int64{},
+					Tags:        []*models.NestedTag{},
+				},
+				Context: ctx,
+			}, nil)
+			if err != nil {
+				return nil, fmt.Errorf("failed to DcimInterfacesCreate %q, %w", *template.Name, err)
+			}
+			return intf, nil
+		},
+	}
+}
+
+func (d *Device) consolePortKind() componentKind {
+	return componentKind{
+		name:      "console port",
+		field:     "console_ports",
+		templates: d.consolePortTemplates,
+		components: func(ctx context.Context, deviceID int64) ([]component, error) {
+			var components []component
+			params := &dcim.DcimConsolePortsListParams{
+				DeviceID: int64String(deviceID),
+				Context:  ctx,
+			}
+			err := paginate(ctx, 0, 0, func(offset, limit int64) (int, bool, error) {
+				params.Offset = &offset
+				params.Limit = &limit
+				result, err := d.Client.Dcim.DcimConsolePortsList(params, nil)
+				if err != nil {
+					return 0, false, fmt.Errorf("failed to DcimConsolePortsList, %w", err)
+				}
+				for _, port := range result.Payload.Results {
+					if port.Name != nil {
+						components = append(components, component{ID: port.ID, Name: *port.Name, model: port})
+					}
+				}
+				return len(result.Payload.Results), result.Payload.Next != nil, nil
+			})
+			return components, err
+		},
+		create: func(ctx context.Context, deviceID int64, model interface{}) (interface{}, error) {
+			template := model.(*models.ConsolePortTemplate)
+			port := &models.WritableConsolePort{
+				Device:      &deviceID,
+				Name:        template.Name,
+				Label:       template.Label,
+				Description: template.Description,
+				Tags:        []*models.NestedTag{},
+			}
+			if template.Type != nil && template.Type.Value != nil {
+				port.Type = *template.Type.Value
+			}
+			response, err := d.Client.Dcim.DcimConsolePortsCreate(&dcim.DcimConsolePortsCreateParams{
+				Data:    port,
+				Context: ctx,
+			}, nil)
+			if err != nil {
+				return nil, fmt.Errorf("failed to DcimConsolePortsCreate %q, %w", *template.Name, err)
+			}
+			return response, nil
+		},
+	}
+}
+
+func (d *Device) powerPortKind() componentKind {
+	return componentKind{
+		name:      "power port",
+		field:     "power_ports",
+		templates: d.powerPortTemplates,
+		components: func(ctx context.Context, deviceID int64) ([]component, error) {
+			var components []component
+			params := &dcim.DcimPowerPortsListParams{
+				DeviceID: int64String(deviceID),
+				Context:  ctx,
+			}
+			err := paginate(ctx, 0, 0, func(offset, limit int64) (int, bool, error) {
+				params.Offset = &offset
+				params.Limit = &limit
+				result, err := d.Client.Dcim.DcimPowerPortsList(params, nil)
+				if err != nil {
+					return 0, false, fmt.Errorf("failed to DcimPowerPortsList, %w", err)
+				}
+				for _, port := range result.Payload.Results {
+					if port.Name != nil {
+						components = append(components, component{ID: port.ID, Name: *port.Name, model: port})
+					}
+				}
+				return len(result.Payload.Results), result.Payload.Next != nil, nil
+			})
+			return components, err
+		},
+		create: func(ctx context.Context, deviceID int64, model interface{}) (interface{}, error) {
+			template := model.(*models.PowerPortTemplate)
+			port := &models.WritablePowerPort{
+				Device:        &deviceID,
+				Name:          template.Name,
+				MaximumDraw:   template.MaximumDraw,
+				AllocatedDraw: template.AllocatedDraw,
+				Label:         template.Label,
+				Description:   template.Description,
+				Tags:          []*models.NestedTag{},
+			}
+			if template.Type != nil && template.Type.Value != nil {
+				port.Type = *template.Type.Value
+			}
+			response, err := d.Client.Dcim.DcimPowerPortsCreate(&dcim.DcimPowerPortsCreateParams{
+				Data:    port,
+				Context: ctx,
+			}, nil)
+			if err != nil {
+				return nil, fmt.Errorf("failed to DcimPowerPortsCreate %q, %w", *template.Name, err)
+			}
+			return response, nil
+		},
+	}
+}
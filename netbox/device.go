@@ -3,6 +3,7 @@ package netbox
 import (
 	"context"
 	"fmt"
+	"strconv"
 
 	"github.com/go-logr/logr"
 	"github.com/netbox-community/go-netbox/netbox/client/dcim"
@@ -20,6 +21,14 @@ type ids struct {
 	Role int64
 	Type int64
 	Site int64
+	// Tenant is 0 if the device doesn't set one
+	Tenant int64
+	// Platform is 0 if the device doesn't set one
+	Platform int64
+	// Rack is 0 if the device doesn't set one
+	Rack int64
+	// Tags are nil if the device doesn't set them
+	Tags []*models.NestedTag
 }
 
 func NewDevice(s NetboxServer, d *netboxv1.Device) *Device {
@@ -30,56 +39,120 @@ func NewDevice(s NetboxServer, d *netboxv1.Device) *Device {
 }
 
 // Get retrieves Devices from Netbox
-func (d *Device) Get(ctx context.Context) ([]netboxv1.Device, error) {
-	log := logr.FromContext(ctx)
+func (d *Device) Get(ctx context.Context, opts ListOptions) ([]netboxv1.Device, error) {
 	results := []netboxv1.Device{}
 
-	devices, err := d.Client.Dcim.DcimDevicesList(&dcim.DcimDevicesListParams{
-		Name:    &d.Data.Name,
-		Context: ctx,
-	}, nil)
+	err := d.Each(ctx, opts, func(device netboxv1.Device) error {
+		results = append(results, device)
+		return nil
+	})
+
+	return results, err
+}
+
+// Each retrieves Devices from Netbox page by page and calls fn for each one of them
+func (d *Device) Each(ctx context.Context, opts ListOptions, fn func(netboxv1.Device) error) error {
+	log := logr.FromContext(ctx)
+
+	params, err := d.listParams(ctx, opts)
 	if err != nil {
-		return results, fmt.Errorf("failed to DcimDevicesList, %+v", err)
-	}
-	log.V(1).Info("found devices", "count", devices.Payload.Count)
-
-	for _, d := range devices.Payload.Results {
-		results = append(results, netboxv1.Device{
-			TypeMeta: metav1.TypeMeta{
-				Kind:       netboxv1.DeviceKind,
-				APIVersion: netboxv1.GroupVersion.String(),
-			},
-
-			ObjectMeta: metav1.ObjectMeta{
-				Name: *d.Name,
-			},
-			Spec: netboxv1.DeviceSpec{
-				Site:       *d.Site.Name,
-				Role:       *d.DeviceRole.Name,
-				DeviceType: *d.DeviceType.Model,
-			},
-			Status: netboxv1.DeviceStatus{
-				ID:    &d.ID,
-				State: netboxv1.DeviceReadyState,
-			},
-		})
+		return err
 	}
 
-	return results, nil
+	return paginate(ctx, opts.ChunkSize, opts.Limit, func(offset, limit int64) (int, bool, error) {
+		params.Offset = &offset
+		params.Limit = &limit
+
+		devices, err := d.Client.Dcim.DcimDevicesList(params, d.withQuery(opts.query()))
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to DcimDevicesList, %w", err)
+		}
+		log.V(1).Info("found devices", "count", devices.Payload.Count, "offset", offset)
+
+		for _, nbDev := range devices.Payload.Results {
+			if err := fn(deviceFromModel(nbDev)); err != nil {
+				return 0, false, err
+			}
+		}
+
+		return len(devices.Payload.Results), devices.Payload.Next != nil, nil
+	})
+}
+
+func deviceFromModel(d *models.DeviceWithConfigContext) netboxv1.Device {
+	var status, tenant, platform string
+	if d.Status != nil && d.Status.Value != nil {
+		status = *d.Status.Value
+	}
+	if d.Tenant != nil && d.Tenant.Name != nil {
+		tenant = *d.Tenant.Name
+	}
+	if d.Platform != nil && d.Platform.Name != nil {
+		platform = *d.Platform.Name
+	}
+	var tags []string
+	if slugs := tagSlugs(d.Tags); len(slugs) > 0 {
+		tags = slugs
+	}
+	var rack, face string
+	var position int64
+	if d.Rack != nil && d.Rack.Name != nil {
+		rack = *d.Rack.Name
+	}
+	if d.Position != nil {
+		position = *d.Position
+	}
+	if d.Face != nil && d.Face.Value != nil && position != 0 {
+		face = *d.Face.Value
+	}
+
+	return netboxv1.Device{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       netboxv1.DeviceKind,
+			APIVersion: netboxv1.GroupVersion.String(),
+		},
+
+		ObjectMeta: metav1.ObjectMeta{
+			Name: *d.Name,
+		},
+		Spec: netboxv1.DeviceSpec{
+			Site:       *d.Site.Name,
+			Role:       *d.DeviceRole.Name,
+			DeviceType: *d.DeviceType.Model,
+			Platform:   platform,
+			Status:     status,
+			Tenant:     tenant,
+			Rack:       rack,
+			Position:   position,
+			Face:       face,
+			Tags:       tags,
+		},
+		Status: netboxv1.DeviceStatus{
+			ID:    &d.ID,
+			State: netboxv1.DeviceReadyState,
+		},
+	}
 }
 
 // Apply creates or updates a device in Netbox
-func (d *Device) Apply(ctx context.Context) error {
+func (d *Device) Apply(ctx context.Context) (*Result, error) {
 	dev, found, err := d.exists(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if found {
-		return d.update(ctx, dev)
+		result, err := d.update(ctx, dev)
+		if err != nil {
+			return nil, err
+		}
+		return d.syncComponents(ctx, result)
 	}
 
-	return d.create(ctx)
+	if err := d.create(ctx); err != nil {
+		return nil, err
+	}
+	return &Result{Operation: OperationCreated}, nil
 }
 
 // Delete removes the device from Netbox
@@ -101,7 +174,7 @@ func (d *Device) Delete(ctx context.Context) error {
 		Context: ctx,
 	}, nil)
 	if err != nil {
-		return fmt.Errorf("failed to DcimDevicesDelete: %+v", err)
+		return fmt.Errorf("failed to DcimDevicesDelete: %w", err)
 	}
 
 	log.V(1).Info("deleted device", "name", d.Data.Name, "response", response)
@@ -109,6 +182,73 @@ func (d *Device) Delete(ctx context.Context) error {
 	return nil
 }
 
+// Orphan stops managing the device in Netbox without deleting it, by removing the managed tag
+func (d *Device) Orphan(ctx context.Context) error {
+	log := logr.FromContext(ctx)
+
+	nbDev, found, err := d.exists(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !found || !hasManagedTag(nbDev.Tags) {
+		return nil
+	}
+
+	updateParams := &dcim.DcimDevicesUpdateParams{
+		Data: &models.WritableDeviceWithConfigContext{
+			Name:       nbDev.Name,
+			DeviceRole: &nbDev.DeviceRole.ID,
+			DeviceType: &nbDev.DeviceType.ID,
+			Site:       &nbDev.Site.ID,
+			Tags:       withoutManagedTag(nbDev.Tags),
+		},
+		ID:      nbDev.ID,
+		Context: ctx,
+	}
+
+	response, err := d.Client.Dcim.DcimDevicesUpdate(updateParams, nil)
+	if err != nil {
+		return fmt.Errorf("failed to DcimDevicesUpdate: %w", err)
+	}
+
+	log.V(1).Info("orphaned device", "name", d.Data.Name, "response", response)
+
+	return nil
+}
+
+// Validate checks that the referenced objects exist, that the device fits into its rack and that it
+// does not take over an existing device that is not managed
+func (d *Device) Validate(ctx context.Context) error {
+	IDs, err := d.resolveIDs(ctx)
+	if err != nil {
+		return err
+	}
+
+	nbDev, found, err := d.exists(ctx)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return d.checkPlacement(ctx, IDs, nil)
+	}
+	if err := d.checkPlacement(ctx, IDs, nbDev); err != nil {
+		return err
+	}
+
+	// devices with the ID annotation adopt the Netbox device on purpose
+	if _, adopted := d.Data.Annotations[netboxv1.IDAnnotation]; adopted {
+		return nil
+	}
+	if d.Data.Status.ID != nil && *d.Data.Status.ID == nbDev.ID {
+		return nil
+	}
+	if !hasManagedTag(nbDev.Tags) {
+		return &UnmanagedConflictError{Type: "device", Name: d.Data.Name, ID: nbDev.ID}
+	}
+	return nil
+}
+
 func (d *Device) Print(ctx context.Context) error {
 	log := logr.FromContext(ctx)
 
@@ -131,14 +271,29 @@ func (d *Device) create(ctx context.Context) error {
 		return err
 	}
 
+	if err := d.checkPlacement(ctx, IDs, nil); err != nil {
+		return err
+	}
+
+	managed, err := d.managedTag(ctx)
+	if err != nil {
+		return err
+	}
+
+	data := &models.WritableDeviceWithConfigContext{
+		Name:       &d.Data.Name,
+		DeviceRole: &IDs.Role,
+		DeviceType: &IDs.Type,
+		Site:       &IDs.Site,
+		Status:     d.Data.Spec.Status,
+		Tenant:     IDs.tenant(),
+		Platform:   IDs.platform(),
+		Tags:       withManagedTag(IDs.Tags, managed),
+	}
+	d.setPlacement(data, IDs, nil)
+
 	createParams := &dcim.DcimDevicesCreateParams{
-		Data: &models.WritableDeviceWithConfigContext{
-			Name:       &d.Data.Name,
-			DeviceRole: &IDs.Role,
-			DeviceType: &IDs.Type,
-			Site:       &IDs.Site,
-			Tags:       []*models.NestedTag{},
-		},
+		Data:    data,
 		Context: ctx,
 	}
 
@@ -161,44 +316,86 @@ func (d *Device) setStatus(response *models.DeviceWithConfigContext) error {
 	return nil
 }
 
-func (d *Device) update(ctx context.Context, nbDev *models.DeviceWithConfigContext) error {
+func (d *Device) update(ctx context.Context, nbDev *models.DeviceWithConfigContext) (*Result, error) {
 	log := logr.FromContext(ctx)
 
 	IDs, err := d.resolveIDs(ctx)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	changed := IDs.diff(nbDev)
+	if status := d.Data.Spec.Status; status != "" && (nbDev.Status == nil || nbDev.Status.Value == nil || *nbDev.Status.Value != status) {
+		changed = append(changed, "status")
+	}
+	placement := d.placementDiff(nbDev, IDs)
+	changed = append(changed, placement...)
+	if !hasManagedTag(nbDev.Tags) || (IDs.Tags != nil && !sameSlugs(tagSlugs(IDs.Tags), tagSlugs(nbDev.Tags))) {
+		changed = append(changed, "tags")
+	}
+	if len(changed) == 0 {
+		log.V(1).Info("device is up to date", "name", d.Data.Name)
+		return &Result{Operation: OperationUnchanged}, d.setStatus(nbDev)
 	}
 
+	// a new device type may change the height of a mounted device
+	typeChanged := nbDev.DeviceType == nil || nbDev.DeviceType.ID != IDs.Type
+	if len(placement) > 0 || typeChanged {
+		if err := d.checkPlacement(ctx, IDs, nbDev); err != nil {
+			return nil, err
+		}
+	}
+
+	managed, err := d.managedTag(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := nbDev.Tags
+	if IDs.Tags != nil {
+		tags = IDs.Tags
+	}
+
+	data := &models.WritableDeviceWithConfigContext{
+		Name:       &d.Data.Name,
+		DeviceRole: &IDs.Role,
+		DeviceType: &IDs.Type,
+		Site:       &IDs.Site,
+		Status:     d.Data.Spec.Status,
+		Tenant:     IDs.tenant(),
+		Platform:   IDs.platform(),
+		Tags:       withManagedTag(tags, managed),
+	}
+	d.setPlacement(data, IDs, nbDev)
+
 	updateParams := &dcim.DcimDevicesUpdateParams{
-		Data: &models.WritableDeviceWithConfigContext{
-			Name:       &d.Data.Name,
-			DeviceRole: &IDs.Role,
-			DeviceType: &IDs.Type,
-			Site:       &IDs.Site,
-			Tags:       []*models.NestedTag{},
-		},
+		Data:    data,
 		ID:      nbDev.ID,
 		Context: ctx,
 	}
 
 	netboxDevice, err := d.Client.Dcim.DcimDevicesUpdate(updateParams, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	log.V(1).Info("updated device", "response", netboxDevice)
+	log.V(1).Info("updated device", "response", netboxDevice, "changed", changed)
 
-	return d.setStatus(netboxDevice.GetPayload())
+	return &Result{Operation: OperationUpdated, Changed: changed}, d.setStatus(netboxDevice.GetPayload())
 }
 
 func (d *Device) exists(ctx context.Context) (*models.DeviceWithConfigContext, bool, error) {
 	log := logr.FromContext(ctx)
 
+	if id, ok := d.Data.Annotations[netboxv1.IDAnnotation]; ok {
+		return d.read(ctx, id)
+	}
+
 	devices, err := d.Client.Dcim.DcimDevicesList(&dcim.DcimDevicesListParams{
 		Name:    &d.Data.Name,
 		Context: ctx,
 	}, nil)
 	if err != nil {
-		return nil, false, fmt.Errorf("failed to DcimDevicesList, %+v", err)
+		return nil, false, fmt.Errorf("failed to DcimDevicesList, %w", err)
 	}
 
 	if *devices.Payload.Count > 1 {
@@ -214,6 +411,127 @@ func (d *Device) exists(ctx context.Context) (*models.DeviceWithConfigContext, b
 	return devices.Payload.Results[0], true, nil
 }
 
+// diff returns the fields of the Netbox device that do not match the resolved IDs
+func (i *ids) diff(nbDev *models.DeviceWithConfigContext) []string {
+	changed := []string{}
+	if nbDev.DeviceRole == nil || nbDev.DeviceRole.ID != i.Role {
+		changed = append(changed, "role")
+	}
+	if nbDev.DeviceType == nil || nbDev.DeviceType.ID != i.Type {
+		changed = append(changed, "device_type")
+	}
+	if nbDev.Site == nil || nbDev.Site.ID != i.Site {
+		changed = append(changed, "site")
+	}
+	if i.Tenant != 0 && (nbDev.Tenant == nil || nbDev.Tenant.ID != i.Tenant) {
+		changed = append(changed, "tenant")
+	}
+	if i.Platform != 0 && (nbDev.Platform == nil || nbDev.Platform.ID != i.Platform) {
+		changed = append(changed, "platform")
+	}
+	return changed
+}
+
+// tenant returns the tenant ID, or nil to leave the tenant of the Netbox device unchanged
+func (i *ids) tenant() *int64 {
+	if i.Tenant == 0 {
+		return nil
+	}
+	return &i.Tenant
+}
+
+// platform returns the platform ID, or nil to leave the platform of the Netbox device unchanged
+func (i *ids) platform() *int64 {
+	if i.Platform == 0 {
+		return nil
+	}
+	return &i.Platform
+}
+
+// sameSlugs returns true if both lists contain the same slugs, in any order
+func sameSlugs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	seen := map[string]int{}
+	for _, s := range a {
+		seen[s]++
+	}
+	for _, s := range b {
+		if seen[s] == 0 {
+			return false
+		}
+		seen[s]--
+	}
+	return true
+}
+
+// listParams maps the list options onto the device list filters,
+// resolving the names of referenced objects to their IDs
+func (d *Device) listParams(ctx context.Context, opts ListOptions) (*dcim.DcimDevicesListParams, error) {
+	params := &dcim.DcimDevicesListParams{
+		Context: ctx,
+	}
+	if d.Data.Name != "" {
+		params.Name = &d.Data.Name
+	}
+	if opts.Status != "" {
+		params.Status = &opts.Status
+	}
+	if opts.Query != "" {
+		params.Q = &opts.Query
+	}
+
+	filters := []struct {
+		name  string
+		t     string
+		param **string
+	}{
+		{opts.Site, "site", &params.SiteID},
+		{opts.Role, "role", &params.RoleID},
+		{opts.DeviceType, "type", &params.DeviceTypeID},
+		{opts.Tenant, "tenant", &params.TenantID},
+	}
+	for _, f := range filters {
+		if f.name == "" {
+			continue
+		}
+		id, err := d.resolveNameToID(ctx, f.name, f.t)
+		if err != nil {
+			return nil, err
+		}
+		value := strconv.FormatInt(id, 10)
+		*f.param = &value
+	}
+
+	return params, nil
+}
+
+// read looks up an adopted device by its Netbox ID
+func (d *Device) read(ctx context.Context, id string) (*models.DeviceWithConfigContext, bool, error) {
+	log := logr.FromContext(ctx)
+
+	nbID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, false, fmt.Errorf("unexpected %s annotation %q: %w", netboxv1.IDAnnotation, id, err)
+	}
+
+	device, err := d.Client.Dcim.DcimDevicesRead(&dcim.DcimDevicesReadParams{
+		ID:      nbID,
+		Context: ctx,
+	}, nil)
+	if IsNotFound(err) {
+		log.Info("adopted device no longer exists in Netbox", "id", nbID)
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to DcimDevicesRead, %w", err)
+	}
+
+	log.V(1).Info("found adopted device", "id", nbID)
+	return device.GetPayload(), true, nil
+}
+
 func (d *Device) resolveIDs(ctx context.Context) (*ids, error) {
 	log := logr.FromContext(ctx)
 
@@ -235,9 +553,71 @@ func (d *Device) resolveIDs(ctx context.Context) (*ids, error) {
 	}
 	log.V(1).Info("found site", "siteID", siteID)
 
-	return &ids{
+	result := &ids{
 		Role: roleID,
 		Type: typeID,
 		Site: siteID,
-	}, nil
+	}
+
+	if d.Data.Spec.Tenant != "" {
+		if result.Tenant, err = d.resolveNameToID(ctx, d.Data.Spec.Tenant, "tenant"); err != nil {
+			return nil, err
+		}
+		log.V(1).Info("found tenant", "tenantID", result.Tenant)
+	}
+
+	if d.Data.Spec.Platform != "" {
+		if result.Platform, err = d.resolveNameToID(ctx, d.Data.Spec.Platform, "platform"); err != nil {
+			return nil, err
+		}
+		log.V(1).Info("found platform", "platformID", result.Platform)
+	}
+
+	if d.Data.Spec.Rack != "" {
+		rack, err := d.findRack(ctx, siteID, d.Data.Spec.Rack)
+		if err != nil {
+			return nil, err
+		}
+		if rack == nil {
+			return nil, &ReferenceNotFoundError{Type: "rack", Name: d.Data.Spec.Site + "/" + d.Data.Spec.Rack}
+		}
+		result.Rack = rack.ID
+		log.V(1).Info("found rack", "rackID", result.Rack)
+	}
+
+	if len(d.Data.Spec.Tags) > 0 {
+		if result.Tags, err = d.resolveTags(ctx, d.Data.Spec.Tags); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// drift returns the fields of the changelog data that do not match the device spec
+func (d *Device) drift(ctx context.Context, data map[string]interface{}) ([]FieldDiff, error) {
+	ids, err := d.resolveIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := []FieldDiff{}
+	for _, f := range []struct {
+		field, key, name string
+		id               int64
+	}{
+		{"role", "device_role", d.Data.Spec.Role, ids.Role},
+		{"device_type", "device_type", d.Data.Spec.DeviceType, ids.Type},
+		{"site", "site", d.Data.Spec.Site, ids.Site},
+	} {
+		if id, ok := dataID(data[f.key]); ok && id == f.id {
+			continue
+		}
+		diff = append(diff, FieldDiff{
+			Field: f.field,
+			Old:   fmt.Sprintf("%s (ID %d)", f.name, f.id),
+			New:   data[f.key],
+		})
+	}
+	return diff, nil
 }
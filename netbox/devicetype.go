@@ -0,0 +1,314 @@
+package netbox
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	"github.com/netbox-community/go-netbox/netbox/client/dcim"
+	"github.com/netbox-community/go-netbox/netbox/models"
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type DeviceType struct {
+	Data *netboxv1.DeviceType
+	NetboxServer
+
+	manufacturerID int64
+}
+
+func NewDeviceType(s NetboxServer, t *netboxv1.DeviceType) *DeviceType {
+	return &DeviceType{
+		Data:         t,
+		NetboxServer: s,
+	}
+}
+
+func (t *DeviceType) resource() netboxv1.Object {
+	return t.Data
+}
+
+func (t *DeviceType) typeName() string {
+	return "device type"
+}
+
+func (t *DeviceType) path() string {
+	return "/dcim/device-types/"
+}
+
+func (t *DeviceType) tags() []string {
+	return t.Data.Spec.Tags
+}
+
+// cacheKey implements cached, devices refer to device types by model
+func (t *DeviceType) cacheKey() (string, string) {
+	return "type", t.Data.DeviceTypeModelName()
+}
+
+// children implements parent, Netbox refuses to delete device types that still have devices
+func (t *DeviceType) children(ctx context.Context, id int64) ([]string, error) {
+	deviceType := strconv.FormatInt(id, 10)
+	one := int64(1)
+	devices, err := t.Client.Dcim.DcimDevicesList(&dcim.DcimDevicesListParams{
+		DeviceTypeID: &deviceType,
+		Limit:        &one,
+		Context:      ctx,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to DcimDevicesList, %w", err)
+	}
+	return countChildren(childCount{*devices.Payload.Count, "device"}), nil
+}
+
+// resolve looks up the manufacturer of the device type
+func (t *DeviceType) resolve(ctx context.Context) error {
+	id, err := t.resolveNameToID(ctx, t.Data.Spec.Manufacturer, "manufacturer")
+	if err != nil {
+		return err
+	}
+	t.manufacturerID = id
+	return nil
+}
+
+func (t *DeviceType) read(ctx context.Context, id int64) (*current, error) {
+	deviceType, err := t.Client.Dcim.DcimDeviceTypesRead(&dcim.DcimDeviceTypesReadParams{
+		ID:      id,
+		Context: ctx,
+	}, nil)
+	if IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to DcimDeviceTypesRead, %w", err)
+	}
+
+	return deviceTypeCurrent(deviceType.GetPayload()), nil
+}
+
+func deviceTypeCurrent(deviceType *models.DeviceType) *current {
+	return &current{ID: deviceType.ID, Tags: deviceType.Tags, model: deviceType}
+}
+
+// lookup returns the device type with the same model, which is how devices refer to it
+func (t *DeviceType) lookup(ctx context.Context) (*current, error) {
+	model := t.Data.DeviceTypeModelName()
+	result, err := t.Client.Dcim.DcimDeviceTypesList(&dcim.DcimDeviceTypesListParams{
+		Model:   &model,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to DcimDeviceTypesList, %w", err)
+	}
+	if *result.Payload.Count == 0 {
+		return nil, nil
+	}
+	if *result.Payload.Count > 1 {
+		return nil, fmt.Errorf("%d device types with model %q found, cannot proceed", *result.Payload.Count, model)
+	}
+	return deviceTypeCurrent(result.Payload.Results[0]), nil
+}
+
+func (t *DeviceType) slug() string {
+	if t.Data.Spec.Slug != "" {
+		return t.Data.Spec.Slug
+	}
+	return slugify(t.Data.DeviceTypeModelName())
+}
+
+func (t *DeviceType) diff(cur *current) []string {
+	deviceType := cur.model.(*models.DeviceType)
+	spec := t.Data.Spec
+
+	changed := []string{}
+	if deviceType.Model == nil || *deviceType.Model != t.Data.DeviceTypeModelName() {
+		changed = append(changed, "model")
+	}
+	if deviceType.Slug == nil || *deviceType.Slug != t.slug() {
+		changed = append(changed, "slug")
+	}
+	if deviceType.Manufacturer == nil || deviceType.Manufacturer.ID != t.manufacturerID {
+		changed = append(changed, "manufacturer")
+	}
+	if spec.PartNumber != "" && deviceType.PartNumber != spec.PartNumber {
+		changed = append(changed, "part_number")
+	}
+	if spec.UHeight != nil && uHeight(deviceType) != *spec.UHeight {
+		changed = append(changed, "u_height")
+	}
+	if spec.IsFullDepth != nil && deviceType.IsFullDepth != *spec.IsFullDepth {
+		changed = append(changed, "is_full_depth")
+	}
+	return changed
+}
+
+// writable maps the spec to a device type, unset fields keep the current values of the
+// Netbox device type (or the Netbox defaults of new ones)
+func (t *DeviceType) writable(cur *models.DeviceType, tags []*models.NestedTag) *models.WritableDeviceType {
+	spec := t.Data.Spec
+	model, slug := t.Data.DeviceTypeModelName(), t.slug()
+	deviceType := &models.WritableDeviceType{
+		Model:        &model,
+		Slug:         &slug,
+		Manufacturer: &t.manufacturerID,
+		PartNumber:   spec.PartNumber,
+		UHeight:      spec.UHeight,
+		IsFullDepth:  spec.IsFullDepth == nil || *spec.IsFullDepth,
+		Tags:         tags,
+	}
+	if cur != nil {
+		if deviceType.PartNumber == "" {
+			deviceType.PartNumber = cur.PartNumber
+		}
+		if deviceType.UHeight == nil {
+			deviceType.UHeight = cur.UHeight
+		}
+	}
+	return deviceType
+}
+
+// halfDepth marks the device type as half depth if the spec does. The writable model omits
+// false values, which leaves the Netbox default or the current value in place
+func (t *DeviceType) halfDepth(ctx context.Context, deviceType *models.DeviceType) error {
+	if t.Data.Spec.IsFullDepth == nil || *t.Data.Spec.IsFullDepth || !deviceType.IsFullDepth {
+		return nil
+	}
+	if err := t.patchObject(ctx, t.path(), deviceType.ID, map[string]interface{}{"is_full_depth": false}); err != nil {
+		return fmt.Errorf("failed to make device type %d half depth, %w", deviceType.ID, err)
+	}
+	return nil
+}
+
+func (t *DeviceType) create(ctx context.Context, tags []*models.NestedTag) (int64, error) {
+	log := logr.FromContext(ctx)
+
+	deviceType, err := t.Client.Dcim.DcimDeviceTypesCreate(&dcim.DcimDeviceTypesCreateParams{
+		Data:    t.writable(nil, tags),
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to DcimDeviceTypesCreate, %w", err)
+	}
+	log.V(1).Info("created device type", "response", deviceType)
+
+	if err := t.halfDepth(ctx, deviceType.GetPayload()); err != nil {
+		return 0, err
+	}
+	return deviceType.GetPayload().ID, nil
+}
+
+func (t *DeviceType) update(ctx context.Context, cur *current, tags []*models.NestedTag) (int64, error) {
+	log := logr.FromContext(ctx)
+
+	deviceType, err := t.Client.Dcim.DcimDeviceTypesUpdate(&dcim.DcimDeviceTypesUpdateParams{
+		Data:    t.writable(cur.model.(*models.DeviceType), tags),
+		ID:      cur.ID,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to DcimDeviceTypesUpdate, %w", err)
+	}
+	log.V(1).Info("updated device type", "response", deviceType)
+
+	if err := t.halfDepth(ctx, deviceType.GetPayload()); err != nil {
+		return 0, err
+	}
+	return deviceType.GetPayload().ID, nil
+}
+
+// applyParts implements composite, the templates of the spec are created and updated
+func (t *DeviceType) applyParts(ctx context.Context, id int64) ([]string, error) {
+	changed := []string{}
+	for _, templates := range []struct {
+		field string
+		kind  templateKind
+	}{
+		{"interfaces", t.interfaceTemplateKind()},
+		{"console_ports", t.consolePortTemplateKind()},
+		{"power_ports", t.powerPortTemplateKind()},
+	} {
+		updated, err := t.applyTemplates(ctx, id, templates.kind)
+		if err != nil {
+			return nil, err
+		}
+		if updated {
+			changed = append(changed, templates.field)
+		}
+	}
+	return changed, nil
+}
+
+func (t *DeviceType) each(ctx context.Context, opts ListOptions, fn func(netboxv1.Object) error) error {
+	log := logr.FromContext(ctx)
+
+	params := &dcim.DcimDeviceTypesListParams{
+		Context: ctx,
+	}
+	if model := t.Data.DeviceTypeModelName(); model != "" {
+		params.Model = &model
+	}
+	if opts.Query != "" {
+		params.Q = &opts.Query
+	}
+
+	return paginate(ctx, opts.ChunkSize, opts.Limit, func(offset, limit int64) (int, bool, error) {
+		params.Offset = &offset
+		params.Limit = &limit
+
+		deviceTypes, err := t.Client.Dcim.DcimDeviceTypesList(params, t.withQuery(opts.query()))
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to DcimDeviceTypesList, %w", err)
+		}
+		log.V(1).Info("found device types", "count", deviceTypes.Payload.Count, "offset", offset)
+
+		for _, deviceType := range deviceTypes.Payload.Results {
+			if err := fn(deviceTypeFromModel(deviceType)); err != nil {
+				return 0, false, err
+			}
+		}
+
+		return len(deviceTypes.Payload.Results), deviceTypes.Payload.Next != nil, nil
+	})
+}
+
+// deviceTypeFromModel maps a Netbox device type to a DeviceType named after the slug of the
+// device type. The templates are not listed, applying the result leaves them unchanged
+func deviceTypeFromModel(deviceType *models.DeviceType) *netboxv1.DeviceType {
+	fullDepth := deviceType.IsFullDepth
+	height := uHeight(deviceType)
+	spec := netboxv1.DeviceTypeSpec{
+		PartNumber:  deviceType.PartNumber,
+		UHeight:     &height,
+		IsFullDepth: &fullDepth,
+		Tags:        tagSlugs(deviceType.Tags),
+	}
+	if len(spec.Tags) == 0 {
+		spec.Tags = nil
+	}
+	if deviceType.Model != nil {
+		spec.Model = *deviceType.Model
+	}
+	if deviceType.Slug != nil {
+		spec.Slug = *deviceType.Slug
+	}
+	if deviceType.Manufacturer != nil && deviceType.Manufacturer.Name != nil {
+		spec.Manufacturer = *deviceType.Manufacturer.Name
+	}
+
+	id := deviceType.ID
+	return &netboxv1.DeviceType{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       netboxv1.DeviceTypeKind,
+			APIVersion: netboxv1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: netboxv1.ResourceName(spec.Slug),
+		},
+		Spec: spec,
+		Status: netboxv1.ObjectStatus{
+			ID:    &id,
+			State: netboxv1.ObjectReadyState,
+		},
+	}
+}
@@ -0,0 +1,147 @@
+package netbox
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/go-logr/logr"
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeDeviceTypeNetbox serves the device type DCS-7050 with ID 5, which has the interface
+// templates eth1 (management only) and eth2 and the power port template psu1, and the device
+// leaf-01 with ID 10, which only has eth1 and psu1
+func fakeDeviceTypeNetbox(t *testing.T) *fakeNetbox {
+	tag := fmt.Sprintf(`{"id": 1, "name": %q, "slug": %q}`, ManagedTagName, ManagedTagSlug)
+	return newFakeNetbox(t,
+		onGet("/api/dcim/manufacturers/", page(`{"id": 3, "name": "Arista", "slug": "arista"}`)),
+		onGet("/api/dcim/device-types/", page(`{"id": 5, "model": "DCS-7050", "slug": "dcs-7050", "u_height": 1, "is_full_depth": true,
+			"manufacturer": {"id": 3, "name": "Arista", "slug": "arista"}, "tags": [`+tag+`]}`), "model", "DCS-7050"),
+		onGet("/api/dcim/device-types/", page()),
+		onGet("/api/dcim/interface-templates/", page(
+			`{"id": 20, "name": "eth1", "type": {"value": "1000base-t", "label": "1000BASE-T"}, "mgmt_only": true}`,
+			`{"id": 21, "name": "eth2", "type": {"value": "10gbase-x-sfpp", "label": "SFP+"}, "description": "uplink"}`,
+		), "devicetype_id", "5"),
+		onGet("/api/dcim/console-port-templates/", page(), "devicetype_id", "5"),
+		onGet("/api/dcim/power-port-templates/", page(
+			`{"id": 30, "name": "psu1", "type": {"value": "iec-60320-c14", "label": "C14"}}`,
+		), "devicetype_id", "5"),
+		onGet("/api/dcim/interfaces/", page(`{"id": 40, "name": "eth1"}`), "device_id", "10"),
+		onGet("/api/dcim/power-ports/", page(`{"id": 50, "name": "psu1"}`), "device_id", "10"),
+		onPost("/api/dcim/interface-templates/", `{"id": 99}`),
+		onPatch("/api/dcim/interface-templates/20/", `{"id": 20}`),
+		onPost("/api/dcim/interfaces/", `{"id": 99}`),
+	)
+}
+
+func TestDeviceTypeTemplates(t *testing.T) {
+	ctx := logr.NewContext(context.Background(), logr.Discard())
+
+	f := fakeDeviceTypeNetbox(t)
+
+	deviceType := &netboxv1.DeviceType{
+		ObjectMeta: metav1.ObjectMeta{Name: "dcs-7050"},
+		Spec: netboxv1.DeviceTypeSpec{
+			Model:        "DCS-7050",
+			Manufacturer: "Arista",
+			Interfaces: []netboxv1.InterfaceTemplate{
+				{Name: "eth1", Type: "1000base-t"},
+				{Name: "eth2", Type: "10gbase-x-sfpp", Description: "uplink"},
+				{Name: "eth3", Type: "10gbase-x-sfpp"},
+			},
+		},
+	}
+	result, err := f.netbox().Apply(ctx, deviceType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Operation != OperationUpdated || !reflect.DeepEqual(result.Changed, []string{"interfaces"}) {
+		t.Errorf("expected interfaces to be updated, got %s %v", result.Operation, result.Changed)
+	}
+
+	want := []request{
+		{method: http.MethodPatch, path: "/api/dcim/interface-templates/20/", body: map[string]interface{}{
+			"mgmt_only": false,
+		}},
+		{method: http.MethodPost, path: "/api/dcim/interface-templates/", body: map[string]interface{}{
+			"device_type": float64(5), "name": "eth3", "type": "10gbase-x-sfpp",
+		}},
+	}
+	writes := f.requests()
+	if len(writes) != len(want) {
+		t.Fatalf("expected %d writes, got %v", len(want), writes)
+	}
+	for i := range want {
+		if writes[i].method != want[i].method || writes[i].path != want[i].path || !containsFields(writes[i].body, want[i].body) {
+			t.Errorf("expected %v, got %v", want[i], writes[i])
+		}
+	}
+}
+
+// containsFields returns true if body has the fields, go-netbox also sends zero timestamps
+func containsFields(body, fields map[string]interface{}) bool {
+	for k, v := range fields {
+		if !reflect.DeepEqual(body[k], v) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestDeviceSyncComponents(t *testing.T) {
+	ctx := logr.NewContext(context.Background(), logr.Discard())
+
+	tests := []struct {
+		name    string
+		sync    bool
+		want    *Result
+		created []string
+	}{
+		{
+			name:    "sync",
+			sync:    true,
+			want:    &Result{Operation: OperationUpdated, Changed: []string{"interfaces"}},
+			created: []string{"eth2"},
+		},
+		{
+			name: "no sync",
+			want: &Result{Operation: OperationUnchanged},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := fakeDeviceTypeNetbox(t)
+
+			id := int64(10)
+			d := NewDevice(*f.netbox(), &netboxv1.Device{
+				ObjectMeta: metav1.ObjectMeta{Name: "leaf-01"},
+				Spec:       netboxv1.DeviceSpec{DeviceType: "DCS-7050", SyncComponents: tt.sync},
+				Status:     netboxv1.DeviceStatus{ID: &id},
+			})
+			result, err := d.syncComponents(ctx, &Result{Operation: OperationUnchanged})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(result, tt.want) {
+				t.Errorf("expected %+v, got %+v", tt.want, result)
+			}
+
+			var created []string
+			for _, w := range f.requests() {
+				if w.method != http.MethodPost || w.path != "/api/dcim/interfaces/" || w.body["device"] != float64(10) {
+					t.Errorf("unexpected write %v", w)
+					continue
+				}
+				created = append(created, w.body["name"].(string))
+			}
+			if !reflect.DeepEqual(created, tt.created) {
+				t.Errorf("expected created interfaces %v, got %v", tt.created, created)
+			}
+		})
+	}
+}
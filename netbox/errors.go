@@ -0,0 +1,97 @@
+package netbox
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-openapi/runtime"
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+)
+
+// ReferenceNotFoundError is returned when an object refers to another Netbox object
+// (e.g. a site or a device role) that does not exist
+type ReferenceNotFoundError struct {
+	Type string
+	Name string
+}
+
+func (e *ReferenceNotFoundError) Error() string {
+	return fmt.Sprintf("%s %q not found in Netbox", e.Type, e.Name)
+}
+
+// IsReferenceNotFound returns true if err is caused by a missing Netbox object
+func IsReferenceNotFound(err error) bool {
+	var refErr *ReferenceNotFoundError
+	return errors.As(err, &refErr)
+}
+
+// UnmanagedConflictError is returned when an object would take over an existing
+// Netbox object that is not managed by declarative-netbox
+type UnmanagedConflictError struct {
+	Type string
+	Name string
+	ID   int64
+}
+
+func (e *UnmanagedConflictError) Error() string {
+	return fmt.Sprintf("%s %q already exists in Netbox with ID %d and is not managed by declarative-netbox, set the %s annotation to adopt it", e.Type, e.Name, e.ID, netboxv1.IDAnnotation)
+}
+
+// IsUnmanagedConflict returns true if err is caused by an existing unmanaged Netbox object
+func IsUnmanagedConflict(err error) bool {
+	var conflictErr *UnmanagedConflictError
+	return errors.As(err, &conflictErr)
+}
+
+// ConflictError is returned when applying an object would overwrite another
+// Netbox object, e.g. connect a cable to an interface that is already connected
+type ConflictError struct {
+	Type   string
+	Name   string
+	Reason string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s %q conflicts with Netbox: %s", e.Type, e.Name, e.Reason)
+}
+
+// IsConflict returns true if err is caused by a conflicting Netbox object
+func IsConflict(err error) bool {
+	var conflictErr *ConflictError
+	return errors.As(err, &conflictErr)
+}
+
+// HasChildrenError is returned when deleting an object that other Netbox objects
+// still belong to, e.g. a region with sites
+type HasChildrenError struct {
+	Type     string
+	Name     string
+	Children []string
+}
+
+func (e *HasChildrenError) Error() string {
+	return fmt.Sprintf("%s %q still has %s in Netbox, delete them first", e.Type, e.Name, strings.Join(e.Children, ", "))
+}
+
+// IsHasChildren returns true if err is caused by the children of a Netbox object
+func IsHasChildren(err error) bool {
+	var childrenErr *HasChildrenError
+	return errors.As(err, &childrenErr)
+}
+
+// IsAuthFailed returns true if Netbox rejected the API token
+func IsAuthFailed(err error) bool {
+	var apiErr *runtime.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.Code == http.StatusUnauthorized || apiErr.Code == http.StatusForbidden
+}
+
+// IsNotFound returns true if the Netbox object does not exist
+func IsNotFound(err error) bool {
+	var apiErr *runtime.APIError
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound
+}
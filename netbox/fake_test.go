@@ -0,0 +1,158 @@
+package netbox
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// route answers the requests with the method and path that have the query parameters
+type route struct {
+	method string
+	path   string
+	query  map[string]string
+	// status defaults to 201 for POST, 204 for DELETE and 200 otherwise
+	status   int
+	response string
+}
+
+// request is a write request received by a fake Netbox
+type request struct {
+	method string
+	path   string
+	body   map[string]interface{}
+}
+
+// onGet answers GET requests, query are pairs of parameter names and values the request must have
+func onGet(path, response string, query ...string) route {
+	r := route{method: http.MethodGet, path: path, response: response, query: map[string]string{}}
+	for i := 0; i+1 < len(query); i += 2 {
+		r.query[query[i]] = query[i+1]
+	}
+	return r
+}
+
+// page returns a list response with the results
+func page(results ...string) string {
+	return fmt.Sprintf(`{"count": %d, "results": [%s]}`, len(results), strings.Join(results, ", "))
+}
+
+func onPost(path, response string) route {
+	return route{method: http.MethodPost, path: path, response: response}
+}
+
+func onPatch(path, response string) route {
+	return route{method: http.MethodPatch, path: path, response: response}
+}
+
+func onDelete(path string) route {
+	return route{method: http.MethodDelete, path: path}
+}
+
+// fakeNetbox serves the routes in order, the first route matching a request answers it. The
+// managed tag and the created custom field are always served and the bodies of write requests are recorded
+type fakeNetbox struct {
+	*httptest.Server
+	t      *testing.T
+	routes []route
+
+	mu     sync.Mutex
+	writes []request
+}
+
+func newFakeNetbox(t *testing.T, routes ...route) *fakeNetbox {
+	f := &fakeNetbox{
+		t: t,
+		routes: append(routes,
+			onGet("/api/extras/tags/", page(fmt.Sprintf(`{"id": 1, "name": %q, "slug": %q}`, ManagedTagName, ManagedTagSlug))),
+			onGet("/api/extras/custom-fields/", page(fmt.Sprintf(`{"id": 1, "name": %q, "type": {"value": "boolean", "label": "Boolean"}, "content_types": ["%s"]}`,
+				CreatedFieldName, strings.Join(createdFieldTypes, `", "`)))),
+		),
+	}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(f.Close)
+	return f
+}
+
+// netbox returns a NetboxServer for the fake that doesn't retry
+func (f *fakeNetbox) netbox() *NetboxServer {
+	s, err := NewNetboxServer(f.URL, "token", WithRetry(RetryOptions{}))
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	return s
+}
+
+// requests returns the write requests received so far
+func (f *fakeNetbox) requests() []request {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]request{}, f.writes...)
+}
+
+// written returns the bodies of the write requests with the method and path
+func (f *fakeNetbox) written(method, path string) []map[string]interface{} {
+	bodies := []map[string]interface{}{}
+	for _, r := range f.requests() {
+		if r.method == method && r.path == path {
+			bodies = append(bodies, r.body)
+		}
+	}
+	return bodies
+}
+
+func (f *fakeNetbox) serve(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodGet {
+		body := map[string]interface{}{}
+		if r.Method != http.MethodDelete {
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				f.t.Errorf("failed to decode %s %s: %s", r.Method, r.URL.Path, err)
+			}
+		}
+		f.mu.Lock()
+		f.writes = append(f.writes, request{method: r.Method, path: r.URL.Path, body: body})
+		f.mu.Unlock()
+	}
+
+	for _, route := range f.routes {
+		if !route.matches(r) {
+			continue
+		}
+		status := route.status
+		if status == 0 {
+			switch r.Method {
+			case http.MethodPost:
+				status = http.StatusCreated
+			case http.MethodDelete:
+				status = http.StatusNoContent
+			default:
+				status = http.StatusOK
+			}
+		}
+		w.WriteHeader(status)
+		fmt.Fprint(w, route.response)
+		return
+	}
+
+	f.t.Errorf("unexpected request %s %s", r.Method, r.URL)
+	w.WriteHeader(http.StatusNotFound)
+}
+
+func (route route) matches(r *http.Request) bool {
+	if route.method != r.Method || route.path != r.URL.Path {
+		return false
+	}
+	query := r.URL.Query()
+	for k, v := range route.query {
+		if query.Get(k) != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,393 @@
+package netbox
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	"github.com/netbox-community/go-netbox/netbox/client/dcim"
+	"github.com/netbox-community/go-netbox/netbox/models"
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type Interface struct {
+	Data *netboxv1.Interface
+	NetboxServer
+
+	deviceID int64
+	// untaggedID and taggedIDs are the resolved VLANs, nil if not set in the spec
+	untaggedID *int64
+	taggedIDs  []int64
+}
+
+func NewInterface(s NetboxServer, i *netboxv1.Interface) *Interface {
+	return &Interface{
+		Data:         i,
+		NetboxServer: s,
+	}
+}
+
+func (i *Interface) resource() netboxv1.Object {
+	return i.Data
+}
+
+func (i *Interface) typeName() string {
+	return "interface"
+}
+
+func (i *Interface) path() string {
+	return "/dcim/interfaces/"
+}
+
+func (i *Interface) tags() []string {
+	return i.Data.Spec.Tags
+}
+
+// resolve looks up the device and the VLANs
+func (i *Interface) resolve(ctx context.Context) error {
+	spec := i.Data.Spec
+
+	id, err := i.lookupNameToID(ctx, spec.Device, "device")
+	if err != nil {
+		return err
+	}
+	i.deviceID = id
+
+	if spec.UntaggedVLAN == nil && spec.TaggedVLANs == nil {
+		return nil
+	}
+
+	// VLANs without a group are looked up in the site of the device
+	device, err := i.Client.Dcim.DcimDevicesRead(&dcim.DcimDevicesReadParams{
+		ID:      id,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to DcimDevicesRead, %w", err)
+	}
+	var siteID int64
+	if site := device.GetPayload().Site; site != nil {
+		siteID = site.ID
+	}
+
+	if spec.UntaggedVLAN != nil {
+		id, err := i.resolveVLAN(ctx, *spec.UntaggedVLAN, siteID)
+		if err != nil {
+			return err
+		}
+		i.untaggedID = &id
+	}
+	if spec.TaggedVLANs != nil {
+		i.taggedIDs = []int64{}
+		for _, ref := range spec.TaggedVLANs {
+			id, err := i.resolveVLAN(ctx, ref, siteID)
+			if err != nil {
+				return err
+			}
+			i.taggedIDs = append(i.taggedIDs, id)
+		}
+	}
+	return nil
+}
+
+// mode returns the 802.1Q mode of the spec, which defaults to tagged for interfaces with
+// tagged VLANs and to access for interfaces with only an untagged VLAN
+func (i *Interface) mode() string {
+	spec := i.Data.Spec
+	switch {
+	case spec.Mode != "":
+		return spec.Mode
+	case spec.TaggedVLANs != nil:
+		return "tagged"
+	case spec.UntaggedVLAN != nil:
+		return "access"
+	}
+	return ""
+}
+
+func (i *Interface) read(ctx context.Context, id int64) (*current, error) {
+	intf, err := i.Client.Dcim.DcimInterfacesRead(&dcim.DcimInterfacesReadParams{
+		ID:      id,
+		Context: ctx,
+	}, nil)
+	if IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to DcimInterfacesRead, %w", err)
+	}
+
+	return interfaceCurrent(intf.GetPayload()), nil
+}
+
+func interfaceCurrent(intf *models.Interface) *current {
+	return &current{ID: intf.ID, Tags: intf.Tags, model: intf}
+}
+
+// hosts implements attached, interfaces belong to the tenant of their device
+func (i *Interface) hosts(cur *current) []host {
+	hosts := []host{{typ: "device", name: i.Data.Spec.Device}}
+	if cur != nil {
+		if dev := cur.model.(*models.Interface).Device; dev != nil && dev.Name != nil {
+			hosts = append(hosts, host{typ: "device", name: *dev.Name})
+		}
+	}
+	return hosts
+}
+
+// lookup returns the interface of the device with the same name
+func (i *Interface) lookup(ctx context.Context) (*current, error) {
+	intf, err := i.findInterface(ctx, i.deviceID, i.Data.InterfaceName())
+	if err != nil || intf == nil {
+		return nil, err
+	}
+	return interfaceCurrent(intf), nil
+}
+
+// findInterface returns the interface of the device with the name, nil if it doesn't exist
+func (s *NetboxServer) findInterface(ctx context.Context, deviceID int64, name string) (*models.Interface, error) {
+	device := strconv.FormatInt(deviceID, 10)
+	result, err := s.Client.Dcim.DcimInterfacesList(&dcim.DcimInterfacesListParams{
+		DeviceID: &device,
+		Name:     &name,
+		Context:  ctx,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to DcimInterfacesList, %w", err)
+	}
+	if *result.Payload.Count == 0 {
+		return nil, nil
+	}
+	if *result.Payload.Count != 1 {
+		return nil, fmt.Errorf("unexpected number of interfaces %q found: %d", name, *result.Payload.Count)
+	}
+	return result.Payload.Results[0], nil
+}
+
+func (i *Interface) diff(cur *current) []string {
+	intf := cur.model.(*models.Interface)
+	spec := i.Data.Spec
+
+	changed := []string{}
+	if intf.Name == nil || *intf.Name != i.Data.InterfaceName() {
+		changed = append(changed, "name")
+	}
+	if intf.Device == nil || intf.Device.ID != i.deviceID {
+		changed = append(changed, "device")
+	}
+	if intf.Type == nil || intf.Type.Value == nil || *intf.Type.Value != spec.Type {
+		changed = append(changed, "type")
+	}
+	if spec.MTU != 0 && (intf.Mtu == nil || *intf.Mtu != spec.MTU) {
+		changed = append(changed, "mtu")
+	}
+	if spec.MgmtOnly && !intf.MgmtOnly {
+		changed = append(changed, "mgmt_only")
+	}
+	if spec.Label != "" && intf.Label != spec.Label {
+		changed = append(changed, "label")
+	}
+	if spec.Description != "" && intf.Description != spec.Description {
+		changed = append(changed, "description")
+	}
+	if mode := i.mode(); mode != "" && (intf.Mode == nil || intf.Mode.Value == nil || *intf.Mode.Value != mode) {
+		changed = append(changed, "mode")
+	}
+	if i.untaggedID != nil && (intf.UntaggedVlan == nil || intf.UntaggedVlan.ID != *i.untaggedID) {
+		changed = append(changed, "untagged_vlan")
+	}
+	if i.taggedIDs != nil && !sameIDs(i.taggedIDs, vlanIDs(intf.TaggedVlans)) {
+		changed = append(changed, "tagged_vlans")
+	}
+	return changed
+}
+
+func vlanIDs(vlans []*models.NestedVLAN) []int64 {
+	ids := []int64{}
+	for _, vlan := range vlans {
+		ids = append(ids, vlan.ID)
+	}
+	return ids
+}
+
+// sameIDs returns true if both lists contain the same IDs, in any order
+func sameIDs(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	seen := map[int64]int{}
+	for _, id := range a {
+		seen[id]++
+	}
+	for _, id := range b {
+		if seen[id] == 0 {
+			return false
+		}
+		seen[id]--
+	}
+	return true
+}
+
+// writable maps the spec to the writable interface. The 802.1Q mode and VLANs that are not
+// part of the spec are copied from the existing interface so that they stay unchanged
+func (i *Interface) writable(cur *models.Interface, tags []*models.NestedTag) *models.WritableInterface {
+	spec := i.Data.Spec
+	name := i.Data.InterfaceName()
+	intf := &models.WritableInterface{
+		Device:       &i.deviceID,
+		Name:         &name,
+		Type:         &spec.Type,
+		MgmtOnly:     spec.MgmtOnly,
+		Label:        spec.Label,
+		Description:  spec.Description,
+		Mode:         i.mode(),
+		UntaggedVlan: i.untaggedID,
+		TaggedVlans:  []int64{},
+		Tags:         tags,
+	}
+	if spec.MTU != 0 {
+		intf.Mtu = &spec.MTU
+	}
+	if i.taggedIDs != nil {
+		intf.TaggedVlans = i.taggedIDs
+	}
+	if cur != nil {
+		if intf.Mode == "" && cur.Mode != nil && cur.Mode.Value != nil {
+			intf.Mode = *cur.Mode.Value
+		}
+		if i.taggedIDs == nil {
+			intf.TaggedVlans = vlanIDs(cur.TaggedVlans)
+		}
+	}
+	return intf
+}
+
+func (i *Interface) create(ctx context.Context, tags []*models.NestedTag) (int64, error) {
+	log := logr.FromContext(ctx)
+
+	intf, err := i.Client.Dcim.DcimInterfacesCreate(&dcim.DcimInterfacesCreateParams{
+		Data:    i.writable(nil, tags),
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to DcimInterfacesCreate, %w", err)
+	}
+	log.V(1).Info("created interface", "response", intf)
+
+	return intf.GetPayload().ID, nil
+}
+
+func (i *Interface) update(ctx context.Context, cur *current, tags []*models.NestedTag) (int64, error) {
+	log := logr.FromContext(ctx)
+
+	intf, err := i.Client.Dcim.DcimInterfacesUpdate(&dcim.DcimInterfacesUpdateParams{
+		Data:    i.writable(cur.model.(*models.Interface), tags),
+		ID:      cur.ID,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to DcimInterfacesUpdate, %w", err)
+	}
+	log.V(1).Info("updated interface", "response", intf)
+
+	return intf.GetPayload().ID, nil
+}
+
+func (i *Interface) each(ctx context.Context, opts ListOptions, fn func(netboxv1.Object) error) error {
+	log := logr.FromContext(ctx)
+
+	params := &dcim.DcimInterfacesListParams{
+		Context: ctx,
+	}
+	if i.Data.Spec.Device != "" {
+		params.Device = &i.Data.Spec.Device
+	}
+	if name := i.Data.InterfaceName(); name != "" {
+		params.Name = &name
+	}
+	if opts.Query != "" {
+		params.Q = &opts.Query
+	}
+	if opts.Site != "" {
+		id, err := i.resolveNameToID(ctx, opts.Site, "site")
+		if err != nil {
+			return err
+		}
+		site := strconv.FormatInt(id, 10)
+		params.SiteID = &site
+	}
+
+	return paginate(ctx, opts.ChunkSize, opts.Limit, func(offset, limit int64) (int, bool, error) {
+		params.Offset = &offset
+		params.Limit = &limit
+
+		intfs, err := i.Client.Dcim.DcimInterfacesList(params, i.withQuery(opts.query()))
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to DcimInterfacesList, %w", err)
+		}
+		log.V(1).Info("found interfaces", "count", intfs.Payload.Count, "offset", offset)
+
+		for _, intf := range intfs.Payload.Results {
+			if err := fn(interfaceFromModel(intf)); err != nil {
+				return 0, false, err
+			}
+		}
+
+		return len(intfs.Payload.Results), intfs.Payload.Next != nil, nil
+	})
+}
+
+// interfaceFromModel maps a Netbox interface to an Interface named after the device and the interface
+func interfaceFromModel(intf *models.Interface) *netboxv1.Interface {
+	spec := netboxv1.InterfaceSpec{
+		MgmtOnly:    intf.MgmtOnly,
+		Label:       intf.Label,
+		Description: intf.Description,
+		Tags:        tagSlugs(intf.Tags),
+	}
+	if len(spec.Tags) == 0 {
+		spec.Tags = nil
+	}
+	if intf.Device != nil && intf.Device.Name != nil {
+		spec.Device = *intf.Device.Name
+	}
+	if intf.Name != nil {
+		spec.Name = *intf.Name
+	}
+	if intf.Type != nil && intf.Type.Value != nil {
+		spec.Type = *intf.Type.Value
+	}
+	if intf.Mtu != nil {
+		spec.MTU = *intf.Mtu
+	}
+	if intf.Mode != nil && intf.Mode.Value != nil {
+		spec.Mode = *intf.Mode.Value
+	}
+	// exported VLANs are referred to by VID, the group of the VLAN is not part of the interface
+	if intf.UntaggedVlan != nil && intf.UntaggedVlan.Vid != nil {
+		spec.UntaggedVLAN = &netboxv1.VLANReference{VID: *intf.UntaggedVlan.Vid}
+	}
+	for _, vlan := range intf.TaggedVlans {
+		if vlan.Vid != nil {
+			spec.TaggedVLANs = append(spec.TaggedVLANs, netboxv1.VLANReference{VID: *vlan.Vid})
+		}
+	}
+
+	id := intf.ID
+	return &netboxv1.Interface{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       netboxv1.InterfaceKind,
+			APIVersion: netboxv1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: netboxv1.ResourceName(spec.Device, spec.Name),
+		},
+		Spec: spec,
+		Status: netboxv1.ObjectStatus{
+			ID:    &id,
+			State: netboxv1.ObjectReadyState,
+		},
+	}
+}
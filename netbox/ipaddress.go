@@ -0,0 +1,389 @@
+package netbox
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	"github.com/netbox-community/go-netbox/netbox/client/ipam"
+	"github.com/netbox-community/go-netbox/netbox/models"
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type IPAddress struct {
+	Data *netboxv1.IPAddress
+	NetboxServer
+
+	vrfID         *int64
+	tenantID      *int64
+	interfaceID   *int64
+	vmInterfaceID *int64
+}
+
+// vmInterfaceTermination is the assigned object type of addresses on virtual machine interfaces
+const vmInterfaceTermination = "virtualization.vminterface"
+
+func NewIPAddress(s NetboxServer, a *netboxv1.IPAddress) *IPAddress {
+	return &IPAddress{
+		Data:         a,
+		NetboxServer: s,
+	}
+}
+
+func (a *IPAddress) resource() netboxv1.Object {
+	return a.Data
+}
+
+func (a *IPAddress) typeName() string {
+	return "IP address"
+}
+
+func (a *IPAddress) path() string {
+	return "/ipam/ip-addresses/"
+}
+
+func (a *IPAddress) tags() []string {
+	return a.Data.Spec.Tags
+}
+
+// resolve looks up the VRF, the tenant and the interface
+func (a *IPAddress) resolve(ctx context.Context) error {
+	spec := a.Data.Spec
+
+	if spec.Interface != nil && spec.VMInterface != nil {
+		return fmt.Errorf("IP address %s can't be assigned to both a device and a VM interface", spec.Address)
+	}
+
+	if spec.VRF != "" {
+		id, err := a.lookupNameToID(ctx, spec.VRF, "vrf")
+		if err != nil {
+			return err
+		}
+		a.vrfID = &id
+	}
+
+	if spec.Tenant != "" {
+		id, err := a.resolveNameToID(ctx, spec.Tenant, "tenant")
+		if err != nil {
+			return err
+		}
+		a.tenantID = &id
+	}
+
+	if spec.Interface != nil {
+		deviceID, err := a.lookupNameToID(ctx, spec.Interface.Device, "device")
+		if err != nil {
+			return err
+		}
+		intf, err := a.findInterface(ctx, deviceID, spec.Interface.Name)
+		if err != nil {
+			return err
+		}
+		if intf == nil {
+			return &ReferenceNotFoundError{Type: "interface", Name: spec.Interface.Device + "/" + spec.Interface.Name}
+		}
+		a.interfaceID = &intf.ID
+	}
+
+	if spec.VMInterface != nil {
+		vmID, err := a.lookupNameToID(ctx, spec.VMInterface.VirtualMachine, "virtual machine")
+		if err != nil {
+			return err
+		}
+		intf, err := a.findVMInterface(ctx, vmID, spec.VMInterface.Name)
+		if err != nil {
+			return err
+		}
+		if intf == nil {
+			return &ReferenceNotFoundError{Type: "VM interface", Name: spec.VMInterface.VirtualMachine + "/" + spec.VMInterface.Name}
+		}
+		a.vmInterfaceID = &intf.ID
+	}
+	return nil
+}
+
+func (a *IPAddress) read(ctx context.Context, id int64) (*current, error) {
+	addr, err := a.Client.Ipam.IpamIPAddressesRead(&ipam.IpamIPAddressesReadParams{
+		ID:      id,
+		Context: ctx,
+	}, nil)
+	if IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to IpamIPAddressesRead, %w", err)
+	}
+
+	return ipAddressCurrent(addr.GetPayload()), nil
+}
+
+func ipAddressCurrent(addr *models.IPAddress) *current {
+	return &current{ID: addr.ID, Tags: addr.Tags, model: addr}
+}
+
+// hosts implements attached, addresses also belong to the tenant of the device or virtual
+// machine of their interface
+func (a *IPAddress) hosts(cur *current) []host {
+	var hosts []host
+	if ref := a.Data.Spec.Interface; ref != nil {
+		hosts = append(hosts, host{typ: "device", name: ref.Device})
+	}
+	if ref := a.Data.Spec.VMInterface; ref != nil {
+		hosts = append(hosts, host{typ: "virtual machine", name: ref.VirtualMachine})
+	}
+	if cur == nil {
+		return hosts
+	}
+	addr := cur.model.(*models.IPAddress)
+	if addr.AssignedObjectType == nil {
+		return hosts
+	}
+	switch *addr.AssignedObjectType {
+	case interfaceTermination:
+		hosts = append(hosts, host{typ: "device", name: terminationFromModel(addr.AssignedObjectType, addr.AssignedObject).Device})
+	case vmInterfaceTermination:
+		hosts = append(hosts, host{typ: "virtual machine", name: vmInterfaceFromAssigned(addr.AssignedObject).VirtualMachine})
+	}
+	return hosts
+}
+
+// lookup returns the address in the same VRF, or in the global table if the spec has no VRF
+func (a *IPAddress) lookup(ctx context.Context) (*current, error) {
+	result, err := a.Client.Ipam.IpamIPAddressesList(&ipam.IpamIPAddressesListParams{
+		Address: &a.Data.Spec.Address,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to IpamIPAddressesList, %w", err)
+	}
+
+	var found []*models.IPAddress
+	for _, addr := range result.Payload.Results {
+		if sameVRF(addr.Vrf, a.vrfID) {
+			found = append(found, addr)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, nil
+	case 1:
+		return ipAddressCurrent(found[0]), nil
+	default:
+		return nil, fmt.Errorf("unexpected number of IP addresses %q found: %d", a.Data.Spec.Address, len(found))
+	}
+}
+
+func (a *IPAddress) diff(cur *current) []string {
+	addr := cur.model.(*models.IPAddress)
+	spec := a.Data.Spec
+
+	changed := []string{}
+	if addr.Address == nil || *addr.Address != spec.Address {
+		changed = append(changed, "address")
+	}
+	if a.vrfID != nil && !sameVRF(addr.Vrf, a.vrfID) {
+		changed = append(changed, "vrf")
+	}
+	if spec.Status != "" && (addr.Status == nil || addr.Status.Value == nil || *addr.Status.Value != spec.Status) {
+		changed = append(changed, "status")
+	}
+	if spec.Role != "" && (addr.Role == nil || addr.Role.Value == nil || *addr.Role.Value != spec.Role) {
+		changed = append(changed, "role")
+	}
+	if spec.DNSName != "" && addr.DNSName != spec.DNSName {
+		changed = append(changed, "dns_name")
+	}
+	if spec.Description != "" && addr.Description != spec.Description {
+		changed = append(changed, "description")
+	}
+	if a.tenantID != nil && (addr.Tenant == nil || addr.Tenant.ID != *a.tenantID) {
+		changed = append(changed, "tenant")
+	}
+	if a.interfaceID != nil && !assignedTo(addr, interfaceTermination, *a.interfaceID) {
+		changed = append(changed, "interface")
+	}
+	if a.vmInterfaceID != nil && !assignedTo(addr, vmInterfaceTermination, *a.vmInterfaceID) {
+		changed = append(changed, "vm_interface")
+	}
+	return changed
+}
+
+// assignedTo returns true if the address is assigned to the object of the type with the ID
+func assignedTo(addr *models.IPAddress, objectType string, id int64) bool {
+	return addr.AssignedObjectType != nil && *addr.AssignedObjectType == objectType &&
+		addr.AssignedObjectID != nil && *addr.AssignedObjectID == id
+}
+
+func (a *IPAddress) writable(tags []*models.NestedTag) *models.WritableIPAddress {
+	spec := a.Data.Spec
+	addr := &models.WritableIPAddress{
+		Address:     &spec.Address,
+		Vrf:         a.vrfID,
+		Status:      spec.Status,
+		Role:        spec.Role,
+		DNSName:     spec.DNSName,
+		Description: spec.Description,
+		Tenant:      a.tenantID,
+		Tags:        tags,
+	}
+	if a.interfaceID != nil {
+		assigned := interfaceTermination
+		addr.AssignedObjectType = &assigned
+		addr.AssignedObjectID = a.interfaceID
+	}
+	if a.vmInterfaceID != nil {
+		assigned := vmInterfaceTermination
+		addr.AssignedObjectType = &assigned
+		addr.AssignedObjectID = a.vmInterfaceID
+	}
+	return addr
+}
+
+func (a *IPAddress) create(ctx context.Context, tags []*models.NestedTag) (int64, error) {
+	log := logr.FromContext(ctx)
+
+	addr, err := a.Client.Ipam.IpamIPAddressesCreate(&ipam.IpamIPAddressesCreateParams{
+		Data:    a.writable(tags),
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to IpamIPAddressesCreate, %w", err)
+	}
+	log.V(1).Info("created IP address", "response", addr)
+
+	return addr.GetPayload().ID, nil
+}
+
+func (a *IPAddress) update(ctx context.Context, cur *current, tags []*models.NestedTag) (int64, error) {
+	log := logr.FromContext(ctx)
+
+	addr, err := a.Client.Ipam.IpamIPAddressesUpdate(&ipam.IpamIPAddressesUpdateParams{
+		Data:    a.writable(tags),
+		ID:      cur.ID,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to IpamIPAddressesUpdate, %w", err)
+	}
+	log.V(1).Info("updated IP address", "response", addr)
+
+	return addr.GetPayload().ID, nil
+}
+
+func (a *IPAddress) each(ctx context.Context, opts ListOptions, fn func(netboxv1.Object) error) error {
+	log := logr.FromContext(ctx)
+
+	params := &ipam.IpamIPAddressesListParams{
+		Context: ctx,
+	}
+	if a.Data.Spec.Address != "" {
+		params.Address = &a.Data.Spec.Address
+	}
+	if opts.Status != "" {
+		params.Status = &opts.Status
+	}
+	if opts.Query != "" {
+		params.Q = &opts.Query
+	}
+	if opts.Tenant != "" {
+		id, err := a.resolveNameToID(ctx, opts.Tenant, "tenant")
+		if err != nil {
+			return err
+		}
+		tenant := strconv.FormatInt(id, 10)
+		params.TenantID = &tenant
+	}
+
+	return paginate(ctx, opts.ChunkSize, opts.Limit, func(offset, limit int64) (int, bool, error) {
+		params.Offset = &offset
+		params.Limit = &limit
+
+		addrs, err := a.Client.Ipam.IpamIPAddressesList(params, a.withQuery(opts.query()))
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to IpamIPAddressesList, %w", err)
+		}
+		log.V(1).Info("found IP addresses", "count", addrs.Payload.Count, "offset", offset)
+
+		for _, addr := range addrs.Payload.Results {
+			if err := fn(ipAddressFromModel(addr)); err != nil {
+				return 0, false, err
+			}
+		}
+
+		return len(addrs.Payload.Results), addrs.Payload.Next != nil, nil
+	})
+}
+
+// vmInterfaceFromAssigned maps the nested VM interface an address is assigned to
+func vmInterfaceFromAssigned(data interface{}) netboxv1.VMInterfaceReference {
+	ref := netboxv1.VMInterfaceReference{}
+	object, _ := data.(map[string]interface{})
+	if object == nil {
+		return ref
+	}
+	ref.Name, _ = object["name"].(string)
+	if vm, ok := object["virtual_machine"].(map[string]interface{}); ok {
+		ref.VirtualMachine, _ = vm["name"].(string)
+	}
+	return ref
+}
+
+// ipAddressFromModel maps a Netbox IP address to an IPAddress, which is named after the DNS name or the address
+func ipAddressFromModel(addr *models.IPAddress) *netboxv1.IPAddress {
+	spec := netboxv1.IPAddressSpec{
+		DNSName:     addr.DNSName,
+		Description: addr.Description,
+		Tags:        tagSlugs(addr.Tags),
+	}
+	if len(spec.Tags) == 0 {
+		spec.Tags = nil
+	}
+	if addr.Address != nil {
+		spec.Address = *addr.Address
+	}
+	if addr.Vrf != nil && addr.Vrf.Name != nil {
+		spec.VRF = *addr.Vrf.Name
+	}
+	if addr.Status != nil && addr.Status.Value != nil {
+		spec.Status = *addr.Status.Value
+	}
+	if addr.Role != nil && addr.Role.Value != nil {
+		spec.Role = *addr.Role.Value
+	}
+	if addr.Tenant != nil && addr.Tenant.Name != nil {
+		spec.Tenant = *addr.Tenant.Name
+	}
+	if addr.AssignedObjectType != nil && *addr.AssignedObjectType == interfaceTermination {
+		if t := terminationFromModel(addr.AssignedObjectType, addr.AssignedObject); t.Interface != "" {
+			spec.Interface = &netboxv1.InterfaceReference{Device: t.Device, Name: t.Interface}
+		}
+	}
+	if addr.AssignedObjectType != nil && *addr.AssignedObjectType == vmInterfaceTermination {
+		if ref := vmInterfaceFromAssigned(addr.AssignedObject); ref.Name != "" {
+			spec.VMInterface = &ref
+		}
+	}
+
+	name := spec.DNSName
+	if name == "" {
+		name = spec.Address
+	}
+
+	id := addr.ID
+	return &netboxv1.IPAddress{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       netboxv1.IPAddressKind,
+			APIVersion: netboxv1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: netboxv1.ResourceName(name),
+		},
+		Spec: spec,
+		Status: netboxv1.ObjectStatus{
+			ID:    &id,
+			State: netboxv1.ObjectReadyState,
+		},
+	}
+}
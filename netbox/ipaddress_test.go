@@ -0,0 +1,92 @@
+package netbox
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/go-logr/logr"
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeIPAddressNetbox serves device leaf-01 with interface lo and no IP addresses.
+// Created addresses get ID 20
+func fakeIPAddressNetbox(t *testing.T) *fakeNetbox {
+	return newFakeNetbox(t,
+		onGet("/api/dcim/devices/", page(`{"id": 5, "name": "leaf-01"}`), "name", "leaf-01"),
+		onGet("/api/dcim/devices/", page()),
+		onGet("/api/dcim/interfaces/", page(`{"id": 9, "name": "lo"}`), "device_id", "5", "name", "lo"),
+		onGet("/api/dcim/interfaces/", page()),
+		onGet("/api/ipam/ip-addresses/", page()),
+		onPost("/api/ipam/ip-addresses/", `{"id": 20}`),
+	)
+}
+
+func TestIPAddressApply(t *testing.T) {
+	ctx := logr.NewContext(context.Background(), logr.Discard())
+
+	tests := []struct {
+		name    string
+		intf    netboxv1.InterfaceReference
+		check   func(error) bool
+		created bool
+	}{
+		{
+			name:  "missing device",
+			intf:  netboxv1.InterfaceReference{Device: "leaf-02", Name: "lo"},
+			check: IsReferenceNotFound,
+		},
+		{
+			name:  "missing interface",
+			intf:  netboxv1.InterfaceReference{Device: "leaf-01", Name: "lo0"},
+			check: IsReferenceNotFound,
+		},
+		{
+			name:    "existing interface",
+			intf:    netboxv1.InterfaceReference{Device: "leaf-01", Name: "lo"},
+			check:   func(err error) bool { return err == nil },
+			created: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := fakeIPAddressNetbox(t)
+
+			intf := tt.intf
+			addr := &netboxv1.IPAddress{
+				ObjectMeta: metav1.ObjectMeta{Name: "leaf-01-lo"},
+				Spec: netboxv1.IPAddressSpec{
+					Address:   "10.0.0.1/32",
+					Role:      "loopback",
+					Interface: &intf,
+				},
+			}
+
+			_, err := f.netbox().Apply(ctx, addr)
+			if !tt.check(err) {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			created := f.written(http.MethodPost, "/api/ipam/ip-addresses/")
+			if !tt.created {
+				if len(created) > 0 {
+					t.Errorf("unexpected IP address created")
+				}
+				return
+			}
+
+			if len(created) != 1 {
+				t.Fatalf("expected one IP address created, got %v", created)
+			}
+			data := created[0]
+			if data["assigned_object_type"] != interfaceTermination || data["assigned_object_id"] != float64(9) || data["role"] != "loopback" {
+				t.Errorf("unexpected IP address %v", data)
+			}
+			if addr.Status.ID == nil || *addr.Status.ID != 20 || addr.Status.State != netboxv1.ObjectReadyState {
+				t.Errorf("unexpected status %+v", addr.Status)
+			}
+		})
+	}
+}
@@ -0,0 +1,274 @@
+package netbox
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/netbox-community/go-netbox/netbox/client/dcim"
+	"github.com/netbox-community/go-netbox/netbox/models"
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type Location struct {
+	Data *netboxv1.Location
+	NetboxServer
+
+	siteID   int64
+	parentID *int64
+}
+
+func NewLocation(s NetboxServer, l *netboxv1.Location) *Location {
+	return &Location{
+		Data:         l,
+		NetboxServer: s,
+	}
+}
+
+func (l *Location) resource() netboxv1.Object {
+	return l.Data
+}
+
+func (l *Location) typeName() string {
+	return "location"
+}
+
+func (l *Location) path() string {
+	return "/dcim/locations/"
+}
+
+func (l *Location) tags() []string {
+	return nil
+}
+
+// untaggable implements untaggable, Netbox 3.0 locations have no tags
+func (l *Location) untaggable() {}
+
+func (l *Location) resolve(ctx context.Context) error {
+	siteID, err := l.resolveNameToID(ctx, l.Data.Spec.Site, "site")
+	if err != nil {
+		return err
+	}
+	l.siteID = siteID
+
+	if l.Data.Spec.Parent != "" {
+		parent, err := l.findLocation(ctx, siteID, l.Data.Spec.Parent)
+		if err != nil {
+			return err
+		}
+		if parent == nil {
+			return &ReferenceNotFoundError{Type: "location", Name: l.Data.Spec.Site + "/" + l.Data.Spec.Parent}
+		}
+		l.parentID = &parent.ID
+	}
+	return nil
+}
+
+// findLocation returns the location of the site with the name, nil if it doesn't exist
+func (s *NetboxServer) findLocation(ctx context.Context, siteID int64, name string) (*models.Location, error) {
+	site := strconv.FormatInt(siteID, 10)
+	result, err := s.Client.Dcim.DcimLocationsList(&dcim.DcimLocationsListParams{
+		SiteID:  &site,
+		Name:    &name,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to DcimLocationsList, %w", err)
+	}
+	if *result.Payload.Count == 0 {
+		return nil, nil
+	}
+	if *result.Payload.Count != 1 {
+		return nil, fmt.Errorf("unexpected number of locations %q found: %d", name, *result.Payload.Count)
+	}
+	return result.Payload.Results[0], nil
+}
+
+func (l *Location) read(ctx context.Context, id int64) (*current, error) {
+	location, err := l.Client.Dcim.DcimLocationsRead(&dcim.DcimLocationsReadParams{
+		ID:      id,
+		Context: ctx,
+	}, nil)
+	if IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to DcimLocationsRead, %w", err)
+	}
+
+	return locationCurrent(location.GetPayload()), nil
+}
+
+func locationCurrent(location *models.Location) *current {
+	return &current{ID: location.ID, Created: hasCreatedField(location.CustomFields), model: location}
+}
+
+// lookup returns the location of the site with the same name
+func (l *Location) lookup(ctx context.Context) (*current, error) {
+	location, err := l.findLocation(ctx, l.siteID, l.Data.LocationName())
+	if err != nil || location == nil {
+		return nil, err
+	}
+	return locationCurrent(location), nil
+}
+
+func (l *Location) slug() string {
+	if l.Data.Spec.Slug != "" {
+		return l.Data.Spec.Slug
+	}
+	return slugify(l.Data.LocationName())
+}
+
+var invalidSlugChars = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+// slugify turns a name into a slug the same way as the Netbox web UI, e.g. "Row 1" into "row-1"
+func slugify(name string) string {
+	return strings.Trim(invalidSlugChars.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+func (l *Location) diff(cur *current) []string {
+	location := cur.model.(*models.Location)
+	spec := l.Data.Spec
+
+	changed := []string{}
+	if location.Name == nil || *location.Name != l.Data.LocationName() {
+		changed = append(changed, "name")
+	}
+	if location.Slug == nil || *location.Slug != l.slug() {
+		changed = append(changed, "slug")
+	}
+	if location.Site == nil || location.Site.ID != l.siteID {
+		changed = append(changed, "site")
+	}
+	if l.parentID != nil && (location.Parent == nil || location.Parent.ID != *l.parentID) {
+		changed = append(changed, "parent")
+	}
+	if spec.Description != "" && location.Description != spec.Description {
+		changed = append(changed, "description")
+	}
+	return changed
+}
+
+func (l *Location) writable() *models.WritableLocation {
+	name, slug := l.Data.LocationName(), l.slug()
+	return &models.WritableLocation{
+		Name:        &name,
+		Slug:        &slug,
+		Site:        &l.siteID,
+		Parent:      l.parentID,
+		Description: l.Data.Spec.Description,
+	}
+}
+
+func (l *Location) create(ctx context.Context, _ []*models.NestedTag) (int64, error) {
+	log := logr.FromContext(ctx)
+
+	data := l.writable()
+	data.CustomFields = createdCustomFields()
+	location, err := l.Client.Dcim.DcimLocationsCreate(&dcim.DcimLocationsCreateParams{
+		Data:    data,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to DcimLocationsCreate, %w", err)
+	}
+	log.V(1).Info("created location", "response", location)
+
+	return location.GetPayload().ID, nil
+}
+
+func (l *Location) update(ctx context.Context, cur *current, _ []*models.NestedTag) (int64, error) {
+	log := logr.FromContext(ctx)
+
+	location, err := l.Client.Dcim.DcimLocationsUpdate(&dcim.DcimLocationsUpdateParams{
+		Data:    l.writable(),
+		ID:      cur.ID,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to DcimLocationsUpdate, %w", err)
+	}
+	log.V(1).Info("updated location", "response", location)
+
+	return location.GetPayload().ID, nil
+}
+
+func (l *Location) each(ctx context.Context, opts ListOptions, fn func(netboxv1.Object) error) error {
+	log := logr.FromContext(ctx)
+
+	params := &dcim.DcimLocationsListParams{
+		Context: ctx,
+	}
+	if name := l.Data.LocationName(); name != "" {
+		params.Name = &name
+	}
+	if opts.Query != "" {
+		params.Q = &opts.Query
+	}
+	if opts.Site != "" {
+		id, err := l.resolveNameToID(ctx, opts.Site, "site")
+		if err != nil {
+			return err
+		}
+		site := strconv.FormatInt(id, 10)
+		params.SiteID = &site
+	}
+
+	return paginate(ctx, opts.ChunkSize, opts.Limit, func(offset, limit int64) (int, bool, error) {
+		params.Offset = &offset
+		params.Limit = &limit
+
+		locations, err := l.Client.Dcim.DcimLocationsList(params, l.withQuery(opts.query()))
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to DcimLocationsList, %w", err)
+		}
+		log.V(1).Info("found locations", "count", locations.Payload.Count, "offset", offset)
+
+		for _, location := range locations.Payload.Results {
+			if err := fn(locationFromModel(location)); err != nil {
+				return 0, false, err
+			}
+		}
+
+		return len(locations.Payload.Results), locations.Payload.Next != nil, nil
+	})
+}
+
+// locationFromModel maps a Netbox location to a Location named after the slug of the location
+func locationFromModel(location *models.Location) *netboxv1.Location {
+	spec := netboxv1.LocationSpec{
+		Description: location.Description,
+	}
+	if location.Site != nil && location.Site.Name != nil {
+		spec.Site = *location.Site.Name
+	}
+	if location.Name != nil {
+		spec.Name = *location.Name
+	}
+	if location.Slug != nil {
+		spec.Slug = *location.Slug
+	}
+	if location.Parent != nil && location.Parent.Name != nil {
+		spec.Parent = *location.Parent.Name
+	}
+
+	id := location.ID
+	return &netboxv1.Location{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       netboxv1.LocationKind,
+			APIVersion: netboxv1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: netboxv1.ResourceName(spec.Slug),
+		},
+		Spec: spec,
+		Status: netboxv1.ObjectStatus{
+			ID:    &id,
+			State: netboxv1.ObjectReadyState,
+		},
+	}
+}
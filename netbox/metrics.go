@@ -0,0 +1,82 @@
+package netbox
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	apiRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "netbox_api_requests_total",
+			Help: "Number of Netbox API requests by endpoint, method and status code",
+		},
+		[]string{"endpoint", "method", "code"},
+	)
+
+	apiRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "netbox_api_request_duration_seconds",
+			Help:    "Latency of Netbox API requests by endpoint and method",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"endpoint", "method"},
+	)
+
+	resolverCacheRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "netbox_resolver_cache_requests_total",
+			Help: "Number of name to ID lookups by object type and result (hit or miss)",
+		},
+		[]string{"type", "result"},
+	)
+)
+
+// Collectors returns the Netbox client metrics, so that they can be registered
+// with a Prometheus registry, e.g. the controller-runtime metrics.Registry
+func Collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		apiRequests,
+		apiRequestDuration,
+		resolverCacheRequests,
+	}
+}
+
+var idSegment = regexp.MustCompile(`/[0-9]+/`)
+
+// endpoint replaces object IDs in the request path to keep the label cardinality low
+func endpoint(path string) string {
+	return idSegment.ReplaceAllString(path, "/{id}/")
+}
+
+type metricsTransport struct {
+	next http.RoundTripper
+}
+
+// NewMetricsTransport wraps an http.RoundTripper and records the count and latency of every request
+func NewMetricsTransport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &metricsTransport{next: next}
+}
+
+func (t *metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+
+	ep := endpoint(req.URL.Path)
+	apiRequestDuration.WithLabelValues(ep, req.Method).Observe(time.Since(start).Seconds())
+
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	apiRequests.WithLabelValues(ep, req.Method, code).Inc()
+
+	return resp, err
+}
@@ -0,0 +1,18 @@
+package netbox
+
+import "testing"
+
+func TestEndpoint(t *testing.T) {
+	tests := map[string]string{
+		"/api/dcim/devices/":           "/api/dcim/devices/",
+		"/api/dcim/devices/42/":        "/api/dcim/devices/{id}/",
+		"/netbox/api/ipam/vlans/7/":    "/netbox/api/ipam/vlans/{id}/",
+		"/api/dcim/racks/3/elevation/": "/api/dcim/racks/{id}/elevation/",
+	}
+
+	for path, want := range tests {
+		if got := endpoint(path); got != want {
+			t.Errorf("endpoint(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
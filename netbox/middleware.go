@@ -0,0 +1,226 @@
+package netbox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// RetryOptions configures the rate limiting and retry middleware
+// that wraps every request sent to the Netbox API
+type RetryOptions struct {
+	// QPS is the sustained rate of requests per second, 0 disables rate limiting
+	QPS float64 `json:"qps,omitempty"`
+	// Burst is the maximum number of requests sent at once
+	Burst int `json:"burst,omitempty"`
+	// MaxRetries is the number of retries after the first attempt
+	MaxRetries int `json:"maxRetries,omitempty"`
+	// MinBackoff and MaxBackoff bound the exponential backoff between retries
+	MinBackoff time.Duration `json:"minBackoff,omitempty"`
+	MaxBackoff time.Duration `json:"maxBackoff,omitempty"`
+	// AttemptTimeout bounds each attempt including reading the response body, 0 disables it.
+	// Backoff waits are not counted, so retries don't eat into the timeout of the request
+	AttemptTimeout time.Duration `json:"attemptTimeout,omitempty"`
+}
+
+// DefaultRetryOptions returns the options used when none are provided
+func DefaultRetryOptions() RetryOptions {
+	return RetryOptions{
+		QPS:        10,
+		Burst:      20,
+		MaxRetries: 4,
+		MinBackoff: time.Millisecond * 250,
+		MaxBackoff: time.Second * 8,
+	}
+}
+
+type retryTransport struct {
+	next    http.RoundTripper
+	limiter *rate.Limiter
+	opts    RetryOptions
+}
+
+// NewRetryTransport wraps an http.RoundTripper with a token-bucket rate limiter and
+// retries 429, 5xx and connection errors with exponential backoff, honoring Retry-After up to
+// MaxBackoff. Non-idempotent requests (POST, PATCH) are only retried when Netbox has not processed them,
+// i.e. on 429 or when the connection could not be established
+func NewRetryTransport(next http.RoundTripper, opts RetryOptions) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	limiter := rate.NewLimiter(rate.Inf, 0)
+	if opts.QPS > 0 {
+		burst := opts.Burst
+		if burst < 1 {
+			burst = 1
+		}
+		limiter = rate.NewLimiter(rate.Limit(opts.QPS), burst)
+	}
+
+	return &retryTransport{
+		next:    next,
+		limiter: limiter,
+		opts:    opts,
+	}
+}
+
+// RoundTrip sends every attempt as a clone of req, which is left unchanged. Bodies that can't be
+// read again with GetBody are buffered, so that they can be sent by every attempt
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	getBody := req.GetBody
+	buffered := false
+	if req.Body != nil && req.Body != http.NoBody && getBody == nil {
+		body, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		getBody = func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(bytes.NewReader(body)), nil
+		}
+		buffered = true
+	}
+
+	for attempt := 0; ; attempt++ {
+		if err := t.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+
+		// the first attempt sends the body of req unless it was buffered
+		attemptReq := req.Clone(ctx)
+		attemptReq.GetBody = getBody
+		if getBody != nil && (attempt > 0 || buffered) {
+			body, err := getBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq.Body = body
+		}
+
+		resp, err := t.roundTrip(attemptReq)
+		if attempt >= t.opts.MaxRetries || !shouldRetry(attemptReq, resp, err) {
+			return resp, err
+		}
+
+		wait := t.backoff(attempt)
+		if resp != nil {
+			if after, ok := retryAfter(resp); ok {
+				wait = after
+				if t.opts.MaxBackoff > 0 && wait > t.opts.MaxBackoff {
+					wait = t.opts.MaxBackoff
+				}
+			}
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// roundTrip sends a single attempt, cancelled after AttemptTimeout. The timeout keeps
+// running until the response body is closed
+func (t *retryTransport) roundTrip(req *http.Request) (*http.Response, error) {
+	if t.opts.AttemptTimeout <= 0 {
+		return t.next.RoundTrip(req)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), t.opts.AttemptTimeout)
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelBody releases the context of an attempt when the response body is closed
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+func (t *retryTransport) backoff(attempt int) time.Duration {
+	wait := t.opts.MinBackoff << uint(attempt)
+	if wait < t.opts.MinBackoff || (t.opts.MaxBackoff > 0 && wait > t.opts.MaxBackoff) {
+		wait = t.opts.MaxBackoff
+	}
+	return wait
+}
+
+func shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		if errors.Is(err, req.Context().Err()) {
+			return false
+		}
+		return isIdempotent(req.Method) || isDialError(err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return true
+	case resp.StatusCode >= 500:
+		return isIdempotent(req.Method)
+	}
+	return false
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// isDialError returns true if the request never reached the server
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// retryAfter parses the Retry-After header, which is either a number of seconds or an HTTP date
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,170 @@
+package netbox
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testRetryOptions() RetryOptions {
+	return RetryOptions{
+		MaxRetries: 3,
+		MinBackoff: time.Millisecond,
+		MaxBackoff: time.Millisecond * 5,
+	}
+}
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		statuses []int
+		wantCode int
+		wantHits int32
+	}{
+		{name: "get retried on 5xx", method: http.MethodGet, statuses: []int{502, 503, 200}, wantCode: 200, wantHits: 3},
+		{name: "post not retried on 5xx", method: http.MethodPost, statuses: []int{502, 200}, wantCode: 502, wantHits: 1},
+		{name: "post retried on 429", method: http.MethodPost, statuses: []int{429, 201}, wantCode: 201, wantHits: 2},
+		{name: "4xx not retried", method: http.MethodGet, statuses: []int{404, 200}, wantCode: 404, wantHits: 1},
+		{name: "retries exhausted", method: http.MethodDelete, statuses: []int{500, 500, 500, 500, 500}, wantCode: 500, wantHits: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var hits int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := atomic.AddInt32(&hits, 1)
+				if r.Method == http.MethodPost {
+					body, err := ioutil.ReadAll(r.Body)
+					if err != nil || string(body) != "payload" {
+						t.Errorf("unexpected body on attempt %d: %q", n, body)
+					}
+				}
+				w.WriteHeader(tt.statuses[n-1])
+			}))
+			defer srv.Close()
+
+			client := &http.Client{Transport: NewRetryTransport(nil, testRetryOptions())}
+			req, err := http.NewRequest(tt.method, srv.URL, strings.NewReader("payload"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.wantCode {
+				t.Errorf("got status %d, want %d", resp.StatusCode, tt.wantCode)
+			}
+			if hits != tt.wantHits {
+				t.Errorf("got %d requests, want %d", hits, tt.wantHits)
+			}
+		})
+	}
+}
+
+func TestRetryTransportKeepsRequest(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&hits, 1)
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil || string(body) != "payload" {
+			t.Errorf("unexpected body on attempt %d: %q", n, body)
+		}
+		if n == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer srv.Close()
+
+	// a body without GetBody is buffered by the transport, not by the request
+	req, err := http.NewRequest(http.MethodPost, srv.URL, ioutil.NopCloser(strings.NewReader("payload")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := req.Body
+
+	resp, err := NewRetryTransport(nil, testRetryOptions()).RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if hits != 2 || resp.StatusCode != http.StatusOK {
+		t.Errorf("got status %d after %d requests, want 200 after 2", resp.StatusCode, hits)
+	}
+	if req.Body != body || req.GetBody != nil {
+		t.Errorf("the body of the request was replaced")
+	}
+	if resp.Request == req {
+		t.Errorf("the request was sent instead of a clone")
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	resp := &http.Response{Header: http.Header{}}
+	if _, ok := retryAfter(resp); ok {
+		t.Errorf("expected no Retry-After")
+	}
+
+	resp.Header.Set("Retry-After", "3")
+	if wait, ok := retryAfter(resp); !ok || wait != time.Second*3 {
+		t.Errorf("got %v, want 3s", wait)
+	}
+
+	resp.Header.Set("Retry-After", time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat))
+	if wait, ok := retryAfter(resp); !ok || wait != 0 {
+		t.Errorf("got %v, want 0 for a date in the past", wait)
+	}
+}
+
+func TestRetryTransportAttemptTimeout(t *testing.T) {
+	var hits int32
+	release := make(chan struct{})
+	defer close(release)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&hits, 1) {
+		case 1:
+			// the first attempt hangs until it times out
+			select {
+			case <-r.Context().Done():
+			case <-release:
+			}
+		case 2:
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.Write([]byte("ok"))
+		}
+	}))
+	defer srv.Close()
+
+	opts := testRetryOptions()
+	opts.AttemptTimeout = time.Millisecond * 100
+	client := &http.Client{Transport: NewRetryTransport(nil, opts)}
+
+	start := time.Now()
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil || string(body) != "ok" {
+		t.Errorf("unexpected body %q: %v", body, err)
+	}
+
+	if hits != 3 {
+		t.Errorf("got %d requests, want 3", hits)
+	}
+	// Retry-After is clamped to MaxBackoff
+	if elapsed := time.Since(start); elapsed > time.Second*5 {
+		t.Errorf("request took %s", elapsed)
+	}
+}
@@ -9,9 +9,15 @@ import (
 	"time"
 
 	"github.com/go-logr/logr"
-	"github.com/netbox-community/go-netbox/netbox"
+	"github.com/go-openapi/runtime"
+	runtimeclient "github.com/go-openapi/runtime/client"
+	"github.com/go-openapi/strfmt"
 	netboxClient "github.com/netbox-community/go-netbox/netbox/client"
+	"github.com/netbox-community/go-netbox/netbox/client/circuits"
 	"github.com/netbox-community/go-netbox/netbox/client/dcim"
+	"github.com/netbox-community/go-netbox/netbox/client/ipam"
+	"github.com/netbox-community/go-netbox/netbox/client/tenancy"
+	"github.com/netbox-community/go-netbox/netbox/client/virtualization"
 	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
 	"github.com/sirupsen/logrus"
 )
@@ -23,17 +29,113 @@ const (
 	UnsetAction Action = "unset"
 )
 
+// Operation is the outcome of applying an object to Netbox
+type Operation string
+
+const (
+	OperationCreated   Operation = "created"
+	OperationUpdated   Operation = "updated"
+	OperationUnchanged Operation = "unchanged"
+)
+
+// Result describes what Apply has done in Netbox
+type Result struct {
+	Operation Operation
+	// Changed lists the fields that were updated
+	Changed []string
+}
+
 type NetboxServer struct {
 	Client *netboxClient.NetBoxAPI
+	cache  *resolverCache
+	auth   runtime.ClientAuthInfoWriter
+}
+
+type ServerOption func(o *serverOptions)
+
+type serverOptions struct {
+	transport TransportOptions
+	retry     RetryOptions
+	cacheTTL  time.Duration
 }
 
-func NewNetboxServer(url, token string) *NetboxServer {
+// WithTransport sets the TLS, proxy and timeout options of the Netbox client
+func WithTransport(t TransportOptions) ServerOption {
+	return func(o *serverOptions) {
+		o.transport = t
+	}
+}
+
+// WithRetry sets the rate limiting and retry options of the Netbox client
+func WithRetry(r RetryOptions) ServerOption {
+	return func(o *serverOptions) {
+		o.retry = r
+	}
+}
+
+// WithCacheTTL sets how long the IDs of referenced objects are cached, 0 disables caching
+func WithCacheTTL(ttl time.Duration) ServerOption {
+	return func(o *serverOptions) {
+		o.cacheTTL = ttl
+	}
+}
+
+// NewNetboxServer creates a Netbox API client for the server, which can be either
+// a full URL or a host[:port] (plain HTTP is assumed in that case)
+func NewNetboxServer(server, token string, opts ...ServerOption) (*NetboxServer, error) {
+	o := &serverOptions{
+		retry:    DefaultRetryOptions(),
+		cacheTTL: defaultCacheTTL,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	scheme, host, basePath, err := parseServer(server)
+	if err != nil {
+		return nil, err
+	}
+
+	httpC, err := o.transport.HTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	// the client timeout would cover the backoff between retries too, so it is moved
+	// into the retry transport and applied to each attempt instead
+	if o.retry.AttemptTimeout == 0 {
+		o.retry.AttemptTimeout = httpC.Timeout
+	}
+	httpC.Timeout = 0
+	httpC.Transport = NewRetryTransport(NewMetricsTransport(httpC.Transport), o.retry)
+
+	t := runtimeclient.NewWithClient(host, basePath, []string{scheme}, httpC)
+	t.DefaultAuthentication = runtimeclient.APIKeyAuth("Authorization", "header", "Token "+token)
+
 	return &NetboxServer{
-		Client: netbox.NewNetboxWithAPIKey(url, token),
+		Client: netboxClient.New(t, strfmt.Default),
+		cache:  newResolverCache(o.cacheTTL),
+		auth:   t.DefaultAuthentication,
+	}, nil
+}
+
+// withQuery returns request credentials that also add extra query parameters to the request.
+// This is used for filters that go-netbox does not expose, e.g. custom fields
+func (s *NetboxServer) withQuery(query url.Values) runtime.ClientAuthInfoWriter {
+	if len(query) == 0 {
+		return nil
 	}
+
+	return runtimeclient.Compose(s.auth, runtime.ClientAuthInfoWriterFunc(func(r runtime.ClientRequest, _ strfmt.Registry) error {
+		for k, v := range query {
+			if err := r.SetQueryParam(k, v...); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
 }
 
-func (s *NetboxServer) Apply(ctx context.Context, object interface{}) error {
+func (s *NetboxServer) Apply(ctx context.Context, object interface{}) (*Result, error) {
 	log := logr.FromContext(ctx)
 
 	switch o := object.(type) {
@@ -41,10 +143,14 @@ func (s *NetboxServer) Apply(ctx context.Context, object interface{}) error {
 		log.V(1).Info("identified type: Device")
 		return NewDevice(*s, o).Apply(ctx)
 	default:
+		if obj := s.object(o); obj != nil {
+			log.V(1).Info("identified type", "type", obj.typeName())
+			return s.lifecycle(obj).apply(ctx)
+		}
 		log.V(1).Info("identified type: default")
 		log.Error(fmt.Errorf("unknown object type"), fmt.Sprintf("%T", o))
 	}
-	return nil
+	return &Result{Operation: OperationUnchanged}, nil
 }
 
 func (s *NetboxServer) Delete(ctx context.Context, object interface{}) error {
@@ -55,19 +161,104 @@ func (s *NetboxServer) Delete(ctx context.Context, object interface{}) error {
 		log.V(1).Info("identified type: Device")
 		return NewDevice(*s, o).Delete(ctx)
 	default:
+		if obj := s.object(o); obj != nil {
+			log.V(1).Info("identified type", "type", obj.typeName())
+			return s.lifecycle(obj).delete(ctx)
+		}
 		log.V(1).Info("identified type: device")
 		log.Error(fmt.Errorf("unknown object type"), fmt.Sprintf("%T", o))
 	}
 	return nil
 }
 
-func (s *NetboxServer) Get(ctx context.Context, object interface{}) ([]netboxv1.Device, error) {
+// Orphan stops managing the object without removing it from Netbox
+func (s *NetboxServer) Orphan(ctx context.Context, object interface{}) error {
+	log := logr.FromContext(ctx)
+
+	switch o := object.(type) {
+	case *netboxv1.Device:
+		log.V(1).Info("identified type: Device")
+		return NewDevice(*s, o).Orphan(ctx)
+	default:
+		if obj := s.object(o); obj != nil {
+			log.V(1).Info("identified type", "type", obj.typeName())
+			return s.lifecycle(obj).orphan(ctx)
+		}
+		log.V(1).Info("identified type: default")
+		log.Error(fmt.Errorf("unknown object type"), fmt.Sprintf("%T", o))
+	}
+	return nil
+}
+
+// Untaggable returns true for the kinds without tags, whose Netbox objects carry the
+// CreatedFieldName custom field instead of the managed tag
+func (s *NetboxServer) Untaggable(object interface{}) bool {
+	_, ok := s.object(object).(untaggable)
+	return ok
+}
+
+// Validate checks the object against Netbox without changing it
+func (s *NetboxServer) Validate(ctx context.Context, object interface{}) error {
+	log := logr.FromContext(ctx)
+
+	switch o := object.(type) {
+	case *netboxv1.Device:
+		log.V(1).Info("identified type: Device")
+		return NewDevice(*s, o).Validate(ctx)
+	default:
+		if obj := s.object(o); obj != nil {
+			log.V(1).Info("identified type", "type", obj.typeName())
+			return s.lifecycle(obj).validate(ctx)
+		}
+		log.V(1).Info("identified type: default")
+		log.Error(fmt.Errorf("unknown object type"), fmt.Sprintf("%T", o))
+	}
+	return nil
+}
+
+// ListOptions narrows down the objects returned by Get
+type ListOptions struct {
+	// Site is the name of the site the objects belong to
+	Site string
+	// Role is the name of the device role
+	Role string
+	// DeviceType is the model of the device type
+	DeviceType string
+	// Tenant is the name of the tenant the objects belong to
+	Tenant string
+	// Status is the Netbox status value, e.g. active or planned
+	Status string
+	// Query is a free-text search, same as the search box in the web UI
+	Query string
+	// Tags are the slugs of tags assigned to the objects
+	Tags []string
+	// CustomFields match the values of custom fields
+	CustomFields map[string]string
+	// Limit is the maximum number of objects returned, 0 returns all objects
+	Limit int64
+	// ChunkSize is the number of objects requested per page
+	ChunkSize int64
+}
+
+// query returns the filters that are not part of the generated list parameters
+func (o ListOptions) query() url.Values {
+	query := url.Values{}
+	for _, tag := range o.Tags {
+		query.Add("tag", tag)
+	}
+	for k, v := range o.CustomFields {
+		query.Add("cf_"+k, v)
+	}
+	return query
+}
+
+func (s *NetboxServer) Get(ctx context.Context, object interface{}, opts ListOptions) ([]netboxv1.Device, error) {
 	log := logr.FromContext(ctx)
 
 	switch o := object.(type) {
 	case *netboxv1.Device:
 		log.V(1).Info("identified type: Device")
-		return NewDevice(*s, o).Get(ctx)
+		return NewDevice(*s, o).Get(ctx, opts)
 	default:
 		log.V(1).Info("identified type: default")
 		log.Error(fmt.Errorf("unknown object type"), fmt.Sprintf("%T", o))
@@ -75,7 +266,48 @@ func (s *NetboxServer) Get(ctx context.Context, object interface{}) ([]netboxv1.
 	return []netboxv1.Device{}, nil
 }
 
+// Each streams the objects matching the kind of object from Netbox, calling fn for each one of them
+func (s *NetboxServer) Each(ctx context.Context, object interface{}, opts ListOptions, fn func(interface{}) error) error {
+	log := logr.FromContext(ctx)
+
+	switch o := object.(type) {
+	case *netboxv1.Device:
+		log.V(1).Info("identified type: Device")
+		return NewDevice(*s, o).Each(ctx, opts, func(d netboxv1.Device) error {
+			return fn(d)
+		})
+	default:
+		if obj := s.object(o); obj != nil {
+			log.V(1).Info("identified type", "type", obj.typeName())
+			return obj.each(ctx, opts, func(o netboxv1.Object) error {
+				return fn(o)
+			})
+		}
+		log.V(1).Info("identified type: default")
+		log.Error(fmt.Errorf("unknown object type"), fmt.Sprintf("%T", o))
+	}
+	return nil
+}
+
 func (s *NetboxServer) resolveNameToID(ctx context.Context, name, t string) (int64, error) {
+	if s.cache == nil {
+		return s.lookupNameToID(ctx, name, t)
+	}
+
+	if id, ok := s.cache.get(t, name); ok {
+		return id, nil
+	}
+
+	id, err := s.lookupNameToID(ctx, name, t)
+	if err != nil {
+		return id, err
+	}
+	s.cache.set(t, name, id)
+
+	return id, nil
+}
+
+func (s *NetboxServer) lookupNameToID(ctx context.Context, name, t string) (int64, error) {
 
 	switch t {
 	case "role":
@@ -86,6 +318,9 @@ func (s *NetboxServer) resolveNameToID(ctx context.Context, name, t string) (int
 		if err != nil {
 			return -1, err
 		}
+		if *roles.GetPayload().Count == 0 {
+			return -1, &ReferenceNotFoundError{Type: "role", Name: name}
+		}
 		if *roles.GetPayload().Count != 1 {
 			return -1, fmt.Errorf("unexpected number of roles %q found: %d", name, *roles.GetPayload().Count)
 		}
@@ -98,6 +333,9 @@ func (s *NetboxServer) resolveNameToID(ctx context.Context, name, t string) (int
 		if err != nil {
 			return -1, err
 		}
+		if *models.GetPayload().Count == 0 {
+			return -1, &ReferenceNotFoundError{Type: "device type", Name: name}
+		}
 		if *models.GetPayload().Count != 1 {
 			return -1, fmt.Errorf("unexpected number of models %q found: %d", name, *models.GetPayload().Count)
 		}
@@ -110,25 +348,243 @@ func (s *NetboxServer) resolveNameToID(ctx context.Context, name, t string) (int
 		if err != nil {
 			return -1, err
 		}
+		if *sites.GetPayload().Count == 0 {
+			return -1, &ReferenceNotFoundError{Type: "site", Name: name}
+		}
 		if *sites.GetPayload().Count != 1 {
 			return -1, fmt.Errorf("unexpected number of sites %q found: %d", name, *sites.GetPayload().Count)
 		}
 		return sites.GetPayload().Results[0].ID, nil
+	case "device":
+		devices, err := s.Client.Dcim.DcimDevicesList(&dcim.DcimDevicesListParams{
+			Name:    &name,
+			Context: ctx,
+		}, nil)
+		if err != nil {
+			return -1, err
+		}
+		if *devices.GetPayload().Count == 0 {
+			return -1, &ReferenceNotFoundError{Type: "device", Name: name}
+		}
+		if *devices.GetPayload().Count != 1 {
+			return -1, fmt.Errorf("unexpected number of devices %q found: %d", name, *devices.GetPayload().Count)
+		}
+		return devices.GetPayload().Results[0].ID, nil
+	case "rack role":
+		roles, err := s.Client.Dcim.DcimRackRolesList(&dcim.DcimRackRolesListParams{
+			Name:    &name,
+			Context: ctx,
+		}, nil)
+		if err != nil {
+			return -1, err
+		}
+		if *roles.GetPayload().Count == 0 {
+			return -1, &ReferenceNotFoundError{Type: "rack role", Name: name}
+		}
+		if *roles.GetPayload().Count != 1 {
+			return -1, fmt.Errorf("unexpected number of rack roles %q found: %d", name, *roles.GetPayload().Count)
+		}
+		return roles.GetPayload().Results[0].ID, nil
+	case "circuit":
+		circuits, err := s.Client.Circuits.CircuitsCircuitsList(&circuits.CircuitsCircuitsListParams{
+			Cid:     &name,
+			Context: ctx,
+		}, nil)
+		if err != nil {
+			return -1, err
+		}
+		if *circuits.GetPayload().Count == 0 {
+			return -1, &ReferenceNotFoundError{Type: "circuit", Name: name}
+		}
+		if *circuits.GetPayload().Count != 1 {
+			return -1, fmt.Errorf("unexpected number of circuits %q found: %d", name, *circuits.GetPayload().Count)
+		}
+		return circuits.GetPayload().Results[0].ID, nil
+	case "tenant":
+		tenants, err := s.Client.Tenancy.TenancyTenantsList(&tenancy.TenancyTenantsListParams{
+			Name:    &name,
+			Context: ctx,
+		}, nil)
+		if err != nil {
+			return -1, err
+		}
+		if *tenants.GetPayload().Count == 0 {
+			return -1, &ReferenceNotFoundError{Type: "tenant", Name: name}
+		}
+		if *tenants.GetPayload().Count != 1 {
+			return -1, fmt.Errorf("unexpected number of tenants %q found: %d", name, *tenants.GetPayload().Count)
+		}
+		return tenants.GetPayload().Results[0].ID, nil
+	case "tenant group":
+		groups, err := s.Client.Tenancy.TenancyTenantGroupsList(&tenancy.TenancyTenantGroupsListParams{
+			Name:    &name,
+			Context: ctx,
+		}, nil)
+		if err != nil {
+			return -1, err
+		}
+		if *groups.GetPayload().Count == 0 {
+			return -1, &ReferenceNotFoundError{Type: "tenant group", Name: name}
+		}
+		if *groups.GetPayload().Count != 1 {
+			return -1, fmt.Errorf("unexpected number of tenant groups %q found: %d", name, *groups.GetPayload().Count)
+		}
+		return groups.GetPayload().Results[0].ID, nil
+	case "region":
+		regions, err := s.Client.Dcim.DcimRegionsList(&dcim.DcimRegionsListParams{
+			Name:    &name,
+			Context: ctx,
+		}, nil)
+		if err != nil {
+			return -1, err
+		}
+		if *regions.GetPayload().Count == 0 {
+			return -1, &ReferenceNotFoundError{Type: "region", Name: name}
+		}
+		if *regions.GetPayload().Count != 1 {
+			return -1, fmt.Errorf("unexpected number of regions %q found: %d", name, *regions.GetPayload().Count)
+		}
+		return regions.GetPayload().Results[0].ID, nil
+	case "site group":
+		groups, err := s.Client.Dcim.DcimSiteGroupsList(&dcim.DcimSiteGroupsListParams{
+			Name:    &name,
+			Context: ctx,
+		}, nil)
+		if err != nil {
+			return -1, err
+		}
+		if *groups.GetPayload().Count == 0 {
+			return -1, &ReferenceNotFoundError{Type: "site group", Name: name}
+		}
+		if *groups.GetPayload().Count != 1 {
+			return -1, fmt.Errorf("unexpected number of site groups %q found: %d", name, *groups.GetPayload().Count)
+		}
+		return groups.GetPayload().Results[0].ID, nil
+	case "vrf":
+		vrfs, err := s.Client.Ipam.IpamVrfsList(&ipam.IpamVrfsListParams{
+			Name:    &name,
+			Context: ctx,
+		}, nil)
+		if err != nil {
+			return -1, err
+		}
+		if *vrfs.GetPayload().Count == 0 {
+			return -1, &ReferenceNotFoundError{Type: "VRF", Name: name}
+		}
+		if *vrfs.GetPayload().Count != 1 {
+			return -1, fmt.Errorf("unexpected number of VRFs %q found: %d", name, *vrfs.GetPayload().Count)
+		}
+		return vrfs.GetPayload().Results[0].ID, nil
+	case "route target":
+		targets, err := s.Client.Ipam.IpamRouteTargetsList(&ipam.IpamRouteTargetsListParams{
+			Name:    &name,
+			Context: ctx,
+		}, nil)
+		if err != nil {
+			return -1, err
+		}
+		if *targets.GetPayload().Count == 0 {
+			return -1, &ReferenceNotFoundError{Type: "route target", Name: name}
+		}
+		if *targets.GetPayload().Count != 1 {
+			return -1, fmt.Errorf("unexpected number of route targets %q found: %d", name, *targets.GetPayload().Count)
+		}
+		return targets.GetPayload().Results[0].ID, nil
+	case "platform":
+		platforms, err := s.Client.Dcim.DcimPlatformsList(&dcim.DcimPlatformsListParams{
+			Name:    &name,
+			Context: ctx,
+		}, nil)
+		if err != nil {
+			return -1, err
+		}
+		if *platforms.GetPayload().Count == 0 {
+			return -1, &ReferenceNotFoundError{Type: "platform", Name: name}
+		}
+		if *platforms.GetPayload().Count != 1 {
+			return -1, fmt.Errorf("unexpected number of platforms %q found: %d", name, *platforms.GetPayload().Count)
+		}
+		return platforms.GetPayload().Results[0].ID, nil
+	case "manufacturer":
+		manufacturers, err := s.Client.Dcim.DcimManufacturersList(&dcim.DcimManufacturersListParams{
+			Name:    &name,
+			Context: ctx,
+		}, nil)
+		if err != nil {
+			return -1, err
+		}
+		if *manufacturers.GetPayload().Count == 0 {
+			return -1, &ReferenceNotFoundError{Type: "manufacturer", Name: name}
+		}
+		if *manufacturers.GetPayload().Count != 1 {
+			return -1, fmt.Errorf("unexpected number of manufacturers %q found: %d", name, *manufacturers.GetPayload().Count)
+		}
+		return manufacturers.GetPayload().Results[0].ID, nil
+	case "cluster type":
+		types, err := s.Client.Virtualization.VirtualizationClusterTypesList(&virtualization.VirtualizationClusterTypesListParams{
+			Name:    &name,
+			Context: ctx,
+		}, nil)
+		if err != nil {
+			return -1, err
+		}
+		if *types.GetPayload().Count == 0 {
+			return -1, &ReferenceNotFoundError{Type: "cluster type", Name: name}
+		}
+		if *types.GetPayload().Count != 1 {
+			return -1, fmt.Errorf("unexpected number of cluster types %q found: %d", name, *types.GetPayload().Count)
+		}
+		return types.GetPayload().Results[0].ID, nil
+	case "cluster":
+		clusters, err := s.Client.Virtualization.VirtualizationClustersList(&virtualization.VirtualizationClustersListParams{
+			Name:    &name,
+			Context: ctx,
+		}, nil)
+		if err != nil {
+			return -1, err
+		}
+		if *clusters.GetPayload().Count == 0 {
+			return -1, &ReferenceNotFoundError{Type: "cluster", Name: name}
+		}
+		if *clusters.GetPayload().Count != 1 {
+			return -1, fmt.Errorf("unexpected number of clusters %q found: %d", name, *clusters.GetPayload().Count)
+		}
+		return clusters.GetPayload().Results[0].ID, nil
+	case "virtual machine":
+		vms, err := s.Client.Virtualization.VirtualizationVirtualMachinesList(&virtualization.VirtualizationVirtualMachinesListParams{
+			Name:    &name,
+			Context: ctx,
+		}, nil)
+		if err != nil {
+			return -1, err
+		}
+		if *vms.GetPayload().Count == 0 {
+			return -1, &ReferenceNotFoundError{Type: "virtual machine", Name: name}
+		}
+		if *vms.GetPayload().Count != 1 {
+			return -1, fmt.Errorf("unexpected number of virtual machines %q found: %d", name, *vms.GetPayload().Count)
+		}
+		return vms.GetPayload().Results[0].ID, nil
 	default:
 		return -1, fmt.Errorf("unexpected type %q", t)
 	}
 }
 
-func AuthCheck(s, t string) error {
-	httpC := &http.Client{
-		Timeout: time.Second * 20,
+func AuthCheck(s, t string, opts TransportOptions) error {
+	httpC, err := opts.HTTPClient()
+	if err != nil {
+		return err
 	}
 
-	checkURL, err := url.Parse(s)
+	scheme, host, basePath, err := parseServer(s)
 	if err != nil {
 		return err
 	}
-	checkURL.Path = path.Join(checkURL.Path, "/api/dcim/sites/")
+	checkURL := url.URL{
+		Scheme: scheme,
+		Host:   host,
+		Path:   path.Join(basePath, "/dcim/sites") + "/",
+	}
 
 	req, err := http.NewRequest("GET", checkURL.String(), nil)
 	if err != nil {
@@ -0,0 +1,476 @@
+package netbox
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/strfmt"
+	"github.com/netbox-community/go-netbox/netbox/models"
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+)
+
+// object is the Netbox side of a kind handled by the generic lifecycle, which
+// implements apply, delete and orphan the same way as for devices. Implementations
+// resolve the references of the spec and map the spec to the go-netbox models
+type object interface {
+	// resource returns the Kubernetes resource of the object
+	resource() netboxv1.Object
+	// typeName is the name of the Netbox type used in errors, e.g. "cable"
+	typeName() string
+	// path is the API path of the Netbox objects, e.g. "/dcim/cables/"
+	path() string
+	// tags returns the slugs of the spec tags, nil leaves the tags of the Netbox object unchanged
+	tags() []string
+	// resolve looks up the Netbox objects referenced by the spec. Missing references are returned
+	// as ReferenceNotFound, which keeps the resource Pending until they are created. Objects that
+	// are managed by resources come and go with them, so their IDs are looked up with
+	// lookupNameToID instead of the cached resolveNameToID
+	resolve(ctx context.Context) error
+	// read returns the Netbox object with the ID, nil if it doesn't exist
+	read(ctx context.Context, id int64) (*current, error)
+	// lookup returns the Netbox object matching the resolved spec, nil if it doesn't exist
+	lookup(ctx context.Context) (*current, error)
+	// diff returns the fields of the Netbox object that don't match the resolved spec
+	diff(cur *current) []string
+	// create and update write the resolved spec to Netbox and return the ID of the Netbox object
+	create(ctx context.Context, tags []*models.NestedTag) (int64, error)
+	update(ctx context.Context, cur *current, tags []*models.NestedTag) (int64, error)
+	// each lists the Netbox objects page by page and calls fn for each one of them
+	each(ctx context.Context, opts ListOptions, fn func(netboxv1.Object) error) error
+}
+
+// untaggable is implemented by the objects that Netbox can't tag, e.g. locations. Instead of the
+// managed tag, the objects created by declarative-netbox carry the created custom field. Other
+// existing objects are only adopted with the ID annotation and are left in place on delete
+type untaggable interface {
+	untaggable()
+}
+
+// cached is implemented by the objects that other objects refer to through the resolver
+// cache, e.g. sites. Their cache entry is dropped when they are deleted
+type cached interface {
+	// cacheKey returns the type and the name of the object in the resolver cache
+	cacheKey() (string, string)
+}
+
+// parent is implemented by the objects that other Netbox objects belong to, e.g. regions.
+// They are not deleted while they have children, which Netbox would delete or detach with them
+type parent interface {
+	// children describes the children of the Netbox object with the ID, e.g. "2 sites", nil if it has none
+	children(ctx context.Context, id int64) ([]string, error)
+}
+
+// composite is implemented by the objects with parts that are Netbox objects of their own,
+// e.g. the interface templates of device types. The parts are applied after the object
+type composite interface {
+	// applyParts creates and updates the parts of the Netbox object with the ID and returns the changed fields
+	applyParts(ctx context.Context, id int64) ([]string, error)
+}
+
+// childCount is the number of children of one type, e.g. sites
+type childCount struct {
+	count int64
+	name  string
+}
+
+// countChildren describes the children of each type, skipping the types without children
+func countChildren(counts ...childCount) []string {
+	var result []string
+	for _, c := range counts {
+		switch {
+		case c.count == 1:
+			result = append(result, "1 "+c.name)
+		case c.count > 1:
+			result = append(result, fmt.Sprintf("%d %ss", c.count, c.name))
+		}
+	}
+	return result
+}
+
+// current is an existing Netbox object
+type current struct {
+	ID   int64
+	Tags []*models.NestedTag
+	// Created is set for untaggable objects that carry the created custom field
+	Created bool
+	// model is the go-netbox model of the object
+	model interface{}
+}
+
+// object returns the Netbox side of the kinds handled by the generic lifecycle, nil for other kinds
+func (s *NetboxServer) object(obj interface{}) object {
+	switch o := obj.(type) {
+	case *netboxv1.TenantGroup:
+		return NewTenantGroup(*s, o)
+	case *netboxv1.Tenant:
+		return NewTenant(*s, o)
+	case *netboxv1.Region:
+		return NewRegion(*s, o)
+	case *netboxv1.SiteGroup:
+		return NewSiteGroup(*s, o)
+	case *netboxv1.Site:
+		return NewSite(*s, o)
+	case *netboxv1.Location:
+		return NewLocation(*s, o)
+	case *netboxv1.Rack:
+		return NewRack(*s, o)
+	case *netboxv1.VLANGroup:
+		return NewVLANGroup(*s, o)
+	case *netboxv1.VLAN:
+		return NewVLAN(*s, o)
+	case *netboxv1.RouteTarget:
+		return NewRouteTarget(*s, o)
+	case *netboxv1.VRF:
+		return NewVRF(*s, o)
+	case *netboxv1.Prefix:
+		return NewPrefix(*s, o)
+	case *netboxv1.Platform:
+		return NewPlatform(*s, o)
+	case *netboxv1.DeviceType:
+		return NewDeviceType(*s, o)
+	case *netboxv1.ClusterType:
+		return NewClusterType(*s, o)
+	case *netboxv1.Cluster:
+		return NewCluster(*s, o)
+	case *netboxv1.VirtualMachine:
+		return NewVirtualMachine(*s, o)
+	case *netboxv1.Interface:
+		return NewInterface(*s, o)
+	case *netboxv1.VMInterface:
+		return NewVMInterface(*s, o)
+	case *netboxv1.Cable:
+		return NewCable(*s, o)
+	case *netboxv1.IPAddress:
+		return NewIPAddress(*s, o)
+	}
+	return nil
+}
+
+// lifecycle applies, deletes and orphans objects, resolving their references at most once
+type lifecycle struct {
+	object
+	s        *NetboxServer
+	resolved bool
+}
+
+func (s *NetboxServer) lifecycle(o object) *lifecycle {
+	return &lifecycle{object: o, s: s}
+}
+
+func (l *lifecycle) resolve(ctx context.Context) error {
+	if l.resolved {
+		return nil
+	}
+	if err := l.object.resolve(ctx); err != nil {
+		return err
+	}
+	l.resolved = true
+	return nil
+}
+
+// find returns the Netbox object of the resource, looking it up by the ID annotation
+// of adopted objects, then by the ID in the status and finally by the spec
+func (l *lifecycle) find(ctx context.Context) (*current, error) {
+	log := logr.FromContext(ctx)
+	res := l.resource()
+
+	if id, ok := res.GetAnnotations()[netboxv1.IDAnnotation]; ok {
+		nbID, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected %s annotation %q: %w", netboxv1.IDAnnotation, id, err)
+		}
+		cur, err := l.read(ctx, nbID)
+		if err == nil && cur == nil {
+			log.Info("adopted object no longer exists in Netbox", "type", l.typeName(), "id", nbID)
+		}
+		return cur, err
+	}
+
+	if id := res.GetObjectStatus().ID; id != nil {
+		cur, err := l.read(ctx, *id)
+		if err != nil || cur != nil {
+			return cur, err
+		}
+		log.V(1).Info("object no longer exists in Netbox", "type", l.typeName(), "id", *id)
+	}
+
+	if err := l.resolve(ctx); err != nil {
+		return nil, err
+	}
+	return l.lookup(ctx)
+}
+
+// apply creates or updates the Netbox object of the resource
+func (l *lifecycle) apply(ctx context.Context) (*Result, error) {
+	log := logr.FromContext(ctx)
+
+	if err := l.resolve(ctx); err != nil {
+		return nil, err
+	}
+
+	_, untagged := l.object.(untaggable)
+
+	var tags []*models.NestedTag
+	if slugs := l.tags(); len(slugs) > 0 && !untagged {
+		var err error
+		if tags, err = l.s.resolveTags(ctx, slugs); err != nil {
+			return nil, err
+		}
+	}
+
+	var managed *models.NestedTag
+	if untagged {
+		if err := l.s.createdField(ctx); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		if managed, err = l.s.managedTag(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	cur, err := l.find(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if cur == nil {
+		id, err := l.create(ctx, withManagedTag(tags, managed))
+		if err != nil {
+			return nil, err
+		}
+		log.V(1).Info("created object", "type", l.typeName(), "id", id)
+		if _, err := l.applyParts(ctx, id); err != nil {
+			return nil, err
+		}
+		return &Result{Operation: OperationCreated}, l.setStatus(id)
+	}
+
+	if untagged && !cur.Created && !l.claimed(cur) {
+		return nil, &UnmanagedConflictError{Type: l.typeName(), Name: l.resource().GetName(), ID: cur.ID}
+	}
+
+	changed := l.diff(cur)
+	if !untagged && (!hasManagedTag(cur.Tags) || (tags != nil && !sameSlugs(tagSlugs(tags), tagSlugs(cur.Tags)))) {
+		changed = append(changed, "tags")
+	}
+
+	id := cur.ID
+	if len(changed) > 0 {
+		if tags == nil {
+			tags = cur.Tags
+		}
+		if id, err = l.update(ctx, cur, withManagedTag(tags, managed)); err != nil {
+			return nil, err
+		}
+		log.V(1).Info("updated object", "type", l.typeName(), "id", id, "changed", changed)
+	}
+
+	parts, err := l.applyParts(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	changed = append(changed, parts...)
+	if len(changed) == 0 {
+		log.V(1).Info("object is up to date", "type", l.typeName(), "id", id)
+		return &Result{Operation: OperationUnchanged}, l.setStatus(id)
+	}
+
+	return &Result{Operation: OperationUpdated, Changed: changed}, l.setStatus(id)
+}
+
+// applyParts applies the parts of composite objects, other objects have none
+func (l *lifecycle) applyParts(ctx context.Context, id int64) ([]string, error) {
+	c, ok := l.object.(composite)
+	if !ok {
+		return nil, nil
+	}
+	return c.applyParts(ctx, id)
+}
+
+// delete removes the Netbox object of the resource
+func (l *lifecycle) delete(ctx context.Context) error {
+	log := logr.FromContext(ctx)
+
+	cur, err := l.find(ctx)
+	if IsReferenceNotFound(err) {
+		// objects that are only found through their references are removed together with them
+		log.V(1).Info("references of the object no longer exist", "type", l.typeName(), "reason", err.Error())
+		return nil
+	}
+	if err != nil || cur == nil {
+		return err
+	}
+
+	if _, untagged := l.object.(untaggable); untagged && !cur.Created {
+		log.Info("leaving object that the resource did not create in Netbox", "type", l.typeName(), "id", cur.ID)
+		return nil
+	}
+
+	if p, ok := l.object.(parent); ok {
+		children, err := p.children(ctx, cur.ID)
+		if err != nil {
+			return err
+		}
+		if len(children) > 0 {
+			return &HasChildrenError{Type: l.typeName(), Name: l.resource().GetName(), Children: children}
+		}
+	}
+
+	if err := l.s.deleteObject(ctx, l.path(), cur.ID); err != nil {
+		return err
+	}
+	if c, ok := l.object.(cached); ok && l.s.cache != nil {
+		l.s.cache.forget(c.cacheKey())
+	}
+	log.V(1).Info("deleted object", "type", l.typeName(), "id", cur.ID)
+	return nil
+}
+
+// orphan removes the managed tag, or the created custom field of untaggable objects, leaving
+// the Netbox object in place
+func (l *lifecycle) orphan(ctx context.Context) error {
+	log := logr.FromContext(ctx)
+
+	cur, err := l.find(ctx)
+	if IsReferenceNotFound(err) {
+		return nil
+	}
+	if err != nil || cur == nil {
+		return err
+	}
+
+	if _, untagged := l.object.(untaggable); untagged {
+		if !cur.Created {
+			return nil
+		}
+		if err := l.s.patchObject(ctx, l.path(), cur.ID, map[string]interface{}{
+			"custom_fields": map[string]interface{}{CreatedFieldName: nil},
+		}); err != nil {
+			return err
+		}
+		log.V(1).Info("orphaned object", "type", l.typeName(), "id", cur.ID)
+		return nil
+	}
+
+	if !hasManagedTag(cur.Tags) {
+		return nil
+	}
+
+	if err := l.s.patchTags(ctx, l.path(), cur.ID, withoutManagedTag(cur.Tags)); err != nil {
+		return err
+	}
+	log.V(1).Info("orphaned object", "type", l.typeName(), "id", cur.ID)
+	return nil
+}
+
+// validate checks that the references exist and that the resource does not
+// take over an existing Netbox object that is not managed
+func (l *lifecycle) validate(ctx context.Context) error {
+	if err := l.resolve(ctx); err != nil {
+		return err
+	}
+
+	cur, err := l.find(ctx)
+	if err != nil || cur == nil {
+		return err
+	}
+
+	managed := hasManagedTag(cur.Tags)
+	if _, untagged := l.object.(untaggable); untagged {
+		managed = cur.Created
+	}
+	if !managed && !l.claimed(cur) {
+		return &UnmanagedConflictError{Type: l.typeName(), Name: l.resource().GetName(), ID: cur.ID}
+	}
+	return nil
+}
+
+// claimed returns true if the resource already holds the Netbox object: it was adopted
+// with the ID annotation or it is the object in the status
+func (l *lifecycle) claimed(cur *current) bool {
+	res := l.resource()
+	if _, adopted := res.GetAnnotations()[netboxv1.IDAnnotation]; adopted {
+		return true
+	}
+	id := res.GetObjectStatus().ID
+	return id != nil && *id == cur.ID
+}
+
+func (l *lifecycle) setStatus(id int64) error {
+	if id == 0 {
+		return fmt.Errorf("unexpected %s ID: 0", l.typeName())
+	}
+
+	status := l.resource().GetObjectStatus()
+	status.ID = &id
+	status.State = netboxv1.ObjectReadyState
+	status.Message = ""
+	return nil
+}
+
+// patchTags replaces the tags of a Netbox object
+func (s *NetboxServer) patchTags(ctx context.Context, path string, id int64, tags []*models.NestedTag) error {
+	if err := s.patchObject(ctx, path, id, map[string]interface{}{"tags": tags}); err != nil {
+		return fmt.Errorf("failed to update the tags of %s%d, %w", path, id, err)
+	}
+	return nil
+}
+
+// patchObject updates the fields of a Netbox object. The partial updates of go-netbox
+// send all fields of the writable models, so the request is built by hand
+func (s *NetboxServer) patchObject(ctx context.Context, path string, id int64, fields map[string]interface{}) error {
+	_, err := s.Client.Transport.Submit(&runtime.ClientOperation{
+		ID:                 "partial_update",
+		Method:             http.MethodPatch,
+		PathPattern:        path + "{id}/",
+		ProducesMediaTypes: []string{"application/json"},
+		ConsumesMediaTypes: []string{"application/json"},
+		Schemes:            []string{"http"},
+		Params: runtime.ClientRequestWriterFunc(func(r runtime.ClientRequest, _ strfmt.Registry) error {
+			if err := r.SetPathParam("id", strconv.FormatInt(id, 10)); err != nil {
+				return err
+			}
+			return r.SetBodyParam(fields)
+		}),
+		Reader: runtime.ClientResponseReaderFunc(func(response runtime.ClientResponse, consumer runtime.Consumer) (interface{}, error) {
+			if response.Code() != http.StatusOK {
+				return nil, runtime.NewAPIError("partial_update", response.Message(), response.Code())
+			}
+			return nil, nil
+		}),
+		Context: ctx,
+	})
+	return err
+}
+
+// deleteObject removes a Netbox object, objects that no longer exist are ignored
+func (s *NetboxServer) deleteObject(ctx context.Context, path string, id int64) error {
+	_, err := s.Client.Transport.Submit(&runtime.ClientOperation{
+		ID:                 "delete",
+		Method:             http.MethodDelete,
+		PathPattern:        path + "{id}/",
+		ProducesMediaTypes: []string{"application/json"},
+		ConsumesMediaTypes: []string{"application/json"},
+		Schemes:            []string{"http"},
+		Params: runtime.ClientRequestWriterFunc(func(r runtime.ClientRequest, _ strfmt.Registry) error {
+			return r.SetPathParam("id", strconv.FormatInt(id, 10))
+		}),
+		Reader: runtime.ClientResponseReaderFunc(func(response runtime.ClientResponse, consumer runtime.Consumer) (interface{}, error) {
+			if response.Code() != http.StatusNoContent {
+				return nil, runtime.NewAPIError("delete", response.Message(), response.Code())
+			}
+			return nil, nil
+		}),
+		Context: ctx,
+	})
+	if err != nil && !IsNotFound(err) {
+		return fmt.Errorf("failed to delete %s%d, %w", path, id, err)
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+package netbox
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-logr/logr"
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// clusterTypeRoutes serve the cluster type k8s with ID 7, which was made by hand, and the cluster
+// type openstack with ID 8, which was created by declarative-netbox. Created cluster types get ID 5
+func clusterTypeRoutes() []route {
+	k8s := `{"id": 7, "name": "k8s", "slug": "k8s"}`
+	openstack := `{"id": 8, "name": "openstack", "slug": "openstack", "custom_fields": {"declarative_netbox": true}}`
+	return []route{
+		onGet("/api/virtualization/cluster-types/", page(k8s), "name", "k8s"),
+		onGet("/api/virtualization/cluster-types/", page(openstack), "name", "openstack"),
+		onGet("/api/virtualization/cluster-types/", page()),
+		onGet("/api/virtualization/cluster-types/7/", k8s),
+		onGet("/api/virtualization/cluster-types/8/", openstack),
+		onGet("/api/virtualization/cluster-types/5/", `{"id": 5, "name": "vmware", "slug": "vmware", "custom_fields": {"declarative_netbox": true}}`),
+		onPost("/api/virtualization/cluster-types/", `{"id": 5, "name": "vmware", "slug": "vmware"}`),
+		onPatch("/api/virtualization/cluster-types/8/", openstack),
+		onDelete("/api/virtualization/cluster-types/7/"),
+		onDelete("/api/virtualization/cluster-types/8/"),
+	}
+}
+
+// testClusterType returns a cluster type adopting the Netbox object with the ID annotation,
+// or holding the object with the status ID. Resources applied by nbctl have neither
+func testClusterType(name, annotation string, id int64) *netboxv1.ClusterType {
+	clusterType := &netboxv1.ClusterType{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	if annotation != "" {
+		clusterType.Annotations = map[string]string{netboxv1.IDAnnotation: annotation}
+	}
+	if id != 0 {
+		clusterType.Status.ID = &id
+	}
+	return clusterType
+}
+
+func TestUntaggableApply(t *testing.T) {
+	ctx := logr.NewContext(context.Background(), logr.Discard())
+
+	tests := []struct {
+		name        string
+		clusterType *netboxv1.ClusterType
+		want        Operation
+		conflict    bool
+	}{
+		{name: "new", clusterType: testClusterType("vmware", "", 0), want: OperationCreated},
+		{name: "made by hand", clusterType: testClusterType("k8s", "", 0), conflict: true},
+		{name: "adopted", clusterType: testClusterType("k8s", "7", 0), want: OperationUnchanged},
+		{name: "re-applied without status", clusterType: testClusterType("openstack", "", 0), want: OperationUnchanged},
+		{name: "re-applied with status", clusterType: testClusterType("openstack", "", 8), want: OperationUnchanged},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeNetbox(t, clusterTypeRoutes()...)
+			s := f.netbox()
+
+			result, err := s.Apply(ctx, tt.clusterType)
+			if tt.conflict {
+				if !IsUnmanagedConflict(err) {
+					t.Errorf("expected an unmanaged conflict, got %v", err)
+				}
+				if err := s.Validate(ctx, tt.clusterType); !IsUnmanagedConflict(err) {
+					t.Errorf("expected validate to return an unmanaged conflict, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Operation != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, result.Operation)
+			}
+			if err := s.Validate(ctx, tt.clusterType); err != nil {
+				t.Errorf("unexpected validate error %v", err)
+			}
+
+			// only created objects carry the created custom field
+			writes := f.requests()
+			if tt.want != OperationCreated {
+				if len(writes) > 0 {
+					t.Errorf("unexpected writes %v", writes)
+				}
+				return
+			}
+			want := map[string]interface{}{CreatedFieldName: true}
+			if len(writes) != 1 || !reflect.DeepEqual(writes[0].body["custom_fields"], want) {
+				t.Errorf("expected custom fields %v, got %v", want, writes)
+			}
+		})
+	}
+}
+
+func TestUntaggableDeleteAndOrphan(t *testing.T) {
+	ctx := logr.NewContext(context.Background(), logr.Discard())
+
+	tests := []struct {
+		name        string
+		clusterType *netboxv1.ClusterType
+		orphan      bool
+		want        []request
+	}{
+		{
+			name:        "created",
+			clusterType: testClusterType("openstack", "", 0),
+			want:        []request{{method: "DELETE", path: "/api/virtualization/cluster-types/8/", body: map[string]interface{}{}}},
+		},
+		{name: "adopted", clusterType: testClusterType("k8s", "7", 7)},
+		{name: "made by hand", clusterType: testClusterType("k8s", "", 0)},
+		{
+			name:        "orphaned",
+			clusterType: testClusterType("openstack", "", 8),
+			orphan:      true,
+			want: []request{{method: "PATCH", path: "/api/virtualization/cluster-types/8/", body: map[string]interface{}{
+				"custom_fields": map[string]interface{}{CreatedFieldName: nil},
+			}}},
+		},
+		{name: "orphaned made by hand", clusterType: testClusterType("k8s", "", 0), orphan: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeNetbox(t, clusterTypeRoutes()...)
+
+			var err error
+			if tt.orphan {
+				err = f.netbox().Orphan(ctx, tt.clusterType)
+			} else {
+				err = f.netbox().Delete(ctx, tt.clusterType)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := f.requests(); !reflect.DeepEqual(got, tt.want) && len(got)+len(tt.want) > 0 {
+				t.Errorf("expected requests %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCreatedField(t *testing.T) {
+	ctx := logr.NewContext(context.Background(), logr.Discard())
+
+	tests := []struct {
+		name  string
+		field string
+		want  []request
+	}{
+		{
+			name: "missing",
+			want: []request{{method: "POST", path: "/api/extras/custom-fields/", body: map[string]interface{}{
+				"name":          CreatedFieldName,
+				"type":          "boolean",
+				"choices":       nil,
+				"label":         "Created by declarative-netbox",
+				"description":   "Set on the objects without tags that declarative-netbox created and deletes",
+				"content_types": []interface{}{"dcim.location", "dcim.platform", "dcim.region", "dcim.sitegroup", "ipam.vlangroup", "tenancy.tenantgroup", "virtualization.clustertype"},
+			}}},
+		},
+		{
+			name:  "missing content types",
+			field: `{"id": 3, "name": "declarative_netbox", "content_types": ["dcim.region", "dcim.site"]}`,
+			want: []request{{method: "PATCH", path: "/api/extras/custom-fields/3/", body: map[string]interface{}{
+				"content_types": []interface{}{"dcim.region", "dcim.site", "dcim.location", "dcim.platform", "dcim.sitegroup", "ipam.vlangroup", "tenancy.tenantgroup", "virtualization.clustertype"},
+			}}},
+		},
+		{
+			name:  "complete",
+			field: `{"id": 3, "name": "declarative_netbox", "content_types": ["dcim.location", "dcim.platform", "dcim.region", "dcim.sitegroup", "ipam.vlangroup", "tenancy.tenantgroup", "virtualization.clustertype"]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields := page()
+			if tt.field != "" {
+				fields = page(tt.field)
+			}
+			f := newFakeNetbox(t,
+				onGet("/api/extras/custom-fields/", fields),
+				onPost("/api/extras/custom-fields/", `{"id": 3, "name": "declarative_netbox"}`),
+				onPatch("/api/extras/custom-fields/3/", `{"id": 3, "name": "declarative_netbox"}`),
+			)
+
+			if err := f.netbox().createdField(ctx); err != nil {
+				t.Fatal(err)
+			}
+			if got := f.requests(); !reflect.DeepEqual(got, tt.want) && len(got)+len(tt.want) > 0 {
+				t.Errorf("expected requests %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestUntaggable(t *testing.T) {
+	s := &NetboxServer{}
+	tests := []struct {
+		object interface{}
+		want   bool
+	}{
+		{object: &netboxv1.ClusterType{}, want: true},
+		{object: &netboxv1.VLANGroup{}, want: true},
+		{object: &netboxv1.Site{}},
+		{object: &netboxv1.Device{}},
+	}
+
+	for _, tt := range tests {
+		if got := s.Untaggable(tt.object); got != tt.want {
+			t.Errorf("expected %T untaggable %v, got %v", tt.object, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package netbox
+
+import "context"
+
+// DefaultChunkSize is the number of objects requested per page
+const DefaultChunkSize = 100
+
+// pageFunc fetches up to limit objects starting at offset. It returns the number
+// of objects received and whether Netbox has more pages (i.e. the next link is set)
+type pageFunc func(offset, limit int64) (received int, more bool, err error)
+
+// paginate calls fetch for every page of a list endpoint, until there are no more pages
+// or limit objects have been received. A limit of 0 returns all objects
+func paginate(ctx context.Context, chunkSize, limit int64, fetch pageFunc) error {
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+
+	var offset int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		pageSize := chunkSize
+		if limit > 0 && limit-offset < pageSize {
+			pageSize = limit - offset
+		}
+
+		received, more, err := fetch(offset, pageSize)
+		if err != nil {
+			return err
+		}
+		offset += int64(received)
+
+		if !more || received == 0 || (limit > 0 && offset >= limit) {
+			return nil
+		}
+	}
+}
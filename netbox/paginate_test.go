@@ -0,0 +1,49 @@
+package netbox
+
+import (
+	"context"
+	"testing"
+)
+
+func TestPaginate(t *testing.T) {
+	tests := []struct {
+		name      string
+		total     int64
+		chunkSize int64
+		limit     int64
+		want      int64
+		wantPages int
+	}{
+		{name: "single page", total: 20, chunkSize: 50, want: 20, wantPages: 1},
+		{name: "several pages", total: 120, chunkSize: 50, want: 120, wantPages: 3},
+		{name: "limit within first page", total: 120, chunkSize: 50, limit: 10, want: 10, wantPages: 1},
+		{name: "limit across pages", total: 120, chunkSize: 50, limit: 75, want: 75, wantPages: 2},
+		{name: "default chunk size", total: 250, want: 250, wantPages: 3},
+		{name: "empty", total: 0, chunkSize: 50, want: 0, wantPages: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got int64
+			pages := 0
+			err := paginate(context.Background(), tt.chunkSize, tt.limit, func(offset, limit int64) (int, bool, error) {
+				pages++
+				if offset != got {
+					t.Errorf("page %d: offset %d, want %d", pages, offset, got)
+				}
+				received := tt.total - offset
+				if received > limit {
+					received = limit
+				}
+				got += received
+				return int(received), offset+received < tt.total, nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want || pages != tt.wantPages {
+				t.Errorf("got %d objects in %d pages, want %d in %d", got, pages, tt.want, tt.wantPages)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package netbox
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/netbox-community/go-netbox/netbox/client/dcim"
+	"github.com/netbox-community/go-netbox/netbox/models"
+)
+
+const defaultFace = "front"
+
+// unitRange is the rack units occupied by a device, from bottom to top
+type unitRange struct {
+	bottom, top int64
+}
+
+func (u unitRange) overlaps(o unitRange) bool {
+	return u.bottom <= o.top && o.bottom <= u.top
+}
+
+func (u unitRange) String() string {
+	if u.bottom == u.top {
+		return fmt.Sprintf("U%d", u.bottom)
+	}
+	return fmt.Sprintf("U%d-U%d", u.bottom, u.top)
+}
+
+// deviceTypes looks up the height and depth of device types, each one at most once
+type deviceTypes struct {
+	s     *NetboxServer
+	types map[int64]*models.DeviceType
+}
+
+func (t *deviceTypes) get(ctx context.Context, id int64) (*models.DeviceType, error) {
+	if dt, ok := t.types[id]; ok {
+		return dt, nil
+	}
+	result, err := t.s.Client.Dcim.DcimDeviceTypesRead(&dcim.DcimDeviceTypesReadParams{
+		ID:      id,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to DcimDeviceTypesRead, %w", err)
+	}
+	t.types[id] = result.GetPayload()
+	return t.types[id], nil
+}
+
+func uHeight(dt *models.DeviceType) int64 {
+	if dt.UHeight == nil {
+		return 1
+	}
+	return *dt.UHeight
+}
+
+// placementDiff returns the placement fields of the Netbox device that do not match the spec.
+// Fields that are not set in the spec are left as they are in Netbox
+func (d *Device) placementDiff(nbDev *models.DeviceWithConfigContext, IDs *ids) []string {
+	spec := d.Data.Spec
+	changed := []string{}
+	if IDs.Rack != 0 && (nbDev.Rack == nil || nbDev.Rack.ID != IDs.Rack) {
+		changed = append(changed, "rack")
+	}
+	if spec.Position != 0 && (nbDev.Position == nil || *nbDev.Position != spec.Position) {
+		changed = append(changed, "position")
+	}
+	if spec.Face != "" && (nbDev.Face == nil || nbDev.Face.Value == nil || *nbDev.Face.Value != spec.Face) {
+		changed = append(changed, "face")
+	}
+	return changed
+}
+
+// face returns the face of the spec, or else the current face of the Netbox device (nil for new devices).
+// Netbox requires a face for mounted devices, so new devices default to the front
+func (d *Device) face(nbDev *models.DeviceWithConfigContext) string {
+	if d.Data.Spec.Face != "" {
+		return d.Data.Spec.Face
+	}
+	if nbDev != nil && nbDev.Face != nil && nbDev.Face.Value != nil {
+		return *nbDev.Face.Value
+	}
+	return defaultFace
+}
+
+// setPlacement copies the rack placement of the spec into the writable device. nbDev is the
+// current Netbox device, nil for new devices
+func (d *Device) setPlacement(data *models.WritableDeviceWithConfigContext, IDs *ids, nbDev *models.DeviceWithConfigContext) {
+	spec := d.Data.Spec
+	if IDs.Rack != 0 {
+		data.Rack = &IDs.Rack
+	}
+	if spec.Position != 0 {
+		position := spec.Position
+		data.Position = &position
+		data.Face = d.face(nbDev)
+	}
+	if spec.Face != "" {
+		data.Face = spec.Face
+	}
+}
+
+// checkPlacement returns a ConflictError if the device doesn't fit into the rack, given the
+// height of its device type, or if it overlaps another device mounted on the same face.
+// Full-depth devices occupy both faces. nbDev is the current Netbox device, nil for new devices
+func (d *Device) checkPlacement(ctx context.Context, IDs *ids, nbDev *models.DeviceWithConfigContext) error {
+	spec := d.Data.Spec
+	if spec.Position == 0 {
+		return nil
+	}
+	if IDs.Rack == 0 {
+		return &ConflictError{Type: "device", Name: d.Data.Name, Reason: "a position requires a rack"}
+	}
+
+	types := &deviceTypes{s: &d.NetboxServer, types: map[int64]*models.DeviceType{}}
+	dt, err := types.get(ctx, IDs.Type)
+	if err != nil {
+		return err
+	}
+	height := uHeight(dt)
+	if height == 0 {
+		return &ConflictError{Type: "device", Name: d.Data.Name, Reason: fmt.Sprintf("0U device type %q can't have a rack position", spec.DeviceType)}
+	}
+
+	rack, err := d.Client.Dcim.DcimRacksRead(&dcim.DcimRacksReadParams{
+		ID:      IDs.Rack,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to DcimRacksRead, %w", err)
+	}
+
+	units := unitRange{bottom: spec.Position, top: spec.Position + height - 1}
+	if rackHeight := rack.GetPayload().UHeight; units.top > rackHeight {
+		return &ConflictError{
+			Type:   "device",
+			Name:   d.Data.Name,
+			Reason: fmt.Sprintf("position %s exceeds the %d units of rack %q", units, rackHeight, spec.Rack),
+		}
+	}
+
+	face := d.face(nbDev)
+	var self int64
+	if nbDev != nil {
+		self = nbDev.ID
+	}
+
+	rackID := strconv.FormatInt(IDs.Rack, 10)
+	params := &dcim.DcimDevicesListParams{
+		RackID:  &rackID,
+		Context: ctx,
+	}
+	return paginate(ctx, 0, 0, func(offset, limit int64) (int, bool, error) {
+		params.Offset = &offset
+		params.Limit = &limit
+
+		devices, err := d.Client.Dcim.DcimDevicesList(params, nil)
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to DcimDevicesList, %w", err)
+		}
+
+		for _, other := range devices.Payload.Results {
+			if other.ID == self || other.Position == nil || other.DeviceType == nil {
+				continue
+			}
+			otherType, err := types.get(ctx, other.DeviceType.ID)
+			if err != nil {
+				return 0, false, err
+			}
+			otherUnits := unitRange{bottom: *other.Position, top: *other.Position + uHeight(otherType) - 1}
+			if !units.overlaps(otherUnits) {
+				continue
+			}
+			otherFace := defaultFace
+			if other.Face != nil && other.Face.Value != nil {
+				otherFace = *other.Face.Value
+			}
+			if face != otherFace && !dt.IsFullDepth && !otherType.IsFullDepth {
+				continue
+			}
+			name := fmt.Sprintf("%d", other.ID)
+			if other.Name != nil {
+				name = *other.Name
+			}
+			return 0, false, &ConflictError{
+				Type:   "device",
+				Name:   d.Data.Name,
+				Reason: fmt.Sprintf("position %s in rack %q overlaps device %q at %s", units, spec.Rack, name, otherUnits),
+			}
+		}
+
+		return len(devices.Payload.Results), devices.Payload.Next != nil, nil
+	})
+}
@@ -0,0 +1,106 @@
+package netbox
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/netbox-community/go-netbox/netbox/models"
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeRackNetbox serves rack 3 with 42 units, holding leaf-97 (1U) at U40 on the front
+// and server-01 (2U, full depth) at U10 on the rear
+func fakeRackNetbox(t *testing.T) *fakeNetbox {
+	return newFakeNetbox(t,
+		onGet("/api/dcim/device-types/1/", `{"id": 1, "model": "SN3420", "u_height": 1}`),
+		onGet("/api/dcim/device-types/2/", `{"id": 2, "model": "R740", "u_height": 2, "is_full_depth": true}`),
+		onGet("/api/dcim/racks/3/", `{"id": 3, "name": "r1", "u_height": 42}`),
+		onGet("/api/dcim/devices/", page(
+			`{"id": 7, "name": "leaf-97", "device_type": {"id": 1}, "position": 40, "face": {"value": "front"}}`,
+			`{"id": 8, "name": "server-01", "device_type": {"id": 2}, "position": 10, "face": {"value": "rear"}}`,
+		), "rack_id", "3"),
+	)
+}
+
+// mounted returns a Netbox device mounted on the face
+func mounted(id int64, face string) *models.DeviceWithConfigContext {
+	return &models.DeviceWithConfigContext{ID: id, Face: &models.DeviceWithConfigContextFace{Value: &face}}
+}
+
+func TestCheckPlacement(t *testing.T) {
+	ctx := logr.NewContext(context.Background(), logr.Discard())
+
+	tests := []struct {
+		name     string
+		typeID   int64
+		position int64
+		face     string
+		current  *models.DeviceWithConfigContext
+		conflict bool
+	}{
+		{name: "free unit", typeID: 1, position: 41},
+		{name: "same unit and face", typeID: 1, position: 40, conflict: true},
+		{name: "same unit on the other face", typeID: 1, position: 40, face: "rear"},
+		{name: "moving the device itself", typeID: 1, position: 40, current: mounted(7, "front")},
+		{name: "moving a device on the rear", typeID: 1, position: 40, current: mounted(9, "rear")},
+		{name: "moving a device to the front", typeID: 1, position: 40, face: "front", current: mounted(9, "rear"), conflict: true},
+		{name: "overlapping a full depth device", typeID: 1, position: 11, conflict: true},
+		{name: "full depth on the other face", typeID: 2, position: 39, face: "rear", conflict: true},
+		{name: "above the top of the rack", typeID: 2, position: 42, conflict: true},
+	}
+
+	s := fakeRackNetbox(t).netbox()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDevice(*s, &netboxv1.Device{
+				ObjectMeta: metav1.ObjectMeta{Name: "leaf-98"},
+				Spec: netboxv1.DeviceSpec{
+					Rack:     "r1",
+					Position: tt.position,
+					Face:     tt.face,
+				},
+			})
+
+			err := d.checkPlacement(ctx, &ids{Type: tt.typeID, Rack: 3}, tt.current)
+			if tt.conflict && !IsConflict(err) {
+				t.Errorf("expected a conflict, got %v", err)
+			}
+			if !tt.conflict && err != nil {
+				t.Errorf("unexpected error %v", err)
+			}
+		})
+	}
+}
+
+func TestSetPlacement(t *testing.T) {
+	tests := []struct {
+		name    string
+		face    string
+		current *models.DeviceWithConfigContext
+		want    string
+	}{
+		{name: "new device", want: "front"},
+		{name: "new device with a face", face: "rear", want: "rear"},
+		{name: "existing device", current: mounted(9, "rear"), want: "rear"},
+		{name: "existing device without a face", current: &models.DeviceWithConfigContext{ID: 9}, want: "front"},
+		{name: "existing device with a new face", face: "front", current: mounted(9, "rear"), want: "front"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDevice(NetboxServer{}, &netboxv1.Device{
+				ObjectMeta: metav1.ObjectMeta{Name: "leaf-98"},
+				Spec:       netboxv1.DeviceSpec{Rack: "r1", Position: 40, Face: tt.face},
+			})
+
+			data := &models.WritableDeviceWithConfigContext{}
+			d.setPlacement(data, &ids{Rack: 3}, tt.current)
+			if data.Face != tt.want {
+				t.Errorf("expected face %q, got %q", tt.want, data.Face)
+			}
+		})
+	}
+}
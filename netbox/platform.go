@@ -0,0 +1,267 @@
+package netbox
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	"github.com/netbox-community/go-netbox/netbox/client/dcim"
+	"github.com/netbox-community/go-netbox/netbox/client/virtualization"
+	"github.com/netbox-community/go-netbox/netbox/models"
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type Platform struct {
+	Data *netboxv1.Platform
+	NetboxServer
+
+	manufacturerID *int64
+}
+
+func NewPlatform(s NetboxServer, p *netboxv1.Platform) *Platform {
+	return &Platform{
+		Data:         p,
+		NetboxServer: s,
+	}
+}
+
+func (p *Platform) resource() netboxv1.Object {
+	return p.Data
+}
+
+func (p *Platform) typeName() string {
+	return "platform"
+}
+
+func (p *Platform) path() string {
+	return "/dcim/platforms/"
+}
+
+func (p *Platform) tags() []string {
+	return nil
+}
+
+// untaggable implements untaggable, Netbox 3.0 platforms have no tags
+func (p *Platform) untaggable() {}
+
+// cacheKey implements cached, devices and virtual machines refer to platforms by name
+func (p *Platform) cacheKey() (string, string) {
+	return "platform", p.Data.PlatformName()
+}
+
+// children implements parent, Netbox detaches the devices and virtual machines of deleted platforms
+func (p *Platform) children(ctx context.Context, id int64) ([]string, error) {
+	platform := strconv.FormatInt(id, 10)
+	one := int64(1)
+	devices, err := p.Client.Dcim.DcimDevicesList(&dcim.DcimDevicesListParams{
+		PlatformID: &platform,
+		Limit:      &one,
+		Context:    ctx,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to DcimDevicesList, %w", err)
+	}
+	vms, err := p.Client.Virtualization.VirtualizationVirtualMachinesList(&virtualization.VirtualizationVirtualMachinesListParams{
+		PlatformID: &platform,
+		Limit:      &one,
+		Context:    ctx,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to VirtualizationVirtualMachinesList, %w", err)
+	}
+	return countChildren(
+		childCount{*devices.Payload.Count, "device"},
+		childCount{*vms.Payload.Count, "virtual machine"},
+	), nil
+}
+
+// resolve looks up the manufacturer of the platform
+func (p *Platform) resolve(ctx context.Context) error {
+	if p.Data.Spec.Manufacturer == "" {
+		return nil
+	}
+	id, err := p.resolveNameToID(ctx, p.Data.Spec.Manufacturer, "manufacturer")
+	if err != nil {
+		return err
+	}
+	p.manufacturerID = &id
+	return nil
+}
+
+func (p *Platform) read(ctx context.Context, id int64) (*current, error) {
+	platform, err := p.Client.Dcim.DcimPlatformsRead(&dcim.DcimPlatformsReadParams{
+		ID:      id,
+		Context: ctx,
+	}, nil)
+	if IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to DcimPlatformsRead, %w", err)
+	}
+
+	return platformCurrent(platform.GetPayload()), nil
+}
+
+func platformCurrent(platform *models.Platform) *current {
+	return &current{ID: platform.ID, Created: hasCreatedField(platform.CustomFields), model: platform}
+}
+
+// lookup returns the platform with the same name, platform names are unique in Netbox
+func (p *Platform) lookup(ctx context.Context) (*current, error) {
+	name := p.Data.PlatformName()
+	result, err := p.Client.Dcim.DcimPlatformsList(&dcim.DcimPlatformsListParams{
+		Name:    &name,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to DcimPlatformsList, %w", err)
+	}
+	if *result.Payload.Count == 0 {
+		return nil, nil
+	}
+	return platformCurrent(result.Payload.Results[0]), nil
+}
+
+func (p *Platform) slug() string {
+	if p.Data.Spec.Slug != "" {
+		return p.Data.Spec.Slug
+	}
+	return slugify(p.Data.PlatformName())
+}
+
+func (p *Platform) diff(cur *current) []string {
+	platform := cur.model.(*models.Platform)
+	spec := p.Data.Spec
+
+	changed := []string{}
+	if platform.Name == nil || *platform.Name != p.Data.PlatformName() {
+		changed = append(changed, "name")
+	}
+	if platform.Slug == nil || *platform.Slug != p.slug() {
+		changed = append(changed, "slug")
+	}
+	if p.manufacturerID != nil && (platform.Manufacturer == nil || platform.Manufacturer.ID != *p.manufacturerID) {
+		changed = append(changed, "manufacturer")
+	}
+	if spec.NapalmDriver != "" && platform.NapalmDriver != spec.NapalmDriver {
+		changed = append(changed, "napalm_driver")
+	}
+	if spec.Description != "" && platform.Description != spec.Description {
+		changed = append(changed, "description")
+	}
+	return changed
+}
+
+func (p *Platform) writable() *models.WritablePlatform {
+	spec := p.Data.Spec
+	name, slug := p.Data.PlatformName(), p.slug()
+	return &models.WritablePlatform{
+		Name:         &name,
+		Slug:         &slug,
+		Manufacturer: p.manufacturerID,
+		NapalmDriver: spec.NapalmDriver,
+		Description:  spec.Description,
+	}
+}
+
+func (p *Platform) create(ctx context.Context, _ []*models.NestedTag) (int64, error) {
+	log := logr.FromContext(ctx)
+
+	data := p.writable()
+	data.CustomFields = createdCustomFields()
+	platform, err := p.Client.Dcim.DcimPlatformsCreate(&dcim.DcimPlatformsCreateParams{
+		Data:    data,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to DcimPlatformsCreate, %w", err)
+	}
+	log.V(1).Info("created platform", "response", platform)
+
+	return platform.GetPayload().ID, nil
+}
+
+func (p *Platform) update(ctx context.Context, cur *current, _ []*models.NestedTag) (int64, error) {
+	log := logr.FromContext(ctx)
+
+	platform, err := p.Client.Dcim.DcimPlatformsUpdate(&dcim.DcimPlatformsUpdateParams{
+		Data:    p.writable(),
+		ID:      cur.ID,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to DcimPlatformsUpdate, %w", err)
+	}
+	log.V(1).Info("updated platform", "response", platform)
+
+	return platform.GetPayload().ID, nil
+}
+
+func (p *Platform) each(ctx context.Context, opts ListOptions, fn func(netboxv1.Object) error) error {
+	log := logr.FromContext(ctx)
+
+	params := &dcim.DcimPlatformsListParams{
+		Context: ctx,
+	}
+	if name := p.Data.PlatformName(); name != "" {
+		params.Name = &name
+	}
+	if opts.Query != "" {
+		params.Q = &opts.Query
+	}
+
+	return paginate(ctx, opts.ChunkSize, opts.Limit, func(offset, limit int64) (int, bool, error) {
+		params.Offset = &offset
+		params.Limit = &limit
+
+		platforms, err := p.Client.Dcim.DcimPlatformsList(params, p.withQuery(opts.query()))
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to DcimPlatformsList, %w", err)
+		}
+		log.V(1).Info("found platforms", "count", platforms.Payload.Count, "offset", offset)
+
+		for _, platform := range platforms.Payload.Results {
+			if err := fn(platformFromModel(platform)); err != nil {
+				return 0, false, err
+			}
+		}
+
+		return len(platforms.Payload.Results), platforms.Payload.Next != nil, nil
+	})
+}
+
+// platformFromModel maps a Netbox platform to a Platform named after the slug of the platform
+func platformFromModel(platform *models.Platform) *netboxv1.Platform {
+	spec := netboxv1.PlatformSpec{
+		NapalmDriver: platform.NapalmDriver,
+		Description:  platform.Description,
+	}
+	if platform.Name != nil {
+		spec.Name = *platform.Name
+	}
+	if platform.Slug != nil {
+		spec.Slug = *platform.Slug
+	}
+	if platform.Manufacturer != nil && platform.Manufacturer.Name != nil {
+		spec.Manufacturer = *platform.Manufacturer.Name
+	}
+
+	id := platform.ID
+	return &netboxv1.Platform{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       netboxv1.PlatformKind,
+			APIVersion: netboxv1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: netboxv1.ResourceName(spec.Slug),
+		},
+		Spec: spec,
+		Status: netboxv1.ObjectStatus{
+			ID:    &id,
+			State: netboxv1.ObjectReadyState,
+		},
+	}
+}
@@ -0,0 +1,308 @@
+package netbox
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	"github.com/netbox-community/go-netbox/netbox/client/ipam"
+	"github.com/netbox-community/go-netbox/netbox/models"
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type Prefix struct {
+	Data *netboxv1.Prefix
+	NetboxServer
+
+	vrfID    *int64
+	siteID   *int64
+	tenantID *int64
+}
+
+func NewPrefix(s NetboxServer, p *netboxv1.Prefix) *Prefix {
+	return &Prefix{
+		Data:         p,
+		NetboxServer: s,
+	}
+}
+
+func (p *Prefix) resource() netboxv1.Object {
+	return p.Data
+}
+
+func (p *Prefix) typeName() string {
+	return "prefix"
+}
+
+func (p *Prefix) path() string {
+	return "/ipam/prefixes/"
+}
+
+func (p *Prefix) tags() []string {
+	return p.Data.Spec.Tags
+}
+
+// resolve looks up the site, the tenant and the VRF
+func (p *Prefix) resolve(ctx context.Context) error {
+	spec := p.Data.Spec
+
+	if spec.VRF != "" {
+		id, err := p.lookupNameToID(ctx, spec.VRF, "vrf")
+		if err != nil {
+			return err
+		}
+		p.vrfID = &id
+	}
+
+	if spec.Site != "" {
+		id, err := p.resolveNameToID(ctx, spec.Site, "site")
+		if err != nil {
+			return err
+		}
+		p.siteID = &id
+	}
+
+	if spec.Tenant != "" {
+		id, err := p.resolveNameToID(ctx, spec.Tenant, "tenant")
+		if err != nil {
+			return err
+		}
+		p.tenantID = &id
+	}
+	return nil
+}
+
+func (p *Prefix) read(ctx context.Context, id int64) (*current, error) {
+	prefix, err := p.Client.Ipam.IpamPrefixesRead(&ipam.IpamPrefixesReadParams{
+		ID:      id,
+		Context: ctx,
+	}, nil)
+	if IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to IpamPrefixesRead, %w", err)
+	}
+
+	return prefixCurrent(prefix.GetPayload()), nil
+}
+
+func prefixCurrent(prefix *models.Prefix) *current {
+	return &current{ID: prefix.ID, Tags: prefix.Tags, model: prefix}
+}
+
+// sameVRF returns true if the nested VRF is the VRF with the ID, or the global table if id is nil
+func sameVRF(vrf *models.NestedVRF, id *int64) bool {
+	if id == nil {
+		return vrf == nil
+	}
+	return vrf != nil && vrf.ID == *id
+}
+
+// lookup returns the prefix in the same VRF, or in the global table if the spec has no VRF
+func (p *Prefix) lookup(ctx context.Context) (*current, error) {
+	result, err := p.Client.Ipam.IpamPrefixesList(&ipam.IpamPrefixesListParams{
+		Prefix:  &p.Data.Spec.Prefix,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to IpamPrefixesList, %w", err)
+	}
+
+	var found []*models.Prefix
+	for _, prefix := range result.Payload.Results {
+		if sameVRF(prefix.Vrf, p.vrfID) {
+			found = append(found, prefix)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, nil
+	case 1:
+		return prefixCurrent(found[0]), nil
+	default:
+		return nil, fmt.Errorf("unexpected number of prefixes %q found: %d", p.Data.Spec.Prefix, len(found))
+	}
+}
+
+func (p *Prefix) diff(cur *current) []string {
+	prefix := cur.model.(*models.Prefix)
+	spec := p.Data.Spec
+
+	changed := []string{}
+	if prefix.Prefix == nil || *prefix.Prefix != spec.Prefix {
+		changed = append(changed, "prefix")
+	}
+	if p.vrfID != nil && !sameVRF(prefix.Vrf, p.vrfID) {
+		changed = append(changed, "vrf")
+	}
+	if p.siteID != nil && (prefix.Site == nil || prefix.Site.ID != *p.siteID) {
+		changed = append(changed, "site")
+	}
+	if spec.Status != "" && (prefix.Status == nil || prefix.Status.Value == nil || *prefix.Status.Value != spec.Status) {
+		changed = append(changed, "status")
+	}
+	if p.tenantID != nil && (prefix.Tenant == nil || prefix.Tenant.ID != *p.tenantID) {
+		changed = append(changed, "tenant")
+	}
+	if spec.IsPool && !prefix.IsPool {
+		changed = append(changed, "is_pool")
+	}
+	if spec.Description != "" && prefix.Description != spec.Description {
+		changed = append(changed, "description")
+	}
+	return changed
+}
+
+func (p *Prefix) writable(tags []*models.NestedTag) *models.WritablePrefix {
+	spec := p.Data.Spec
+	return &models.WritablePrefix{
+		Prefix:      &spec.Prefix,
+		Vrf:         p.vrfID,
+		Site:        p.siteID,
+		Status:      spec.Status,
+		Tenant:      p.tenantID,
+		IsPool:      spec.IsPool,
+		Description: spec.Description,
+		Tags:        tags,
+	}
+}
+
+func (p *Prefix) create(ctx context.Context, tags []*models.NestedTag) (int64, error) {
+	log := logr.FromContext(ctx)
+
+	prefix, err := p.Client.Ipam.IpamPrefixesCreate(&ipam.IpamPrefixesCreateParams{
+		Data:    p.writable(tags),
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to IpamPrefixesCreate, %w", err)
+	}
+	log.V(1).Info("created prefix", "response", prefix)
+
+	return prefix.GetPayload().ID, nil
+}
+
+func (p *Prefix) update(ctx context.Context, cur *current, tags []*models.NestedTag) (int64, error) {
+	log := logr.FromContext(ctx)
+
+	prefix, err := p.Client.Ipam.IpamPrefixesUpdate(&ipam.IpamPrefixesUpdateParams{
+		Data:    p.writable(tags),
+		ID:      cur.ID,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to IpamPrefixesUpdate, %w", err)
+	}
+	log.V(1).Info("updated prefix", "response", prefix)
+
+	return prefix.GetPayload().ID, nil
+}
+
+func (p *Prefix) each(ctx context.Context, opts ListOptions, fn func(netboxv1.Object) error) error {
+	log := logr.FromContext(ctx)
+
+	params := &ipam.IpamPrefixesListParams{
+		Context: ctx,
+	}
+	if p.Data.Spec.Prefix != "" {
+		params.Prefix = &p.Data.Spec.Prefix
+	}
+	if p.Data.Spec.VRF != "" {
+		// the vrf filter matches route distinguishers, VRFs are filtered by ID instead
+		id, err := p.lookupNameToID(ctx, p.Data.Spec.VRF, "vrf")
+		if err != nil {
+			return err
+		}
+		vrf := strconv.FormatInt(id, 10)
+		params.VrfID = &vrf
+	}
+	if opts.Status != "" {
+		params.Status = &opts.Status
+	}
+	if opts.Query != "" {
+		params.Q = &opts.Query
+	}
+	for _, f := range []struct {
+		name  string
+		t     string
+		param **string
+	}{
+		{opts.Site, "site", &params.SiteID},
+		{opts.Tenant, "tenant", &params.TenantID},
+	} {
+		if f.name == "" {
+			continue
+		}
+		id, err := p.resolveNameToID(ctx, f.name, f.t)
+		if err != nil {
+			return err
+		}
+		value := strconv.FormatInt(id, 10)
+		*f.param = &value
+	}
+
+	return paginate(ctx, opts.ChunkSize, opts.Limit, func(offset, limit int64) (int, bool, error) {
+		params.Offset = &offset
+		params.Limit = &limit
+
+		prefixes, err := p.Client.Ipam.IpamPrefixesList(params, p.withQuery(opts.query()))
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to IpamPrefixesList, %w", err)
+		}
+		log.V(1).Info("found prefixes", "count", prefixes.Payload.Count, "offset", offset)
+
+		for _, prefix := range prefixes.Payload.Results {
+			if err := fn(prefixFromModel(prefix)); err != nil {
+				return 0, false, err
+			}
+		}
+
+		return len(prefixes.Payload.Results), prefixes.Payload.Next != nil, nil
+	})
+}
+
+// prefixFromModel maps a Netbox prefix to a Prefix named after its VRF and the prefix
+func prefixFromModel(prefix *models.Prefix) *netboxv1.Prefix {
+	spec := netboxv1.PrefixSpec{
+		IsPool:      prefix.IsPool,
+		Description: prefix.Description,
+		Tags:        tagSlugs(prefix.Tags),
+	}
+	if len(spec.Tags) == 0 {
+		spec.Tags = nil
+	}
+	if prefix.Prefix != nil {
+		spec.Prefix = *prefix.Prefix
+	}
+	if prefix.Vrf != nil && prefix.Vrf.Name != nil {
+		spec.VRF = *prefix.Vrf.Name
+	}
+	if prefix.Site != nil && prefix.Site.Name != nil {
+		spec.Site = *prefix.Site.Name
+	}
+	if prefix.Status != nil && prefix.Status.Value != nil {
+		spec.Status = *prefix.Status.Value
+	}
+	if prefix.Tenant != nil && prefix.Tenant.Name != nil {
+		spec.Tenant = *prefix.Tenant.Name
+	}
+
+	id := prefix.ID
+	return &netboxv1.Prefix{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       netboxv1.PrefixKind,
+			APIVersion: netboxv1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: netboxv1.ResourceName(spec.VRF, spec.Prefix),
+		},
+		Spec: spec,
+		Status: netboxv1.ObjectStatus{
+			ID:    &id,
+			State: netboxv1.ObjectReadyState,
+		},
+	}
+}
@@ -0,0 +1,307 @@
+package netbox
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	"github.com/netbox-community/go-netbox/netbox/client/dcim"
+	"github.com/netbox-community/go-netbox/netbox/models"
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type Rack struct {
+	Data *netboxv1.Rack
+	NetboxServer
+
+	siteID     int64
+	locationID *int64
+	roleID     *int64
+	tenantID   *int64
+}
+
+func NewRack(s NetboxServer, r *netboxv1.Rack) *Rack {
+	return &Rack{
+		Data:         r,
+		NetboxServer: s,
+	}
+}
+
+func (r *Rack) resource() netboxv1.Object {
+	return r.Data
+}
+
+func (r *Rack) typeName() string {
+	return "rack"
+}
+
+func (r *Rack) path() string {
+	return "/dcim/racks/"
+}
+
+func (r *Rack) tags() []string {
+	return r.Data.Spec.Tags
+}
+
+// resolve looks up the site, the role, the tenant and the location of the site
+func (r *Rack) resolve(ctx context.Context) error {
+	spec := r.Data.Spec
+
+	siteID, err := r.resolveNameToID(ctx, spec.Site, "site")
+	if err != nil {
+		return err
+	}
+	r.siteID = siteID
+
+	if spec.Location != "" {
+		location, err := r.findLocation(ctx, siteID, spec.Location)
+		if err != nil {
+			return err
+		}
+		if location == nil {
+			return &ReferenceNotFoundError{Type: "location", Name: spec.Site + "/" + spec.Location}
+		}
+		r.locationID = &location.ID
+	}
+
+	if spec.Role != "" {
+		id, err := r.resolveNameToID(ctx, spec.Role, "rack role")
+		if err != nil {
+			return err
+		}
+		r.roleID = &id
+	}
+
+	if spec.Tenant != "" {
+		id, err := r.resolveNameToID(ctx, spec.Tenant, "tenant")
+		if err != nil {
+			return err
+		}
+		r.tenantID = &id
+	}
+	return nil
+}
+
+// findRack returns the rack of the site with the name, nil if it doesn't exist
+func (s *NetboxServer) findRack(ctx context.Context, siteID int64, name string) (*models.Rack, error) {
+	site := strconv.FormatInt(siteID, 10)
+	result, err := s.Client.Dcim.DcimRacksList(&dcim.DcimRacksListParams{
+		SiteID:  &site,
+		Name:    &name,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to DcimRacksList, %w", err)
+	}
+	if *result.Payload.Count == 0 {
+		return nil, nil
+	}
+	if *result.Payload.Count != 1 {
+		return nil, fmt.Errorf("unexpected number of racks %q found: %d", name, *result.Payload.Count)
+	}
+	return result.Payload.Results[0], nil
+}
+
+func (r *Rack) read(ctx context.Context, id int64) (*current, error) {
+	rack, err := r.Client.Dcim.DcimRacksRead(&dcim.DcimRacksReadParams{
+		ID:      id,
+		Context: ctx,
+	}, nil)
+	if IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to DcimRacksRead, %w", err)
+	}
+
+	return rackCurrent(rack.GetPayload()), nil
+}
+
+func rackCurrent(rack *models.Rack) *current {
+	return &current{ID: rack.ID, Tags: rack.Tags, model: rack}
+}
+
+// lookup returns the rack of the site with the same name
+func (r *Rack) lookup(ctx context.Context) (*current, error) {
+	rack, err := r.findRack(ctx, r.siteID, r.Data.RackName())
+	if err != nil || rack == nil {
+		return nil, err
+	}
+	return rackCurrent(rack), nil
+}
+
+func (r *Rack) diff(cur *current) []string {
+	rack := cur.model.(*models.Rack)
+	spec := r.Data.Spec
+
+	changed := []string{}
+	if rack.Name == nil || *rack.Name != r.Data.RackName() {
+		changed = append(changed, "name")
+	}
+	if rack.Site == nil || rack.Site.ID != r.siteID {
+		changed = append(changed, "site")
+	}
+	if r.locationID != nil && (rack.Location == nil || rack.Location.ID != *r.locationID) {
+		changed = append(changed, "location")
+	}
+	if r.roleID != nil && (rack.Role == nil || rack.Role.ID != *r.roleID) {
+		changed = append(changed, "role")
+	}
+	if spec.Status != "" && (rack.Status == nil || rack.Status.Value == nil || *rack.Status.Value != spec.Status) {
+		changed = append(changed, "status")
+	}
+	if spec.UHeight != 0 && rack.UHeight != spec.UHeight {
+		changed = append(changed, "u_height")
+	}
+	if r.tenantID != nil && (rack.Tenant == nil || rack.Tenant.ID != *r.tenantID) {
+		changed = append(changed, "tenant")
+	}
+	return changed
+}
+
+func (r *Rack) writable(tags []*models.NestedTag) *models.WritableRack {
+	spec := r.Data.Spec
+	name := r.Data.RackName()
+	return &models.WritableRack{
+		Name:     &name,
+		Site:     &r.siteID,
+		Location: r.locationID,
+		Role:     r.roleID,
+		Status:   spec.Status,
+		UHeight:  spec.UHeight,
+		Tenant:   r.tenantID,
+		Tags:     tags,
+	}
+}
+
+func (r *Rack) create(ctx context.Context, tags []*models.NestedTag) (int64, error) {
+	log := logr.FromContext(ctx)
+
+	rack, err := r.Client.Dcim.DcimRacksCreate(&dcim.DcimRacksCreateParams{
+		Data:    r.writable(tags),
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to DcimRacksCreate, %w", err)
+	}
+	log.V(1).Info("created rack", "response", rack)
+
+	return rack.GetPayload().ID, nil
+}
+
+func (r *Rack) update(ctx context.Context, cur *current, tags []*models.NestedTag) (int64, error) {
+	log := logr.FromContext(ctx)
+
+	rack, err := r.Client.Dcim.DcimRacksUpdate(&dcim.DcimRacksUpdateParams{
+		Data:    r.writable(tags),
+		ID:      cur.ID,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to DcimRacksUpdate, %w", err)
+	}
+	log.V(1).Info("updated rack", "response", rack)
+
+	return rack.GetPayload().ID, nil
+}
+
+func (r *Rack) each(ctx context.Context, opts ListOptions, fn func(netboxv1.Object) error) error {
+	log := logr.FromContext(ctx)
+
+	params := &dcim.DcimRacksListParams{
+		Context: ctx,
+	}
+	if name := r.Data.RackName(); name != "" {
+		params.Name = &name
+	}
+	if opts.Status != "" {
+		params.Status = &opts.Status
+	}
+	if opts.Query != "" {
+		params.Q = &opts.Query
+	}
+	for _, f := range []struct {
+		name  string
+		t     string
+		param **string
+	}{
+		{opts.Site, "site", &params.SiteID},
+		{opts.Tenant, "tenant", &params.TenantID},
+	} {
+		if f.name == "" {
+			continue
+		}
+		id, err := r.resolveNameToID(ctx, f.name, f.t)
+		if err != nil {
+			return err
+		}
+		value := strconv.FormatInt(id, 10)
+		*f.param = &value
+	}
+
+	return paginate(ctx, opts.ChunkSize, opts.Limit, func(offset, limit int64) (int, bool, error) {
+		params.Offset = &offset
+		params.Limit = &limit
+
+		racks, err := r.Client.Dcim.DcimRacksList(params, r.withQuery(opts.query()))
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to DcimRacksList, %w", err)
+		}
+		log.V(1).Info("found racks", "count", racks.Payload.Count, "offset", offset)
+
+		for _, rack := range racks.Payload.Results {
+			if err := fn(rackFromModel(rack)); err != nil {
+				return 0, false, err
+			}
+		}
+
+		return len(racks.Payload.Results), racks.Payload.Next != nil, nil
+	})
+}
+
+// rackFromModel maps a Netbox rack to a Rack named after the rack
+func rackFromModel(rack *models.Rack) *netboxv1.Rack {
+	spec := netboxv1.RackSpec{
+		UHeight: rack.UHeight,
+		Tags:    tagSlugs(rack.Tags),
+	}
+	if len(spec.Tags) == 0 {
+		spec.Tags = nil
+	}
+	if rack.Name != nil {
+		spec.Name = *rack.Name
+	}
+	if rack.Site != nil && rack.Site.Name != nil {
+		spec.Site = *rack.Site.Name
+	}
+	if rack.Location != nil && rack.Location.Name != nil {
+		spec.Location = *rack.Location.Name
+	}
+	if rack.Role != nil && rack.Role.Name != nil {
+		spec.Role = *rack.Role.Name
+	}
+	if rack.Status != nil && rack.Status.Value != nil {
+		spec.Status = *rack.Status.Value
+	}
+	if rack.Tenant != nil && rack.Tenant.Name != nil {
+		spec.Tenant = *rack.Tenant.Name
+	}
+
+	id := rack.ID
+	return &netboxv1.Rack{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       netboxv1.RackKind,
+			APIVersion: netboxv1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: netboxv1.ResourceName(spec.Name),
+		},
+		Spec: spec,
+		Status: netboxv1.ObjectStatus{
+			ID:    &id,
+			State: netboxv1.ObjectReadyState,
+		},
+	}
+}
@@ -0,0 +1,255 @@
+package netbox
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	"github.com/netbox-community/go-netbox/netbox/client/dcim"
+	"github.com/netbox-community/go-netbox/netbox/models"
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type Region struct {
+	Data *netboxv1.Region
+	NetboxServer
+
+	parentID *int64
+}
+
+func NewRegion(s NetboxServer, r *netboxv1.Region) *Region {
+	return &Region{
+		Data:         r,
+		NetboxServer: s,
+	}
+}
+
+func (r *Region) resource() netboxv1.Object {
+	return r.Data
+}
+
+func (r *Region) typeName() string {
+	return "region"
+}
+
+func (r *Region) path() string {
+	return "/dcim/regions/"
+}
+
+func (r *Region) tags() []string {
+	return nil
+}
+
+// untaggable implements untaggable, Netbox 3.0 regions have no tags
+func (r *Region) untaggable() {}
+
+// children implements parent, Netbox deletes the child regions of deleted regions and detaches their sites
+func (r *Region) children(ctx context.Context, id int64) ([]string, error) {
+	parent := strconv.FormatInt(id, 10)
+	one := int64(1)
+	regions, err := r.Client.Dcim.DcimRegionsList(&dcim.DcimRegionsListParams{
+		ParentID: &parent,
+		Limit:    &one,
+		Context:  ctx,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to DcimRegionsList, %w", err)
+	}
+	sites, err := r.Client.Dcim.DcimSitesList(&dcim.DcimSitesListParams{
+		RegionID: &parent,
+		Limit:    &one,
+		Context:  ctx,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to DcimSitesList, %w", err)
+	}
+	return countChildren(
+		childCount{*regions.Payload.Count, "region"},
+		childCount{*sites.Payload.Count, "site"},
+	), nil
+}
+
+// resolve looks up the parent region
+func (r *Region) resolve(ctx context.Context) error {
+	if r.Data.Spec.Parent == "" {
+		return nil
+	}
+	id, err := r.lookupNameToID(ctx, r.Data.Spec.Parent, "region")
+	if err != nil {
+		return err
+	}
+	r.parentID = &id
+	return nil
+}
+
+func (r *Region) read(ctx context.Context, id int64) (*current, error) {
+	region, err := r.Client.Dcim.DcimRegionsRead(&dcim.DcimRegionsReadParams{
+		ID:      id,
+		Context: ctx,
+	}, nil)
+	if IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to DcimRegionsRead, %w", err)
+	}
+
+	return regionCurrent(region.GetPayload()), nil
+}
+
+func regionCurrent(region *models.Region) *current {
+	return &current{ID: region.ID, Created: hasCreatedField(region.CustomFields), model: region}
+}
+
+// lookup returns the region with the same name, region names are unique in Netbox
+func (r *Region) lookup(ctx context.Context) (*current, error) {
+	name := r.Data.RegionName()
+	result, err := r.Client.Dcim.DcimRegionsList(&dcim.DcimRegionsListParams{
+		Name:    &name,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to DcimRegionsList, %w", err)
+	}
+	if *result.Payload.Count == 0 {
+		return nil, nil
+	}
+	return regionCurrent(result.Payload.Results[0]), nil
+}
+
+func (r *Region) slug() string {
+	if r.Data.Spec.Slug != "" {
+		return r.Data.Spec.Slug
+	}
+	return slugify(r.Data.RegionName())
+}
+
+func (r *Region) diff(cur *current) []string {
+	region := cur.model.(*models.Region)
+	spec := r.Data.Spec
+
+	changed := []string{}
+	if region.Name == nil || *region.Name != r.Data.RegionName() {
+		changed = append(changed, "name")
+	}
+	if region.Slug == nil || *region.Slug != r.slug() {
+		changed = append(changed, "slug")
+	}
+	if r.parentID != nil && (region.Parent == nil || region.Parent.ID != *r.parentID) {
+		changed = append(changed, "parent")
+	}
+	if spec.Description != "" && region.Description != spec.Description {
+		changed = append(changed, "description")
+	}
+	return changed
+}
+
+func (r *Region) writable() *models.WritableRegion {
+	name, slug := r.Data.RegionName(), r.slug()
+	return &models.WritableRegion{
+		Name:        &name,
+		Slug:        &slug,
+		Parent:      r.parentID,
+		Description: r.Data.Spec.Description,
+	}
+}
+
+func (r *Region) create(ctx context.Context, _ []*models.NestedTag) (int64, error) {
+	log := logr.FromContext(ctx)
+
+	data := r.writable()
+	data.CustomFields = createdCustomFields()
+	region, err := r.Client.Dcim.DcimRegionsCreate(&dcim.DcimRegionsCreateParams{
+		Data:    data,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to DcimRegionsCreate, %w", err)
+	}
+	log.V(1).Info("created region", "response", region)
+
+	return region.GetPayload().ID, nil
+}
+
+func (r *Region) update(ctx context.Context, cur *current, _ []*models.NestedTag) (int64, error) {
+	log := logr.FromContext(ctx)
+
+	region, err := r.Client.Dcim.DcimRegionsUpdate(&dcim.DcimRegionsUpdateParams{
+		Data:    r.writable(),
+		ID:      cur.ID,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to DcimRegionsUpdate, %w", err)
+	}
+	log.V(1).Info("updated region", "response", region)
+
+	return region.GetPayload().ID, nil
+}
+
+func (r *Region) each(ctx context.Context, opts ListOptions, fn func(netboxv1.Object) error) error {
+	log := logr.FromContext(ctx)
+
+	params := &dcim.DcimRegionsListParams{
+		Context: ctx,
+	}
+	if name := r.Data.RegionName(); name != "" {
+		params.Name = &name
+	}
+	if opts.Query != "" {
+		params.Q = &opts.Query
+	}
+
+	return paginate(ctx, opts.ChunkSize, opts.Limit, func(offset, limit int64) (int, bool, error) {
+		params.Offset = &offset
+		params.Limit = &limit
+
+		regions, err := r.Client.Dcim.DcimRegionsList(params, r.withQuery(opts.query()))
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to DcimRegionsList, %w", err)
+		}
+		log.V(1).Info("found regions", "count", regions.Payload.Count, "offset", offset)
+
+		for _, region := range regions.Payload.Results {
+			if err := fn(regionFromModel(region)); err != nil {
+				return 0, false, err
+			}
+		}
+
+		return len(regions.Payload.Results), regions.Payload.Next != nil, nil
+	})
+}
+
+// regionFromModel maps a Netbox region to a Region named after the slug of the region
+func regionFromModel(region *models.Region) *netboxv1.Region {
+	spec := netboxv1.RegionSpec{
+		Description: region.Description,
+	}
+	if region.Name != nil {
+		spec.Name = *region.Name
+	}
+	if region.Slug != nil {
+		spec.Slug = *region.Slug
+	}
+	if region.Parent != nil && region.Parent.Name != nil {
+		spec.Parent = *region.Parent.Name
+	}
+
+	id := region.ID
+	return &netboxv1.Region{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       netboxv1.RegionKind,
+			APIVersion: netboxv1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: netboxv1.ResourceName(spec.Slug),
+		},
+		Spec: spec,
+		Status: netboxv1.ObjectStatus{
+			ID:    &id,
+			State: netboxv1.ObjectReadyState,
+		},
+	}
+}
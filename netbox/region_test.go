@@ -0,0 +1,78 @@
+package netbox
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeRegionNetbox serves the region emea with ID 1, which has the child region uk with
+// ID 2 and three sites, and the empty region apac with ID 3. Both carry the created custom field
+func fakeRegionNetbox(t *testing.T) *fakeNetbox {
+	return newFakeNetbox(t,
+		onGet("/api/dcim/regions/", page(`{"id": 1, "name": "emea", "slug": "emea", "custom_fields": {"declarative_netbox": true}}`), "name", "emea"),
+		onGet("/api/dcim/regions/", page(`{"id": 3, "name": "apac", "slug": "apac", "custom_fields": {"declarative_netbox": true}}`), "name", "apac"),
+		onGet("/api/dcim/regions/", page(`{"id": 2, "name": "uk", "slug": "uk"}`), "parent_id", "1"),
+		onGet("/api/dcim/regions/", page()),
+		onGet("/api/dcim/regions/1/", `{"id": 1, "name": "emea", "slug": "emea", "custom_fields": {"declarative_netbox": true}}`),
+		onGet("/api/dcim/regions/3/", `{"id": 3, "name": "apac", "slug": "apac", "custom_fields": {"declarative_netbox": true}}`),
+		onGet("/api/dcim/sites/", `{"count": 3, "results": [{"id": 7, "name": "lon1", "slug": "lon1"}]}`, "region_id", "1"),
+		onGet("/api/dcim/sites/", page()),
+		onDelete("/api/dcim/regions/1/"),
+		onDelete("/api/dcim/regions/3/"),
+	)
+}
+
+func TestRegionDeleteWithChildren(t *testing.T) {
+	ctx := logr.NewContext(context.Background(), logr.Discard())
+
+	tests := []struct {
+		name        string
+		region      string
+		id          int64
+		wantDeleted []string
+		wantErr     string
+	}{
+		{
+			name:    "children",
+			region:  "emea",
+			id:      1,
+			wantErr: `region "emea" still has 1 region, 3 sites in Netbox, delete them first`,
+		},
+		{
+			name:        "no children",
+			region:      "apac",
+			id:          3,
+			wantDeleted: []string{"/api/dcim/regions/3/"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := fakeRegionNetbox(t)
+
+			region := &netboxv1.Region{ObjectMeta: metav1.ObjectMeta{Name: tt.region}}
+			region.Status.ID = &tt.id
+
+			err := f.netbox().Delete(ctx, region)
+			if tt.wantErr != "" {
+				if !IsHasChildren(err) || err.Error() != tt.wantErr {
+					t.Fatalf("expected %q, got %v", tt.wantErr, err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			var deleted []string
+			for _, r := range f.requests() {
+				deleted = append(deleted, r.path)
+			}
+			if len(deleted) != len(tt.wantDeleted) || (len(deleted) > 0 && deleted[0] != tt.wantDeleted[0]) {
+				t.Errorf("expected deletes %v, got %v", tt.wantDeleted, deleted)
+			}
+		})
+	}
+}
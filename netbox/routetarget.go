@@ -0,0 +1,222 @@
+package netbox
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	"github.com/netbox-community/go-netbox/netbox/client/ipam"
+	"github.com/netbox-community/go-netbox/netbox/models"
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type RouteTarget struct {
+	Data *netboxv1.RouteTarget
+	NetboxServer
+
+	tenantID *int64
+}
+
+func NewRouteTarget(s NetboxServer, r *netboxv1.RouteTarget) *RouteTarget {
+	return &RouteTarget{
+		Data:         r,
+		NetboxServer: s,
+	}
+}
+
+func (r *RouteTarget) resource() netboxv1.Object {
+	return r.Data
+}
+
+func (r *RouteTarget) typeName() string {
+	return "route target"
+}
+
+func (r *RouteTarget) path() string {
+	return "/ipam/route-targets/"
+}
+
+func (r *RouteTarget) tags() []string {
+	return r.Data.Spec.Tags
+}
+
+func (r *RouteTarget) resolve(ctx context.Context) error {
+	if r.Data.Spec.Tenant == "" {
+		return nil
+	}
+	id, err := r.resolveNameToID(ctx, r.Data.Spec.Tenant, "tenant")
+	if err != nil {
+		return err
+	}
+	r.tenantID = &id
+	return nil
+}
+
+func (r *RouteTarget) read(ctx context.Context, id int64) (*current, error) {
+	target, err := r.Client.Ipam.IpamRouteTargetsRead(&ipam.IpamRouteTargetsReadParams{
+		ID:      id,
+		Context: ctx,
+	}, nil)
+	if IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to IpamRouteTargetsRead, %w", err)
+	}
+
+	return routeTargetCurrent(target.GetPayload()), nil
+}
+
+func routeTargetCurrent(target *models.RouteTarget) *current {
+	return &current{ID: target.ID, Tags: target.Tags, model: target}
+}
+
+// lookup returns the route target with the same name, route target names are unique in Netbox
+func (r *RouteTarget) lookup(ctx context.Context) (*current, error) {
+	name := r.Data.RouteTargetName()
+	result, err := r.Client.Ipam.IpamRouteTargetsList(&ipam.IpamRouteTargetsListParams{
+		Name:    &name,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to IpamRouteTargetsList, %w", err)
+	}
+	if *result.Payload.Count == 0 {
+		return nil, nil
+	}
+	return routeTargetCurrent(result.Payload.Results[0]), nil
+}
+
+func (r *RouteTarget) diff(cur *current) []string {
+	target := cur.model.(*models.RouteTarget)
+	spec := r.Data.Spec
+
+	changed := []string{}
+	if target.Name == nil || *target.Name != r.Data.RouteTargetName() {
+		changed = append(changed, "name")
+	}
+	if r.tenantID != nil && (target.Tenant == nil || target.Tenant.ID != *r.tenantID) {
+		changed = append(changed, "tenant")
+	}
+	if spec.Description != "" && target.Description != spec.Description {
+		changed = append(changed, "description")
+	}
+	return changed
+}
+
+func (r *RouteTarget) writable(tags []*models.NestedTag) *models.WritableRouteTarget {
+	name := r.Data.RouteTargetName()
+	return &models.WritableRouteTarget{
+		Name:        &name,
+		Tenant:      r.tenantID,
+		Description: r.Data.Spec.Description,
+		Tags:        tags,
+	}
+}
+
+func (r *RouteTarget) create(ctx context.Context, tags []*models.NestedTag) (int64, error) {
+	log := logr.FromContext(ctx)
+
+	target, err := r.Client.Ipam.IpamRouteTargetsCreate(&ipam.IpamRouteTargetsCreateParams{
+		Data:    r.writable(tags),
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to IpamRouteTargetsCreate, %w", err)
+	}
+	log.V(1).Info("created route target", "response", target)
+
+	return target.GetPayload().ID, nil
+}
+
+func (r *RouteTarget) update(ctx context.Context, cur *current, tags []*models.NestedTag) (int64, error) {
+	log := logr.FromContext(ctx)
+
+	target, err := r.Client.Ipam.IpamRouteTargetsUpdate(&ipam.IpamRouteTargetsUpdateParams{
+		Data:    r.writable(tags),
+		ID:      cur.ID,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to IpamRouteTargetsUpdate, %w", err)
+	}
+	log.V(1).Info("updated route target", "response", target)
+
+	return target.GetPayload().ID, nil
+}
+
+func (r *RouteTarget) each(ctx context.Context, opts ListOptions, fn func(netboxv1.Object) error) error {
+	log := logr.FromContext(ctx)
+
+	params := &ipam.IpamRouteTargetsListParams{
+		Context: ctx,
+	}
+	if name := r.Data.RouteTargetName(); name != "" {
+		params.Name = &name
+	}
+	if opts.Query != "" {
+		params.Q = &opts.Query
+	}
+	if opts.Tenant != "" {
+		id, err := r.resolveNameToID(ctx, opts.Tenant, "tenant")
+		if err != nil {
+			return err
+		}
+		tenant := strconv.FormatInt(id, 10)
+		params.TenantID = &tenant
+	}
+
+	return paginate(ctx, opts.ChunkSize, opts.Limit, func(offset, limit int64) (int, bool, error) {
+		params.Offset = &offset
+		params.Limit = &limit
+
+		targets, err := r.Client.Ipam.IpamRouteTargetsList(params, r.withQuery(opts.query()))
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to IpamRouteTargetsList, %w", err)
+		}
+		log.V(1).Info("found route targets", "count", targets.Payload.Count, "offset", offset)
+
+		for _, target := range targets.Payload.Results {
+			if err := fn(routeTargetFromModel(target)); err != nil {
+				return 0, false, err
+			}
+		}
+
+		return len(targets.Payload.Results), targets.Payload.Next != nil, nil
+	})
+}
+
+// routeTargetFromModel maps a Netbox route target to a RouteTarget named after the route target
+func routeTargetFromModel(target *models.RouteTarget) *netboxv1.RouteTarget {
+	spec := netboxv1.RouteTargetSpec{
+		Description: target.Description,
+		Tags:        tagSlugs(target.Tags),
+	}
+	if len(spec.Tags) == 0 {
+		spec.Tags = nil
+	}
+	if target.Name != nil {
+		spec.Name = *target.Name
+	}
+	if target.Tenant != nil && target.Tenant.Name != nil {
+		spec.Tenant = *target.Tenant.Name
+	}
+
+	id := target.ID
+	return &netboxv1.RouteTarget{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       netboxv1.RouteTargetKind,
+			APIVersion: netboxv1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: netboxv1.ResourceName("rt", spec.Name),
+		},
+		Spec: spec,
+		Status: netboxv1.ObjectStatus{
+			ID:    &id,
+			State: netboxv1.ObjectReadyState,
+		},
+	}
+}
@@ -0,0 +1,296 @@
+package netbox
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	"github.com/netbox-community/go-netbox/netbox/client/dcim"
+	"github.com/netbox-community/go-netbox/netbox/models"
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type Site struct {
+	Data *netboxv1.Site
+	NetboxServer
+
+	tenantID *int64
+	regionID *int64
+	groupID  *int64
+}
+
+func NewSite(s NetboxServer, site *netboxv1.Site) *Site {
+	return &Site{
+		Data:         site,
+		NetboxServer: s,
+	}
+}
+
+func (s *Site) resource() netboxv1.Object {
+	return s.Data
+}
+
+func (s *Site) typeName() string {
+	return "site"
+}
+
+func (s *Site) path() string {
+	return "/dcim/sites/"
+}
+
+func (s *Site) tags() []string {
+	return s.Data.Spec.Tags
+}
+
+// cacheKey implements cached, other objects refer to sites by name
+func (s *Site) cacheKey() (string, string) {
+	return "site", s.Data.SiteName()
+}
+
+// resolve looks up the tenant, the region and the site group
+func (s *Site) resolve(ctx context.Context) error {
+	spec := s.Data.Spec
+
+	if spec.Tenant != "" {
+		id, err := s.resolveNameToID(ctx, spec.Tenant, "tenant")
+		if err != nil {
+			return err
+		}
+		s.tenantID = &id
+	}
+
+	if spec.Region != "" {
+		id, err := s.lookupNameToID(ctx, spec.Region, "region")
+		if err != nil {
+			return err
+		}
+		s.regionID = &id
+	}
+
+	if spec.Group != "" {
+		id, err := s.lookupNameToID(ctx, spec.Group, "site group")
+		if err != nil {
+			return err
+		}
+		s.groupID = &id
+	}
+	return nil
+}
+
+func (s *Site) read(ctx context.Context, id int64) (*current, error) {
+	site, err := s.Client.Dcim.DcimSitesRead(&dcim.DcimSitesReadParams{
+		ID:      id,
+		Context: ctx,
+	}, nil)
+	if IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to DcimSitesRead, %w", err)
+	}
+
+	return siteCurrent(site.GetPayload()), nil
+}
+
+func siteCurrent(site *models.Site) *current {
+	return &current{ID: site.ID, Tags: site.Tags, model: site}
+}
+
+// lookup returns the site with the same name, site names are unique in Netbox
+func (s *Site) lookup(ctx context.Context) (*current, error) {
+	name := s.Data.SiteName()
+	result, err := s.Client.Dcim.DcimSitesList(&dcim.DcimSitesListParams{
+		Name:    &name,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to DcimSitesList, %w", err)
+	}
+	if *result.Payload.Count == 0 {
+		return nil, nil
+	}
+	return siteCurrent(result.Payload.Results[0]), nil
+}
+
+func (s *Site) slug() string {
+	if s.Data.Spec.Slug != "" {
+		return s.Data.Spec.Slug
+	}
+	return slugify(s.Data.SiteName())
+}
+
+func (s *Site) diff(cur *current) []string {
+	site := cur.model.(*models.Site)
+	spec := s.Data.Spec
+
+	changed := []string{}
+	if site.Name == nil || *site.Name != s.Data.SiteName() {
+		changed = append(changed, "name")
+	}
+	if site.Slug == nil || *site.Slug != s.slug() {
+		changed = append(changed, "slug")
+	}
+	if spec.Status != "" && (site.Status == nil || site.Status.Value == nil || *site.Status.Value != spec.Status) {
+		changed = append(changed, "status")
+	}
+	if s.tenantID != nil && (site.Tenant == nil || site.Tenant.ID != *s.tenantID) {
+		changed = append(changed, "tenant")
+	}
+	if s.regionID != nil && (site.Region == nil || site.Region.ID != *s.regionID) {
+		changed = append(changed, "region")
+	}
+	if s.groupID != nil && (site.Group == nil || site.Group.ID != *s.groupID) {
+		changed = append(changed, "group")
+	}
+	if spec.Facility != "" && site.Facility != spec.Facility {
+		changed = append(changed, "facility")
+	}
+	if spec.TimeZone != "" && site.TimeZone != spec.TimeZone {
+		changed = append(changed, "time_zone")
+	}
+	if spec.Description != "" && site.Description != spec.Description {
+		changed = append(changed, "description")
+	}
+	return changed
+}
+
+func (s *Site) writable(tags []*models.NestedTag) *models.WritableSite {
+	spec := s.Data.Spec
+	name, slug := s.Data.SiteName(), s.slug()
+	return &models.WritableSite{
+		Name:        &name,
+		Slug:        &slug,
+		Status:      spec.Status,
+		Tenant:      s.tenantID,
+		Region:      s.regionID,
+		Group:       s.groupID,
+		Facility:    spec.Facility,
+		TimeZone:    spec.TimeZone,
+		Description: spec.Description,
+		Tags:        tags,
+	}
+}
+
+func (s *Site) create(ctx context.Context, tags []*models.NestedTag) (int64, error) {
+	log := logr.FromContext(ctx)
+
+	site, err := s.Client.Dcim.DcimSitesCreate(&dcim.DcimSitesCreateParams{
+		Data:    s.writable(tags),
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to DcimSitesCreate, %w", err)
+	}
+	log.V(1).Info("created site", "response", site)
+
+	return site.GetPayload().ID, nil
+}
+
+func (s *Site) update(ctx context.Context, cur *current, tags []*models.NestedTag) (int64, error) {
+	log := logr.FromContext(ctx)
+
+	site, err := s.Client.Dcim.DcimSitesUpdate(&dcim.DcimSitesUpdateParams{
+		Data:    s.writable(tags),
+		ID:      cur.ID,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to DcimSitesUpdate, %w", err)
+	}
+	log.V(1).Info("updated site", "response", site)
+
+	return site.GetPayload().ID, nil
+}
+
+func (s *Site) each(ctx context.Context, opts ListOptions, fn func(netboxv1.Object) error) error {
+	log := logr.FromContext(ctx)
+
+	params := &dcim.DcimSitesListParams{
+		Context: ctx,
+	}
+	if name := s.Data.SiteName(); name != "" {
+		params.Name = &name
+	}
+	if opts.Status != "" {
+		params.Status = &opts.Status
+	}
+	if opts.Query != "" {
+		params.Q = &opts.Query
+	}
+	if opts.Tenant != "" {
+		id, err := s.resolveNameToID(ctx, opts.Tenant, "tenant")
+		if err != nil {
+			return err
+		}
+		tenant := strconv.FormatInt(id, 10)
+		params.TenantID = &tenant
+	}
+
+	return paginate(ctx, opts.ChunkSize, opts.Limit, func(offset, limit int64) (int, bool, error) {
+		params.Offset = &offset
+		params.Limit = &limit
+
+		sites, err := s.Client.Dcim.DcimSitesList(params, s.withQuery(opts.query()))
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to DcimSitesList, %w", err)
+		}
+		log.V(1).Info("found sites", "count", sites.Payload.Count, "offset", offset)
+
+		for _, site := range sites.Payload.Results {
+			if err := fn(siteFromModel(site)); err != nil {
+				return 0, false, err
+			}
+		}
+
+		return len(sites.Payload.Results), sites.Payload.Next != nil, nil
+	})
+}
+
+// siteFromModel maps a Netbox site to a Site named after the slug of the site
+func siteFromModel(site *models.Site) *netboxv1.Site {
+	spec := netboxv1.SiteSpec{
+		Facility:    site.Facility,
+		TimeZone:    site.TimeZone,
+		Description: site.Description,
+		Tags:        tagSlugs(site.Tags),
+	}
+	if len(spec.Tags) == 0 {
+		spec.Tags = nil
+	}
+	if site.Name != nil {
+		spec.Name = *site.Name
+	}
+	if site.Slug != nil {
+		spec.Slug = *site.Slug
+	}
+	if site.Status != nil && site.Status.Value != nil {
+		spec.Status = *site.Status.Value
+	}
+	if site.Tenant != nil && site.Tenant.Name != nil {
+		spec.Tenant = *site.Tenant.Name
+	}
+	if site.Region != nil && site.Region.Name != nil {
+		spec.Region = *site.Region.Name
+	}
+	if site.Group != nil && site.Group.Name != nil {
+		spec.Group = *site.Group.Name
+	}
+
+	id := site.ID
+	return &netboxv1.Site{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       netboxv1.SiteKind,
+			APIVersion: netboxv1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: netboxv1.ResourceName(spec.Slug),
+		},
+		Spec: spec,
+		Status: netboxv1.ObjectStatus{
+			ID:    &id,
+			State: netboxv1.ObjectReadyState,
+		},
+	}
+}
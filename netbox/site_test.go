@@ -0,0 +1,55 @@
+package netbox
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-logr/logr"
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+)
+
+func TestDeleteSiteForgetsCachedID(t *testing.T) {
+	ctx := logr.NewContext(context.Background(), logr.Discard())
+
+	// the site is recreated with a new ID after each delete
+	id := 1
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/dcim/sites/":
+			fmt.Fprintf(w, `{"count": 1, "results": [{"id": %d, "name": "CITC", "slug": "citc"}]}`, id)
+		case r.Method == http.MethodGet && r.URL.Path == fmt.Sprintf("/api/dcim/sites/%d/", id):
+			fmt.Fprintf(w, `{"id": %d, "name": "CITC", "slug": "citc"}`, id)
+		case r.Method == http.MethodDelete && r.URL.Path == fmt.Sprintf("/api/dcim/sites/%d/", id):
+			id++
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	s, err := NewNetboxServer(srv.URL, "token", WithRetry(RetryOptions{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := s.resolveNameToID(ctx, "CITC", "site")
+	if err != nil || got != 1 {
+		t.Fatalf("got site %d, %v, want 1", got, err)
+	}
+
+	site := &netboxv1.Site{Spec: netboxv1.SiteSpec{Name: "CITC"}}
+	if err := s.Delete(ctx, site); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	got, err = s.resolveNameToID(ctx, "CITC", "site")
+	if err != nil || got != 2 {
+		t.Errorf("got site %d, %v, want 2", got, err)
+	}
+}
@@ -0,0 +1,255 @@
+package netbox
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	"github.com/netbox-community/go-netbox/netbox/client/dcim"
+	"github.com/netbox-community/go-netbox/netbox/models"
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type SiteGroup struct {
+	Data *netboxv1.SiteGroup
+	NetboxServer
+
+	parentID *int64
+}
+
+func NewSiteGroup(s NetboxServer, g *netboxv1.SiteGroup) *SiteGroup {
+	return &SiteGroup{
+		Data:         g,
+		NetboxServer: s,
+	}
+}
+
+func (g *SiteGroup) resource() netboxv1.Object {
+	return g.Data
+}
+
+func (g *SiteGroup) typeName() string {
+	return "site group"
+}
+
+func (g *SiteGroup) path() string {
+	return "/dcim/site-groups/"
+}
+
+func (g *SiteGroup) tags() []string {
+	return nil
+}
+
+// untaggable implements untaggable, Netbox 3.0 site groups have no tags
+func (g *SiteGroup) untaggable() {}
+
+// children implements parent, Netbox deletes the child site groups of deleted site groups and detaches their sites
+func (g *SiteGroup) children(ctx context.Context, id int64) ([]string, error) {
+	parent := strconv.FormatInt(id, 10)
+	one := int64(1)
+	groups, err := g.Client.Dcim.DcimSiteGroupsList(&dcim.DcimSiteGroupsListParams{
+		ParentID: &parent,
+		Limit:    &one,
+		Context:  ctx,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to DcimSiteGroupsList, %w", err)
+	}
+	sites, err := g.Client.Dcim.DcimSitesList(&dcim.DcimSitesListParams{
+		GroupID: &parent,
+		Limit:   &one,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to DcimSitesList, %w", err)
+	}
+	return countChildren(
+		childCount{*groups.Payload.Count, "site group"},
+		childCount{*sites.Payload.Count, "site"},
+	), nil
+}
+
+// resolve looks up the parent group
+func (g *SiteGroup) resolve(ctx context.Context) error {
+	if g.Data.Spec.Parent == "" {
+		return nil
+	}
+	id, err := g.lookupNameToID(ctx, g.Data.Spec.Parent, "site group")
+	if err != nil {
+		return err
+	}
+	g.parentID = &id
+	return nil
+}
+
+func (g *SiteGroup) read(ctx context.Context, id int64) (*current, error) {
+	group, err := g.Client.Dcim.DcimSiteGroupsRead(&dcim.DcimSiteGroupsReadParams{
+		ID:      id,
+		Context: ctx,
+	}, nil)
+	if IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to DcimSiteGroupsRead, %w", err)
+	}
+
+	return siteGroupCurrent(group.GetPayload()), nil
+}
+
+func siteGroupCurrent(group *models.SiteGroup) *current {
+	return &current{ID: group.ID, Created: hasCreatedField(group.CustomFields), model: group}
+}
+
+// lookup returns the site group with the same name, site group names are unique in Netbox
+func (g *SiteGroup) lookup(ctx context.Context) (*current, error) {
+	name := g.Data.SiteGroupName()
+	result, err := g.Client.Dcim.DcimSiteGroupsList(&dcim.DcimSiteGroupsListParams{
+		Name:    &name,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to DcimSiteGroupsList, %w", err)
+	}
+	if *result.Payload.Count == 0 {
+		return nil, nil
+	}
+	return siteGroupCurrent(result.Payload.Results[0]), nil
+}
+
+func (g *SiteGroup) slug() string {
+	if g.Data.Spec.Slug != "" {
+		return g.Data.Spec.Slug
+	}
+	return slugify(g.Data.SiteGroupName())
+}
+
+func (g *SiteGroup) diff(cur *current) []string {
+	group := cur.model.(*models.SiteGroup)
+	spec := g.Data.Spec
+
+	changed := []string{}
+	if group.Name == nil || *group.Name != g.Data.SiteGroupName() {
+		changed = append(changed, "name")
+	}
+	if group.Slug == nil || *group.Slug != g.slug() {
+		changed = append(changed, "slug")
+	}
+	if g.parentID != nil && (group.Parent == nil || group.Parent.ID != *g.parentID) {
+		changed = append(changed, "parent")
+	}
+	if spec.Description != "" && group.Description != spec.Description {
+		changed = append(changed, "description")
+	}
+	return changed
+}
+
+func (g *SiteGroup) writable() *models.WritableSiteGroup {
+	name, slug := g.Data.SiteGroupName(), g.slug()
+	return &models.WritableSiteGroup{
+		Name:        &name,
+		Slug:        &slug,
+		Parent:      g.parentID,
+		Description: g.Data.Spec.Description,
+	}
+}
+
+func (g *SiteGroup) create(ctx context.Context, _ []*models.NestedTag) (int64, error) {
+	log := logr.FromContext(ctx)
+
+	data := g.writable()
+	data.CustomFields = createdCustomFields()
+	group, err := g.Client.Dcim.DcimSiteGroupsCreate(&dcim.DcimSiteGroupsCreateParams{
+		Data:    data,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to DcimSiteGroupsCreate, %w", err)
+	}
+	log.V(1).Info("created site group", "response", group)
+
+	return group.GetPayload().ID, nil
+}
+
+func (g *SiteGroup) update(ctx context.Context, cur *current, _ []*models.NestedTag) (int64, error) {
+	log := logr.FromContext(ctx)
+
+	group, err := g.Client.Dcim.DcimSiteGroupsUpdate(&dcim.DcimSiteGroupsUpdateParams{
+		Data:    g.writable(),
+		ID:      cur.ID,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to DcimSiteGroupsUpdate, %w", err)
+	}
+	log.V(1).Info("updated site group", "response", group)
+
+	return group.GetPayload().ID, nil
+}
+
+func (g *SiteGroup) each(ctx context.Context, opts ListOptions, fn func(netboxv1.Object) error) error {
+	log := logr.FromContext(ctx)
+
+	params := &dcim.DcimSiteGroupsListParams{
+		Context: ctx,
+	}
+	if name := g.Data.SiteGroupName(); name != "" {
+		params.Name = &name
+	}
+	if opts.Query != "" {
+		params.Q = &opts.Query
+	}
+
+	return paginate(ctx, opts.ChunkSize, opts.Limit, func(offset, limit int64) (int, bool, error) {
+		params.Offset = &offset
+		params.Limit = &limit
+
+		groups, err := g.Client.Dcim.DcimSiteGroupsList(params, g.withQuery(opts.query()))
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to DcimSiteGroupsList, %w", err)
+		}
+		log.V(1).Info("found site groups", "count", groups.Payload.Count, "offset", offset)
+
+		for _, group := range groups.Payload.Results {
+			if err := fn(siteGroupFromModel(group)); err != nil {
+				return 0, false, err
+			}
+		}
+
+		return len(groups.Payload.Results), groups.Payload.Next != nil, nil
+	})
+}
+
+// siteGroupFromModel maps a Netbox site group to a SiteGroup named after the slug of the group
+func siteGroupFromModel(group *models.SiteGroup) *netboxv1.SiteGroup {
+	spec := netboxv1.SiteGroupSpec{
+		Description: group.Description,
+	}
+	if group.Name != nil {
+		spec.Name = *group.Name
+	}
+	if group.Slug != nil {
+		spec.Slug = *group.Slug
+	}
+	if group.Parent != nil && group.Parent.Name != nil {
+		spec.Parent = *group.Parent.Name
+	}
+
+	id := group.ID
+	return &netboxv1.SiteGroup{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       netboxv1.SiteGroupKind,
+			APIVersion: netboxv1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: netboxv1.ResourceName(spec.Slug),
+		},
+		Spec: spec,
+		Status: netboxv1.ObjectStatus{
+			ID:    &id,
+			State: netboxv1.ObjectReadyState,
+		},
+	}
+}
@@ -0,0 +1,214 @@
+package netbox
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/netbox-community/go-netbox/netbox/client/extras"
+	"github.com/netbox-community/go-netbox/netbox/models"
+)
+
+// ManagedTag is attached to every object managed by declarative-netbox
+const (
+	ManagedTagName  = "declarative-netbox"
+	ManagedTagSlug  = "declarative-netbox"
+	managedTagColor = "2196f3"
+)
+
+// managedTag returns the tag that marks objects as managed, creating it in Netbox if necessary
+func (s *NetboxServer) managedTag(ctx context.Context) (*models.NestedTag, error) {
+	name, slug := ManagedTagName, ManagedTagSlug
+
+	if s.cache != nil {
+		if id, ok := s.cache.get("tag", slug); ok {
+			return &models.NestedTag{ID: id, Name: &name, Slug: &slug}, nil
+		}
+	}
+
+	tags, err := s.Client.Extras.ExtrasTagsList(&extras.ExtrasTagsListParams{
+		Slug:    &slug,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to ExtrasTagsList, %w", err)
+	}
+
+	var id int64
+	if *tags.GetPayload().Count > 0 {
+		id = tags.GetPayload().Results[0].ID
+	} else {
+		created, err := s.Client.Extras.ExtrasTagsCreate(&extras.ExtrasTagsCreateParams{
+			Data: &models.Tag{
+				Name:        &name,
+				Slug:        &slug,
+				Color:       managedTagColor,
+				Description: "Managed by declarative-netbox",
+			},
+			Context: ctx,
+		}, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to ExtrasTagsCreate, %w", err)
+		}
+		id = created.GetPayload().ID
+	}
+
+	if s.cache != nil {
+		s.cache.set("tag", slug, id)
+	}
+
+	return &models.NestedTag{ID: id, Name: &name, Slug: &slug}, nil
+}
+
+// hasManagedTag returns true if the list contains the managed tag
+func hasManagedTag(tags []*models.NestedTag) bool {
+	for _, t := range tags {
+		if t.Slug != nil && *t.Slug == ManagedTagSlug {
+			return true
+		}
+	}
+	return false
+}
+
+// withManagedTag returns the existing tags with the managed tag appended if missing,
+// managed is nil for the objects that can't be tagged
+func withManagedTag(tags []*models.NestedTag, managed *models.NestedTag) []*models.NestedTag {
+	if managed == nil || hasManagedTag(tags) {
+		return tags
+	}
+	return append(append([]*models.NestedTag{}, tags...), managed)
+}
+
+// withoutManagedTag returns the existing tags without the managed tag
+func withoutManagedTag(tags []*models.NestedTag) []*models.NestedTag {
+	result := []*models.NestedTag{}
+	for _, t := range tags {
+		if t.Slug != nil && *t.Slug == ManagedTagSlug {
+			continue
+		}
+		result = append(result, t)
+	}
+	return result
+}
+
+// resolveTags looks up the tags with the given slugs
+func (s *NetboxServer) resolveTags(ctx context.Context, slugs []string) ([]*models.NestedTag, error) {
+	result := []*models.NestedTag{}
+	for _, slug := range slugs {
+		slug := slug
+		tags, err := s.Client.Extras.ExtrasTagsList(&extras.ExtrasTagsListParams{
+			Slug:    &slug,
+			Context: ctx,
+		}, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to ExtrasTagsList, %w", err)
+		}
+		if *tags.GetPayload().Count == 0 {
+			return nil, &ReferenceNotFoundError{Type: "tag", Name: slug}
+		}
+		tag := tags.GetPayload().Results[0]
+		result = append(result, &models.NestedTag{ID: tag.ID, Name: tag.Name, Slug: tag.Slug})
+	}
+	return result, nil
+}
+
+// tagSlugs returns the slugs of the tags, except for the managed tag
+func tagSlugs(tags []*models.NestedTag) []string {
+	slugs := []string{}
+	for _, t := range withoutManagedTag(tags) {
+		if t.Slug != nil {
+			slugs = append(slugs, *t.Slug)
+		}
+	}
+	return slugs
+}
+
+// CreatedFieldName is the boolean custom field set on the objects created by declarative-netbox
+// that Netbox can't tag, e.g. locations
+const CreatedFieldName = "declarative_netbox"
+
+// createdFieldTypes are the content types of the objects that Netbox can't tag
+var createdFieldTypes = []string{
+	"dcim.location", "dcim.platform", "dcim.region", "dcim.sitegroup",
+	"ipam.vlangroup", "tenancy.tenantgroup", "virtualization.clustertype",
+}
+
+// createdCustomFields returns the custom fields marking an untaggable object as created by declarative-netbox
+func createdCustomFields() map[string]interface{} {
+	return map[string]interface{}{CreatedFieldName: true}
+}
+
+// hasCreatedField returns true if the custom fields of an object mark it as created by declarative-netbox
+func hasCreatedField(customFields interface{}) bool {
+	fields, _ := customFields.(map[string]interface{})
+	created, _ := fields[CreatedFieldName].(bool)
+	return created
+}
+
+// createdField makes sure that the created custom field exists for all untaggable objects,
+// creating it in Netbox or adding the missing content types if necessary
+func (s *NetboxServer) createdField(ctx context.Context) error {
+	name := CreatedFieldName
+
+	if s.cache != nil {
+		if _, ok := s.cache.get("custom field", name); ok {
+			return nil
+		}
+	}
+
+	fields, err := s.Client.Extras.ExtrasCustomFieldsList(&extras.ExtrasCustomFieldsListParams{
+		Name:    &name,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to ExtrasCustomFieldsList, %w", err)
+	}
+
+	var id int64
+	if *fields.GetPayload().Count > 0 {
+		field := fields.GetPayload().Results[0]
+		id = field.ID
+		if missing := missingSlugs(field.ContentTypes, createdFieldTypes); len(missing) > 0 {
+			err := s.patchObject(ctx, "/extras/custom-fields/", id, map[string]interface{}{
+				"content_types": append(field.ContentTypes, missing...),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	} else {
+		created, err := s.Client.Extras.ExtrasCustomFieldsCreate(&extras.ExtrasCustomFieldsCreateParams{
+			Data: &models.WritableCustomField{
+				Name:         &name,
+				Type:         "boolean",
+				Label:        "Created by declarative-netbox",
+				Description:  "Set on the objects without tags that declarative-netbox created and deletes",
+				ContentTypes: createdFieldTypes,
+			},
+			Context: ctx,
+		}, nil)
+		if err != nil {
+			return fmt.Errorf("failed to ExtrasCustomFieldsCreate, %w", err)
+		}
+		id = created.GetPayload().ID
+	}
+
+	if s.cache != nil {
+		s.cache.set("custom field", name, id)
+	}
+	return nil
+}
+
+// missingSlugs returns the slugs of want that are not in have
+func missingSlugs(have, want []string) []string {
+	var missing []string
+	for _, w := range want {
+		found := false
+		for _, h := range have {
+			found = found || h == w
+		}
+		if !found {
+			missing = append(missing, w)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,319 @@
+package netbox
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	"github.com/netbox-community/go-netbox/netbox/client/dcim"
+	"github.com/netbox-community/go-netbox/netbox/models"
+)
+
+// The templates of a device type are matched by name. Missing templates are created and the
+// fields that don't match the spec are patched, since the writable models omit false and empty
+// values. Templates missing from the spec are left in place
+
+// component is an interface, console port or power port of a device, or a template of one
+// of a device type. model is the go-netbox model
+type component struct {
+	ID    int64
+	Name  string
+	model interface{}
+}
+
+// templateKind holds what differs between the template kinds of a device type
+type templateKind struct {
+	// name is used in logs and errors, path is the API path of the templates
+	name, path string
+	// names of the templates in the spec
+	names []string
+	// list returns the templates of the device type with the ID
+	list func(ctx context.Context, id int64) ([]component, error)
+	// create creates the ith template of the spec for the device type with the ID
+	create func(ctx context.Context, id int64, i int) (interface{}, error)
+	// diff returns the fields of a Netbox template that don't match the ith template of the spec
+	diff func(cur interface{}, i int) map[string]interface{}
+}
+
+// applyTemplates creates and updates the templates of one kind for the device type with the ID
+func (t *DeviceType) applyTemplates(ctx context.Context, id int64, kind templateKind) (bool, error) {
+	log := logr.FromContext(ctx)
+	if len(kind.names) == 0 {
+		return false, nil
+	}
+
+	templates, err := kind.list(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	existing := map[string]component{}
+	for _, template := range templates {
+		existing[template.Name] = template
+	}
+
+	changed := false
+	for i, name := range kind.names {
+		cur, ok := existing[name]
+		if !ok {
+			template, err := kind.create(ctx, id, i)
+			if err != nil {
+				return false, err
+			}
+			log.V(1).Info("created "+kind.name, "response", template)
+			changed = true
+			continue
+		}
+
+		fields := kind.diff(cur.model, i)
+		if len(fields) == 0 {
+			continue
+		}
+		if err := t.patchObject(ctx, kind.path, cur.ID, fields); err != nil {
+			return false, fmt.Errorf("failed to update %s %q, %w", kind.name, name, err)
+		}
+		log.V(1).Info("updated "+kind.name, "id", cur.ID, "fields", fields)
+		changed = true
+	}
+	return changed, nil
+}
+
+func (t *DeviceType) interfaceTemplateKind() templateKind {
+	specs := t.Data.Spec.Interfaces
+	kind := templateKind{
+		name: "interface template",
+		path: "/dcim/interface-templates/",
+		list: t.interfaceTemplates,
+		create: func(ctx context.Context, id int64, i int) (interface{}, error) {
+			spec := specs[i]
+			template, err := t.Client.Dcim.DcimInterfaceTemplatesCreate(&dcim.DcimInterfaceTemplatesCreateParams{
+				Data: &models.WritableInterfaceTemplate{
+					DeviceType:  &id,
+					Name:        &spec.Name,
+					Type:        &spec.Type,
+					MgmtOnly:    spec.MgmtOnly,
+					Label:       spec.Label,
+					Description: spec.Description,
+				},
+				Context: ctx,
+			}, nil)
+			if err != nil {
+				return nil, fmt.Errorf("failed to DcimInterfaceTemplatesCreate %q, %w", spec.Name, err)
+			}
+			return template, nil
+		},
+		diff: func(model interface{}, i int) map[string]interface{} {
+			cur, spec := model.(*models.InterfaceTemplate), specs[i]
+			fields := map[string]interface{}{}
+			if cur.Type == nil || cur.Type.Value == nil || *cur.Type.Value != spec.Type {
+				fields["type"] = spec.Type
+			}
+			if cur.MgmtOnly != spec.MgmtOnly {
+				fields["mgmt_only"] = spec.MgmtOnly
+			}
+			templateLabels(fields, cur.Label, spec.Label, cur.Description, spec.Description)
+			return fields
+		},
+	}
+	for _, spec := range specs {
+		kind.names = append(kind.names, spec.Name)
+	}
+	return kind
+}
+
+func (t *DeviceType) consolePortTemplateKind() templateKind {
+	specs := t.Data.Spec.ConsolePorts
+	kind := templateKind{
+		name: "console port template",
+		path: "/dcim/console-port-templates/",
+		list: t.consolePortTemplates,
+		create: func(ctx context.Context, id int64, i int) (interface{}, error) {
+			spec := specs[i]
+			template, err := t.Client.Dcim.DcimConsolePortTemplatesCreate(&dcim.DcimConsolePortTemplatesCreateParams{
+				Data: &models.WritableConsolePortTemplate{
+					DeviceType:  &id,
+					Name:        &spec.Name,
+					Type:        spec.Type,
+					Label:       spec.Label,
+					Description: spec.Description,
+				},
+				Context: ctx,
+			}, nil)
+			if err != nil {
+				return nil, fmt.Errorf("failed to DcimConsolePortTemplatesCreate %q, %w", spec.Name, err)
+			}
+			return template, nil
+		},
+		diff: func(model interface{}, i int) map[string]interface{} {
+			cur, spec := model.(*models.ConsolePortTemplate), specs[i]
+			fields := map[string]interface{}{}
+			curType := ""
+			if cur.Type != nil && cur.Type.Value != nil {
+				curType = *cur.Type.Value
+			}
+			if curType != spec.Type {
+				fields["type"] = spec.Type
+			}
+			templateLabels(fields, cur.Label, spec.Label, cur.Description, spec.Description)
+			return fields
+		},
+	}
+	for _, spec := range specs {
+		kind.names = append(kind.names, spec.Name)
+	}
+	return kind
+}
+
+func (t *DeviceType) powerPortTemplateKind() templateKind {
+	specs := t.Data.Spec.PowerPorts
+	kind := templateKind{
+		name: "power port template",
+		path: "/dcim/power-port-templates/",
+		list: t.powerPortTemplates,
+		create: func(ctx context.Context, id int64, i int) (interface{}, error) {
+			spec := specs[i]
+			template, err := t.Client.Dcim.DcimPowerPortTemplatesCreate(&dcim.DcimPowerPortTemplatesCreateParams{
+				Data: &models.WritablePowerPortTemplate{
+					DeviceType:    &id,
+					Name:          &spec.Name,
+					Type:          spec.Type,
+					MaximumDraw:   optionalInt64(spec.MaximumDraw),
+					AllocatedDraw: optionalInt64(spec.AllocatedDraw),
+					Label:         spec.Label,
+					Description:   spec.Description,
+				},
+				Context: ctx,
+			}, nil)
+			if err != nil {
+				return nil, fmt.Errorf("failed to DcimPowerPortTemplatesCreate %q, %w", spec.Name, err)
+			}
+			return template, nil
+		},
+		diff: func(model interface{}, i int) map[string]interface{} {
+			cur, spec := model.(*models.PowerPortTemplate), specs[i]
+			fields := map[string]interface{}{}
+			curType := ""
+			if cur.Type != nil && cur.Type.Value != nil {
+				curType = *cur.Type.Value
+			}
+			if curType != spec.Type {
+				fields["type"] = spec.Type
+			}
+			if !sameDraw(cur.MaximumDraw, spec.MaximumDraw) {
+				fields["maximum_draw"] = optionalInt64(spec.MaximumDraw)
+			}
+			if !sameDraw(cur.AllocatedDraw, spec.AllocatedDraw) {
+				fields["allocated_draw"] = optionalInt64(spec.AllocatedDraw)
+			}
+			templateLabels(fields, cur.Label, spec.Label, cur.Description, spec.Description)
+			return fields
+		},
+	}
+	for _, spec := range specs {
+		kind.names = append(kind.names, spec.Name)
+	}
+	return kind
+}
+
+// interfaceTemplates lists the interface templates of the device type with the ID
+func (s *NetboxServer) interfaceTemplates(ctx context.Context, id int64) ([]component, error) {
+	var templates []component
+	params := &dcim.DcimInterfaceTemplatesListParams{
+		DevicetypeID: int64String(id),
+		Context:      ctx,
+	}
+	err := paginate(ctx, 0, 0, func(offset, limit int64) (int, bool, error) {
+		params.Offset = &offset
+		params.Limit = &limit
+		result, err := s.Client.Dcim.DcimInterfaceTemplatesList(params, nil)
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to DcimInterfaceTemplatesList, %w", err)
+		}
+		for _, template := range result.Payload.Results {
+			if template.Name != nil {
+				templates = append(templates, component{ID: template.ID, Name: *template.Name, model: template})
+			}
+		}
+		return len(result.Payload.Results), result.Payload.Next != nil, nil
+	})
+	return templates, err
+}
+
+// consolePortTemplates lists the console port templates of the device type with the ID
+func (s *NetboxServer) consolePortTemplates(ctx context.Context, id int64) ([]component, error) {
+	var templates []component
+	params := &dcim.DcimConsolePortTemplatesListParams{
+		DevicetypeID: int64String(id),
+		Context:      ctx,
+	}
+	err := paginate(ctx, 0, 0, func(offset, limit int64) (int, bool, error) {
+		params.Offset = &offset
+		params.Limit = &limit
+		result, err := s.Client.Dcim.DcimConsolePortTemplatesList(params, nil)
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to DcimConsolePortTemplatesList, %w", err)
+		}
+		for _, template := range result.Payload.Results {
+			if template.Name != nil {
+				templates = append(templates, component{ID: template.ID, Name: *template.Name, model: template})
+			}
+		}
+		return len(result.Payload.Results), result.Payload.Next != nil, nil
+	})
+	return templates, err
+}
+
+// powerPortTemplates lists the power port templates of the device type with the ID
+func (s *NetboxServer) powerPortTemplates(ctx context.Context, id int64) ([]component, error) {
+	var templates []component
+	params := &dcim.DcimPowerPortTemplatesListParams{
+		DevicetypeID: int64String(id),
+		Context:      ctx,
+	}
+	err := paginate(ctx, 0, 0, func(offset, limit int64) (int, bool, error) {
+		params.Offset = &offset
+		params.Limit = &limit
+		result, err := s.Client.Dcim.DcimPowerPortTemplatesList(params, nil)
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to DcimPowerPortTemplatesList, %w", err)
+		}
+		for _, template := range result.Payload.Results {
+			if template.Name != nil {
+				templates = append(templates, component{ID: template.ID, Name: *template.Name, model: template})
+			}
+		}
+		return len(result.Payload.Results), result.Payload.Next != nil, nil
+	})
+	return templates, err
+}
+
+// templateLabels adds the label and the description to the patched fields if they changed
+func templateLabels(fields map[string]interface{}, curLabel, label, curDescription, description string) {
+	if curLabel != label {
+		fields["label"] = label
+	}
+	if curDescription != description {
+		fields["description"] = description
+	}
+}
+
+// sameDraw compares a power draw of Netbox with the spec, where 0 means unset
+func sameDraw(cur *int64, draw int64) bool {
+	if cur == nil {
+		return draw == 0
+	}
+	return *cur == draw
+}
+
+// optionalInt64 returns nil for 0, i.e. unset
+func optionalInt64(v int64) *int64 {
+	if v == 0 {
+		return nil
+	}
+	return &v
+}
+
+func int64String(v int64) *string {
+	s := strconv.FormatInt(v, 10)
+	return &s
+}
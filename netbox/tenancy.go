@@ -0,0 +1,146 @@
+package netbox
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/netbox-community/go-netbox/netbox/client/dcim"
+	"github.com/netbox-community/go-netbox/netbox/client/virtualization"
+	"github.com/netbox-community/go-netbox/netbox/models"
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+)
+
+// host is a device or a virtual machine, identified by its type and name, e.g. "device" and leaf-01
+type host struct {
+	typ  string
+	name string
+}
+
+// attached is implemented by the objects that are part of devices or virtual machines, e.g.
+// interfaces. They belong to the tenants of their devices and virtual machines
+type attached interface {
+	// hosts returns the devices and virtual machines of the spec and of the existing Netbox object, if any
+	hosts(cur *current) []host
+}
+
+// Tenants returns the names of the tenants owning the Netbox objects that applying or deleting
+// the object changes: its existing Netbox object and the devices and virtual machines that
+// interfaces, cables and IP addresses are attached to. Objects without a tenant are returned
+// as "". Kinds that don't belong to tenants, e.g. regions, return nil
+func (s *NetboxServer) Tenants(ctx context.Context, object interface{}) ([]string, error) {
+	if dev, ok := object.(*netboxv1.Device); ok {
+		nbDev, found, err := NewDevice(*s, dev).exists(ctx)
+		if err != nil || !found {
+			return nil, err
+		}
+		return []string{nestedTenantName(nbDev.Tenant)}, nil
+	}
+
+	obj := s.object(object)
+	if obj == nil {
+		return nil, nil
+	}
+	l := s.lifecycle(obj)
+
+	// objects whose references don't exist yet can't be found, but their hosts may exist
+	cur, err := l.find(ctx)
+	if err != nil && !IsReferenceNotFound(err) {
+		return nil, err
+	}
+
+	var tenants []string
+	if cur != nil {
+		if tenant, ok := modelTenant(cur.model); ok {
+			tenants = append(tenants, tenant)
+		}
+	}
+	if a, ok := obj.(attached); ok {
+		seen := map[host]bool{}
+		for _, h := range a.hosts(cur) {
+			if seen[h] {
+				continue
+			}
+			seen[h] = true
+			found, err := s.hostTenants(ctx, h)
+			if err != nil {
+				return nil, err
+			}
+			tenants = append(tenants, found...)
+		}
+	}
+	return tenants, nil
+}
+
+// modelTenant returns the tenant of a Netbox object, tenants belong to themselves. It returns
+// false for the types of objects without a tenant
+func modelTenant(model interface{}) (string, bool) {
+	switch m := model.(type) {
+	case *models.Tenant:
+		if m.Name == nil {
+			return "", true
+		}
+		return *m.Name, true
+	case *models.Site:
+		return nestedTenantName(m.Tenant), true
+	case *models.Rack:
+		return nestedTenantName(m.Tenant), true
+	case *models.VLAN:
+		return nestedTenantName(m.Tenant), true
+	case *models.VRF:
+		return nestedTenantName(m.Tenant), true
+	case *models.RouteTarget:
+		return nestedTenantName(m.Tenant), true
+	case *models.Prefix:
+		return nestedTenantName(m.Tenant), true
+	case *models.IPAddress:
+		return nestedTenantName(m.Tenant), true
+	case *models.Cluster:
+		return nestedTenantName(m.Tenant), true
+	case *models.VirtualMachineWithConfigContext:
+		return nestedTenantName(m.Tenant), true
+	}
+	return "", false
+}
+
+func nestedTenantName(tenant *models.NestedTenant) string {
+	if tenant == nil || tenant.Name == nil {
+		return ""
+	}
+	return *tenant.Name
+}
+
+// hostTenants returns the tenants of the devices or virtual machines with the name, none if they don't exist
+func (s *NetboxServer) hostTenants(ctx context.Context, h host) ([]string, error) {
+	if h.name == "" {
+		return nil, nil
+	}
+
+	var tenants []string
+	switch h.typ {
+	case "device":
+		result, err := s.Client.Dcim.DcimDevicesList(&dcim.DcimDevicesListParams{
+			Name:    &h.name,
+			Context: ctx,
+		}, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to DcimDevicesList, %w", err)
+		}
+		for _, dev := range result.Payload.Results {
+			tenants = append(tenants, nestedTenantName(dev.Tenant))
+		}
+	case "virtual machine":
+		result, err := s.Client.Virtualization.VirtualizationVirtualMachinesList(&virtualization.VirtualizationVirtualMachinesListParams{
+			Name:    &h.name,
+			Context: ctx,
+		}, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to VirtualizationVirtualMachinesList, %w", err)
+		}
+		for _, vm := range result.Payload.Results {
+			tenants = append(tenants, nestedTenantName(vm.Tenant))
+		}
+	default:
+		return nil, fmt.Errorf("unexpected host type %q", h.typ)
+	}
+	return tenants, nil
+}
@@ -0,0 +1,71 @@
+package netbox
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-logr/logr"
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeTenancyNetbox serves the device leaf-01 of tenant team-a with the interface eth1, the
+// device leaf-02 of tenant team-b with the interface eth2 and the site lon1 of tenant team-b
+func fakeTenancyNetbox(t *testing.T) *fakeNetbox {
+	leaf1 := `{"id": 5, "name": "leaf-01", "tenant": {"id": 1, "name": "team-a", "slug": "team-a"}}`
+	leaf2 := `{"id": 6, "name": "leaf-02", "tenant": {"id": 2, "name": "team-b", "slug": "team-b"}}`
+	eth1 := `{"id": 9, "name": "eth1", "device": {"id": 5, "name": "leaf-01"}}`
+	return newFakeNetbox(t,
+		onGet("/api/dcim/devices/", page(leaf1), "name", "leaf-01"),
+		onGet("/api/dcim/devices/", page(leaf2), "name", "leaf-02"),
+		onGet("/api/dcim/devices/", page()),
+		onGet("/api/dcim/interfaces/", page(eth1), "device_id", "5", "name", "eth1"),
+		onGet("/api/dcim/interfaces/", page()),
+		onGet("/api/dcim/interfaces/12/", `{"id": 12, "name": "eth2", "device": {"id": 6, "name": "leaf-02"}}`),
+		onGet("/api/dcim/sites/", page(`{"id": 3, "name": "lon1", "slug": "lon1", "tenant": {"id": 2, "name": "team-b", "slug": "team-b"}}`), "name", "lon1"),
+		onGet("/api/dcim/sites/", page()),
+	)
+}
+
+func TestTenants(t *testing.T) {
+	ctx := logr.NewContext(context.Background(), logr.Discard())
+
+	intf := func(device, name string, id int64) *netboxv1.Interface {
+		i := &netboxv1.Interface{
+			ObjectMeta: metav1.ObjectMeta{Name: device + "-" + name},
+			Spec:       netboxv1.InterfaceSpec{Device: device, Name: name},
+		}
+		if id != 0 {
+			i.Status.ID = &id
+		}
+		return i
+	}
+
+	tests := []struct {
+		name   string
+		object interface{}
+		want   []string
+	}{
+		{name: "new interface", object: intf("leaf-01", "eth3", 0), want: []string{"team-a"}},
+		{name: "existing interface", object: intf("leaf-01", "eth1", 0), want: []string{"team-a"}},
+		{name: "interface of another tenant", object: intf("leaf-02", "eth3", 0), want: []string{"team-b"}},
+		{name: "interface moved from another tenant", object: intf("leaf-01", "eth2", 12), want: []string{"team-a", "team-b"}},
+		{name: "missing device", object: intf("leaf-03", "eth1", 0)},
+		{name: "existing site", object: &netboxv1.Site{ObjectMeta: metav1.ObjectMeta{Name: "lon1"}}, want: []string{"team-b"}},
+		{name: "new site", object: &netboxv1.Site{ObjectMeta: metav1.ObjectMeta{Name: "lon2"}}},
+		{name: "device", object: &netboxv1.Device{ObjectMeta: metav1.ObjectMeta{Name: "leaf-02"}}, want: []string{"team-b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fakeTenancyNetbox(t).netbox().Tenants(ctx, tt.object)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected tenants %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
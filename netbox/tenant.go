@@ -0,0 +1,242 @@
+package netbox
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	"github.com/netbox-community/go-netbox/netbox/client/tenancy"
+	"github.com/netbox-community/go-netbox/netbox/models"
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type Tenant struct {
+	Data *netboxv1.Tenant
+	NetboxServer
+
+	groupID *int64
+}
+
+func NewTenant(s NetboxServer, t *netboxv1.Tenant) *Tenant {
+	return &Tenant{
+		Data:         t,
+		NetboxServer: s,
+	}
+}
+
+func (t *Tenant) resource() netboxv1.Object {
+	return t.Data
+}
+
+func (t *Tenant) typeName() string {
+	return "tenant"
+}
+
+func (t *Tenant) path() string {
+	return "/tenancy/tenants/"
+}
+
+func (t *Tenant) tags() []string {
+	return t.Data.Spec.Tags
+}
+
+// cacheKey implements cached, other objects refer to tenants by name
+func (t *Tenant) cacheKey() (string, string) {
+	return "tenant", t.Data.TenantName()
+}
+
+// resolve looks up the tenant group
+func (t *Tenant) resolve(ctx context.Context) error {
+	if t.Data.Spec.Group == "" {
+		return nil
+	}
+	id, err := t.lookupNameToID(ctx, t.Data.Spec.Group, "tenant group")
+	if err != nil {
+		return err
+	}
+	t.groupID = &id
+	return nil
+}
+
+func (t *Tenant) read(ctx context.Context, id int64) (*current, error) {
+	tenant, err := t.Client.Tenancy.TenancyTenantsRead(&tenancy.TenancyTenantsReadParams{
+		ID:      id,
+		Context: ctx,
+	}, nil)
+	if IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to TenancyTenantsRead, %w", err)
+	}
+
+	return tenantCurrent(tenant.GetPayload()), nil
+}
+
+func tenantCurrent(tenant *models.Tenant) *current {
+	return &current{ID: tenant.ID, Tags: tenant.Tags, model: tenant}
+}
+
+// lookup returns the tenant with the same name, tenant names are unique in Netbox
+func (t *Tenant) lookup(ctx context.Context) (*current, error) {
+	name := t.Data.TenantName()
+	result, err := t.Client.Tenancy.TenancyTenantsList(&tenancy.TenancyTenantsListParams{
+		Name:    &name,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to TenancyTenantsList, %w", err)
+	}
+	if *result.Payload.Count == 0 {
+		return nil, nil
+	}
+	return tenantCurrent(result.Payload.Results[0]), nil
+}
+
+func (t *Tenant) slug() string {
+	if t.Data.Spec.Slug != "" {
+		return t.Data.Spec.Slug
+	}
+	return slugify(t.Data.TenantName())
+}
+
+func (t *Tenant) diff(cur *current) []string {
+	tenant := cur.model.(*models.Tenant)
+	spec := t.Data.Spec
+
+	changed := []string{}
+	if tenant.Name == nil || *tenant.Name != t.Data.TenantName() {
+		changed = append(changed, "name")
+	}
+	if tenant.Slug == nil || *tenant.Slug != t.slug() {
+		changed = append(changed, "slug")
+	}
+	if t.groupID != nil && (tenant.Group == nil || tenant.Group.ID != *t.groupID) {
+		changed = append(changed, "group")
+	}
+	if spec.Description != "" && tenant.Description != spec.Description {
+		changed = append(changed, "description")
+	}
+	return changed
+}
+
+func (t *Tenant) writable(tags []*models.NestedTag) *models.WritableTenant {
+	name, slug := t.Data.TenantName(), t.slug()
+	return &models.WritableTenant{
+		Name:        &name,
+		Slug:        &slug,
+		Group:       t.groupID,
+		Description: t.Data.Spec.Description,
+		Tags:        tags,
+	}
+}
+
+func (t *Tenant) create(ctx context.Context, tags []*models.NestedTag) (int64, error) {
+	log := logr.FromContext(ctx)
+
+	tenant, err := t.Client.Tenancy.TenancyTenantsCreate(&tenancy.TenancyTenantsCreateParams{
+		Data:    t.writable(tags),
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to TenancyTenantsCreate, %w", err)
+	}
+	log.V(1).Info("created tenant", "response", tenant)
+
+	return tenant.GetPayload().ID, nil
+}
+
+func (t *Tenant) update(ctx context.Context, cur *current, tags []*models.NestedTag) (int64, error) {
+	log := logr.FromContext(ctx)
+
+	tenant, err := t.Client.Tenancy.TenancyTenantsUpdate(&tenancy.TenancyTenantsUpdateParams{
+		Data:    t.writable(tags),
+		ID:      cur.ID,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to TenancyTenantsUpdate, %w", err)
+	}
+	log.V(1).Info("updated tenant", "response", tenant)
+
+	return tenant.GetPayload().ID, nil
+}
+
+func (t *Tenant) each(ctx context.Context, opts ListOptions, fn func(netboxv1.Object) error) error {
+	log := logr.FromContext(ctx)
+
+	params := &tenancy.TenancyTenantsListParams{
+		Context: ctx,
+	}
+	if name := t.Data.TenantName(); name != "" {
+		params.Name = &name
+	}
+	if t.Data.Spec.Group != "" {
+		id, err := t.lookupNameToID(ctx, t.Data.Spec.Group, "tenant group")
+		if err != nil {
+			return err
+		}
+		group := strconv.FormatInt(id, 10)
+		params.GroupID = &group
+	}
+	if opts.Query != "" {
+		params.Q = &opts.Query
+	}
+
+	return paginate(ctx, opts.ChunkSize, opts.Limit, func(offset, limit int64) (int, bool, error) {
+		params.Offset = &offset
+		params.Limit = &limit
+
+		tenants, err := t.Client.Tenancy.TenancyTenantsList(params, t.withQuery(opts.query()))
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to TenancyTenantsList, %w", err)
+		}
+		log.V(1).Info("found tenants", "count", tenants.Payload.Count, "offset", offset)
+
+		for _, tenant := range tenants.Payload.Results {
+			if err := fn(tenantFromModel(tenant)); err != nil {
+				return 0, false, err
+			}
+		}
+
+		return len(tenants.Payload.Results), tenants.Payload.Next != nil, nil
+	})
+}
+
+// tenantFromModel maps a Netbox tenant to a Tenant named after the slug of the tenant
+func tenantFromModel(tenant *models.Tenant) *netboxv1.Tenant {
+	spec := netboxv1.TenantSpec{
+		Description: tenant.Description,
+		Tags:        tagSlugs(tenant.Tags),
+	}
+	if len(spec.Tags) == 0 {
+		spec.Tags = nil
+	}
+	if tenant.Name != nil {
+		spec.Name = *tenant.Name
+	}
+	if tenant.Slug != nil {
+		spec.Slug = *tenant.Slug
+	}
+	if tenant.Group != nil && tenant.Group.Name != nil {
+		spec.Group = *tenant.Group.Name
+	}
+
+	id := tenant.ID
+	return &netboxv1.Tenant{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       netboxv1.TenantKind,
+			APIVersion: netboxv1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: netboxv1.ResourceName(spec.Slug),
+		},
+		Spec: spec,
+		Status: netboxv1.ObjectStatus{
+			ID:    &id,
+			State: netboxv1.ObjectReadyState,
+		},
+	}
+}
@@ -0,0 +1,228 @@
+package netbox
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/netbox-community/go-netbox/netbox/client/tenancy"
+	"github.com/netbox-community/go-netbox/netbox/models"
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type TenantGroup struct {
+	Data *netboxv1.TenantGroup
+	NetboxServer
+
+	parentID *int64
+}
+
+func NewTenantGroup(s NetboxServer, g *netboxv1.TenantGroup) *TenantGroup {
+	return &TenantGroup{
+		Data:         g,
+		NetboxServer: s,
+	}
+}
+
+func (g *TenantGroup) resource() netboxv1.Object {
+	return g.Data
+}
+
+func (g *TenantGroup) typeName() string {
+	return "tenant group"
+}
+
+func (g *TenantGroup) path() string {
+	return "/tenancy/tenant-groups/"
+}
+
+func (g *TenantGroup) tags() []string {
+	return nil
+}
+
+// untaggable implements untaggable, Netbox 3.0 tenant groups have no tags
+func (g *TenantGroup) untaggable() {}
+
+// resolve looks up the parent group
+func (g *TenantGroup) resolve(ctx context.Context) error {
+	if g.Data.Spec.Parent == "" {
+		return nil
+	}
+	id, err := g.lookupNameToID(ctx, g.Data.Spec.Parent, "tenant group")
+	if err != nil {
+		return err
+	}
+	g.parentID = &id
+	return nil
+}
+
+func (g *TenantGroup) read(ctx context.Context, id int64) (*current, error) {
+	group, err := g.Client.Tenancy.TenancyTenantGroupsRead(&tenancy.TenancyTenantGroupsReadParams{
+		ID:      id,
+		Context: ctx,
+	}, nil)
+	if IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to TenancyTenantGroupsRead, %w", err)
+	}
+
+	return tenantGroupCurrent(group.GetPayload()), nil
+}
+
+func tenantGroupCurrent(group *models.TenantGroup) *current {
+	return &current{ID: group.ID, Created: hasCreatedField(group.CustomFields), model: group}
+}
+
+// lookup returns the tenant group with the same name, tenant group names are unique in Netbox
+func (g *TenantGroup) lookup(ctx context.Context) (*current, error) {
+	name := g.Data.TenantGroupName()
+	result, err := g.Client.Tenancy.TenancyTenantGroupsList(&tenancy.TenancyTenantGroupsListParams{
+		Name:    &name,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to TenancyTenantGroupsList, %w", err)
+	}
+	if *result.Payload.Count == 0 {
+		return nil, nil
+	}
+	return tenantGroupCurrent(result.Payload.Results[0]), nil
+}
+
+func (g *TenantGroup) slug() string {
+	if g.Data.Spec.Slug != "" {
+		return g.Data.Spec.Slug
+	}
+	return slugify(g.Data.TenantGroupName())
+}
+
+func (g *TenantGroup) diff(cur *current) []string {
+	group := cur.model.(*models.TenantGroup)
+	spec := g.Data.Spec
+
+	changed := []string{}
+	if group.Name == nil || *group.Name != g.Data.TenantGroupName() {
+		changed = append(changed, "name")
+	}
+	if group.Slug == nil || *group.Slug != g.slug() {
+		changed = append(changed, "slug")
+	}
+	if g.parentID != nil && (group.Parent == nil || group.Parent.ID != *g.parentID) {
+		changed = append(changed, "parent")
+	}
+	if spec.Description != "" && group.Description != spec.Description {
+		changed = append(changed, "description")
+	}
+	return changed
+}
+
+func (g *TenantGroup) writable() *models.WritableTenantGroup {
+	name, slug := g.Data.TenantGroupName(), g.slug()
+	return &models.WritableTenantGroup{
+		Name:        &name,
+		Slug:        &slug,
+		Parent:      g.parentID,
+		Description: g.Data.Spec.Description,
+	}
+}
+
+func (g *TenantGroup) create(ctx context.Context, _ []*models.NestedTag) (int64, error) {
+	log := logr.FromContext(ctx)
+
+	data := g.writable()
+	data.CustomFields = createdCustomFields()
+	group, err := g.Client.Tenancy.TenancyTenantGroupsCreate(&tenancy.TenancyTenantGroupsCreateParams{
+		Data:    data,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to TenancyTenantGroupsCreate, %w", err)
+	}
+	log.V(1).Info("created tenant group", "response", group)
+
+	return group.GetPayload().ID, nil
+}
+
+func (g *TenantGroup) update(ctx context.Context, cur *current, _ []*models.NestedTag) (int64, error) {
+	log := logr.FromContext(ctx)
+
+	group, err := g.Client.Tenancy.TenancyTenantGroupsUpdate(&tenancy.TenancyTenantGroupsUpdateParams{
+		Data:    g.writable(),
+		ID:      cur.ID,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to TenancyTenantGroupsUpdate, %w", err)
+	}
+	log.V(1).Info("updated tenant group", "response", group)
+
+	return group.GetPayload().ID, nil
+}
+
+func (g *TenantGroup) each(ctx context.Context, opts ListOptions, fn func(netboxv1.Object) error) error {
+	log := logr.FromContext(ctx)
+
+	params := &tenancy.TenancyTenantGroupsListParams{
+		Context: ctx,
+	}
+	if name := g.Data.TenantGroupName(); name != "" {
+		params.Name = &name
+	}
+	if opts.Query != "" {
+		params.Q = &opts.Query
+	}
+
+	return paginate(ctx, opts.ChunkSize, opts.Limit, func(offset, limit int64) (int, bool, error) {
+		params.Offset = &offset
+		params.Limit = &limit
+
+		groups, err := g.Client.Tenancy.TenancyTenantGroupsList(params, g.withQuery(opts.query()))
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to TenancyTenantGroupsList, %w", err)
+		}
+		log.V(1).Info("found tenant groups", "count", groups.Payload.Count, "offset", offset)
+
+		for _, group := range groups.Payload.Results {
+			if err := fn(tenantGroupFromModel(group)); err != nil {
+				return 0, false, err
+			}
+		}
+
+		return len(groups.Payload.Results), groups.Payload.Next != nil, nil
+	})
+}
+
+// tenantGroupFromModel maps a Netbox tenant group to a TenantGroup named after the slug of the group
+func tenantGroupFromModel(group *models.TenantGroup) *netboxv1.TenantGroup {
+	spec := netboxv1.TenantGroupSpec{
+		Description: group.Description,
+	}
+	if group.Name != nil {
+		spec.Name = *group.Name
+	}
+	if group.Slug != nil {
+		spec.Slug = *group.Slug
+	}
+	if group.Parent != nil && group.Parent.Name != nil {
+		spec.Parent = *group.Parent.Name
+	}
+
+	id := group.ID
+	return &netboxv1.TenantGroup{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       netboxv1.TenantGroupKind,
+			APIVersion: netboxv1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: netboxv1.ResourceName(spec.Slug),
+		},
+		Spec: spec,
+		Status: netboxv1.ObjectStatus{
+			ID:    &id,
+			State: netboxv1.ObjectReadyState,
+		},
+	}
+}
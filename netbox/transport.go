@@ -0,0 +1,110 @@
+package netbox
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+const defaultTimeout = time.Second * 20
+
+// TransportOptions defines how to connect to the Netbox API.
+// The same options are used by nbctl and the controller.
+type TransportOptions struct {
+	// CAFile is a PEM bundle used to verify the Netbox server certificate
+	CAFile string `json:"caFile,omitempty"`
+	// CertFile and KeyFile are the client certificate and key for mutual TLS
+	CertFile string `json:"certFile,omitempty"`
+	KeyFile  string `json:"keyFile,omitempty"`
+	// InsecureSkipVerify disables server certificate verification
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+	// Proxy is the URL of the HTTP proxy, defaults to the environment
+	Proxy string `json:"proxy,omitempty"`
+	// Timeout is the timeout of each attempt of a request sent to the Netbox API, the
+	// retries of the same request get a new timeout
+	Timeout time.Duration `json:"timeout,omitempty"`
+}
+
+// HTTPClient builds an http.Client from the transport options
+func (o TransportOptions) HTTPClient() (*http.Client, error) {
+	tlsConfig, err := o.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	if o.Proxy != "" {
+		proxyURL, err := url.Parse(o.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse proxy URL %q: %s", o.Proxy, err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	timeout := o.Timeout
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+	}, nil
+}
+
+func (o TransportOptions) tlsConfig() (*tls.Config, error) {
+	cfg := &tls.Config{
+		InsecureSkipVerify: o.InsecureSkipVerify,
+	}
+
+	if o.CAFile != "" {
+		pem, err := ioutil.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file %q: %s", o.CAFile, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %q", o.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if o.CertFile != "" || o.KeyFile != "" {
+		if o.CertFile == "" || o.KeyFile == "" {
+			return nil, fmt.Errorf("both client certificate and key must be provided")
+		}
+		cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %s", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
+// parseServer splits a Netbox server address into scheme, host and API base path.
+// The address can be a full URL (https://netbox.example.com/netbox) or just a host[:port]
+func parseServer(server string) (scheme, host, basePath string, err error) {
+	if !strings.Contains(server, "://") {
+		server = "http://" + server
+	}
+
+	u, err := url.Parse(server)
+	if err != nil {
+		return "", "", "", err
+	}
+	if u.Host == "" {
+		return "", "", "", fmt.Errorf("no host found in %q", server)
+	}
+
+	return u.Scheme, u.Host, path.Join("/", u.Path, "api"), nil
+}
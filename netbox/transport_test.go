@@ -0,0 +1,37 @@
+package netbox
+
+import "testing"
+
+func TestParseServer(t *testing.T) {
+	tests := []struct {
+		server   string
+		scheme   string
+		host     string
+		basePath string
+		wantErr  bool
+	}{
+		{server: "k8s-netbox.default", scheme: "http", host: "k8s-netbox.default", basePath: "/api"},
+		{server: "http://localhost:32178", scheme: "http", host: "localhost:32178", basePath: "/api"},
+		{server: "https://netbox.example.com/netbox/", scheme: "https", host: "netbox.example.com", basePath: "/netbox/api"},
+		{server: "https://", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		scheme, host, basePath, err := parseServer(tt.server)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("parseServer(%q) error = %v, wantErr %v", tt.server, err, tt.wantErr)
+		}
+		if tt.wantErr {
+			continue
+		}
+		if scheme != tt.scheme || host != tt.host || basePath != tt.basePath {
+			t.Errorf("parseServer(%q) = %q, %q, %q, want %q, %q, %q", tt.server, scheme, host, basePath, tt.scheme, tt.host, tt.basePath)
+		}
+	}
+}
+
+func TestTransportOptionsKeyPair(t *testing.T) {
+	if _, err := (TransportOptions{CertFile: "cert.pem"}).HTTPClient(); err == nil {
+		t.Errorf("expected an error when the client key is missing")
+	}
+}
@@ -0,0 +1,332 @@
+package netbox
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	"github.com/netbox-community/go-netbox/netbox/client/virtualization"
+	"github.com/netbox-community/go-netbox/netbox/models"
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type VirtualMachine struct {
+	Data *netboxv1.VirtualMachine
+	NetboxServer
+
+	clusterID  int64
+	roleID     *int64
+	platformID *int64
+	tenantID   *int64
+}
+
+func NewVirtualMachine(s NetboxServer, v *netboxv1.VirtualMachine) *VirtualMachine {
+	return &VirtualMachine{
+		Data:         v,
+		NetboxServer: s,
+	}
+}
+
+func (v *VirtualMachine) resource() netboxv1.Object {
+	return v.Data
+}
+
+func (v *VirtualMachine) typeName() string {
+	return "virtual machine"
+}
+
+func (v *VirtualMachine) path() string {
+	return "/virtualization/virtual-machines/"
+}
+
+func (v *VirtualMachine) tags() []string {
+	return v.Data.Spec.Tags
+}
+
+// resolve looks up the cluster, the role, the platform and the tenant
+func (v *VirtualMachine) resolve(ctx context.Context) error {
+	spec := v.Data.Spec
+
+	clusterID, err := v.lookupNameToID(ctx, spec.Cluster, "cluster")
+	if err != nil {
+		return err
+	}
+	v.clusterID = clusterID
+
+	for _, ref := range []struct {
+		name string
+		t    string
+		id   **int64
+	}{
+		{spec.Role, "role", &v.roleID},
+		{spec.Platform, "platform", &v.platformID},
+		{spec.Tenant, "tenant", &v.tenantID},
+	} {
+		if ref.name == "" {
+			continue
+		}
+		id, err := v.resolveNameToID(ctx, ref.name, ref.t)
+		if err != nil {
+			return err
+		}
+		*ref.id = &id
+	}
+	return nil
+}
+
+func (v *VirtualMachine) read(ctx context.Context, id int64) (*current, error) {
+	vm, err := v.Client.Virtualization.VirtualizationVirtualMachinesRead(&virtualization.VirtualizationVirtualMachinesReadParams{
+		ID:      id,
+		Context: ctx,
+	}, nil)
+	if IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to VirtualizationVirtualMachinesRead, %w", err)
+	}
+
+	return virtualMachineCurrent(vm.GetPayload()), nil
+}
+
+func virtualMachineCurrent(vm *models.VirtualMachineWithConfigContext) *current {
+	return &current{ID: vm.ID, Tags: vm.Tags, model: vm}
+}
+
+// lookup returns the virtual machine of the cluster with the same name
+func (v *VirtualMachine) lookup(ctx context.Context) (*current, error) {
+	name := v.Data.VirtualMachineName()
+	cluster := strconv.FormatInt(v.clusterID, 10)
+	result, err := v.Client.Virtualization.VirtualizationVirtualMachinesList(&virtualization.VirtualizationVirtualMachinesListParams{
+		Name:      &name,
+		ClusterID: &cluster,
+		Context:   ctx,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to VirtualizationVirtualMachinesList, %w", err)
+	}
+	if *result.Payload.Count == 0 {
+		return nil, nil
+	}
+	if *result.Payload.Count != 1 {
+		return nil, fmt.Errorf("unexpected number of virtual machines %q found: %d", name, *result.Payload.Count)
+	}
+	return virtualMachineCurrent(result.Payload.Results[0]), nil
+}
+
+func (v *VirtualMachine) diff(cur *current) []string {
+	vm := cur.model.(*models.VirtualMachineWithConfigContext)
+	spec := v.Data.Spec
+
+	changed := []string{}
+	if vm.Name == nil || *vm.Name != v.Data.VirtualMachineName() {
+		changed = append(changed, "name")
+	}
+	if vm.Cluster == nil || vm.Cluster.ID != v.clusterID {
+		changed = append(changed, "cluster")
+	}
+	if spec.Status != "" && (vm.Status == nil || vm.Status.Value == nil || *vm.Status.Value != spec.Status) {
+		changed = append(changed, "status")
+	}
+	if v.roleID != nil && (vm.Role == nil || vm.Role.ID != *v.roleID) {
+		changed = append(changed, "role")
+	}
+	if v.platformID != nil && (vm.Platform == nil || vm.Platform.ID != *v.platformID) {
+		changed = append(changed, "platform")
+	}
+	if v.tenantID != nil && (vm.Tenant == nil || vm.Tenant.ID != *v.tenantID) {
+		changed = append(changed, "tenant")
+	}
+	if spec.VCPUs != 0 && (vm.Vcpus == nil || *vm.Vcpus != float64(spec.VCPUs)) {
+		changed = append(changed, "vcpus")
+	}
+	if spec.Memory != 0 && (vm.Memory == nil || *vm.Memory != spec.Memory) {
+		changed = append(changed, "memory")
+	}
+	if spec.Disk != 0 && (vm.Disk == nil || *vm.Disk != spec.Disk) {
+		changed = append(changed, "disk")
+	}
+	return changed
+}
+
+// writable maps the spec to the writable virtual machine. The site is derived from the
+// cluster by Netbox, resources that are not part of the spec are copied from the existing
+// virtual machine so that they stay unchanged
+func (v *VirtualMachine) writable(cur *models.VirtualMachineWithConfigContext, tags []*models.NestedTag) *models.WritableVirtualMachineWithConfigContext {
+	spec := v.Data.Spec
+	name := v.Data.VirtualMachineName()
+	vm := &models.WritableVirtualMachineWithConfigContext{
+		Name:     &name,
+		Cluster:  &v.clusterID,
+		Status:   spec.Status,
+		Role:     v.roleID,
+		Platform: v.platformID,
+		Tenant:   v.tenantID,
+		Tags:     tags,
+	}
+	if spec.VCPUs != 0 {
+		vcpus := float64(spec.VCPUs)
+		vm.Vcpus = &vcpus
+	}
+	if spec.Memory != 0 {
+		vm.Memory = &spec.Memory
+	}
+	if spec.Disk != 0 {
+		vm.Disk = &spec.Disk
+	}
+	if cur != nil {
+		if vm.Vcpus == nil {
+			vm.Vcpus = cur.Vcpus
+		}
+		if vm.Memory == nil {
+			vm.Memory = cur.Memory
+		}
+		if vm.Disk == nil {
+			vm.Disk = cur.Disk
+		}
+	}
+	return vm
+}
+
+func (v *VirtualMachine) create(ctx context.Context, tags []*models.NestedTag) (int64, error) {
+	log := logr.FromContext(ctx)
+
+	vm, err := v.Client.Virtualization.VirtualizationVirtualMachinesCreate(&virtualization.VirtualizationVirtualMachinesCreateParams{
+		Data:    v.writable(nil, tags),
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to VirtualizationVirtualMachinesCreate, %w", err)
+	}
+	log.V(1).Info("created virtual machine", "response", vm)
+
+	return vm.GetPayload().ID, nil
+}
+
+func (v *VirtualMachine) update(ctx context.Context, cur *current, tags []*models.NestedTag) (int64, error) {
+	log := logr.FromContext(ctx)
+
+	vm, err := v.Client.Virtualization.VirtualizationVirtualMachinesUpdate(&virtualization.VirtualizationVirtualMachinesUpdateParams{
+		Data:    v.writable(cur.model.(*models.VirtualMachineWithConfigContext), tags),
+		ID:      cur.ID,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to VirtualizationVirtualMachinesUpdate, %w", err)
+	}
+	log.V(1).Info("updated virtual machine", "response", vm)
+
+	return vm.GetPayload().ID, nil
+}
+
+func (v *VirtualMachine) each(ctx context.Context, opts ListOptions, fn func(netboxv1.Object) error) error {
+	log := logr.FromContext(ctx)
+
+	params := &virtualization.VirtualizationVirtualMachinesListParams{
+		Context: ctx,
+	}
+	if name := v.Data.VirtualMachineName(); name != "" {
+		params.Name = &name
+	}
+	if opts.Status != "" {
+		params.Status = &opts.Status
+	}
+	if opts.Query != "" {
+		params.Q = &opts.Query
+	}
+	for _, f := range []struct {
+		name  string
+		t     string
+		param **string
+	}{
+		{opts.Site, "site", &params.SiteID},
+		{opts.Tenant, "tenant", &params.TenantID},
+		{opts.Role, "role", &params.RoleID},
+	} {
+		if f.name == "" {
+			continue
+		}
+		id, err := v.resolveNameToID(ctx, f.name, f.t)
+		if err != nil {
+			return err
+		}
+		value := strconv.FormatInt(id, 10)
+		*f.param = &value
+	}
+
+	return paginate(ctx, opts.ChunkSize, opts.Limit, func(offset, limit int64) (int, bool, error) {
+		params.Offset = &offset
+		params.Limit = &limit
+
+		vms, err := v.Client.Virtualization.VirtualizationVirtualMachinesList(params, v.withQuery(opts.query()))
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to VirtualizationVirtualMachinesList, %w", err)
+		}
+		log.V(1).Info("found virtual machines", "count", vms.Payload.Count, "offset", offset)
+
+		for _, vm := range vms.Payload.Results {
+			if err := fn(virtualMachineFromModel(vm)); err != nil {
+				return 0, false, err
+			}
+		}
+
+		return len(vms.Payload.Results), vms.Payload.Next != nil, nil
+	})
+}
+
+// virtualMachineFromModel maps a Netbox virtual machine to a VirtualMachine named after the virtual machine.
+// Netbox allows fractional vCPUs, they are rounded to whole CPUs
+func virtualMachineFromModel(vm *models.VirtualMachineWithConfigContext) *netboxv1.VirtualMachine {
+	spec := netboxv1.VirtualMachineSpec{
+		Tags: tagSlugs(vm.Tags),
+	}
+	if len(spec.Tags) == 0 {
+		spec.Tags = nil
+	}
+	if vm.Name != nil {
+		spec.Name = *vm.Name
+	}
+	if vm.Cluster != nil && vm.Cluster.Name != nil {
+		spec.Cluster = *vm.Cluster.Name
+	}
+	if vm.Status != nil && vm.Status.Value != nil {
+		spec.Status = *vm.Status.Value
+	}
+	if vm.Role != nil && vm.Role.Name != nil {
+		spec.Role = *vm.Role.Name
+	}
+	if vm.Platform != nil && vm.Platform.Name != nil {
+		spec.Platform = *vm.Platform.Name
+	}
+	if vm.Tenant != nil && vm.Tenant.Name != nil {
+		spec.Tenant = *vm.Tenant.Name
+	}
+	if vm.Vcpus != nil {
+		spec.VCPUs = int64(math.Round(*vm.Vcpus))
+	}
+	if vm.Memory != nil {
+		spec.Memory = *vm.Memory
+	}
+	if vm.Disk != nil {
+		spec.Disk = *vm.Disk
+	}
+
+	id := vm.ID
+	return &netboxv1.VirtualMachine{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       netboxv1.VirtualMachineKind,
+			APIVersion: netboxv1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: netboxv1.ResourceName(spec.Name),
+		},
+		Spec: spec,
+		Status: netboxv1.ObjectStatus{
+			ID:    &id,
+			State: netboxv1.ObjectReadyState,
+		},
+	}
+}
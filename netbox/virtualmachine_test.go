@@ -0,0 +1,91 @@
+package netbox
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/go-logr/logr"
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeVirtualizationNetbox serves the virtual machine web-1 with ID 1 and its interface eth0
+// with ID 11. Created VM interfaces get ID 12 and are enabled
+func fakeVirtualizationNetbox(t *testing.T) *fakeNetbox {
+	return newFakeNetbox(t,
+		onGet("/api/virtualization/virtual-machines/", page(`{"id": 1, "name": "web-1"}`), "name", "web-1"),
+		onGet("/api/virtualization/virtual-machines/", page()),
+		onGet("/api/virtualization/interfaces/", page(`{"id": 11, "name": "eth0", "virtual_machine": {"id": 1, "name": "web-1"}}`), "virtual_machine_id", "1", "name", "eth0"),
+		onGet("/api/virtualization/interfaces/", page()),
+		onPost("/api/virtualization/interfaces/", `{"id": 12, "name": "eth1", "enabled": true, "virtual_machine": {"id": 1, "name": "web-1"}}`),
+		onPatch("/api/virtualization/interfaces/12/", `{"id": 12, "name": "eth1", "enabled": false}`),
+	)
+}
+
+func TestIPAddressVMInterface(t *testing.T) {
+	ctx := logr.NewContext(context.Background(), logr.Discard())
+
+	tests := []struct {
+		name  string
+		ref   netboxv1.VMInterfaceReference
+		want  int64
+		check func(error) bool
+	}{
+		{name: "interface", ref: netboxv1.VMInterfaceReference{VirtualMachine: "web-1", Name: "eth0"}, want: 11},
+		{name: "missing interface", ref: netboxv1.VMInterfaceReference{VirtualMachine: "web-1", Name: "eth9"}, check: IsReferenceNotFound},
+		{name: "missing virtual machine", ref: netboxv1.VMInterfaceReference{VirtualMachine: "web-2", Name: "eth0"}, check: IsReferenceNotFound},
+	}
+
+	s := fakeVirtualizationNetbox(t).netbox()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref := tt.ref
+			a := NewIPAddress(*s, &netboxv1.IPAddress{
+				Spec: netboxv1.IPAddressSpec{Address: "10.0.10.10/24", VMInterface: &ref},
+			})
+			err := a.resolve(ctx)
+			if tt.check != nil {
+				if !tt.check(err) {
+					t.Errorf("unexpected error %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			addr := a.writable(nil)
+			if addr.AssignedObjectType == nil || *addr.AssignedObjectType != vmInterfaceTermination ||
+				addr.AssignedObjectID == nil || *addr.AssignedObjectID != tt.want {
+				t.Errorf("got assigned object %v %v, want %s %d", addr.AssignedObjectType, addr.AssignedObjectID, vmInterfaceTermination, tt.want)
+			}
+		})
+	}
+}
+
+func TestVMInterfaceDisable(t *testing.T) {
+	ctx := logr.NewContext(context.Background(), logr.Discard())
+
+	f := fakeVirtualizationNetbox(t)
+
+	enabled := false
+	i := NewVMInterface(*f.netbox(), &netboxv1.VMInterface{
+		ObjectMeta: metav1.ObjectMeta{Name: "eth1"},
+		Spec:       netboxv1.VMInterfaceSpec{VirtualMachine: "web-1", Enabled: &enabled},
+	})
+	if err := i.resolve(ctx); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	id, err := i.create(ctx, nil)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if id != 12 {
+		t.Errorf("got VM interface %d, want 12", id)
+	}
+	patches := f.written(http.MethodPatch, "/api/virtualization/interfaces/12/")
+	if len(patches) != 1 || patches[0]["enabled"] != false {
+		t.Errorf("got patches %v, want enabled false", patches)
+	}
+}
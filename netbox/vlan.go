@@ -0,0 +1,411 @@
+package netbox
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	"github.com/netbox-community/go-netbox/netbox/client/ipam"
+	"github.com/netbox-community/go-netbox/netbox/models"
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type VLAN struct {
+	Data *netboxv1.VLAN
+	NetboxServer
+
+	siteID   int64
+	groupID  int64
+	tenantID *int64
+}
+
+func NewVLAN(s NetboxServer, v *netboxv1.VLAN) *VLAN {
+	return &VLAN{
+		Data:         v,
+		NetboxServer: s,
+	}
+}
+
+func (v *VLAN) resource() netboxv1.Object {
+	return v.Data
+}
+
+func (v *VLAN) typeName() string {
+	return "VLAN"
+}
+
+func (v *VLAN) path() string {
+	return "/ipam/vlans/"
+}
+
+func (v *VLAN) tags() []string {
+	return v.Data.Spec.Tags
+}
+
+// resolve looks up the site, the tenant and the VLAN group
+func (v *VLAN) resolve(ctx context.Context) error {
+	spec := v.Data.Spec
+
+	if spec.Site != "" {
+		id, err := v.resolveNameToID(ctx, spec.Site, "site")
+		if err != nil {
+			return err
+		}
+		v.siteID = id
+	}
+
+	if spec.Group != "" {
+		group, err := v.findVLANGroup(ctx, spec.Group, v.siteID)
+		if err != nil {
+			return err
+		}
+		if group == nil {
+			return &ReferenceNotFoundError{Type: "VLAN group", Name: spec.Group}
+		}
+		v.groupID = group.ID
+	}
+
+	if spec.Tenant != "" {
+		id, err := v.resolveNameToID(ctx, spec.Tenant, "tenant")
+		if err != nil {
+			return err
+		}
+		v.tenantID = &id
+	}
+	return nil
+}
+
+// findVLANs returns the VLANs with the VID, or with the name if vid is 0, that are accepted by match
+func (s *NetboxServer) findVLANs(ctx context.Context, vid int64, name string, match func(*models.VLAN) bool) ([]*models.VLAN, error) {
+	params := &ipam.IpamVlansListParams{
+		Context: ctx,
+	}
+	if vid != 0 {
+		value := strconv.FormatInt(vid, 10)
+		params.Vid = &value
+	} else {
+		params.Name = &name
+	}
+
+	vlans := []*models.VLAN{}
+	err := paginate(ctx, 0, 0, func(offset, limit int64) (int, bool, error) {
+		params.Offset = &offset
+		params.Limit = &limit
+
+		result, err := s.Client.Ipam.IpamVlansList(params, nil)
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to IpamVlansList, %w", err)
+		}
+		for _, vlan := range result.Payload.Results {
+			if match(vlan) {
+				vlans = append(vlans, vlan)
+			}
+		}
+		return len(result.Payload.Results), result.Payload.Next != nil, nil
+	})
+	return vlans, err
+}
+
+// inGroup returns a match func for the VLANs of the group, or the VLANs without a group if groupID is 0
+func inGroup(groupID int64) func(*models.VLAN) bool {
+	return func(vlan *models.VLAN) bool {
+		if groupID == 0 {
+			return vlan.Group == nil
+		}
+		return vlan.Group != nil && vlan.Group.ID == groupID
+	}
+}
+
+// inSite returns a match func for the VLANs of the site without a group, or global VLANs if siteID is 0
+func inSite(siteID int64) func(*models.VLAN) bool {
+	return func(vlan *models.VLAN) bool {
+		if vlan.Group != nil {
+			return false
+		}
+		if siteID == 0 {
+			return vlan.Site == nil
+		}
+		return vlan.Site != nil && vlan.Site.ID == siteID
+	}
+}
+
+// resolveVLAN returns the ID of the VLAN the reference refers to. Without a group, VLANs of the
+// site are preferred over global VLANs, so that the same VID in groups or other sites doesn't make
+// the reference ambiguous
+func (s *NetboxServer) resolveVLAN(ctx context.Context, ref netboxv1.VLANReference, siteID int64) (int64, error) {
+	name := vlanReferenceName(ref)
+	if ref.VID == 0 && ref.Name == "" {
+		return 0, fmt.Errorf("VLAN reference needs a vid or a name")
+	}
+
+	var vlans []*models.VLAN
+	if ref.Group != "" {
+		group, err := s.findVLANGroup(ctx, ref.Group, siteID)
+		if err != nil {
+			return 0, err
+		}
+		if group == nil {
+			return 0, &ReferenceNotFoundError{Type: "VLAN group", Name: ref.Group}
+		}
+		if vlans, err = s.findVLANs(ctx, ref.VID, ref.Name, inGroup(group.ID)); err != nil {
+			return 0, err
+		}
+	} else {
+		for _, site := range []int64{siteID, 0} {
+			var err error
+			if vlans, err = s.findVLANs(ctx, ref.VID, ref.Name, inSite(site)); err != nil {
+				return 0, err
+			}
+			if len(vlans) > 0 {
+				break
+			}
+		}
+	}
+
+	switch len(vlans) {
+	case 0:
+		return 0, &ReferenceNotFoundError{Type: "VLAN", Name: name}
+	case 1:
+		return vlans[0].ID, nil
+	default:
+		return 0, fmt.Errorf("unexpected number of VLANs %q found: %d", name, len(vlans))
+	}
+}
+
+// vlanReferenceName returns the VID or name of the referenced VLAN, prefixed by the group if set
+func vlanReferenceName(ref netboxv1.VLANReference) string {
+	name := ref.Name
+	if ref.VID != 0 {
+		name = strconv.FormatInt(ref.VID, 10)
+	}
+	if ref.Group != "" {
+		name = ref.Group + "/" + name
+	}
+	return name
+}
+
+func (v *VLAN) read(ctx context.Context, id int64) (*current, error) {
+	vlan, err := v.Client.Ipam.IpamVlansRead(&ipam.IpamVlansReadParams{
+		ID:      id,
+		Context: ctx,
+	}, nil)
+	if IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to IpamVlansRead, %w", err)
+	}
+
+	return vlanCurrent(vlan.GetPayload()), nil
+}
+
+func vlanCurrent(vlan *models.VLAN) *current {
+	return &current{ID: vlan.ID, Tags: vlan.Tags, model: vlan}
+}
+
+// lookup returns the VLAN with the same VID in the group, or in the site if the VLAN has no group
+func (v *VLAN) lookup(ctx context.Context) (*current, error) {
+	match := inSite(v.siteID)
+	if v.groupID != 0 {
+		match = inGroup(v.groupID)
+	}
+	vlans, err := v.findVLANs(ctx, v.Data.Spec.VID, "", match)
+	if err != nil {
+		return nil, err
+	}
+	switch len(vlans) {
+	case 0:
+		return nil, nil
+	case 1:
+		return vlanCurrent(vlans[0]), nil
+	default:
+		return nil, fmt.Errorf("unexpected number of VLANs %d found: %d", v.Data.Spec.VID, len(vlans))
+	}
+}
+
+func (v *VLAN) diff(cur *current) []string {
+	vlan := cur.model.(*models.VLAN)
+	spec := v.Data.Spec
+
+	changed := []string{}
+	if vlan.Vid == nil || *vlan.Vid != spec.VID {
+		changed = append(changed, "vid")
+	}
+	if vlan.Name == nil || *vlan.Name != v.Data.VLANName() {
+		changed = append(changed, "name")
+	}
+	if v.siteID != 0 && (vlan.Site == nil || vlan.Site.ID != v.siteID) {
+		changed = append(changed, "site")
+	}
+	if v.groupID != 0 && (vlan.Group == nil || vlan.Group.ID != v.groupID) {
+		changed = append(changed, "group")
+	}
+	if spec.Status != "" && (vlan.Status == nil || vlan.Status.Value == nil || *vlan.Status.Value != spec.Status) {
+		changed = append(changed, "status")
+	}
+	if v.tenantID != nil && (vlan.Tenant == nil || vlan.Tenant.ID != *v.tenantID) {
+		changed = append(changed, "tenant")
+	}
+	if spec.Description != "" && vlan.Description != spec.Description {
+		changed = append(changed, "description")
+	}
+	return changed
+}
+
+func (v *VLAN) writable(tags []*models.NestedTag) *models.WritableVLAN {
+	spec := v.Data.Spec
+	name := v.Data.VLANName()
+	vlan := &models.WritableVLAN{
+		Vid:         &spec.VID,
+		Name:        &name,
+		Status:      spec.Status,
+		Tenant:      v.tenantID,
+		Description: spec.Description,
+		Tags:        tags,
+	}
+	if v.siteID != 0 {
+		vlan.Site = &v.siteID
+	}
+	if v.groupID != 0 {
+		vlan.Group = &v.groupID
+	}
+	return vlan
+}
+
+func (v *VLAN) create(ctx context.Context, tags []*models.NestedTag) (int64, error) {
+	log := logr.FromContext(ctx)
+
+	vlan, err := v.Client.Ipam.IpamVlansCreate(&ipam.IpamVlansCreateParams{
+		Data:    v.writable(tags),
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to IpamVlansCreate, %w", err)
+	}
+	log.V(1).Info("created VLAN", "response", vlan)
+
+	return vlan.GetPayload().ID, nil
+}
+
+func (v *VLAN) update(ctx context.Context, cur *current, tags []*models.NestedTag) (int64, error) {
+	log := logr.FromContext(ctx)
+
+	vlan, err := v.Client.Ipam.IpamVlansUpdate(&ipam.IpamVlansUpdateParams{
+		Data:    v.writable(tags),
+		ID:      cur.ID,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to IpamVlansUpdate, %w", err)
+	}
+	log.V(1).Info("updated VLAN", "response", vlan)
+
+	return vlan.GetPayload().ID, nil
+}
+
+func (v *VLAN) each(ctx context.Context, opts ListOptions, fn func(netboxv1.Object) error) error {
+	log := logr.FromContext(ctx)
+
+	params := &ipam.IpamVlansListParams{
+		Context: ctx,
+	}
+	if name := v.Data.VLANName(); name != "" {
+		params.Name = &name
+	}
+	if opts.Status != "" {
+		params.Status = &opts.Status
+	}
+	if opts.Query != "" {
+		params.Q = &opts.Query
+	}
+	for _, f := range []struct {
+		name  string
+		t     string
+		param **string
+	}{
+		{opts.Site, "site", &params.SiteID},
+		{opts.Tenant, "tenant", &params.TenantID},
+	} {
+		if f.name == "" {
+			continue
+		}
+		id, err := v.resolveNameToID(ctx, f.name, f.t)
+		if err != nil {
+			return err
+		}
+		value := strconv.FormatInt(id, 10)
+		*f.param = &value
+	}
+
+	return paginate(ctx, opts.ChunkSize, opts.Limit, func(offset, limit int64) (int, bool, error) {
+		params.Offset = &offset
+		params.Limit = &limit
+
+		vlans, err := v.Client.Ipam.IpamVlansList(params, v.withQuery(opts.query()))
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to IpamVlansList, %w", err)
+		}
+		log.V(1).Info("found VLANs", "count", vlans.Payload.Count, "offset", offset)
+
+		for _, vlan := range vlans.Payload.Results {
+			if err := fn(vlanFromModel(vlan)); err != nil {
+				return 0, false, err
+			}
+		}
+
+		return len(vlans.Payload.Results), vlans.Payload.Next != nil, nil
+	})
+}
+
+// vlanFromModel maps a Netbox VLAN to a VLAN named after its group or site and its VID,
+// since VLAN names and VIDs are only unique within their group or site
+func vlanFromModel(vlan *models.VLAN) *netboxv1.VLAN {
+	spec := netboxv1.VLANSpec{
+		Description: vlan.Description,
+		Tags:        tagSlugs(vlan.Tags),
+	}
+	if len(spec.Tags) == 0 {
+		spec.Tags = nil
+	}
+	if vlan.Vid != nil {
+		spec.VID = *vlan.Vid
+	}
+	if vlan.Name != nil {
+		spec.Name = *vlan.Name
+	}
+	if vlan.Site != nil && vlan.Site.Name != nil {
+		spec.Site = *vlan.Site.Name
+	}
+	if vlan.Group != nil && vlan.Group.Name != nil {
+		spec.Group = *vlan.Group.Name
+	}
+	if vlan.Status != nil && vlan.Status.Value != nil {
+		spec.Status = *vlan.Status.Value
+	}
+	if vlan.Tenant != nil && vlan.Tenant.Name != nil {
+		spec.Tenant = *vlan.Tenant.Name
+	}
+
+	scope := spec.Group
+	if scope == "" {
+		scope = spec.Site
+	}
+
+	id := vlan.ID
+	return &netboxv1.VLAN{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       netboxv1.VLANKind,
+			APIVersion: netboxv1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: netboxv1.ResourceName(scope, "vlan", strconv.FormatInt(spec.VID, 10)),
+		},
+		Spec: spec,
+		Status: netboxv1.ObjectStatus{
+			ID:    &id,
+			State: netboxv1.ObjectReadyState,
+		},
+	}
+}
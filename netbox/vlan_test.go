@@ -0,0 +1,114 @@
+package netbox
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+)
+
+// fakeVLANNetbox serves VID 100 in group servers of site 2, in group other of site 3 and in
+// site 2 without a group, and VID 300 as a global VLAN
+func fakeVLANNetbox(t *testing.T) *fakeNetbox {
+	web := []string{
+		`{"id": 11, "vid": 100, "name": "web", "site": {"id": 2}, "group": {"id": 1, "name": "servers"}}`,
+		`{"id": 12, "vid": 100, "name": "web", "site": {"id": 3}, "group": {"id": 2, "name": "other"}}`,
+		`{"id": 13, "vid": 100, "name": "web", "site": {"id": 2}}`,
+	}
+	return newFakeNetbox(t,
+		onGet("/api/ipam/vlan-groups/", page(`{"id": 1, "name": "servers", "slug": "servers", "scope_type": "dcim.site", "scope_id": 2, "scope": {"id": 2, "name": "CITC"}}`), "name", "servers"),
+		onGet("/api/ipam/vlan-groups/", page(`{"id": 2, "name": "other", "slug": "other", "scope_type": "dcim.site", "scope_id": 3, "scope": {"id": 3, "name": "lab"}}`), "name", "other"),
+		onGet("/api/ipam/vlan-groups/", page()),
+		onGet("/api/ipam/vlans/", page(web...), "vid", "100"),
+		onGet("/api/ipam/vlans/", page(web...), "name", "web"),
+		onGet("/api/ipam/vlans/", page(`{"id": 14, "vid": 300, "name": "mgmt"}`), "vid", "300"),
+		onGet("/api/ipam/vlans/", page()),
+	)
+}
+
+func TestResolveVLAN(t *testing.T) {
+	ctx := logr.NewContext(context.Background(), logr.Discard())
+
+	tests := []struct {
+		name  string
+		ref   netboxv1.VLANReference
+		want  int64
+		check func(error) bool
+	}{
+		{name: "VID in group", ref: netboxv1.VLANReference{VID: 100, Group: "servers"}, want: 11},
+		{name: "VID in group of another site", ref: netboxv1.VLANReference{VID: 100, Group: "other"}, want: 12},
+		{name: "name in group", ref: netboxv1.VLANReference{Name: "web", Group: "servers"}, want: 11},
+		{name: "VID in site", ref: netboxv1.VLANReference{VID: 100}, want: 13},
+		{name: "global VID", ref: netboxv1.VLANReference{VID: 300}, want: 14},
+		{name: "missing VID", ref: netboxv1.VLANReference{VID: 400}, check: IsReferenceNotFound},
+		{name: "missing group", ref: netboxv1.VLANReference{VID: 100, Group: "storage"}, check: IsReferenceNotFound},
+	}
+
+	s := fakeVLANNetbox(t).netbox()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := s.resolveVLAN(ctx, tt.ref, 2)
+			if tt.check != nil {
+				if !tt.check(err) {
+					t.Errorf("unexpected error %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if id != tt.want {
+				t.Errorf("got VLAN %d, want %d", id, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindVLANGroup(t *testing.T) {
+	ctx := logr.NewContext(context.Background(), logr.Discard())
+
+	s := newFakeNetbox(t,
+		onGet("/api/ipam/vlan-groups/", page(
+			`{"id": 3, "name": "storage", "slug": "storage-citc", "scope_type": "dcim.site", "scope_id": 2}`,
+			`{"id": 4, "name": "storage", "slug": "storage-lab", "scope_type": "dcim.site", "scope_id": 3}`,
+		), "name", "storage"),
+		onGet("/api/ipam/vlan-groups/", page()),
+	).netbox()
+
+	tests := []struct {
+		name    string
+		group   string
+		siteID  int64
+		want    int64
+		wantErr bool
+	}{
+		{name: "group of the site", group: "storage", siteID: 2, want: 3},
+		{name: "missing group", group: "servers", siteID: 2},
+		{name: "several groups without a site", group: "storage", wantErr: true},
+		{name: "several groups of other sites", group: "storage", siteID: 5, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group, err := s.findVLANGroup(ctx, tt.group, tt.siteID)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got group %v", group)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			var id int64
+			if group != nil {
+				id = group.ID
+			}
+			if id != tt.want {
+				t.Errorf("got group %d, want %d", id, tt.want)
+			}
+		})
+	}
+}
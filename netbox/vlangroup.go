@@ -0,0 +1,351 @@
+package netbox
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/strfmt"
+	"github.com/netbox-community/go-netbox/netbox/models"
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// siteScope is the scope type of VLAN groups scoped to a site
+const siteScope = "dcim.site"
+
+// vlanGroup is a Netbox VLAN group. Netbox returns the scope as a nested object, which the
+// go-netbox model declares as a string and fails to decode, so the requests are built by hand
+type vlanGroup struct {
+	ID          int64  `json:"id,omitempty"`
+	Name        string `json:"name"`
+	Slug        string `json:"slug"`
+	ScopeType   string `json:"scope_type,omitempty"`
+	ScopeID     *int64 `json:"scope_id,omitempty"`
+	Description string `json:"description,omitempty"`
+	// CustomFields are only written when the group is created
+	CustomFields map[string]interface{} `json:"custom_fields,omitempty"`
+	Scope        *struct {
+		Name string `json:"name"`
+	} `json:"scope,omitempty"`
+}
+
+type vlanGroupList struct {
+	Next    *string      `json:"next"`
+	Results []*vlanGroup `json:"results"`
+}
+
+// scopedTo returns true if the group is scoped to the site, or a global group if siteID is 0
+func (g *vlanGroup) scopedTo(siteID int64) bool {
+	if siteID == 0 {
+		return g.ScopeType == ""
+	}
+	return g.ScopeType == siteScope && g.ScopeID != nil && *g.ScopeID == siteID
+}
+
+type VLANGroup struct {
+	Data *netboxv1.VLANGroup
+	NetboxServer
+
+	siteID int64
+}
+
+func NewVLANGroup(s NetboxServer, g *netboxv1.VLANGroup) *VLANGroup {
+	return &VLANGroup{
+		Data:         g,
+		NetboxServer: s,
+	}
+}
+
+func (g *VLANGroup) resource() netboxv1.Object {
+	return g.Data
+}
+
+func (g *VLANGroup) typeName() string {
+	return "VLAN group"
+}
+
+func (g *VLANGroup) path() string {
+	return "/ipam/vlan-groups/"
+}
+
+func (g *VLANGroup) tags() []string {
+	return nil
+}
+
+// untaggable implements untaggable, Netbox 3.0 VLAN groups have no tags
+func (g *VLANGroup) untaggable() {}
+
+func (g *VLANGroup) resolve(ctx context.Context) error {
+	if g.Data.Spec.Site == "" {
+		return nil
+	}
+	siteID, err := g.resolveNameToID(ctx, g.Data.Spec.Site, "site")
+	if err != nil {
+		return err
+	}
+	g.siteID = siteID
+	return nil
+}
+
+// vlanGroupRequest sends a request to the VLAN groups API and decodes the response into result.
+// The ID is added to the path if it is not 0
+func (s *NetboxServer) vlanGroupRequest(ctx context.Context, method string, id int64, query url.Values, body, result interface{}) error {
+	path := "/ipam/vlan-groups/"
+	if id != 0 {
+		path += "{id}/"
+	}
+	expected := http.StatusOK
+	if method == http.MethodPost {
+		expected = http.StatusCreated
+	}
+
+	_, err := s.Client.Transport.Submit(&runtime.ClientOperation{
+		ID:                 "ipam_vlan-groups",
+		Method:             method,
+		PathPattern:        path,
+		ProducesMediaTypes: []string{"application/json"},
+		ConsumesMediaTypes: []string{"application/json"},
+		Schemes:            []string{"http"},
+		Params: runtime.ClientRequestWriterFunc(func(r runtime.ClientRequest, _ strfmt.Registry) error {
+			if id != 0 {
+				if err := r.SetPathParam("id", strconv.FormatInt(id, 10)); err != nil {
+					return err
+				}
+			}
+			for key, values := range query {
+				if err := r.SetQueryParam(key, values...); err != nil {
+					return err
+				}
+			}
+			if body != nil {
+				return r.SetBodyParam(body)
+			}
+			return nil
+		}),
+		Reader: runtime.ClientResponseReaderFunc(func(response runtime.ClientResponse, consumer runtime.Consumer) (interface{}, error) {
+			if response.Code() != expected {
+				return nil, runtime.NewAPIError("ipam_vlan-groups", response.Message(), response.Code())
+			}
+			return nil, consumer.Consume(response.Body(), result)
+		}),
+		Context: ctx,
+	})
+	return err
+}
+
+// listVLANGroups returns the VLAN groups matching the query, following the pages of the results
+func (s *NetboxServer) listVLANGroups(ctx context.Context, query url.Values) ([]*vlanGroup, error) {
+	groups := []*vlanGroup{}
+	err := paginate(ctx, 0, 0, func(offset, limit int64) (int, bool, error) {
+		query.Set("offset", strconv.FormatInt(offset, 10))
+		query.Set("limit", strconv.FormatInt(limit, 10))
+
+		page := &vlanGroupList{}
+		if err := s.vlanGroupRequest(ctx, http.MethodGet, 0, query, nil, page); err != nil {
+			return 0, false, fmt.Errorf("failed to list VLAN groups, %w", err)
+		}
+		groups = append(groups, page.Results...)
+		return len(page.Results), page.Next != nil, nil
+	})
+	return groups, err
+}
+
+// findVLANGroup returns the VLAN group with the name, nil if it doesn't exist. Group names are
+// only unique within their scope, so groups of the site are preferred if several groups match, and
+// an error is returned if that doesn't leave a single group
+func (s *NetboxServer) findVLANGroup(ctx context.Context, name string, siteID int64) (*vlanGroup, error) {
+	groups, err := s.listVLANGroups(ctx, url.Values{"name": {name}})
+	if err != nil {
+		return nil, err
+	}
+	if len(groups) > 1 && siteID != 0 {
+		scoped := []*vlanGroup{}
+		for _, g := range groups {
+			if g.scopedTo(siteID) {
+				scoped = append(scoped, g)
+			}
+		}
+		if len(scoped) > 0 {
+			groups = scoped
+		}
+	}
+	switch len(groups) {
+	case 0:
+		return nil, nil
+	case 1:
+		return groups[0], nil
+	default:
+		return nil, fmt.Errorf("unexpected number of VLAN groups %q found: %d", name, len(groups))
+	}
+}
+
+func (g *VLANGroup) read(ctx context.Context, id int64) (*current, error) {
+	group := &vlanGroup{}
+	err := g.vlanGroupRequest(ctx, http.MethodGet, id, nil, nil, group)
+	if IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read VLAN group, %w", err)
+	}
+
+	return vlanGroupCurrent(group), nil
+}
+
+func vlanGroupCurrent(group *vlanGroup) *current {
+	return &current{ID: group.ID, Created: hasCreatedField(group.CustomFields), model: group}
+}
+
+// lookup returns the VLAN group with the same name and scope
+func (g *VLANGroup) lookup(ctx context.Context) (*current, error) {
+	groups, err := g.listVLANGroups(ctx, url.Values{"name": {g.Data.VLANGroupName()}})
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range groups {
+		if group.scopedTo(g.siteID) {
+			return vlanGroupCurrent(group), nil
+		}
+	}
+	return nil, nil
+}
+
+func (g *VLANGroup) slug() string {
+	if g.Data.Spec.Slug != "" {
+		return g.Data.Spec.Slug
+	}
+	return slugify(g.Data.VLANGroupName())
+}
+
+func (g *VLANGroup) diff(cur *current) []string {
+	group := cur.model.(*vlanGroup)
+	spec := g.Data.Spec
+
+	changed := []string{}
+	if group.Name != g.Data.VLANGroupName() {
+		changed = append(changed, "name")
+	}
+	if group.Slug != g.slug() {
+		changed = append(changed, "slug")
+	}
+	if g.siteID != 0 && !group.scopedTo(g.siteID) {
+		changed = append(changed, "scope")
+	}
+	if spec.Description != "" && group.Description != spec.Description {
+		changed = append(changed, "description")
+	}
+	return changed
+}
+
+// writable maps the spec to the VLAN group. Groups without a site keep their scope on updates
+func (g *VLANGroup) writable() *vlanGroup {
+	group := &vlanGroup{
+		Name:        g.Data.VLANGroupName(),
+		Slug:        g.slug(),
+		Description: g.Data.Spec.Description,
+	}
+	if g.siteID != 0 {
+		group.ScopeType = siteScope
+		group.ScopeID = &g.siteID
+	}
+	return group
+}
+
+func (g *VLANGroup) create(ctx context.Context, _ []*models.NestedTag) (int64, error) {
+	log := logr.FromContext(ctx)
+
+	data := g.writable()
+	data.CustomFields = createdCustomFields()
+
+	group := &vlanGroup{}
+	if err := g.vlanGroupRequest(ctx, http.MethodPost, 0, nil, data, group); err != nil {
+		return 0, fmt.Errorf("failed to create VLAN group, %w", err)
+	}
+	log.V(1).Info("created VLAN group", "response", group)
+
+	return group.ID, nil
+}
+
+func (g *VLANGroup) update(ctx context.Context, cur *current, _ []*models.NestedTag) (int64, error) {
+	log := logr.FromContext(ctx)
+
+	group := &vlanGroup{}
+	if err := g.vlanGroupRequest(ctx, http.MethodPut, cur.ID, nil, g.writable(), group); err != nil {
+		return 0, fmt.Errorf("failed to update VLAN group, %w", err)
+	}
+	log.V(1).Info("updated VLAN group", "response", group)
+
+	return group.ID, nil
+}
+
+func (g *VLANGroup) each(ctx context.Context, opts ListOptions, fn func(netboxv1.Object) error) error {
+	log := logr.FromContext(ctx)
+
+	query := opts.query()
+	if name := g.Data.VLANGroupName(); name != "" {
+		query.Set("name", name)
+	}
+	if opts.Query != "" {
+		query.Set("q", opts.Query)
+	}
+	if opts.Site != "" {
+		id, err := g.resolveNameToID(ctx, opts.Site, "site")
+		if err != nil {
+			return err
+		}
+		query.Set("scope_type", siteScope)
+		query.Set("scope_id", strconv.FormatInt(id, 10))
+	}
+
+	return paginate(ctx, opts.ChunkSize, opts.Limit, func(offset, limit int64) (int, bool, error) {
+		query.Set("offset", strconv.FormatInt(offset, 10))
+		query.Set("limit", strconv.FormatInt(limit, 10))
+
+		page := &vlanGroupList{}
+		if err := g.vlanGroupRequest(ctx, http.MethodGet, 0, query, nil, page); err != nil {
+			return 0, false, fmt.Errorf("failed to list VLAN groups, %w", err)
+		}
+		log.V(1).Info("found VLAN groups", "count", len(page.Results), "offset", offset)
+
+		for _, group := range page.Results {
+			if err := fn(vlanGroupFromModel(group)); err != nil {
+				return 0, false, err
+			}
+		}
+
+		return len(page.Results), page.Next != nil, nil
+	})
+}
+
+// vlanGroupFromModel maps a Netbox VLAN group to a VLANGroup named after the slug of the group
+func vlanGroupFromModel(group *vlanGroup) *netboxv1.VLANGroup {
+	spec := netboxv1.VLANGroupSpec{
+		Name:        group.Name,
+		Slug:        group.Slug,
+		Description: group.Description,
+	}
+	if group.ScopeType == siteScope && group.Scope != nil {
+		spec.Site = group.Scope.Name
+	}
+
+	id := group.ID
+	return &netboxv1.VLANGroup{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       netboxv1.VLANGroupKind,
+			APIVersion: netboxv1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: netboxv1.ResourceName(spec.Slug),
+		},
+		Spec: spec,
+		Status: netboxv1.ObjectStatus{
+			ID:    &id,
+			State: netboxv1.ObjectReadyState,
+		},
+	}
+}
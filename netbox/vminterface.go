@@ -0,0 +1,301 @@
+package netbox
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/netbox-community/go-netbox/netbox/client/virtualization"
+	"github.com/netbox-community/go-netbox/netbox/models"
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type VMInterface struct {
+	Data *netboxv1.VMInterface
+	NetboxServer
+
+	vmID int64
+}
+
+func NewVMInterface(s NetboxServer, i *netboxv1.VMInterface) *VMInterface {
+	return &VMInterface{
+		Data:         i,
+		NetboxServer: s,
+	}
+}
+
+func (i *VMInterface) resource() netboxv1.Object {
+	return i.Data
+}
+
+func (i *VMInterface) typeName() string {
+	return "VM interface"
+}
+
+func (i *VMInterface) path() string {
+	return "/virtualization/interfaces/"
+}
+
+func (i *VMInterface) tags() []string {
+	return i.Data.Spec.Tags
+}
+
+// resolve looks up the virtual machine
+func (i *VMInterface) resolve(ctx context.Context) error {
+	id, err := i.lookupNameToID(ctx, i.Data.Spec.VirtualMachine, "virtual machine")
+	if err != nil {
+		return err
+	}
+	i.vmID = id
+	return nil
+}
+
+func (i *VMInterface) read(ctx context.Context, id int64) (*current, error) {
+	intf, err := i.Client.Virtualization.VirtualizationInterfacesRead(&virtualization.VirtualizationInterfacesReadParams{
+		ID:      id,
+		Context: ctx,
+	}, nil)
+	if IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to VirtualizationInterfacesRead, %w", err)
+	}
+
+	return vmInterfaceCurrent(intf.GetPayload()), nil
+}
+
+func vmInterfaceCurrent(intf *models.VMInterface) *current {
+	return &current{ID: intf.ID, Tags: intf.Tags, model: intf}
+}
+
+// hosts implements attached, VM interfaces belong to the tenant of their virtual machine
+func (i *VMInterface) hosts(cur *current) []host {
+	hosts := []host{{typ: "virtual machine", name: i.Data.Spec.VirtualMachine}}
+	if cur != nil {
+		if vm := cur.model.(*models.VMInterface).VirtualMachine; vm != nil && vm.Name != nil {
+			hosts = append(hosts, host{typ: "virtual machine", name: *vm.Name})
+		}
+	}
+	return hosts
+}
+
+// lookup returns the interface of the virtual machine with the same name
+func (i *VMInterface) lookup(ctx context.Context) (*current, error) {
+	intf, err := i.findVMInterface(ctx, i.vmID, i.Data.VMInterfaceName())
+	if err != nil || intf == nil {
+		return nil, err
+	}
+	return vmInterfaceCurrent(intf), nil
+}
+
+// findVMInterface returns the interface of the virtual machine with the name, nil if it doesn't exist
+func (s *NetboxServer) findVMInterface(ctx context.Context, vmID int64, name string) (*models.VMInterface, error) {
+	vm := strconv.FormatInt(vmID, 10)
+	result, err := s.Client.Virtualization.VirtualizationInterfacesList(&virtualization.VirtualizationInterfacesListParams{
+		VirtualMachineID: &vm,
+		Name:             &name,
+		Context:          ctx,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to VirtualizationInterfacesList, %w", err)
+	}
+	if *result.Payload.Count == 0 {
+		return nil, nil
+	}
+	if *result.Payload.Count != 1 {
+		return nil, fmt.Errorf("unexpected number of VM interfaces %q found: %d", name, *result.Payload.Count)
+	}
+	return result.Payload.Results[0], nil
+}
+
+func (i *VMInterface) diff(cur *current) []string {
+	intf := cur.model.(*models.VMInterface)
+	spec := i.Data.Spec
+
+	changed := []string{}
+	if intf.Name == nil || *intf.Name != i.Data.VMInterfaceName() {
+		changed = append(changed, "name")
+	}
+	if intf.VirtualMachine == nil || intf.VirtualMachine.ID != i.vmID {
+		changed = append(changed, "virtual_machine")
+	}
+	if spec.Enabled != nil && intf.Enabled != *spec.Enabled {
+		changed = append(changed, "enabled")
+	}
+	if spec.MTU != 0 && (intf.Mtu == nil || *intf.Mtu != spec.MTU) {
+		changed = append(changed, "mtu")
+	}
+	if spec.MACAddress != "" && (intf.MacAddress == nil || !strings.EqualFold(*intf.MacAddress, spec.MACAddress)) {
+		changed = append(changed, "mac_address")
+	}
+	if spec.Description != "" && intf.Description != spec.Description {
+		changed = append(changed, "description")
+	}
+	return changed
+}
+
+// writable maps the spec to the writable interface. The 802.1Q mode and VLANs are not part
+// of the spec and are copied from the existing interface so that they stay unchanged
+func (i *VMInterface) writable(cur *models.VMInterface, tags []*models.NestedTag) *models.WritableVMInterface {
+	spec := i.Data.Spec
+	name := i.Data.VMInterfaceName()
+	intf := &models.WritableVMInterface{
+		VirtualMachine: &i.vmID,
+		Name:           &name,
+		Enabled:        spec.Enabled == nil || *spec.Enabled,
+		Description:    spec.Description,
+		TaggedVlans:    []int64{},
+		Tags:           tags,
+	}
+	if spec.MTU != 0 {
+		intf.Mtu = &spec.MTU
+	}
+	if spec.MACAddress != "" {
+		intf.MacAddress = &spec.MACAddress
+	}
+	if cur != nil {
+		if spec.Enabled == nil {
+			intf.Enabled = cur.Enabled
+		}
+		if cur.Mode != nil && cur.Mode.Value != nil {
+			intf.Mode = *cur.Mode.Value
+		}
+		if cur.UntaggedVlan != nil {
+			intf.UntaggedVlan = &cur.UntaggedVlan.ID
+		}
+		intf.TaggedVlans = vlanIDs(cur.TaggedVlans)
+	}
+	return intf
+}
+
+// disable turns off the interface if the spec does. The writable model omits false values,
+// which leaves the Netbox default or the current value in place
+func (i *VMInterface) disable(ctx context.Context, intf *models.VMInterface) error {
+	if i.Data.Spec.Enabled == nil || *i.Data.Spec.Enabled || !intf.Enabled {
+		return nil
+	}
+	if err := i.patchObject(ctx, i.path(), intf.ID, map[string]interface{}{"enabled": false}); err != nil {
+		return fmt.Errorf("failed to disable VM interface %d, %w", intf.ID, err)
+	}
+	return nil
+}
+
+func (i *VMInterface) create(ctx context.Context, tags []*models.NestedTag) (int64, error) {
+	log := logr.FromContext(ctx)
+
+	intf, err := i.Client.Virtualization.VirtualizationInterfacesCreate(&virtualization.VirtualizationInterfacesCreateParams{
+		Data:    i.writable(nil, tags),
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to VirtualizationInterfacesCreate, %w", err)
+	}
+	log.V(1).Info("created VM interface", "response", intf)
+
+	if err := i.disable(ctx, intf.GetPayload()); err != nil {
+		return 0, err
+	}
+	return intf.GetPayload().ID, nil
+}
+
+func (i *VMInterface) update(ctx context.Context, cur *current, tags []*models.NestedTag) (int64, error) {
+	log := logr.FromContext(ctx)
+
+	intf, err := i.Client.Virtualization.VirtualizationInterfacesUpdate(&virtualization.VirtualizationInterfacesUpdateParams{
+		Data:    i.writable(cur.model.(*models.VMInterface), tags),
+		ID:      cur.ID,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to VirtualizationInterfacesUpdate, %w", err)
+	}
+	log.V(1).Info("updated VM interface", "response", intf)
+
+	if err := i.disable(ctx, intf.GetPayload()); err != nil {
+		return 0, err
+	}
+	return intf.GetPayload().ID, nil
+}
+
+func (i *VMInterface) each(ctx context.Context, opts ListOptions, fn func(netboxv1.Object) error) error {
+	log := logr.FromContext(ctx)
+
+	params := &virtualization.VirtualizationInterfacesListParams{
+		Context: ctx,
+	}
+	if i.Data.Spec.VirtualMachine != "" {
+		params.VirtualMachine = &i.Data.Spec.VirtualMachine
+	}
+	if name := i.Data.VMInterfaceName(); name != "" {
+		params.Name = &name
+	}
+	if opts.Query != "" {
+		params.Q = &opts.Query
+	}
+
+	return paginate(ctx, opts.ChunkSize, opts.Limit, func(offset, limit int64) (int, bool, error) {
+		params.Offset = &offset
+		params.Limit = &limit
+
+		intfs, err := i.Client.Virtualization.VirtualizationInterfacesList(params, i.withQuery(opts.query()))
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to VirtualizationInterfacesList, %w", err)
+		}
+		log.V(1).Info("found VM interfaces", "count", intfs.Payload.Count, "offset", offset)
+
+		for _, intf := range intfs.Payload.Results {
+			if err := fn(vmInterfaceFromModel(intf)); err != nil {
+				return 0, false, err
+			}
+		}
+
+		return len(intfs.Payload.Results), intfs.Payload.Next != nil, nil
+	})
+}
+
+// vmInterfaceFromModel maps a Netbox VM interface to a VMInterface named after the virtual machine and the interface
+func vmInterfaceFromModel(intf *models.VMInterface) *netboxv1.VMInterface {
+	spec := netboxv1.VMInterfaceSpec{
+		Description: intf.Description,
+		Tags:        tagSlugs(intf.Tags),
+	}
+	if len(spec.Tags) == 0 {
+		spec.Tags = nil
+	}
+	if intf.VirtualMachine != nil && intf.VirtualMachine.Name != nil {
+		spec.VirtualMachine = *intf.VirtualMachine.Name
+	}
+	if intf.Name != nil {
+		spec.Name = *intf.Name
+	}
+	if !intf.Enabled {
+		enabled := false
+		spec.Enabled = &enabled
+	}
+	if intf.Mtu != nil {
+		spec.MTU = *intf.Mtu
+	}
+	if intf.MacAddress != nil {
+		spec.MACAddress = *intf.MacAddress
+	}
+
+	id := intf.ID
+	return &netboxv1.VMInterface{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       netboxv1.VMInterfaceKind,
+			APIVersion: netboxv1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: netboxv1.ResourceName(spec.VirtualMachine, spec.Name),
+		},
+		Spec: spec,
+		Status: netboxv1.ObjectStatus{
+			ID:    &id,
+			State: netboxv1.ObjectReadyState,
+		},
+	}
+}
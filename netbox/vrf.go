@@ -0,0 +1,325 @@
+package netbox
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	"github.com/netbox-community/go-netbox/netbox/client/ipam"
+	"github.com/netbox-community/go-netbox/netbox/models"
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type VRF struct {
+	Data *netboxv1.VRF
+	NetboxServer
+
+	tenantID *int64
+	// importIDs and exportIDs are the resolved route targets, nil if not set in the spec
+	importIDs []int64
+	exportIDs []int64
+}
+
+func NewVRF(s NetboxServer, v *netboxv1.VRF) *VRF {
+	return &VRF{
+		Data:         v,
+		NetboxServer: s,
+	}
+}
+
+func (v *VRF) resource() netboxv1.Object {
+	return v.Data
+}
+
+func (v *VRF) typeName() string {
+	return "VRF"
+}
+
+func (v *VRF) path() string {
+	return "/ipam/vrfs/"
+}
+
+func (v *VRF) tags() []string {
+	return v.Data.Spec.Tags
+}
+
+// resolve looks up the tenant and the route targets
+func (v *VRF) resolve(ctx context.Context) error {
+	spec := v.Data.Spec
+
+	if spec.Tenant != "" {
+		id, err := v.resolveNameToID(ctx, spec.Tenant, "tenant")
+		if err != nil {
+			return err
+		}
+		v.tenantID = &id
+	}
+
+	for _, f := range []struct {
+		names []string
+		ids   *[]int64
+	}{
+		{spec.ImportTargets, &v.importIDs},
+		{spec.ExportTargets, &v.exportIDs},
+	} {
+		if f.names == nil {
+			continue
+		}
+		*f.ids = []int64{}
+		for _, name := range f.names {
+			id, err := v.lookupNameToID(ctx, name, "route target")
+			if err != nil {
+				return err
+			}
+			*f.ids = append(*f.ids, id)
+		}
+	}
+	return nil
+}
+
+func (v *VRF) read(ctx context.Context, id int64) (*current, error) {
+	vrf, err := v.Client.Ipam.IpamVrfsRead(&ipam.IpamVrfsReadParams{
+		ID:      id,
+		Context: ctx,
+	}, nil)
+	if IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to IpamVrfsRead, %w", err)
+	}
+
+	return vrfCurrent(vrf.GetPayload()), nil
+}
+
+func vrfCurrent(vrf *models.VRF) *current {
+	return &current{ID: vrf.ID, Tags: vrf.Tags, model: vrf}
+}
+
+// lookup returns the VRF with the same name, and the same route distinguisher if the spec sets one
+func (v *VRF) lookup(ctx context.Context) (*current, error) {
+	name := v.Data.VRFName()
+	params := &ipam.IpamVrfsListParams{
+		Name:    &name,
+		Context: ctx,
+	}
+	if v.Data.Spec.RD != "" {
+		params.Rd = &v.Data.Spec.RD
+	}
+	result, err := v.Client.Ipam.IpamVrfsList(params, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to IpamVrfsList, %w", err)
+	}
+	if *result.Payload.Count == 0 {
+		return nil, nil
+	}
+	if *result.Payload.Count != 1 {
+		return nil, fmt.Errorf("unexpected number of VRFs %q found: %d", name, *result.Payload.Count)
+	}
+	return vrfCurrent(result.Payload.Results[0]), nil
+}
+
+func routeTargetIDs(targets []*models.NestedRouteTarget) []int64 {
+	ids := []int64{}
+	for _, target := range targets {
+		ids = append(ids, target.ID)
+	}
+	return ids
+}
+
+func (v *VRF) diff(cur *current) []string {
+	vrf := cur.model.(*models.VRF)
+	spec := v.Data.Spec
+
+	changed := []string{}
+	if vrf.Name == nil || *vrf.Name != v.Data.VRFName() {
+		changed = append(changed, "name")
+	}
+	if spec.RD != "" && (vrf.Rd == nil || *vrf.Rd != spec.RD) {
+		changed = append(changed, "rd")
+	}
+	if v.tenantID != nil && (vrf.Tenant == nil || vrf.Tenant.ID != *v.tenantID) {
+		changed = append(changed, "tenant")
+	}
+	if spec.EnforceUnique != nil && vrf.EnforceUnique != *spec.EnforceUnique {
+		changed = append(changed, "enforce_unique")
+	}
+	if v.importIDs != nil && !sameIDs(v.importIDs, routeTargetIDs(vrf.ImportTargets)) {
+		changed = append(changed, "import_targets")
+	}
+	if v.exportIDs != nil && !sameIDs(v.exportIDs, routeTargetIDs(vrf.ExportTargets)) {
+		changed = append(changed, "export_targets")
+	}
+	if spec.Description != "" && vrf.Description != spec.Description {
+		changed = append(changed, "description")
+	}
+	return changed
+}
+
+// writable maps the spec to the writable VRF. The route targets that are not part of the spec
+// are copied from the existing VRF, since the writable model always sends them
+func (v *VRF) writable(cur *models.VRF, tags []*models.NestedTag) *models.WritableVRF {
+	spec := v.Data.Spec
+	name := v.Data.VRFName()
+	vrf := &models.WritableVRF{
+		Name:          &name,
+		Tenant:        v.tenantID,
+		EnforceUnique: spec.EnforceUnique != nil && *spec.EnforceUnique,
+		ImportTargets: v.importIDs,
+		ExportTargets: v.exportIDs,
+		Description:   spec.Description,
+		Tags:          tags,
+	}
+	if spec.RD != "" {
+		vrf.Rd = &spec.RD
+	}
+	if vrf.ImportTargets == nil {
+		vrf.ImportTargets = []int64{}
+		if cur != nil {
+			vrf.ImportTargets = routeTargetIDs(cur.ImportTargets)
+		}
+	}
+	if vrf.ExportTargets == nil {
+		vrf.ExportTargets = []int64{}
+		if cur != nil {
+			vrf.ExportTargets = routeTargetIDs(cur.ExportTargets)
+		}
+	}
+	return vrf
+}
+
+// disableEnforceUnique turns off enforce_unique if the spec does. The writable model
+// omits false values, which leaves the Netbox default or the current value in place
+func (v *VRF) disableEnforceUnique(ctx context.Context, vrf *models.VRF) error {
+	if v.Data.Spec.EnforceUnique == nil || *v.Data.Spec.EnforceUnique || !vrf.EnforceUnique {
+		return nil
+	}
+	if err := v.patchObject(ctx, v.path(), vrf.ID, map[string]interface{}{"enforce_unique": false}); err != nil {
+		return fmt.Errorf("failed to disable enforce_unique of VRF %d, %w", vrf.ID, err)
+	}
+	return nil
+}
+
+func (v *VRF) create(ctx context.Context, tags []*models.NestedTag) (int64, error) {
+	log := logr.FromContext(ctx)
+
+	vrf, err := v.Client.Ipam.IpamVrfsCreate(&ipam.IpamVrfsCreateParams{
+		Data:    v.writable(nil, tags),
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to IpamVrfsCreate, %w", err)
+	}
+	log.V(1).Info("created VRF", "response", vrf)
+
+	return vrf.GetPayload().ID, v.disableEnforceUnique(ctx, vrf.GetPayload())
+}
+
+func (v *VRF) update(ctx context.Context, cur *current, tags []*models.NestedTag) (int64, error) {
+	log := logr.FromContext(ctx)
+
+	vrf, err := v.Client.Ipam.IpamVrfsUpdate(&ipam.IpamVrfsUpdateParams{
+		Data:    v.writable(cur.model.(*models.VRF), tags),
+		ID:      cur.ID,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to IpamVrfsUpdate, %w", err)
+	}
+	log.V(1).Info("updated VRF", "response", vrf)
+
+	return vrf.GetPayload().ID, v.disableEnforceUnique(ctx, vrf.GetPayload())
+}
+
+func (v *VRF) each(ctx context.Context, opts ListOptions, fn func(netboxv1.Object) error) error {
+	log := logr.FromContext(ctx)
+
+	params := &ipam.IpamVrfsListParams{
+		Context: ctx,
+	}
+	if name := v.Data.VRFName(); name != "" {
+		params.Name = &name
+	}
+	if opts.Query != "" {
+		params.Q = &opts.Query
+	}
+	if opts.Tenant != "" {
+		id, err := v.resolveNameToID(ctx, opts.Tenant, "tenant")
+		if err != nil {
+			return err
+		}
+		tenant := strconv.FormatInt(id, 10)
+		params.TenantID = &tenant
+	}
+
+	return paginate(ctx, opts.ChunkSize, opts.Limit, func(offset, limit int64) (int, bool, error) {
+		params.Offset = &offset
+		params.Limit = &limit
+
+		vrfs, err := v.Client.Ipam.IpamVrfsList(params, v.withQuery(opts.query()))
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to IpamVrfsList, %w", err)
+		}
+		log.V(1).Info("found VRFs", "count", vrfs.Payload.Count, "offset", offset)
+
+		for _, vrf := range vrfs.Payload.Results {
+			if err := fn(vrfFromModel(vrf)); err != nil {
+				return 0, false, err
+			}
+		}
+
+		return len(vrfs.Payload.Results), vrfs.Payload.Next != nil, nil
+	})
+}
+
+func routeTargetNames(targets []*models.NestedRouteTarget) []string {
+	var names []string
+	for _, target := range targets {
+		if target.Name != nil {
+			names = append(names, *target.Name)
+		}
+	}
+	return names
+}
+
+// vrfFromModel maps a Netbox VRF to a VRF named after the VRF
+func vrfFromModel(vrf *models.VRF) *netboxv1.VRF {
+	enforceUnique := vrf.EnforceUnique
+	spec := netboxv1.VRFSpec{
+		EnforceUnique: &enforceUnique,
+		ImportTargets: routeTargetNames(vrf.ImportTargets),
+		ExportTargets: routeTargetNames(vrf.ExportTargets),
+		Description:   vrf.Description,
+		Tags:          tagSlugs(vrf.Tags),
+	}
+	if len(spec.Tags) == 0 {
+		spec.Tags = nil
+	}
+	if vrf.Name != nil {
+		spec.Name = *vrf.Name
+	}
+	if vrf.Rd != nil {
+		spec.RD = *vrf.Rd
+	}
+	if vrf.Tenant != nil && vrf.Tenant.Name != nil {
+		spec.Tenant = *vrf.Tenant.Name
+	}
+
+	id := vrf.ID
+	return &netboxv1.VRF{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       netboxv1.VRFKind,
+			APIVersion: netboxv1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: netboxv1.ResourceName(spec.Name),
+		},
+		Spec: spec,
+		Status: netboxv1.ObjectStatus{
+			ID:    &id,
+			State: netboxv1.ObjectReadyState,
+		},
+	}
+}
@@ -0,0 +1,91 @@
+package netbox
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/go-logr/logr"
+	netboxv1 "github.com/networkop/declarative-netbox/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeVRFNetbox serves VRF blue with ID 1 and the prefix 10.0.0.0/24 in the global table
+// (ID 21) and in VRF blue (ID 22). Created VRFs have enforce_unique set
+func fakeVRFNetbox(t *testing.T) *fakeNetbox {
+	return newFakeNetbox(t,
+		onGet("/api/ipam/vrfs/", page(`{"id": 1, "name": "blue"}`), "name", "blue"),
+		onGet("/api/ipam/vrfs/", page()),
+		onPost("/api/ipam/vrfs/", `{"id": 2, "name": "red", "enforce_unique": true}`),
+		onPatch("/api/ipam/vrfs/2/", `{"id": 2, "name": "red", "enforce_unique": false}`),
+		onGet("/api/ipam/prefixes/", page(
+			`{"id": 21, "prefix": "10.0.0.0/24"}`,
+			`{"id": 22, "prefix": "10.0.0.0/24", "vrf": {"id": 1, "name": "blue"}}`,
+		)),
+	)
+}
+
+func TestPrefixLookup(t *testing.T) {
+	ctx := logr.NewContext(context.Background(), logr.Discard())
+
+	tests := []struct {
+		name  string
+		vrf   string
+		want  int64
+		check func(error) bool
+	}{
+		{name: "global table", want: 21},
+		{name: "VRF", vrf: "blue", want: 22},
+		{name: "missing VRF", vrf: "green", check: IsReferenceNotFound},
+	}
+
+	s := fakeVRFNetbox(t).netbox()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPrefix(*s, &netboxv1.Prefix{
+				Spec: netboxv1.PrefixSpec{Prefix: "10.0.0.0/24", VRF: tt.vrf},
+			})
+			err := p.resolve(ctx)
+			if tt.check != nil {
+				if !tt.check(err) {
+					t.Errorf("unexpected error %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			cur, err := p.lookup(ctx)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if cur == nil || cur.ID != tt.want {
+				t.Errorf("got prefix %v, want %d", cur, tt.want)
+			}
+		})
+	}
+}
+
+func TestVRFDisableEnforceUnique(t *testing.T) {
+	ctx := logr.NewContext(context.Background(), logr.Discard())
+
+	f := fakeVRFNetbox(t)
+
+	enforceUnique := false
+	v := NewVRF(*f.netbox(), &netboxv1.VRF{
+		ObjectMeta: metav1.ObjectMeta{Name: "red"},
+		Spec:       netboxv1.VRFSpec{EnforceUnique: &enforceUnique},
+	})
+	id, err := v.create(ctx, nil)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if id != 2 {
+		t.Errorf("got VRF %d, want 2", id)
+	}
+	patches := f.written(http.MethodPatch, "/api/ipam/vrfs/2/")
+	if len(patches) != 1 || patches[0]["enforce_unique"] != false {
+		t.Errorf("got patches %v, want enforce_unique false", patches)
+	}
+}